@@ -4,8 +4,6 @@ import (
 	"net/http"
 	"tezosign/api/response"
 	"tezosign/common/apperrors"
-	"tezosign/repos"
-	"tezosign/services"
 	"tezosign/types"
 
 	"github.com/gorilla/mux"
@@ -24,7 +22,7 @@ func (api *API) AddressIsRevealed(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	service := services.New(repos.New(networkContext.Db), repos.New(networkContext.IndexerDB), networkContext.Client, networkContext.Auth, net)
+	service := newService(r, net, networkContext)
 
 	isRevealed, err := service.AddressRevealed(address)
 	if err != nil {
@@ -50,7 +48,7 @@ func (api *API) AddressBalance(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	service := services.New(repos.New(networkContext.Db), repos.New(networkContext.IndexerDB), networkContext.Client, networkContext.Auth, net)
+	service := newService(r, net, networkContext)
 
 	balance, err := service.AddressBalance(address)
 	if err != nil {
@@ -70,7 +68,7 @@ func (api *API) AddressContracts(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	service := services.New(repos.New(networkContext.Db), repos.New(networkContext.IndexerDB), networkContext.Client, networkContext.Auth, net)
+	service := newService(r, net, networkContext)
 
 	contracts, err := service.GetAccountContracts(user)
 	if err != nil {
@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"tezosign/common/log"
 	"tezosign/conf"
@@ -32,6 +33,8 @@ type (
 		cfg          conf.Config
 		provider     NetworkContextProvider
 		queryDecoder *schema.Decoder
+		//Validated on config load
+		trustedProxies []*net.IPNet
 	}
 
 	// Route stores an API route data
@@ -54,10 +57,12 @@ type NetworkContextProvider interface {
 func NewAPI(cfg conf.Config, provider NetworkContextProvider) *API {
 	queryDecoder := schema.NewDecoder()
 	queryDecoder.IgnoreUnknownKeys(true)
+	trustedProxies, _ := cfg.API.RateLimit.TrustedProxyNets()
 	api := &API{
-		cfg:          cfg,
-		provider:     provider,
-		queryDecoder: queryDecoder,
+		cfg:            cfg,
+		provider:       provider,
+		queryDecoder:   queryDecoder,
+		trustedProxies: trustedProxies,
 	}
 	api.initialize()
 	return api
@@ -69,7 +74,8 @@ func (api *API) Run() error {
 }
 
 func (api *API) Stop() error {
-	ctx, _ := context.WithTimeout(context.Background(), gracefulTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), gracefulTimeout)
+	defer cancel()
 	return api.server.Shutdown(ctx)
 }
 
@@ -86,11 +92,14 @@ func (api *API) initialize(handlerArr ...negroni.Handler) {
 		wrapper.Use(handler)
 	}
 
+	wrapper.Use(negroni.HandlerFunc(api.RequestContext))
+
 	wrapper.Use(cors.New(cors.Options{
 		AllowedOrigins:   api.cfg.API.CORSAllowedOrigins,
 		AllowCredentials: true,
 		AllowedMethods:   []string{"POST", "GET", "OPTIONS", "PUT", "DELETE"},
-		AllowedHeaders:   []string{"Accept", "Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization", "User-Env"},
+		AllowedHeaders:   []string{"Accept", "Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization", "User-Env", RequestIDHeader},
+		ExposedHeaders:   []string{RequestIDHeader},
 	}))
 
 	//Static file
@@ -110,16 +119,25 @@ func (api *API) initialize(handlerArr ...negroni.Handler) {
 	//public routes
 	HandleActions(api.router, wrapper, actionsAPIPrefix, []*Route{
 		//Auth flow
-		{Path: "/{network}/auth/request", Method: http.MethodPost, Func: api.AuthRequest, Middleware: mw},
-		{Path: "/{network}/auth", Method: http.MethodPost, Func: api.Auth, Middleware: mw},
-		{Path: "/{network}/auth/refresh", Method: http.MethodPost, Func: api.RefreshAuth, Middleware: mw},
+		{Path: "/{network}/auth/request", Method: http.MethodPost, Func: api.AuthRequest, Middleware: []negroni.HandlerFunc{api.CheckAndLoadNetwork, api.RateLimitByIP(RateLimitAuthRequest)}},
+		{Path: "/{network}/auth", Method: http.MethodPost, Func: api.Auth, Middleware: []negroni.HandlerFunc{api.CheckAndLoadNetwork, api.RateLimitByIP(RateLimitAuth)}},
+		{Path: "/{network}/auth/refresh", Method: http.MethodPost, Func: api.RefreshAuth, Middleware: []negroni.HandlerFunc{api.CheckAndLoadNetwork, api.RateLimitByIP(RateLimitAuthRefresh)}},
 		{Path: "/{network}/auth/restore", Method: http.MethodGet, Func: api.RestoreAuth, Middleware: mw},
+		{Path: "/{network}/auth/jwks", Method: http.MethodGet, Func: api.JWKS, Middleware: mw},
 		{Path: "/{network}/logout", Method: http.MethodGet, Func: api.Logout, Middleware: mw},
 		{Path: "/{network}/exchange_rates", Method: http.MethodGet, Func: api.TezosExchangeRates, Middleware: mw},
+		{Path: "/{network}/bakers", Method: http.MethodGet, Func: api.BakersList, Middleware: mw},
+		{Path: "/{network}/asset/{asset_id}/price", Method: http.MethodGet, Func: api.AssetPrice, Middleware: mw},
+		{Path: "/{network}/asset/{asset_id}/prices", Method: http.MethodGet, Func: api.AssetPriceHistory, Middleware: mw},
 
 		{Path: "/{network}/{address}/revealed", Method: http.MethodGet, Func: api.AddressIsRevealed, Middleware: mw},
 		{Path: "/{network}/origination/{tx_id}", Method: http.MethodGet, Func: api.ContractOrigination, Middleware: mw},
 		{Path: "/{network}/{address}/balance", Method: http.MethodGet, Func: api.AddressBalance, Middleware: mw},
+
+		//Converter
+		{Path: "/{network}/convert/pack", Method: http.MethodPost, Func: api.ConvertPack, Middleware: []negroni.HandlerFunc{api.CheckAndLoadNetwork, api.RateLimitByIP(RateLimitConverter)}},
+		{Path: "/{network}/convert/unpack", Method: http.MethodPost, Func: api.ConvertUnpack, Middleware: []negroni.HandlerFunc{api.CheckAndLoadNetwork, api.RateLimitByIP(RateLimitConverter)}},
+		{Path: "/{network}/convert/michelson", Method: http.MethodPost, Func: api.ConvertMichelson, Middleware: []negroni.HandlerFunc{api.CheckAndLoadNetwork, api.RateLimitByIP(RateLimitConverter)}},
 	})
 
 	mw = []negroni.HandlerFunc{
@@ -128,14 +146,24 @@ func (api *API) initialize(handlerArr ...negroni.Handler) {
 	}
 
 	HandleActions(api.router, wrapper, actionsAPIPrefix, []*Route{
+		//Cache counters
+		{Path: "/{network}/cache/stats", Method: http.MethodGet, Func: api.CacheStats, Middleware: mw},
+
+		//Sessions
+		{Path: "/{network}/sessions", Method: http.MethodGet, Func: api.SessionsList, Middleware: mw},
+		{Path: "/{network}/sessions/revoke_others", Method: http.MethodPost, Func: api.RevokeOtherSessions, Middleware: mw},
+		{Path: "/{network}/session/{session_id}/revoke", Method: http.MethodPost, Func: api.RevokeSession, Middleware: mw},
+
 		//Get contracts by pubkey
 		{Path: "/{network}/contracts", Method: http.MethodGet, Func: api.AddressContracts, Middleware: mw},
 		//Init contract storage
 		{Path: "/{network}/contract/storage/init", Method: http.MethodPost, Func: api.ContractStorageInit, Middleware: mw},
 		//Get contract info
 		{Path: "/{network}/contract/{contract_id}/info", Method: http.MethodGet, Func: api.ContractInfo, Middleware: mw},
+		//Entrypoints parameters JSON schema for contract_call action
+		{Path: "/{network}/contract/{contract_id}/entrypoints", Method: http.MethodGet, Func: api.ContractEntrypoints, Middleware: mw},
 		//Create operation
-		{Path: "/{network}/contract/operation", Method: http.MethodPost, Func: api.ContractOperation, Middleware: mw},
+		{Path: "/{network}/contract/operation", Method: http.MethodPost, Func: api.ContractOperation, Middleware: []negroni.HandlerFunc{api.CheckAndLoadNetwork, api.RequireJWT, api.RateLimitByPubKey(RateLimitContractOperation)}},
 
 		//Build payload by operation
 		{Path: "/{network}/contract/operation/{operation_id}/payload", Method: http.MethodGet, Func: api.OperationSignPayload, Middleware: mw},
@@ -152,11 +180,21 @@ func (api *API) initialize(handlerArr ...negroni.Handler) {
 		{Path: "/{network}/contract/{contract_id}/assets_rates", Method: http.MethodGet, Func: api.AssetsExchangeRates, Middleware: mw},
 		//Get asset metadata
 		{Path: "/{network}/asset/{asset_id}/meta_data", Method: http.MethodGet, Func: api.AssetMetadata, Middleware: mw},
+		//Contract portfolio valuation
+		{Path: "/{network}/contract/{contract_id}/portfolio", Method: http.MethodGet, Func: api.Portfolio, Middleware: mw},
 
 		//Vesting contract
 		{Path: "/{network}/contract/vesting/storage/init", Method: http.MethodPost, Func: api.VestingContractStorageInit, Middleware: mw},
 		//Vesting contract info
 		{Path: "/{network}/contract/vesting/{vesting_contract_id}/info", Method: http.MethodGet, Func: api.VestingContractInfo, Middleware: mw},
+		//Vesting unlock schedule projection
+		{Path: "/{network}/contract/vesting/{vesting_contract_id}/schedule", Method: http.MethodGet, Func: api.VestingSchedule, Middleware: mw},
+		//Vesting unlock milestones calendar
+		{Path: "/{network}/contract/vesting/{vesting_contract_id}/schedule.ics", Method: http.MethodGet, Func: api.VestingScheduleCalendar, Middleware: mw},
+		//Vesting contract vest and setDelegate calls
+		{Path: "/{network}/contract/vesting/{vesting_contract_id}/history", Method: http.MethodGet, Func: api.VestingHistory, Middleware: mw},
+		//On-chain vested counter against recorded vest calls
+		{Path: "/{network}/contract/vesting/{vesting_contract_id}/reconciliation", Method: http.MethodGet, Func: api.VestingReconciliation, Middleware: mw},
 		//Direct vesting contract call
 		{Path: "/{network}/contract/vesting/operation", Method: http.MethodPost, Func: api.VestingContractOperation, Middleware: mw},
 		//Get contract vestings list
@@ -172,7 +210,7 @@ func (api *API) initialize(handlerArr ...negroni.Handler) {
 	//Endpoints with contract owner restriction
 	HandleActions(api.router, wrapper, actionsAPIPrefix, []*Route{
 		//Create update storage operation
-		{Path: "/{network}/contract/{contract_id}/storage/update", Method: http.MethodPost, Func: api.ContractStorageUpdate, Middleware: mw},
+		{Path: "/{network}/contract/{contract_id}/storage/update", Method: http.MethodPost, Func: api.ContractStorageUpdate, Middleware: []negroni.HandlerFunc{api.CheckAndLoadNetwork, api.RequireJWT, api.RateLimitByPubKey(RateLimitStorageUpdate), api.OwnerAllowance}},
 
 		//Assets
 		//Create contract asset
@@ -181,6 +219,15 @@ func (api *API) initialize(handlerArr ...negroni.Handler) {
 		{Path: "/{network}/contract/{contract_id}/asset/edit", Method: http.MethodPost, Func: api.ContractAssetEdit, Middleware: mw},
 		//Remove contract asset
 		{Path: "/{network}/contract/{contract_id}/asset/delete", Method: http.MethodPost, Func: api.RemoveContractAsset, Middleware: mw},
+		//Discovered assets waiting for owner decision
+		{Path: "/{network}/contract/{contract_id}/assets/suggestions", Method: http.MethodGet, Func: api.AssetSuggestions, Middleware: mw},
+		//Accept discovered asset
+		{Path: "/{network}/contract/{contract_id}/assets/suggestion/accept", Method: http.MethodPost, Func: api.AcceptAssetSuggestion, Middleware: mw},
+		//Dismiss discovered asset
+		{Path: "/{network}/contract/{contract_id}/assets/suggestion/dismiss", Method: http.MethodPost, Func: api.DismissAssetSuggestion, Middleware: mw},
+
+		//Sampled total value including vestings
+		{Path: "/{network}/contract/{contract_id}/portfolio/history", Method: http.MethodGet, Func: api.PortfolioHistory, Middleware: mw},
 
 		//Vesting
 		//Add vesting contract
@@ -189,6 +236,12 @@ func (api *API) initialize(handlerArr ...negroni.Handler) {
 		{Path: "/{network}/contract/{contract_id}/vesting/edit", Method: http.MethodPost, Func: api.ContractVestingEdit, Middleware: mw},
 		//Remove contract asset
 		{Path: "/{network}/contract/{contract_id}/vesting/delete", Method: http.MethodPost, Func: api.RemoveContractVesting, Middleware: mw},
+		//Vesting auto claim rules
+		{Path: "/{network}/contract/{contract_id}/vesting/claim_rules", Method: http.MethodGet, Func: api.VestingClaimRules, Middleware: mw},
+		//Create or replace vesting auto claim rule
+		{Path: "/{network}/contract/{contract_id}/vesting/claim_rule", Method: http.MethodPost, Func: api.VestingClaimRule, Middleware: mw},
+		//Remove vesting auto claim rule
+		{Path: "/{network}/contract/{contract_id}/vesting/claim_rule/delete", Method: http.MethodPost, Func: api.RemoveVestingClaimRule, Middleware: mw},
 	})
 
 	api.server = &http.Server{Addr: fmt.Sprintf(":%d", api.cfg.API.ListenOnPort), Handler: api.router}
@@ -2,7 +2,6 @@ package api
 
 import (
 	"encoding/json"
-	"strconv"
 	"tezosign/common/log"
 
 	"go.uber.org/zap"
@@ -11,8 +10,6 @@ import (
 	"tezosign/api/response"
 	"tezosign/common/apperrors"
 	"tezosign/models"
-	"tezosign/repos"
-	"tezosign/services"
 	"tezosign/types"
 
 	"github.com/gorilla/mux"
@@ -32,14 +29,14 @@ func (api *API) AssetsList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	service := services.New(repos.New(networkContext.Db), repos.New(networkContext.IndexerDB), networkContext.Client, networkContext.Auth, net)
+	service := newService(r, net, networkContext).WithConfig(api.cfg)
 
 	assets, err := service.AssetsList(user, contractAddress)
 	if err != nil {
 		//Unwrap apperror
 		err, IsAppErr := apperrors.Unwrap(err)
 		if !IsAppErr {
-			log.Error("AssetsList error: ", zap.Error(err))
+			log.Ctx(r.Context()).Error("AssetsList error: ", zap.Error(err))
 		}
 
 		response.JsonError(w, err)
@@ -63,7 +60,7 @@ func (api *API) AssetsExchangeRates(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	service := services.New(repos.New(networkContext.Db), repos.New(networkContext.IndexerDB), networkContext.Client, networkContext.Auth, net)
+	service := newService(r, net, networkContext).WithConfig(api.cfg)
 
 	assets, err := service.AssetsExchangeRates(user, contractAddress)
 	if err != nil {
@@ -95,19 +92,19 @@ func (api *API) ContractAsset(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err = data.Validate(); err != nil {
+	if err = data.ValidateToken(); err != nil {
 		response.JsonError(w, apperrors.New(apperrors.ErrBadRequest, err.Error()))
 		return
 	}
 
-	service := services.New(repos.New(networkContext.Db), repos.New(networkContext.IndexerDB), networkContext.Client, networkContext.Auth, net)
+	service := newService(r, net, networkContext).WithConfig(api.cfg)
 
 	reps, err := service.ContractAsset(user, contractAddress, data)
 	if err != nil {
 		//Unwrap apperror
 		err, IsAppErr := apperrors.Unwrap(err)
 		if !IsAppErr {
-			log.Error("ContractAsset error: ", zap.Error(err))
+			log.Ctx(r.Context()).Error("ContractAsset error: ", zap.Error(err))
 		}
 
 		response.JsonError(w, err)
@@ -143,14 +140,14 @@ func (api *API) ContractAssetEdit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	service := services.New(repos.New(networkContext.Db), repos.New(networkContext.IndexerDB), networkContext.Client, networkContext.Auth, net)
+	service := newService(r, net, networkContext).WithConfig(api.cfg)
 
 	reps, err := service.ContractAssetEdit(user, contractAddress, data)
 	if err != nil {
 		//Unwrap apperror
 		err, IsAppErr := apperrors.Unwrap(err)
 		if !IsAppErr {
-			log.Error("ContractAssetEdit error: ", zap.Error(err))
+			log.Ctx(r.Context()).Error("ContractAssetEdit error: ", zap.Error(err))
 		}
 
 		response.JsonError(w, err)
@@ -186,14 +183,14 @@ func (api *API) RemoveContractAsset(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	service := services.New(repos.New(networkContext.Db), repos.New(networkContext.IndexerDB), networkContext.Client, networkContext.Auth, net)
+	service := newService(r, net, networkContext).WithConfig(api.cfg)
 
 	err = service.RemoveContractAsset(user, contractAddress, data)
 	if err != nil {
 		//Unwrap apperror
 		err, IsAppErr := apperrors.Unwrap(err)
 		if !IsAppErr {
-			log.Error("RemoveContractAsset error: ", zap.Error(err))
+			log.Ctx(r.Context()).Error("RemoveContractAsset error: ", zap.Error(err))
 		}
 
 		response.JsonError(w, err)
@@ -210,30 +207,20 @@ func (api *API) AssetMetadata(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	assetID := types.Address(mux.Vars(r)["asset_id"])
-	if err := assetID.Validate(); err != nil {
-		response.JsonError(w, apperrors.New(apperrors.ErrBadParam, "asset_id"))
+	assetID, tokenID, err := assetTokenParams(r)
+	if err != nil {
+		response.JsonError(w, err)
 		return
 	}
 
-	var tokenID int64
-	value := r.URL.Query().Get("token_id")
-	if len(value) != 0 {
-		tokenID, err = strconv.ParseInt(value, 10, 64)
-		if err != nil {
-			response.JsonError(w, apperrors.New(apperrors.ErrBadParam, "token_id"))
-			return
-		}
-	}
-
-	service := services.New(repos.New(networkContext.Db), repos.New(networkContext.IndexerDB), networkContext.Client, networkContext.Auth, net)
+	service := newService(r, net, networkContext).WithConfig(api.cfg)
 
 	resp, err := service.GetAssetMetadata(assetID, tokenID)
 	if err != nil {
 		//Unwrap apperror
 		err, IsAppErr := apperrors.Unwrap(err)
 		if !IsAppErr {
-			log.Error("GetAssetMetadata error: ", zap.Error(err))
+			log.Ctx(r.Context()).Error("GetAssetMetadata error: ", zap.Error(err))
 		}
 
 		response.JsonError(w, err)
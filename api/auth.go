@@ -8,8 +8,6 @@ import (
 	"tezosign/common/apperrors"
 	"tezosign/conf"
 	"tezosign/models"
-	"tezosign/repos"
-	"tezosign/services"
 
 	"github.com/gorilla/mux"
 )
@@ -34,9 +32,9 @@ func (api *API) AuthRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	service := services.New(repos.New(networkContext.Db), repos.New(networkContext.IndexerDB), networkContext.Client, networkContext.Auth, net)
+	service := newService(r, net, networkContext)
 
-	resp, err := service.AuthRequest(req)
+	resp, err := service.AuthRequest(req, api.cfg.API.Domain)
 	if err != nil {
 		response.JsonError(w, err)
 		return
@@ -65,9 +63,9 @@ func (api *API) Auth(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	service := services.New(repos.New(networkContext.Db), repos.New(networkContext.IndexerDB), networkContext.Client, networkContext.Auth, net)
+	service := newService(r, net, networkContext)
 
-	resp, err := service.Auth(req)
+	resp, err := service.Auth(req, api.cfg.API.Domain, api.sessionMeta(r))
 	if err != nil {
 		response.JsonError(w, err)
 		return
@@ -100,9 +98,9 @@ func (api *API) RefreshAuth(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	service := services.New(repos.New(networkContext.Db), repos.New(networkContext.IndexerDB), networkContext.Client, networkContext.Auth, net)
+	service := newService(r, net, networkContext)
 
-	resp, err := service.RefreshAuthSession(data.RefreshToken)
+	resp, err := service.RefreshAuthSession(data.RefreshToken, api.sessionMeta(r))
 	if err != nil {
 		response.JsonError(w, err)
 		return
@@ -144,6 +142,18 @@ func (api *API) RestoreAuth(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (api *API) JWKS(w http.ResponseWriter, r *http.Request) {
+	net, networkContext, err := GetNetworkContext(r)
+	if err != nil {
+		response.JsonError(w, err)
+		return
+	}
+
+	service := newService(r, net, networkContext)
+
+	response.Json(w, service.JWKS())
+}
+
 func (api *API) Logout(w http.ResponseWriter, r *http.Request) {
 	net, networkContext, err := GetNetworkContext(r)
 	if err != nil {
@@ -159,7 +169,7 @@ func (api *API) Logout(w http.ResponseWriter, r *http.Request) {
 
 	defer api.clearCookie(net, w)
 
-	service := services.New(repos.New(networkContext.Db), repos.New(networkContext.IndexerDB), networkContext.Client, networkContext.Auth, net)
+	service := newService(r, net, networkContext)
 
 	err = service.Logout(cookie.Value)
 	if err != nil {
@@ -170,6 +180,13 @@ func (api *API) Logout(w http.ResponseWriter, r *http.Request) {
 	response.Json(w, map[string]interface{}{"message": "success"})
 }
 
+func (api *API) sessionMeta(r *http.Request) models.SessionMeta {
+	return models.SessionMeta{
+		UserAgent: r.UserAgent(),
+		IP:        api.clientIP(r),
+	}
+}
+
 func getCookieName(net models.Network) string {
 	return fmt.Sprintf("%s_%s", "session", string(net))
 }
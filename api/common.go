@@ -17,3 +17,13 @@ func (api *API) Health(w http.ResponseWriter, r *http.Request) {
 		"status": true,
 	})
 }
+
+func (api *API) CacheStats(w http.ResponseWriter, r *http.Request) {
+	_, networkContext, err := GetNetworkContext(r)
+	if err != nil {
+		response.JsonError(w, err)
+		return
+	}
+
+	response.Json(w, networkContext.Cache.Stats())
+}
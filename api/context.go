@@ -5,6 +5,8 @@ import (
 	"tezosign/common/apperrors"
 	"tezosign/infrustructure"
 	"tezosign/models"
+	"tezosign/repos"
+	"tezosign/services"
 	"tezosign/types"
 )
 
@@ -40,3 +42,11 @@ func GetNetworkContext(r *http.Request) (net models.Network, networkContext infr
 
 	return net, networkContext, nil
 }
+
+//newService creates request scoped service, db queries and node calls are canceled with request
+func newService(r *http.Request, net models.Network, networkContext infrustructure.NetworkContext) *services.ServiceFacade {
+	ctx := r.Context()
+
+	return services.New(ctx, repos.New(networkContext.Db.WithContext(ctx)), repos.New(networkContext.IndexerDB.WithContext(ctx)), networkContext.Client, networkContext.Auth, net).
+		WithCache(networkContext.Cache)
+}
@@ -7,8 +7,6 @@ import (
 	"tezosign/common/apperrors"
 	"tezosign/common/log"
 	"tezosign/models"
-	"tezosign/repos"
-	"tezosign/services"
 	"tezosign/types"
 
 	"github.com/gorilla/mux"
@@ -36,14 +34,14 @@ func (api *API) ContractStorageInit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	service := services.New(repos.New(networkContext.Db), repos.New(networkContext.IndexerDB), networkContext.Client, networkContext.Auth, net)
+	service := newService(r, net, networkContext)
 
 	resp, err := service.BuildContractInitStorage(req)
 	if err != nil {
 		//Unwrap apperror
 		err, IsAppErr := apperrors.Unwrap(err)
 		if !IsAppErr {
-			log.Error("BuildContractInitStorage error: ", zap.Error(err))
+			log.Ctx(r.Context()).Error("BuildContractInitStorage error: ", zap.Error(err))
 		}
 
 		response.JsonError(w, err)
@@ -80,14 +78,14 @@ func (api *API) ContractStorageUpdate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	service := services.New(repos.New(networkContext.Db), repos.New(networkContext.IndexerDB), networkContext.Client, networkContext.Auth, net)
+	service := newService(r, net, networkContext)
 
 	resp, err := service.BuildContractStorageUpdateOperation(user, contractID, req)
 	if err != nil {
 		//Unwrap apperror
 		err, IsAppErr := apperrors.Unwrap(err)
 		if !IsAppErr {
-			log.Error("ContractStorageUpdate error: ", zap.Error(err))
+			log.Ctx(r.Context()).Error("ContractStorageUpdate error: ", zap.Error(err))
 		}
 
 		response.JsonError(w, err)
@@ -111,14 +109,44 @@ func (api *API) ContractInfo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	service := services.New(repos.New(networkContext.Db), repos.New(networkContext.IndexerDB), networkContext.Client, networkContext.Auth, net)
+	service := newService(r, net, networkContext).WithConfig(api.cfg)
 
 	resp, err := service.ContractInfo(contractID)
 	if err != nil {
 		//Unwrap apperror
 		err, IsAppErr := apperrors.Unwrap(err)
 		if !IsAppErr {
-			log.Error("ContractInfo error: ", zap.Error(err))
+			log.Ctx(r.Context()).Error("ContractInfo error: ", zap.Error(err))
+		}
+
+		response.JsonError(w, err)
+		return
+	}
+
+	response.Json(w, resp)
+}
+
+func (api *API) ContractEntrypoints(w http.ResponseWriter, r *http.Request) {
+	_, net, networkContext, err := GetUserNetworkContext(r)
+	if err != nil {
+		response.JsonError(w, err)
+		return
+	}
+
+	contractID := types.Address(mux.Vars(r)[ContractIDParam])
+	if err := contractID.Validate(); err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadParam, ContractIDParam))
+		return
+	}
+
+	service := newService(r, net, networkContext)
+
+	resp, err := service.ContractEntrypoints(contractID)
+	if err != nil {
+		//Unwrap apperror
+		err, IsAppErr := apperrors.Unwrap(err)
+		if !IsAppErr {
+			log.Ctx(r.Context()).Error("ContractEntrypoints error: ", zap.Error(err))
 		}
 
 		response.JsonError(w, err)
@@ -148,14 +176,14 @@ func (api *API) ContractOperation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	service := services.New(repos.New(networkContext.Db), repos.New(networkContext.IndexerDB), networkContext.Client, networkContext.Auth, net)
+	service := newService(r, net, networkContext).WithConfig(api.cfg)
 
 	resp, err := service.ContractOperation(user, req)
 	if err != nil {
 		//Unwrap apperror
 		err, IsAppErr := apperrors.Unwrap(err)
 		if !IsAppErr {
-			log.Error("BuildContractOperationToSign error: ", zap.Error(err))
+			log.Ctx(r.Context()).Error("BuildContractOperationToSign error: ", zap.Error(err))
 		}
 
 		response.JsonError(w, err)
@@ -185,14 +213,14 @@ func (api *API) OperationSignPayload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	service := services.New(repos.New(networkContext.Db), repos.New(networkContext.IndexerDB), networkContext.Client, networkContext.Auth, net)
+	service := newService(r, net, networkContext)
 
 	resp, err := service.BuildContractOperationToSign(user, operationID, payloadType)
 	if err != nil {
 		//Unwrap apperror
 		err, IsAppErr := apperrors.Unwrap(err)
 		if !IsAppErr {
-			log.Error("BuildContractOperationReject error: ", zap.Error(err))
+			log.Ctx(r.Context()).Error("BuildContractOperationReject error: ", zap.Error(err))
 		}
 
 		response.JsonError(w, err)
@@ -228,14 +256,14 @@ func (api *API) ContractOperationSignature(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	service := services.New(repos.New(networkContext.Db), repos.New(networkContext.IndexerDB), networkContext.Client, networkContext.Auth, net)
+	service := newService(r, net, networkContext)
 
 	resp, err := service.SaveContractOperationSignature(user, operationID, req)
 	if err != nil {
 		//Unwrap apperror
 		err, IsAppErr := apperrors.Unwrap(err)
 		if !IsAppErr {
-			log.Error("SaveContractOperationSignature error: ", zap.Error(err))
+			log.Ctx(r.Context()).Error("SaveContractOperationSignature error: ", zap.Error(err))
 		}
 
 		response.JsonError(w, err)
@@ -264,14 +292,14 @@ func (api *API) ContractOperationBuild(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	service := services.New(repos.New(networkContext.Db), repos.New(networkContext.IndexerDB), networkContext.Client, networkContext.Auth, net)
+	service := newService(r, net, networkContext)
 
 	resp, err := service.BuildContractOperation(user, operationID, payloadType)
 	if err != nil {
 		//Unwrap apperror
 		err, IsAppErr := apperrors.Unwrap(err)
 		if !IsAppErr {
-			log.Error("ContractOperationBuild error: ", zap.Error(err))
+			log.Ctx(r.Context()).Error("ContractOperationBuild error: ", zap.Error(err))
 		}
 
 		response.JsonError(w, err)
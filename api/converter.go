@@ -0,0 +1,149 @@
+package api
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"tezosign/api/response"
+	"tezosign/common/apperrors"
+	"tezosign/models"
+	"tezosign/services/converter"
+
+	"blockwatch.cc/tzindex/micheline"
+)
+
+func (api *API) ConvertPack(w http.ResponseWriter, r *http.Request) {
+	var req models.ConvertPackRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadRequest))
+		return
+	}
+
+	err = req.Validate()
+	if err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadRequest, err.Error()))
+		return
+	}
+
+	typ, err := converter.ParseExpression(req.Type)
+	if err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadParam, "type"))
+		return
+	}
+
+	value, err := converter.ParseExpression(req.Value)
+	if err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadParam, "value"))
+		return
+	}
+
+	packed, err := converter.Pack(typ, value)
+	if err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadParam, err.Error()))
+		return
+	}
+
+	resp, err := convertResponse(value)
+	if err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadParam, "value"))
+		return
+	}
+
+	resp.Packed = hex.EncodeToString(packed)
+
+	response.Json(w, resp)
+}
+
+func (api *API) ConvertUnpack(w http.ResponseWriter, r *http.Request) {
+	var req models.ConvertUnpackRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadRequest))
+		return
+	}
+
+	err = req.Validate()
+	if err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadRequest, err.Error()))
+		return
+	}
+
+	typ, err := converter.ParseExpression(req.Type)
+	if err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadParam, "type"))
+		return
+	}
+
+	//Checked on validation
+	packed, _ := hex.DecodeString(req.Packed)
+
+	value, err := converter.Unpack(typ, packed)
+	if err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadParam, err.Error()))
+		return
+	}
+
+	resp, err := convertResponse(value)
+	if err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadParam, "packed"))
+		return
+	}
+
+	resp.Packed = req.Packed
+
+	response.Json(w, resp)
+}
+
+func (api *API) ConvertMichelson(w http.ResponseWriter, r *http.Request) {
+	var req models.ConvertMichelsonRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadRequest))
+		return
+	}
+
+	err = req.Validate()
+	if err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadRequest, err.Error()))
+		return
+	}
+
+	var expr *micheline.Prim
+	if len(req.Binary) > 0 {
+		//Checked on validation
+		bt, _ := hex.DecodeString(req.Binary)
+		expr, err = converter.ParseBinary(bt)
+	} else {
+		expr, err = converter.ParseExpression(req.Expression)
+	}
+	if err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadParam, err.Error()))
+		return
+	}
+
+	resp, err := convertResponse(expr)
+	if err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadParam, "expression"))
+		return
+	}
+
+	response.Json(w, resp)
+}
+
+func convertResponse(expr *micheline.Prim) (resp models.ConvertResponse, err error) {
+	bt, err := expr.MarshalBinary()
+	if err != nil {
+		return resp, err
+	}
+
+	resp.Micheline, err = expr.MarshalJSON()
+	if err != nil {
+		return resp, err
+	}
+
+	resp.Binary = hex.EncodeToString(bt)
+	resp.Michelson = converter.PrintMichelson(expr)
+
+	return resp, nil
+}
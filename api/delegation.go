@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+	"tezosign/api/response"
+	"tezosign/common/apperrors"
+	"tezosign/common/log"
+	"tezosign/models"
+
+	"go.uber.org/zap"
+)
+
+func (api *API) BakersList(w http.ResponseWriter, r *http.Request) {
+	net, networkContext, err := GetNetworkContext(r)
+	if err != nil {
+		response.JsonError(w, err)
+		return
+	}
+
+	var params models.CommonParams
+	err = api.queryDecoder.Decode(&params, r.URL.Query())
+	if err != nil {
+		response.JsonError(w, err)
+		return
+	}
+
+	if err = params.Validate(); err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadRequest, err.Error()))
+		return
+	}
+
+	service := newService(r, net, networkContext).WithConfig(api.cfg)
+
+	bakers, err := service.BakersList(params)
+	if err != nil {
+		//Unwrap apperror
+		err, IsAppErr := apperrors.Unwrap(err)
+		if !IsAppErr {
+			log.Ctx(r.Context()).Error("BakersList error: ", zap.Error(err))
+		}
+
+		response.JsonError(w, err)
+		return
+	}
+
+	response.Json(w, bakers)
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"tezosign/api/response"
+	"tezosign/common/apperrors"
+	"tezosign/common/log"
+	"tezosign/models"
+	"tezosign/types"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+func (api *API) AssetSuggestions(w http.ResponseWriter, r *http.Request) {
+	_, net, networkContext, err := GetUserNetworkContext(r)
+	if err != nil {
+		response.JsonError(w, err)
+		return
+	}
+
+	contractAddress := types.Address(mux.Vars(r)[ContractIDParam])
+	err = contractAddress.Validate()
+	if err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadParam, ContractIDParam))
+		return
+	}
+
+	service := newService(r, net, networkContext).WithConfig(api.cfg)
+
+	assets, err := service.AssetSuggestions(contractAddress)
+	if err != nil {
+		//Unwrap apperror
+		err, IsAppErr := apperrors.Unwrap(err)
+		if !IsAppErr {
+			log.Ctx(r.Context()).Error("AssetSuggestions error: ", zap.Error(err))
+		}
+
+		response.JsonError(w, err)
+		return
+	}
+
+	response.Json(w, assets)
+}
+
+func (api *API) AcceptAssetSuggestion(w http.ResponseWriter, r *http.Request) {
+	_, net, networkContext, err := GetUserNetworkContext(r)
+	if err != nil {
+		response.JsonError(w, err)
+		return
+	}
+
+	contractAddress := types.Address(mux.Vars(r)[ContractIDParam])
+	err = contractAddress.Validate()
+	if err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadParam, ContractIDParam))
+		return
+	}
+
+	var data models.Asset
+	err = json.NewDecoder(r.Body).Decode(&data)
+	if err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadRequest))
+		return
+	}
+
+	if err = data.Address.Validate(); err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadParam, "address"))
+		return
+	}
+
+	service := newService(r, net, networkContext).WithConfig(api.cfg)
+
+	asset, err := service.AcceptAssetSuggestion(contractAddress, data)
+	if err != nil {
+		//Unwrap apperror
+		err, IsAppErr := apperrors.Unwrap(err)
+		if !IsAppErr {
+			log.Ctx(r.Context()).Error("AcceptAssetSuggestion error: ", zap.Error(err))
+		}
+
+		response.JsonError(w, err)
+		return
+	}
+
+	response.Json(w, asset)
+}
+
+func (api *API) DismissAssetSuggestion(w http.ResponseWriter, r *http.Request) {
+	_, net, networkContext, err := GetUserNetworkContext(r)
+	if err != nil {
+		response.JsonError(w, err)
+		return
+	}
+
+	contractAddress := types.Address(mux.Vars(r)[ContractIDParam])
+	err = contractAddress.Validate()
+	if err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadParam, ContractIDParam))
+		return
+	}
+
+	var data models.Asset
+	err = json.NewDecoder(r.Body).Decode(&data)
+	if err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadRequest))
+		return
+	}
+
+	if err = data.Address.Validate(); err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadParam, "address"))
+		return
+	}
+
+	service := newService(r, net, networkContext).WithConfig(api.cfg)
+
+	err = service.DismissAssetSuggestion(contractAddress, data)
+	if err != nil {
+		//Unwrap apperror
+		err, IsAppErr := apperrors.Unwrap(err)
+		if !IsAppErr {
+			log.Ctx(r.Context()).Error("DismissAssetSuggestion error: ", zap.Error(err))
+		}
+
+		response.JsonError(w, err)
+		return
+	}
+
+	response.Json(w, map[string]interface{}{"message": "success"})
+}
@@ -2,18 +2,25 @@ package api
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
 	"tezosign/api/response"
 	"tezosign/common/apperrors"
 	"tezosign/common/log"
-	"tezosign/repos"
-	"tezosign/services"
 	"tezosign/types"
+	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/gorilla/mux"
+	"github.com/urfave/negroni"
 )
 
 type ContextKey string
@@ -22,8 +29,70 @@ const (
 	ContextUserPubKey        ContextKey = "user_pubkey"
 	ContextNetworkKey        ContextKey = "network"
 	ContextNetworkContextKey ContextKey = "network_context"
+	ContextSessionKey        ContextKey = "session"
 )
 
+const (
+	RequestIDHeader    = "X-Request-ID"
+	maxRequestIDLength = 64
+)
+
+//RequestContext attaches request id and route timeout to request context
+func (api *API) RequestContext(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	requestID := r.Header.Get(RequestIDHeader)
+	if !isValidRequestID(requestID) {
+		requestID = newRequestID()
+	}
+	w.Header().Set(RequestIDHeader, requestID)
+
+	ctx := log.WithRequestID(r.Context(), requestID)
+
+	if timeout := api.routeTimeout(r); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	next(w, r.WithContext(ctx))
+}
+
+//routeTimeout returns timeout by matched route template, default timeout otherwise
+func (api *API) routeTimeout(r *http.Request) time.Duration {
+	timeout := api.cfg.API.RequestTimeout
+
+	if route := mux.CurrentRoute(r); route != nil {
+		if path, err := route.GetPathTemplate(); err == nil {
+			if routeTimeout, ok := api.cfg.API.RouteTimeouts[path]; ok {
+				timeout = routeTimeout
+			}
+		}
+	}
+
+	return time.Duration(timeout) * time.Second
+}
+
+//Client request id is logged as is, so only safe characters are allowed
+func isValidRequestID(requestID string) bool {
+	if len(requestID) == 0 || len(requestID) > maxRequestIDLength {
+		return false
+	}
+
+	for _, c := range requestID {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+	bt := make([]byte, 16)
+	//Id is not security sensitive
+	_, _ = rand.Read(bt)
+	return hex.EncodeToString(bt)
+}
+
 func (api *API) RequireJWT(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
 	net, err := ToNetwork(mux.Vars(r)["network"])
 	if err != nil {
@@ -37,18 +106,18 @@ func (api *API) RequireJWT(w http.ResponseWriter, r *http.Request, next http.Han
 		return
 	}
 
-	userPubKey, err := auth.CheckSignatureAndGetUserPubKey(r)
+	claims, err := auth.CheckSignatureAndGetClaims(r)
 	if err != nil {
 		response.JsonError(w, err)
 		return
 	}
 
-	if userPubKey == "" {
+	if claims.PubKey == "" {
 		response.JsonError(w, apperrors.New(apperrors.ErrService))
 		return
 	}
 
-	typedPubKey := types.PubKey(userPubKey)
+	typedPubKey := claims.PubKey
 
 	err = typedPubKey.Validate()
 	if err != nil {
@@ -56,8 +125,30 @@ func (api *API) RequireJWT(w http.ResponseWriter, r *http.Request, next http.Han
 		return
 	}
 
+	networkContext, err := api.provider.GetNetworkContext(net)
+	if err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadParam, err.Error()))
+		return
+	}
+
+	service := newService(r, net, networkContext)
+
+	//Check access token deny-list
+	isRevoked, err := service.IsAccessTokenRevoked(claims)
+	if err != nil {
+		log.Ctx(r.Context()).Error("IsAccessTokenRevoked error: ", zap.Error(err))
+		response.JsonError(w, err)
+		return
+	}
+
+	if isRevoked {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadJwt))
+		return
+	}
+
 	ctx := r.Context()
 	ctx = context.WithValue(ctx, ContextUserPubKey, typedPubKey)
+	ctx = context.WithValue(ctx, ContextSessionKey, claims.SessionID)
 	r = r.WithContext(ctx)
 
 	next(w, r)
@@ -89,14 +180,14 @@ const ContractIDParam = "contract_id"
 func (api *API) OwnerAllowance(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
 	user, net, networkContext, err := GetUserNetworkContext(r)
 	if err != nil {
-		log.Error("Probably OwnerAllowance middleware was called without auth")
+		log.Ctx(r.Context()).Error("Probably OwnerAllowance middleware was called without auth")
 		response.JsonError(w, err)
 		return
 	}
 
 	contractID := types.Address(mux.Vars(r)[ContractIDParam])
 	if contractID == "" {
-		log.Error("Probably OwnerAllowance middleware without contract_id param")
+		log.Ctx(r.Context()).Error("Probably OwnerAllowance middleware without contract_id param")
 		response.JsonError(w, errors.New("Contract_id param not found"))
 		return
 	}
@@ -106,7 +197,7 @@ func (api *API) OwnerAllowance(w http.ResponseWriter, r *http.Request, next http
 		return
 	}
 
-	service := services.New(repos.New(networkContext.Db), repos.New(networkContext.IndexerDB), networkContext.Client, networkContext.Auth, net)
+	service := newService(r, net, networkContext)
 
 	isOwner, err := service.GetUserAllowance(user, contractID)
 	if err != nil {
@@ -114,7 +205,7 @@ func (api *API) OwnerAllowance(w http.ResponseWriter, r *http.Request, next http
 			//Unwrap apperror
 			err, IsAppErr := apperrors.Unwrap(err)
 			if !IsAppErr {
-				log.Error("GetUserAllowance error: ", zap.Error(err))
+				log.Ctx(r.Context()).Error("GetUserAllowance error: ", zap.Error(err))
 			}
 
 			response.JsonError(w, err)
@@ -129,3 +220,112 @@ func (api *API) OwnerAllowance(w http.ResponseWriter, r *http.Request, next http
 
 	next(w, r)
 }
+
+//Route names used as keys of rate limit budgets in config
+const (
+	RateLimitAuthRequest       = "auth_request"
+	RateLimitAuth              = "auth"
+	RateLimitAuthRefresh       = "auth_refresh"
+	RateLimitContractOperation = "contract_operation"
+	RateLimitStorageUpdate     = "storage_update"
+	RateLimitConverter         = "converter"
+)
+
+//Limit requests by client IP. Used on public routes
+func (api *API) RateLimitByIP(route string) negroni.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		api.rateLimit(w, r, next, route, api.clientIP(r))
+	}
+}
+
+//Limit requests by user pubkey. Should be used after RequireJWT middleware
+func (api *API) RateLimitByPubKey(route string) negroni.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		userPubKey, ok := r.Context().Value(ContextUserPubKey).(types.PubKey)
+		if !ok {
+			log.Ctx(r.Context()).Error("Probably RateLimitByPubKey middleware was called without auth")
+			response.JsonError(w, apperrors.New(apperrors.ErrService))
+			return
+		}
+
+		api.rateLimit(w, r, next, route, userPubKey.String())
+	}
+}
+
+func (api *API) rateLimit(w http.ResponseWriter, r *http.Request, next http.HandlerFunc, route string, key string) {
+	budget, ok := api.cfg.API.RateLimit.Routes[route]
+	//Route without limits
+	if !ok {
+		next(w, r)
+		return
+	}
+
+	_, networkContext, err := GetNetworkContext(r)
+	if err != nil {
+		log.Ctx(r.Context()).Error("Probably RateLimit middleware was called without network")
+		response.JsonError(w, err)
+		return
+	}
+
+	allowed, retryAfter, err := networkContext.RateLimiter.Take(r.Context(), fmt.Sprintf("%s:%s", route, key), budget)
+	if err != nil {
+		log.Ctx(r.Context()).Error("RateLimiter Take error: ", zap.Error(err))
+		response.JsonError(w, err)
+		return
+	}
+
+	if !allowed {
+		w.Header().Set("Retry-After", strconv.FormatInt(int64(math.Ceil(retryAfter.Seconds())), 10))
+		response.JsonError(w, apperrors.New(apperrors.ErrTooManyRequests, route))
+		return
+	}
+
+	next(w, r)
+}
+
+//clientIP returns right-most address not added by trusted proxies, left entries of X-Forwarded-For are set by client
+func (api *API) clientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	if !api.isTrustedProxy(ip) {
+		return ip
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(forwarded[i])
+		if hop == "" {
+			continue
+		}
+
+		//Garbage can only come from client, last valid hop is used
+		if net.ParseIP(hop) == nil {
+			return ip
+		}
+
+		ip = hop
+		if !api.isTrustedProxy(ip) {
+			return ip
+		}
+	}
+
+	return ip
+}
+
+func (api *API) isTrustedProxy(address string) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+
+	for _, proxy := range api.trustedProxies {
+		if proxy.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+	"tezosign/conf"
+)
+
+func Test_ClientIP(t *testing.T) {
+	trustedProxies, err := conf.RateLimit{TrustedProxies: []string{"10.0.0.1", "192.168.0.0/16", "fd00::/8"}}.TrustedProxyNets()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api := &API{trustedProxies: trustedProxies}
+
+	testCases := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		expIP      string
+	}{
+		{name: "Direct", remoteAddr: "1.2.3.4:5000", expIP: "1.2.3.4"},
+		{name: "Direct spoofed header", remoteAddr: "1.2.3.4:5000", forwarded: []string{"5.6.7.8"}, expIP: "1.2.3.4"},
+		{name: "Trusted proxy", remoteAddr: "10.0.0.1:5000", forwarded: []string{"5.6.7.8"}, expIP: "5.6.7.8"},
+		{name: "Trusted proxy without header", remoteAddr: "10.0.0.1:5000", expIP: "10.0.0.1"},
+		{name: "Spoofed left hop", remoteAddr: "10.0.0.1:5000", forwarded: []string{"9.9.9.9, 5.6.7.8"}, expIP: "5.6.7.8"},
+		{name: "Multi hop", remoteAddr: "10.0.0.1:5000", forwarded: []string{"9.9.9.9, 5.6.7.8, 192.168.1.1"}, expIP: "5.6.7.8"},
+		{name: "Multiple headers", remoteAddr: "10.0.0.1:5000", forwarded: []string{"9.9.9.9", "5.6.7.8, 192.168.1.1"}, expIP: "5.6.7.8"},
+		{name: "Only trusted hops", remoteAddr: "10.0.0.1:5000", forwarded: []string{"192.168.1.2, 192.168.1.1"}, expIP: "192.168.1.2"},
+		{name: "Garbage hop", remoteAddr: "10.0.0.1:5000", forwarded: []string{"5.6.7.8, garbage, 192.168.1.1"}, expIP: "192.168.1.1"},
+		{name: "Empty hops", remoteAddr: "10.0.0.1:5000", forwarded: []string{"5.6.7.8, , "}, expIP: "5.6.7.8"},
+		{name: "IPv6", remoteAddr: "[fd00::1]:5000", forwarded: []string{"2001:db8::1"}, expIP: "2001:db8::1"},
+		{name: "Untrusted remote without port", remoteAddr: "1.2.3.4", forwarded: []string{"5.6.7.8"}, expIP: "1.2.3.4"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = test.remoteAddr
+			for _, value := range test.forwarded {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+
+			if ip := api.clientIP(r); ip != test.expIP {
+				t.Errorf("got %s, want %s", ip, test.expIP)
+			}
+		})
+	}
+}
@@ -6,8 +6,6 @@ import (
 	"tezosign/common/apperrors"
 	"tezosign/common/log"
 	"tezosign/models"
-	"tezosign/repos"
-	"tezosign/services"
 	"tezosign/types"
 
 	"github.com/gorilla/mux"
@@ -40,14 +38,14 @@ func (api *API) ContractOperationsList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	service := services.New(repos.New(networkContext.Db), repos.New(networkContext.IndexerDB), networkContext.Client, nil, net)
+	service := newService(r, net, networkContext)
 
 	list, err := service.GetOperationsList(user, contractAddress, params)
 	if err != nil {
 		//Unwrap apperror
 		err, IsAppErr := apperrors.Unwrap(err)
 		if !IsAppErr {
-			log.Error("ContractOperationsList error: ", zap.Error(err))
+			log.Ctx(r.Context()).Error("ContractOperationsList error: ", zap.Error(err))
 		}
 
 		response.JsonError(w, err)
@@ -70,7 +68,7 @@ func (api *API) ContractOrigination(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	service := services.New(repos.New(networkContext.Db), repos.New(networkContext.IndexerDB), networkContext.Client, networkContext.Auth, net)
+	service := newService(r, net, networkContext)
 
 	contractID, err := service.CheckContractOrigination(txID)
 	if err != nil {
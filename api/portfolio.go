@@ -0,0 +1,87 @@
+package api
+
+import (
+	"net/http"
+	"tezosign/api/response"
+	"tezosign/common/apperrors"
+	"tezosign/common/log"
+	"tezosign/models"
+	"tezosign/types"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+func (api *API) Portfolio(w http.ResponseWriter, r *http.Request) {
+	user, net, networkContext, err := GetUserNetworkContext(r)
+	if err != nil {
+		response.JsonError(w, err)
+		return
+	}
+
+	contractAddress := types.Address(mux.Vars(r)[ContractIDParam])
+	err = contractAddress.Validate()
+	if err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadParam, ContractIDParam))
+		return
+	}
+
+	service := newService(r, net, networkContext).WithConfig(api.cfg)
+
+	resp, err := service.Portfolio(user, contractAddress)
+	if err != nil {
+		//Unwrap apperror
+		err, IsAppErr := apperrors.Unwrap(err)
+		if !IsAppErr {
+			log.Ctx(r.Context()).Error("Portfolio error: ", zap.Error(err))
+		}
+
+		response.JsonError(w, err)
+		return
+	}
+
+	response.Json(w, resp)
+}
+
+func (api *API) PortfolioHistory(w http.ResponseWriter, r *http.Request) {
+	_, net, networkContext, err := GetUserNetworkContext(r)
+	if err != nil {
+		response.JsonError(w, err)
+		return
+	}
+
+	contractAddress := types.Address(mux.Vars(r)[ContractIDParam])
+	err = contractAddress.Validate()
+	if err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadParam, ContractIDParam))
+		return
+	}
+
+	var params models.CommonParams
+	err = api.queryDecoder.Decode(&params, r.URL.Query())
+	if err != nil {
+		response.JsonError(w, err)
+		return
+	}
+
+	if err = params.Validate(); err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadRequest, err.Error()))
+		return
+	}
+
+	service := newService(r, net, networkContext)
+
+	resp, err := service.PortfolioHistory(contractAddress, params)
+	if err != nil {
+		//Unwrap apperror
+		err, IsAppErr := apperrors.Unwrap(err)
+		if !IsAppErr {
+			log.Ctx(r.Context()).Error("PortfolioHistory error: ", zap.Error(err))
+		}
+
+		response.JsonError(w, err)
+		return
+	}
+
+	response.Json(w, resp)
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"tezosign/api/response"
+	"tezosign/common/apperrors"
+	"tezosign/common/log"
+	"tezosign/models"
+	"tezosign/types"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+func assetTokenParams(r *http.Request) (assetID types.Address, tokenID uint64, err error) {
+	assetID = types.Address(mux.Vars(r)["asset_id"])
+	if err = assetID.Validate(); err != nil {
+		return assetID, tokenID, apperrors.New(apperrors.ErrBadParam, "asset_id")
+	}
+
+	value := r.URL.Query().Get("token_id")
+	if len(value) != 0 {
+		tokenID, err = strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return assetID, tokenID, apperrors.New(apperrors.ErrBadParam, "token_id")
+		}
+	}
+
+	return assetID, tokenID, nil
+}
+
+func (api *API) AssetPrice(w http.ResponseWriter, r *http.Request) {
+	net, networkContext, err := GetNetworkContext(r)
+	if err != nil {
+		response.JsonError(w, err)
+		return
+	}
+
+	assetID, tokenID, err := assetTokenParams(r)
+	if err != nil {
+		response.JsonError(w, err)
+		return
+	}
+
+	service := newService(r, net, networkContext)
+
+	resp, err := service.GetAssetPrice(assetID, tokenID)
+	if err != nil {
+		//Unwrap apperror
+		err, IsAppErr := apperrors.Unwrap(err)
+		if !IsAppErr {
+			log.Ctx(r.Context()).Error("GetAssetPrice error: ", zap.Error(err))
+		}
+
+		response.JsonError(w, err)
+		return
+	}
+
+	response.Json(w, resp)
+}
+
+func (api *API) AssetPriceHistory(w http.ResponseWriter, r *http.Request) {
+	net, networkContext, err := GetNetworkContext(r)
+	if err != nil {
+		response.JsonError(w, err)
+		return
+	}
+
+	assetID, tokenID, err := assetTokenParams(r)
+	if err != nil {
+		response.JsonError(w, err)
+		return
+	}
+
+	var params models.CommonParams
+	err = api.queryDecoder.Decode(&params, r.URL.Query())
+	if err != nil {
+		response.JsonError(w, err)
+		return
+	}
+
+	if err = params.Validate(); err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadRequest, err.Error()))
+		return
+	}
+
+	service := newService(r, net, networkContext)
+
+	resp, err := service.AssetPriceHistory(assetID, tokenID, params)
+	if err != nil {
+		//Unwrap apperror
+		err, IsAppErr := apperrors.Unwrap(err)
+		if !IsAppErr {
+			log.Ctx(r.Context()).Error("AssetPriceHistory error: ", zap.Error(err))
+		}
+
+		response.JsonError(w, err)
+		return
+	}
+
+	response.Json(w, resp)
+}
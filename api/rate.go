@@ -3,8 +3,6 @@ package api
 import (
 	"net/http"
 	"tezosign/api/response"
-	"tezosign/repos"
-	"tezosign/services"
 )
 
 func (api *API) TezosExchangeRates(w http.ResponseWriter, r *http.Request) {
@@ -14,7 +12,7 @@ func (api *API) TezosExchangeRates(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	service := services.New(repos.New(networkContext.Db), repos.New(networkContext.IndexerDB), networkContext.Client, networkContext.Auth, net)
+	service := newService(r, net, networkContext)
 
 	rates, err := service.TezosExchangeRates()
 	if err != nil {
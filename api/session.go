@@ -0,0 +1,87 @@
+package api
+
+import (
+	"net/http"
+	"tezosign/api/response"
+	"tezosign/common/apperrors"
+	"tezosign/common/log"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+const sessionIDParam = "session_id"
+
+func (api *API) SessionsList(w http.ResponseWriter, r *http.Request) {
+	user, net, networkContext, err := GetUserNetworkContext(r)
+	if err != nil {
+		response.JsonError(w, err)
+		return
+	}
+
+	service := newService(r, net, networkContext)
+
+	sessions, err := service.SessionsList(user, getSessionID(r))
+	if err != nil {
+		log.Ctx(r.Context()).Error("SessionsList error: ", zap.Error(err))
+		response.JsonError(w, err)
+		return
+	}
+
+	response.Json(w, sessions)
+}
+
+func (api *API) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	user, net, networkContext, err := GetUserNetworkContext(r)
+	if err != nil {
+		response.JsonError(w, err)
+		return
+	}
+
+	sessionID := mux.Vars(r)[sessionIDParam]
+	if sessionID == "" {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadParam, sessionIDParam))
+		return
+	}
+
+	service := newService(r, net, networkContext)
+
+	err = service.RevokeSession(user, sessionID)
+	if err != nil {
+		//Unwrap apperror
+		err, IsAppErr := apperrors.Unwrap(err)
+		if !IsAppErr {
+			log.Ctx(r.Context()).Error("RevokeSession error: ", zap.Error(err))
+		}
+
+		response.JsonError(w, err)
+		return
+	}
+
+	response.Json(w, map[string]interface{}{"message": "success"})
+}
+
+func (api *API) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	user, net, networkContext, err := GetUserNetworkContext(r)
+	if err != nil {
+		response.JsonError(w, err)
+		return
+	}
+
+	service := newService(r, net, networkContext)
+
+	count, err := service.RevokeOtherSessions(user, getSessionID(r))
+	if err != nil {
+		log.Ctx(r.Context()).Error("RevokeOtherSessions error: ", zap.Error(err))
+		response.JsonError(w, err)
+		return
+	}
+
+	response.Json(w, map[string]interface{}{"revoked": count})
+}
+
+//Session of current access token, empty for tokens issued before sessions
+func getSessionID(r *http.Request) string {
+	sessionID, _ := r.Context().Value(ContextSessionKey).(string)
+	return sessionID
+}
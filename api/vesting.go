@@ -2,14 +2,15 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"tezosign/api/response"
 	"tezosign/common/apperrors"
 	"tezosign/common/log"
 	"tezosign/models"
-	"tezosign/repos"
-	"tezosign/services"
+	"tezosign/services/schedule"
 	"tezosign/types"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -37,14 +38,14 @@ func (api *API) VestingContractStorageInit(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	service := services.New(repos.New(networkContext.Db), repos.New(networkContext.IndexerDB), networkContext.Client, networkContext.Auth, net)
+	service := newService(r, net, networkContext)
 
 	resp, err := service.BuildVestingContractInitStorage(req)
 	if err != nil {
 		//Unwrap apperror
 		err, IsAppErr := apperrors.Unwrap(err)
 		if !IsAppErr {
-			log.Error("BuildVestingContractInitStorage error: ", zap.Error(err))
+			log.Ctx(r.Context()).Error("BuildVestingContractInitStorage error: ", zap.Error(err))
 		}
 
 		response.JsonError(w, err)
@@ -75,14 +76,14 @@ func (api *API) VestingContractOperation(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	service := services.New(repos.New(networkContext.Db), repos.New(networkContext.IndexerDB), networkContext.Client, networkContext.Auth, net)
+	service := newService(r, net, networkContext)
 
 	resp, err := service.VestingContractOperation(req)
 	if err != nil {
 		//Unwrap apperror
 		err, IsAppErr := apperrors.Unwrap(err)
 		if !IsAppErr {
-			log.Error("VestingContractOperation error: ", zap.Error(err))
+			log.Ctx(r.Context()).Error("VestingContractOperation error: ", zap.Error(err))
 		}
 
 		response.JsonError(w, err)
@@ -106,14 +107,168 @@ func (api *API) VestingContractInfo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	service := services.New(repos.New(networkContext.Db), repos.New(networkContext.IndexerDB), networkContext.Client, networkContext.Auth, net)
+	service := newService(r, net, networkContext).WithConfig(api.cfg)
 
 	resp, err := service.VestingContractInfo(contractID)
 	if err != nil {
 		//Unwrap apperror
 		err, IsAppErr := apperrors.Unwrap(err)
 		if !IsAppErr {
-			log.Error("VestingContractInfo error: ", zap.Error(err))
+			log.Ctx(r.Context()).Error("VestingContractInfo error: ", zap.Error(err))
+		}
+
+		response.JsonError(w, err)
+		return
+	}
+
+	response.Json(w, resp)
+}
+
+func (api *API) VestingSchedule(w http.ResponseWriter, r *http.Request) {
+	_, net, networkContext, err := GetUserNetworkContext(r)
+	if err != nil {
+		response.JsonError(w, err)
+		return
+	}
+
+	contractID := types.Address(mux.Vars(r)["vesting_contract_id"])
+	if err := contractID.Validate(); err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadParam, "vesting_contract_id"))
+		return
+	}
+
+	params := models.VestingScheduleParams{Granularity: models.VestingScheduleTick}
+	err = api.queryDecoder.Decode(&params, r.URL.Query())
+	if err != nil {
+		response.JsonError(w, err)
+		return
+	}
+
+	if err = params.Validate(); err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadRequest, err.Error()))
+		return
+	}
+
+	service := newService(r, net, networkContext)
+
+	resp, err := service.VestingSchedule(contractID, params.Granularity, params.CommonParams)
+	if err != nil {
+		//Unwrap apperror
+		err, IsAppErr := apperrors.Unwrap(err)
+		if !IsAppErr {
+			log.Ctx(r.Context()).Error("VestingSchedule error: ", zap.Error(err))
+		}
+
+		response.JsonError(w, err)
+		return
+	}
+
+	response.Json(w, resp)
+}
+
+//VestingScheduleCalendar exports unlock milestones as iCalendar file
+func (api *API) VestingScheduleCalendar(w http.ResponseWriter, r *http.Request) {
+	_, net, networkContext, err := GetUserNetworkContext(r)
+	if err != nil {
+		response.JsonError(w, err)
+		return
+	}
+
+	contractID := types.Address(mux.Vars(r)["vesting_contract_id"])
+	if err := contractID.Validate(); err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadParam, "vesting_contract_id"))
+		return
+	}
+
+	params := models.VestingScheduleParams{Granularity: models.VestingScheduleMonth}
+	err = api.queryDecoder.Decode(&params, r.URL.Query())
+	if err != nil {
+		response.JsonError(w, err)
+		return
+	}
+
+	service := newService(r, net, networkContext)
+
+	resp, err := service.VestingSchedule(contractID, params.Granularity, models.CommonParams{Limit: models.MaxLimitSize})
+	if err != nil {
+		//Unwrap apperror
+		err, IsAppErr := apperrors.Unwrap(err)
+		if !IsAppErr {
+			log.Ctx(r.Context()).Error("VestingScheduleCalendar error: ", zap.Error(err))
+		}
+
+		response.JsonError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", contractID.String()+".ics"))
+	w.Write(schedule.ICS(contractID, resp, time.Now()))
+}
+
+func (api *API) VestingHistory(w http.ResponseWriter, r *http.Request) {
+	_, net, networkContext, err := GetUserNetworkContext(r)
+	if err != nil {
+		response.JsonError(w, err)
+		return
+	}
+
+	contractID := types.Address(mux.Vars(r)["vesting_contract_id"])
+	if err := contractID.Validate(); err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadParam, "vesting_contract_id"))
+		return
+	}
+
+	var params models.CommonParams
+	err = api.queryDecoder.Decode(&params, r.URL.Query())
+	if err != nil {
+		response.JsonError(w, err)
+		return
+	}
+
+	if err = params.Validate(); err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadRequest, err.Error()))
+		return
+	}
+
+	service := newService(r, net, networkContext)
+
+	resp, err := service.VestingHistory(contractID, params)
+	if err != nil {
+		//Unwrap apperror
+		err, IsAppErr := apperrors.Unwrap(err)
+		if !IsAppErr {
+			log.Ctx(r.Context()).Error("VestingHistory error: ", zap.Error(err))
+		}
+
+		response.JsonError(w, err)
+		return
+	}
+
+	response.Json(w, resp)
+}
+
+func (api *API) VestingReconciliation(w http.ResponseWriter, r *http.Request) {
+	_, net, networkContext, err := GetUserNetworkContext(r)
+	if err != nil {
+		response.JsonError(w, err)
+		return
+	}
+
+	contractID := types.Address(mux.Vars(r)["vesting_contract_id"])
+	if err := contractID.Validate(); err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadParam, "vesting_contract_id"))
+		return
+	}
+
+	service := newService(r, net, networkContext)
+
+	resp, err := service.VestingReconciliation(contractID)
+	if err != nil {
+		//Unwrap apperror
+		err, IsAppErr := apperrors.Unwrap(err)
+		if !IsAppErr {
+			log.Ctx(r.Context()).Error("VestingReconciliation error: ", zap.Error(err))
 		}
 
 		response.JsonError(w, err)
@@ -137,14 +292,14 @@ func (api *API) VestingsList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	service := services.New(repos.New(networkContext.Db), repos.New(networkContext.IndexerDB), networkContext.Client, networkContext.Auth, net)
+	service := newService(r, net, networkContext)
 
 	reps, err := service.VestingsList(user, contractAddress)
 	if err != nil {
 		//Unwrap apperror
 		err, IsAppErr := apperrors.Unwrap(err)
 		if !IsAppErr {
-			log.Error("VestingsList error: ", zap.Error(err))
+			log.Ctx(r.Context()).Error("VestingsList error: ", zap.Error(err))
 		}
 
 		response.JsonError(w, err)
@@ -180,14 +335,14 @@ func (api *API) ContractVesting(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	service := services.New(repos.New(networkContext.Db), repos.New(networkContext.IndexerDB), networkContext.Client, networkContext.Auth, net)
+	service := newService(r, net, networkContext)
 
 	reps, err := service.ContractVesting(user, contractAddress, data)
 	if err != nil {
 		//Unwrap apperror
 		err, IsAppErr := apperrors.Unwrap(err)
 		if !IsAppErr {
-			log.Error("ContractVesting error: ", zap.Error(err))
+			log.Ctx(r.Context()).Error("ContractVesting error: ", zap.Error(err))
 		}
 
 		response.JsonError(w, err)
@@ -223,14 +378,14 @@ func (api *API) ContractVestingEdit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	service := services.New(repos.New(networkContext.Db), repos.New(networkContext.IndexerDB), networkContext.Client, networkContext.Auth, net)
+	service := newService(r, net, networkContext)
 
 	reps, err := service.ContractVestingEdit(user, contractAddress, data)
 	if err != nil {
 		//Unwrap apperror
 		err, IsAppErr := apperrors.Unwrap(err)
 		if !IsAppErr {
-			log.Error("ContractVestingEdit error: ", zap.Error(err))
+			log.Ctx(r.Context()).Error("ContractVestingEdit error: ", zap.Error(err))
 		}
 
 		response.JsonError(w, err)
@@ -266,14 +421,132 @@ func (api *API) RemoveContractVesting(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	service := services.New(repos.New(networkContext.Db), repos.New(networkContext.IndexerDB), networkContext.Client, networkContext.Auth, net)
+	service := newService(r, net, networkContext)
 
 	err = service.RemoveContractVesting(user, contractAddress, data)
 	if err != nil {
 		//Unwrap apperror
 		err, IsAppErr := apperrors.Unwrap(err)
 		if !IsAppErr {
-			log.Error("RemoveContractVesting error: ", zap.Error(err))
+			log.Ctx(r.Context()).Error("RemoveContractVesting error: ", zap.Error(err))
+		}
+
+		response.JsonError(w, err)
+		return
+	}
+
+	response.Json(w, map[string]interface{}{"message": "success"})
+}
+
+func (api *API) VestingClaimRules(w http.ResponseWriter, r *http.Request) {
+	_, net, networkContext, err := GetUserNetworkContext(r)
+	if err != nil {
+		response.JsonError(w, err)
+		return
+	}
+
+	contractAddress := types.Address(mux.Vars(r)[ContractIDParam])
+	err = contractAddress.Validate()
+	if err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadParam, ContractIDParam))
+		return
+	}
+
+	service := newService(r, net, networkContext)
+
+	resp, err := service.VestingClaimRules(contractAddress)
+	if err != nil {
+		//Unwrap apperror
+		err, IsAppErr := apperrors.Unwrap(err)
+		if !IsAppErr {
+			log.Ctx(r.Context()).Error("VestingClaimRules error: ", zap.Error(err))
+		}
+
+		response.JsonError(w, err)
+		return
+	}
+
+	response.Json(w, resp)
+}
+
+func (api *API) VestingClaimRule(w http.ResponseWriter, r *http.Request) {
+	_, net, networkContext, err := GetUserNetworkContext(r)
+	if err != nil {
+		response.JsonError(w, err)
+		return
+	}
+
+	contractAddress := types.Address(mux.Vars(r)[ContractIDParam])
+	err = contractAddress.Validate()
+	if err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadParam, ContractIDParam))
+		return
+	}
+
+	//Rule is active unless disabled explicitly
+	data := models.VestingClaimRule{IsActive: true}
+	err = json.NewDecoder(r.Body).Decode(&data)
+	if err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadRequest))
+		return
+	}
+
+	if err = data.Validate(); err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadParam, err.Error()))
+		return
+	}
+
+	service := newService(r, net, networkContext).WithConfig(api.cfg)
+
+	err = service.SaveVestingClaimRule(contractAddress, data)
+	if err != nil {
+		//Unwrap apperror
+		err, IsAppErr := apperrors.Unwrap(err)
+		if !IsAppErr {
+			log.Ctx(r.Context()).Error("VestingClaimRule error: ", zap.Error(err))
+		}
+
+		response.JsonError(w, err)
+		return
+	}
+
+	response.Json(w, map[string]interface{}{"message": "success"})
+}
+
+func (api *API) RemoveVestingClaimRule(w http.ResponseWriter, r *http.Request) {
+	_, net, networkContext, err := GetUserNetworkContext(r)
+	if err != nil {
+		response.JsonError(w, err)
+		return
+	}
+
+	contractAddress := types.Address(mux.Vars(r)[ContractIDParam])
+	err = contractAddress.Validate()
+	if err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadParam, ContractIDParam))
+		return
+	}
+
+	var data models.VestingClaimRule
+	err = json.NewDecoder(r.Body).Decode(&data)
+	if err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadRequest))
+		return
+	}
+
+	if err = data.VestingAddress.Validate(); err != nil {
+		response.JsonError(w, apperrors.New(apperrors.ErrBadParam, "vesting_address"))
+		return
+	}
+
+	service := newService(r, net, networkContext)
+
+	err = service.RemoveVestingClaimRule(contractAddress, data.VestingAddress)
+	if err != nil {
+		//Unwrap apperror
+		err, IsAppErr := apperrors.Unwrap(err)
+		if !IsAppErr {
+			log.Ctx(r.Context()).Error("RemoveVestingClaimRule error: ", zap.Error(err))
 		}
 
 		response.JsonError(w, err)
@@ -1,6 +1,7 @@
 package apperrors
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -23,6 +24,8 @@ const (
 	ErrBadSignature        ErrCode = "ERR_BAD_SIGNATURE"
 	ErrBadAuthCookie       ErrCode = "ERR_BAD_AUTH_COOKIE"
 	ErrNotEnoughPermission ErrCode = "ERR_NOT_ENOUGH_PERMISSION"
+	ErrTooManyRequests     ErrCode = "ERR_TOO_MANY_REQUESTS"
+	ErrTimeout             ErrCode = "ERR_TIMEOUT"
 )
 
 type (
@@ -70,6 +73,10 @@ func (e Error) GetHttpCode() int {
 	switch e.Code {
 	case ErrService:
 		return http.StatusInternalServerError
+	case ErrTooManyRequests:
+		return http.StatusTooManyRequests
+	case ErrTimeout:
+		return http.StatusGatewayTimeout
 	default:
 		return http.StatusBadRequest
 	}
@@ -102,8 +109,12 @@ func Unwrap(err error) (error, bool) {
 	return unwrapErr, true
 }
 
-// FromError creates a new Error (ErrService) from common golang error
+// FromError creates a new Error (ErrService or ErrTimeout on request deadline) from common golang error
 func FromError(err error) *Error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &Error{Code: ErrTimeout}
+	}
+
 	if err != nil {
 		return &Error{
 			Code:  ErrService,
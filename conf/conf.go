@@ -1,46 +1,192 @@
 package conf
 
 import (
+	"fmt"
+	"net"
+	"strings"
 	"tezosign/common/baseconf"
 	"tezosign/common/baseconf/types"
 	"tezosign/models"
 	"tezosign/services/rpc_client/client"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 )
 
 type (
 	Config struct {
-		API      API
-		LogLevel string
-		Cron     Cron
-		Networks []Network
+		API           API
+		LogLevel      string
+		Cron          Cron
+		Metadata      Metadata
+		TokenBalances TokenBalances
+		Payloads      Payloads
+		Cache         Cache
+		Networks      []Network
+	}
+
+	Cache struct {
+		//Balances and storages TTL in seconds, entries are also dropped on new head
+		HeadTTL int64
+		//Head level poll period in seconds, 0 disables head invalidation
+		HeadPollPeriod int64
+		//Max entries per bucket, least recently used entries are evicted first
+		BucketSize int
+	}
+
+	Payloads struct {
+		//Execute contract code against current storage before saving payload
+		Simulation bool
+	}
+
+	TokenBalances struct {
+		//Ledger big maps source: indexer or rpc
+		Source string
+		//Use better-call.dev API when ledger can not be read locally
+		RemoteFallback bool
+		//Cached balance lifetime in seconds
+		TTL int64
+	}
+
+	Metadata struct {
+		//IPFS HTTP gateway used for ipfs:// URIs
+		IPFSGateway string
+		//Off-chain fetch timeout in seconds
+		Timeout int64
+		//Max off-chain document size in bytes
+		MaxSize int64
+		//Cached metadata refresh period in seconds
+		RefreshPeriod int64
 	}
 
 	API struct {
 		ListenOnPort       uint64
 		CORSAllowedOrigins []string
 		IsProtocolHttps    bool
+		//Domain used in Sign-In with Tezos messages, signed messages of other domains are rejected
+		Domain    string
+		RateLimit RateLimit
+		//Request timeout in seconds, 0 disables timeout
+		RequestTimeout int64
+		//Request timeouts in seconds by route path like /{network}/contract/operation/{operation_id}/build
+		RouteTimeouts map[string]int64
+	}
+
+	RateLimit struct {
+		//memory or postgres
+		Storage string
+		//Reverse proxies addresses or CIDRs, X-Forwarded-For is used only for requests from them
+		TrustedProxies []string
+		//Budgets by route name
+		Routes map[string]RateLimitBudget
+	}
+
+	RateLimitBudget struct {
+		//Bucket capacity
+		Requests uint64
+		//Full bucket refill period in seconds
+		Period int64
 	}
 
 	Cron struct {
 		Operations int64
 		Assets     int64
+		RateLimit  int64
+		//Expired access tokens deny-list cleanup
+		RevokedTokens int64
+		//Token balances cache refresh
+		TokenBalances int64
+		//Unknown tokens discovery
+		AssetDiscovery int64
+		//DEX prices snapshots
+		AssetPrices int64
+		//Contracts total value history
+		Portfolios int64
+		//Vesting auto claim rules check
+		VestingClaims int64
+		//Vesting contracts calls history
+		VestingOperations int64
+	}
+
+	NodeRpcPool struct {
+		//Node request timeout in seconds
+		Timeout int64
+		//Retries of GET requests on next nodes, negative disables retries
+		Retries int
+		//Consecutive node failures opening its circuit
+		FailureThreshold int
+		//Open circuit period in seconds
+		CooldownPeriod int64
+		//Head level check period in seconds, 0 disables checks
+		HealthCheckPeriod int64
+		//Allowed head level lag behind best node
+		MaxLevelLag int64
 	}
 
 	Auth struct {
+		//Single signing key, used when Keys are not set
 		AuthKey         string
+		Keys            []AuthKey
 		SessionHashKey  string
 		SessionBlockKey string
 	}
 
+	AuthKey struct {
+		//JWT kid header
+		ID string
+		//Hexed ecdsa private key
+		Key string
+		//Key is only used to verify tokens
+		VerifyOnly bool
+		//Unix timestamp from which key signs tokens, newest started key is active
+		ActiveFrom int64
+		//Unix timestamp after which key is dropped, 0 for no limit
+		ExpiresAt int64
+	}
+
 	Network struct {
 		Name          models.Network
 		Params        types.DBParams
 		IndexerParams types.DBParams
 		Auth          Auth
 		NodeRpc       client.TransportConfig
+		//Failover nodes in priority order after NodeRpc
+		NodeRpcFallbacks []client.TransportConfig
+		NodeRpcPool      NodeRpcPool
+		//Owner public key used to propose automatic vesting claims
+		VestingClaimProposer string
+		//Known bakers terms, indexer does not store them
+		Bakers []Baker
+		//Trusted DEX pools for price oracle
+		Dex Dex
 	}
+
+	Dex struct {
+		//Factory contracts which originate pools
+		Factories []string
+		//Indexer code hashes of pool contracts
+		CodeHashes []int64
+		//Min XTZ value of pool counter side reserve
+		MinLiquidity int64
+	}
+
+	Baker struct {
+		Address string
+		//Fee in percents of rewards
+		Fee float64
+		//Accounts which pay rewards, baker address counts only if listed
+		Payouts []string
+	}
+)
+
+const (
+	TokenBalancesSourceIndexer = "indexer"
+	TokenBalancesSourceRPC     = "rpc"
+)
+
+const (
+	RateLimitStorageMemory   = "memory"
+	RateLimitStoragePostgres = "postgres"
 )
 
 const (
@@ -67,9 +213,172 @@ func NewFromFile(cfgFile *string) (cfg Config, err error) {
 // Validate validates all Config fields.
 func (config Config) Validate() error {
 	//TODO add validations
+	err := config.API.Validate()
+	if err != nil {
+		return err
+	}
+
+	switch config.TokenBalances.Source {
+	case "", TokenBalancesSourceIndexer, TokenBalancesSourceRPC:
+	default:
+		return fmt.Errorf("unknown token balances source %s", config.TokenBalances.Source)
+	}
+
+	for i := range config.Networks {
+		err = config.Networks[i].Auth.Validate()
+		if err != nil {
+			return fmt.Errorf("network %s: %s", config.Networks[i].Name, err.Error())
+		}
+
+		for _, baker := range config.Networks[i].Bakers {
+			if baker.Fee < 0 || baker.Fee > 100 {
+				return fmt.Errorf("network %s: wrong baker %s fee", config.Networks[i].Name, baker.Address)
+			}
+		}
+	}
+
 	return baseconf.ValidateBaseConfigStructs(&config)
 }
 
+// Network returns config of network by name
+func (config Config) Network(name models.Network) (network Network, isFound bool) {
+	for i := range config.Networks {
+		if config.Networks[i].Name == name {
+			return config.Networks[i], true
+		}
+	}
+
+	return network, false
+}
+
+func (a API) Validate() error {
+	if a.Domain == "" {
+		return fmt.Errorf("empty sign-in domain")
+	}
+
+	if a.RequestTimeout < 0 {
+		return fmt.Errorf("wrong request timeout")
+	}
+
+	for route, timeout := range a.RouteTimeouts {
+		if timeout < 0 {
+			return fmt.Errorf("wrong request timeout for route %s", route)
+		}
+	}
+
+	return a.RateLimit.Validate()
+}
+
+func (r RateLimit) Validate() error {
+	switch r.Storage {
+	case "", RateLimitStorageMemory, RateLimitStoragePostgres:
+	default:
+		return fmt.Errorf("unknown rate limit storage %s", r.Storage)
+	}
+
+	for route, budget := range r.Routes {
+		if budget.Requests == 0 || budget.Period <= 0 {
+			return fmt.Errorf("wrong rate limit budget for route %s", route)
+		}
+	}
+
+	_, err := r.TrustedProxyNets()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// TrustedProxyNets parses trusted proxies, single address is treated as host network
+func (r RateLimit) TrustedProxyNets() (nets []*net.IPNet, err error) {
+	for _, proxy := range r.TrustedProxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("wrong trusted proxy %s", proxy)
+			}
+
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("wrong trusted proxy %s", proxy)
+		}
+
+		nets = append(nets, ipNet)
+	}
+
+	return nets, nil
+}
+
+func (r RateLimit) IsPostgres() bool {
+	return r.Storage == RateLimitStoragePostgres
+}
+
+// RefillRate returns amount of tokens added to bucket per second
+func (b RateLimitBudget) RefillRate() float64 {
+	return float64(b.Requests) / float64(b.Period)
+}
+
+// Baker returns configured baker terms
+func (n Network) Baker(address string) (baker Baker, isFound bool) {
+	for i := range n.Bakers {
+		if n.Bakers[i].Address == address {
+			return n.Bakers[i], true
+		}
+	}
+
+	return baker, false
+}
+
+// NodeRpcs returns node endpoints in priority order
+func (n Network) NodeRpcs() []client.TransportConfig {
+	return append([]client.TransportConfig{n.NodeRpc}, n.NodeRpcFallbacks...)
+}
+
+func (a Auth) Validate() error {
+	if len(a.Keys) == 0 {
+		return nil
+	}
+
+	now := time.Now().Unix()
+	ids := map[string]bool{}
+	hasSigningKey := false
+	for _, key := range a.Keys {
+		if key.ID == "" {
+			return fmt.Errorf("empty auth key id")
+		}
+
+		if ids[key.ID] {
+			return fmt.Errorf("duplicate auth key id %s", key.ID)
+		}
+		ids[key.ID] = true
+
+		if key.ExpiresAt != 0 && key.ExpiresAt <= key.ActiveFrom {
+			return fmt.Errorf("auth key %s expires before activation", key.ID)
+		}
+
+		if !key.VerifyOnly && key.ActiveFrom <= now && (key.ExpiresAt == 0 || key.ExpiresAt > now) {
+			hasSigningKey = true
+		}
+	}
+
+	//AuthKey signs until configured keys become active
+	if !hasSigningKey && a.AuthKey == "" {
+		return fmt.Errorf("active signing auth key not presented")
+	}
+
+	return nil
+}
+
 // DbLogger is a simple log wrapper for use with gorm and logrus.
 type DbLogger struct{}
 
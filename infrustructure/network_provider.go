@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"tezosign/repos/postgres"
 	"tezosign/services/auth"
+	"tezosign/services/cache"
+	"tezosign/services/ratelimit"
 	"tezosign/services/rpc_client"
+	"time"
 
 	"tezosign/conf"
 	"tezosign/models"
@@ -17,13 +20,16 @@ type NetworkContext struct {
 	IndexerDB *gorm.DB
 	Auth      *auth.Auth
 	Client    *rpc_client.Tezos
+	Cache     *cache.Cache
+
+	RateLimiter ratelimit.Limiter
 }
 
 type Provider struct {
 	networks map[models.Network]NetworkContext
 }
 
-func New(configs []conf.Network) (*Provider, error) {
+func New(configs []conf.Network, rateLimitConf conf.RateLimit, cacheConf conf.Cache) (*Provider, error) {
 	provider := &Provider{
 		networks: make(map[models.Network]NetworkContext),
 	}
@@ -39,7 +45,20 @@ func New(configs []conf.Network) (*Provider, error) {
 			return nil, err
 		}
 
-		rpcClient := rpc_client.New(configs[i].NodeRpc, configs[i].Name, configs[i].Name != models.NetworkMain)
+		poolConf := configs[i].NodeRpcPool
+		rpcClient := rpc_client.NewPool(configs[i].NodeRpcs(), rpc_client.PoolConfig{
+			Timeout:           time.Duration(poolConf.Timeout) * time.Second,
+			Retries:           poolConf.Retries,
+			FailureThreshold:  poolConf.FailureThreshold,
+			CooldownPeriod:    time.Duration(poolConf.CooldownPeriod) * time.Second,
+			HealthCheckPeriod: time.Duration(poolConf.HealthCheckPeriod) * time.Second,
+			MaxLevelLag:       poolConf.MaxLevelLag,
+		}, configs[i].Name, configs[i].Name != models.NetworkMain)
+
+		networkCache := cache.New(time.Duration(cacheConf.HeadTTL)*time.Second, cacheConf.BucketSize)
+		if cacheConf.HeadPollPeriod > 0 {
+			networkCache.WatchHead(time.Duration(cacheConf.HeadPollPeriod)*time.Second, rpcClient.HeadLevel)
+		}
 
 		authProvider, err := auth.NewAuthProvider(configs[i].Auth, configs[i].Name)
 		if err != nil {
@@ -51,6 +70,9 @@ func New(configs []conf.Network) (*Provider, error) {
 			IndexerDB: indexerDb,
 			Auth:      authProvider,
 			Client:    rpcClient,
+			Cache:     networkCache,
+
+			RateLimiter: ratelimit.New(rateLimitConf, db),
 		}
 	}
 	return provider, nil
@@ -58,6 +80,9 @@ func New(configs []conf.Network) (*Provider, error) {
 
 func (p *Provider) Close() {
 	for _, v := range p.networks {
+		v.Client.Close()
+		v.Cache.Close()
+
 		sqlDB, err := v.Db.DB()
 		if err != nil {
 			return
@@ -26,7 +26,7 @@ func main() {
 		log.Fatal("can`t read config from file", zap.Error(err))
 	}
 
-	provider, err := infrustructure.New(cfg.Networks)
+	provider, err := infrustructure.New(cfg.Networks, cfg.API.RateLimit, cfg.Cache)
 	if err != nil {
 		log.Fatal("", zap.Error(err))
 	}
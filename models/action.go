@@ -9,10 +9,12 @@ const (
 	FA2Transfer   ActionType = "fa2_transfer"
 	StorageUpdate ActionType = "storage_update"
 	CustomPayload ActionType = "custom"
+	ContractCall  ActionType = "contract_call"
 
 	//Vesting
 	VestingVest        ActionType = "vesting_vest"
 	VestingSetDelegate ActionType = "vesting_set_delegate"
+	VestingRevoke      ActionType = "vesting_revoke"
 
 	//Income transfer
 	IncomeTransfer    ActionType = "income_transfer"
@@ -26,6 +26,8 @@ type Asset struct {
 
 	TokenID  *uint64 `gorm:"column:ast_token_id" json:"token_id,omitempty"`
 	IsActive bool    `gorm:"column:ast_is_active" json:"-"`
+	//Discovered asset waiting for owner decision
+	IsSuggested bool `gorm:"column:ast_is_suggested" json:"-"`
 
 	ContractID sql.NullInt64 `gorm:"column:ctr_id" json:"-"`
 
@@ -39,7 +41,7 @@ type Asset struct {
 
 func (a Asset) Validate() (err error) {
 
-	if err = a.Address.Validate(); err != nil {
+	if err = a.ValidateToken(); err != nil {
 		return err
 	}
 
@@ -51,6 +53,15 @@ func (a Asset) Validate() (err error) {
 		return errors.New("ticker")
 	}
 
+	return nil
+}
+
+//Validate token reference, other fields can be filled by token metadata
+func (a Asset) ValidateToken() (err error) {
+	if err = a.Address.Validate(); err != nil {
+		return err
+	}
+
 	if a.ContractType != TypeFA12 && a.ContractType != TypeFA2 {
 		return errors.New("contract_type")
 	}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/hex"
 	"fmt"
 	"strings"
 	"tezosign/types"
@@ -12,19 +13,46 @@ type TokenType string
 const (
 	TypeAuth    TokenType = "auth"
 	TypeRefresh TokenType = "refresh"
+	//Sign-In with Tezos structured message
+	TypeAuthMicheline TokenType = "auth_micheline"
 )
 
+type AuthPayloadFormat string
+
+const (
+	AuthPayloadFormatLegacy    AuthPayloadFormat = "legacy"
+	AuthPayloadFormatMicheline AuthPayloadFormat = "micheline"
+)
+
+func (f AuthPayloadFormat) Validate() error {
+	switch f {
+	case "", AuthPayloadFormatLegacy, AuthPayloadFormatMicheline:
+		return nil
+	default:
+		return fmt.Errorf("unknown payload format")
+	}
+}
+
+func (f AuthPayloadFormat) TokenType() TokenType {
+	if f == AuthPayloadFormatMicheline {
+		return TypeAuthMicheline
+	}
+	return TypeAuth
+}
+
 type AuthToken struct {
 	ID        uint64       `gorm:"column:atn_id;primaryKey"`
 	PubKey    types.PubKey `gorm:"column:atn_pubkey"`
 	Type      TokenType    `gorm:"column:atn_type"`
-	Data      string       `gorm:"column:atn_data"` //token uuid
+	Data      string       `gorm:"column:atn_data"`    //token uuid
+	Payload   *string      `gorm:"column:atn_payload"` //signed message for structured formats
 	IsUsed    bool         `gorm:"column:atn_is_used"`
 	ExpiresAt time.Time    `gorm:"column:atn_expires_at"`
 }
 
 type AuthTokenReq struct {
-	PubKey types.PubKey `json:"pub_key"`
+	PubKey types.PubKey      `json:"pub_key"`
+	Format AuthPayloadFormat `json:"format,omitempty"`
 }
 
 func (r AuthTokenReq) Validate() (err error) {
@@ -33,11 +61,19 @@ func (r AuthTokenReq) Validate() (err error) {
 	if err != nil {
 		return err
 	}
+
+	err = r.Format.Validate()
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
 type AuthTokenResp struct {
-	Token AuthTokenPayload `json:"token"`
+	Token       AuthTokenPayload  `json:"token"`
+	PayloadType AuthPayloadFormat `json:"payload_type"`
+	//Human readable message packed into token
+	Message string `json:"message,omitempty"`
 }
 
 func (r AuthToken) Expired() bool {
@@ -69,7 +105,20 @@ func (t AuthTokenPayload) Token() string {
 	return tokens[1]
 }
 
+//Packed micheline payload in hex
+func (t AuthTokenPayload) IsMicheline() bool {
+	return strings.HasPrefix(string(t), "05")
+}
+
 func (t AuthTokenPayload) Validate() (err error) {
+	if t.IsMicheline() {
+		_, err = hex.DecodeString(string(t))
+		if err != nil {
+			return fmt.Errorf("wrong payload format")
+		}
+		return nil
+	}
+
 	if !strings.HasPrefix(string(t), AuthTokenPayloadPrefix) {
 		return fmt.Errorf("wrong payload format")
 	}
@@ -81,8 +130,11 @@ func (t AuthTokenPayload) Validate() (err error) {
 	return nil
 }
 
-//Convert UTF8 to bytes
+//Convert UTF8 to bytes, micheline payload is decoded from hex
 func (t AuthTokenPayload) MarshalBinary() ([]byte, error) {
+	if t.IsMicheline() {
+		return hex.DecodeString(string(t))
+	}
 	return []byte(string(t)), nil
 }
 
@@ -2,6 +2,7 @@ package models
 
 import (
 	"tezosign/types"
+	"time"
 
 	"github.com/wedancedalot/decimal"
 )
@@ -20,3 +21,13 @@ type TokenBalance struct {
 	Balance decimal.Decimal `json:"balance"`
 	TokenId uint64          `json:"token_id"`
 }
+
+//Locally cached token balance of holder
+type HolderTokenBalance struct {
+	ID        uint64          `gorm:"column:tbl_id;primaryKey"`
+	Holder    types.Address   `gorm:"column:tbl_holder"`
+	Asset     types.Address   `gorm:"column:tbl_asset"`
+	TokenID   uint64          `gorm:"column:tbl_token_id"`
+	Balance   decimal.Decimal `gorm:"column:tbl_balance"`
+	UpdatedAt time.Time       `gorm:"column:tbl_updated_at"`
+}
@@ -65,6 +65,8 @@ type ContractInfo struct {
 	Threshold int64         `json:"threshold"`
 	Counter   int64         `json:"counter"`
 	Owners    []Owner       `json:"owners"`
+
+	Delegation *DelegationInfo `json:"delegation,omitempty"`
 }
 
 type Owner struct {
@@ -87,7 +89,12 @@ type ContractOperationRequest struct {
 	AssetID      types.Address  `json:"asset_id,omitempty"`
 	TransferList []TransferUnit `json:"transfer_list,omitempty"`
 
-	//Custom json michelson payload
+	//Contract call, To is called contract
+	Entrypoint string `json:"entrypoint,omitempty"`
+	//JSON value described by entrypoint schema
+	Parameter json.RawMessage `json:"parameter,omitempty"`
+
+	//Custom json michelson payload, contract call lambda
 	CustomPayload types.Payload `json:"custom_payload,omitempty"`
 	//Internal params
 	//Update storage
@@ -226,6 +233,24 @@ func (r ContractOperationRequest) Validate() (err error) {
 		if err != nil {
 			return err
 		}
+	case VestingRevoke:
+		err = r.VestingID.Validate()
+		if err != nil {
+			return err
+		}
+	case ContractCall:
+		err = r.To.Validate()
+		if err != nil {
+			return err
+		}
+
+		if r.Entrypoint == "" {
+			return fmt.Errorf("empty entrypoint")
+		}
+
+		if len(r.Parameter) != 0 && !json.Valid(r.Parameter) {
+			return fmt.Errorf("wrong parameter")
+		}
 	case CustomPayload:
 		if !json.Valid([]byte(r.CustomPayload)) {
 			return fmt.Errorf("wrong custom payload")
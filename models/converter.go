@@ -0,0 +1,69 @@
+package models
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+)
+
+//Expressions are passed as Micheline JSON or as JSON string with Michelson source
+type ConvertPackRequest struct {
+	Type  json.RawMessage `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+func (r ConvertPackRequest) Validate() error {
+	if len(r.Type) == 0 {
+		return errors.New("type")
+	}
+
+	if len(r.Value) == 0 {
+		return errors.New("value")
+	}
+
+	return nil
+}
+
+type ConvertUnpackRequest struct {
+	Type json.RawMessage `json:"type"`
+	//Hex with 0x05 watermark
+	Packed string `json:"packed"`
+}
+
+func (r ConvertUnpackRequest) Validate() error {
+	if len(r.Type) == 0 {
+		return errors.New("type")
+	}
+
+	if _, err := hex.DecodeString(r.Packed); err != nil || len(r.Packed) == 0 {
+		return errors.New("packed")
+	}
+
+	return nil
+}
+
+type ConvertMichelsonRequest struct {
+	//Empty if binary is set
+	Expression json.RawMessage `json:"expression,omitempty"`
+	//Hex of binary Micheline
+	Binary string `json:"binary,omitempty"`
+}
+
+func (r ConvertMichelsonRequest) Validate() error {
+	if (len(r.Expression) == 0) == (len(r.Binary) == 0) {
+		return errors.New("expression")
+	}
+
+	if _, err := hex.DecodeString(r.Binary); err != nil {
+		return errors.New("binary")
+	}
+
+	return nil
+}
+
+type ConvertResponse struct {
+	Packed    string          `json:"packed,omitempty"`
+	Binary    string          `json:"binary"`
+	Micheline json.RawMessage `json:"micheline"`
+	Michelson string          `json:"michelson"`
+}
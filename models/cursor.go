@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+//Last processed indexer level of background job
+type SyncCursor struct {
+	Name      string    `gorm:"column:scr_name;primaryKey"`
+	Level     uint64    `gorm:"column:scr_level"`
+	UpdatedAt time.Time `gorm:"column:scr_updated_at"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import "tezosign/types"
+
+type DelegationInfo struct {
+	Baker types.Address `json:"baker"`
+	//Fee in percents of rewards, known only for configured bakers
+	BakerFee *float64 `json:"baker_fee,omitempty"`
+	//Level of the last delegation operation
+	DelegationLevel uint64 `json:"delegation_level"`
+	//Sum of incoming transfers from baker and baker payout accounts since delegation level
+	EstimatedRewards uint64 `json:"estimated_rewards"`
+	RewardsTransfers uint64 `json:"rewards_transfers"`
+}
+
+type TransfersSummary struct {
+	Amount uint64 `gorm:"column:amount"`
+	Count  uint64 `gorm:"column:count"`
+}
+
+type Baker struct {
+	Address         types.Address `gorm:"column:Address" json:"address"`
+	Balance         uint64        `gorm:"column:Balance" json:"balance"`
+	StakingBalance  uint64        `gorm:"column:StakingBalance" json:"staking_balance"`
+	DelegatorsCount uint64        `gorm:"column:DelegatorsCount" json:"delegators_count"`
+
+	//Indexer does not store baker terms, fee is known only for configured bakers
+	Fee             *float64 `gorm:"-" json:"fee,omitempty"`
+	StakingCapacity uint64   `gorm:"-" json:"staking_capacity"`
+	FreeSpace       int64    `gorm:"-" json:"free_space"`
+}
+
+//Estimate max staking balance which baker deposits allow, frozen deposits percentage is a protocol constant
+func (b *Baker) CalcCapacity(frozenDepositsPercentage int64) {
+	if frozenDepositsPercentage <= 0 {
+		return
+	}
+
+	b.StakingCapacity = b.Balance * 100 / uint64(frozenDepositsPercentage)
+	b.FreeSpace = int64(b.StakingCapacity) - int64(b.StakingBalance)
+}
@@ -0,0 +1,12 @@
+package models
+
+import "encoding/json"
+
+type ContractEntrypoint struct {
+	Name string `json:"name"`
+	//Parameter has literal value and can be called by contract_call action
+	IsCallable bool `json:"is_callable"`
+	//Micheline parameter type
+	Type   json.RawMessage        `json:"type"`
+	Schema map[string]interface{} `json:"schema"`
+}
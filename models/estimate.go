@@ -0,0 +1,84 @@
+package models
+
+import "encoding/json"
+
+//Subset of protocol constants used for estimation
+type ProtocolConstants struct {
+	HardGasLimitPerOperation     int64 `json:"hard_gas_limit_per_operation,string"`
+	HardStorageLimitPerOperation int64 `json:"hard_storage_limit_per_operation,string"`
+	CostPerByte                  int64 `json:"cost_per_byte,string"`
+	OriginationSize              int64 `json:"origination_size"`
+	//Baker deposits share of staking balance, absent before Ithaca
+	FrozenDepositsPercentage int64 `json:"frozen_deposits_percentage"`
+}
+
+//Body of node run_operation helper
+type RunOperationRequest struct {
+	Operation RunOperation `json:"operation"`
+	ChainID   string       `json:"chain_id"`
+}
+
+type RunOperation struct {
+	Branch    string           `json:"branch"`
+	Contents  []RunTransaction `json:"contents"`
+	Signature string           `json:"signature"`
+}
+
+type RunTransaction struct {
+	Kind         string                 `json:"kind"`
+	Source       string                 `json:"source"`
+	Fee          int64                  `json:"fee,string"`
+	Counter      int64                  `json:"counter,string"`
+	GasLimit     int64                  `json:"gas_limit,string"`
+	StorageLimit int64                  `json:"storage_limit,string"`
+	Amount       int64                  `json:"amount,string"`
+	Destination  string                 `json:"destination"`
+	Parameters   *TransactionParameters `json:"parameters,omitempty"`
+}
+
+type TransactionParameters struct {
+	Entrypoint string          `json:"entrypoint"`
+	Value      json.RawMessage `json:"value"`
+}
+
+type RunOperationResult struct {
+	Contents []struct {
+		Metadata struct {
+			OperationResult          OperationResult `json:"operation_result"`
+			InternalOperationResults []struct {
+				Result OperationResult `json:"result"`
+			} `json:"internal_operation_results"`
+		} `json:"metadata"`
+	} `json:"contents"`
+}
+
+type OperationResult struct {
+	Status                       string   `json:"status"`
+	ConsumedGas                  int64    `json:"consumed_gas,string"`
+	ConsumedMilligas             int64    `json:"consumed_milligas,string"`
+	PaidStorageSizeDiff          int64    `json:"paid_storage_size_diff,string"`
+	AllocatedDestinationContract bool     `json:"allocated_destination_contract"`
+	OriginatedContracts          []string `json:"originated_contracts"`
+	Errors                       []struct {
+		ID string `json:"id"`
+	} `json:"errors"`
+}
+
+//Operation rejected by node or failed in simulation
+type OperationFailure struct {
+	Reason string
+}
+
+func (e OperationFailure) Error() string {
+	return e.Reason
+}
+
+//Simulated cost of operation submission, amounts in mutez
+type OperationEstimate struct {
+	GasLimit     int64 `json:"gas_limit"`
+	StorageLimit int64 `json:"storage_limit"`
+	//Storage burn
+	Burn int64 `json:"burn"`
+	//Suggested baker fee
+	Fee int64 `json:"fee"`
+}
@@ -6,6 +6,16 @@ import (
 	"time"
 )
 
+//Indexer account types
+const (
+	AccountTypeUser     uint8 = 0
+	AccountTypeDelegate uint8 = 1
+	AccountTypeContract uint8 = 2
+)
+
+//Indexer operation status of applied operation
+const OperationStatusApplied = 1
+
 type Storage struct {
 	Level     uint64         `gorm:"column:Level"`
 	Current   bool           `gorm:"column:Current"`
@@ -20,13 +30,29 @@ type Script struct {
 	CodeSchema      types.TZKTPrim `gorm:"column:CodeSchema"`
 }
 
+type BigMapKey struct {
+	BigMapPtr int64          `gorm:"column:BigMapPtr"`
+	KeyHash   string         `gorm:"column:KeyHash"`
+	RawValue  types.TZKTPrim `gorm:"column:RawValue"`
+	LastLevel uint64         `gorm:"column:LastLevel"`
+}
+
 type Account struct {
 	Id      uint64        `gorm:"column:Id"`
 	Address types.Address `gorm:"column:Address"`
 	Type    uint8         `gorm:"column:Type"`
 	Balance uint64        `gorm:"column:Balance"`
 
-	DelegateID sql.NullInt64 `gorm:"column:DelegateId"`
+	DelegateID      sql.NullInt64 `gorm:"column:DelegateId"`
+	DelegationLevel sql.NullInt64 `gorm:"column:DelegationLevel"`
+}
+
+//Contract creator and code, used to check DEX pools
+type ContractOrigin struct {
+	Address        types.Address  `gorm:"column:Address"`
+	Balance        uint64         `gorm:"column:Balance"`
+	CodeHash       sql.NullInt64  `gorm:"column:CodeHash"`
+	CreatorAddress sql.NullString `gorm:"column:CreatorAddress"`
 }
 
 type Block struct {
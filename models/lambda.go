@@ -0,0 +1,92 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"tezosign/types"
+)
+
+type LambdaOperationKind string
+
+const (
+	LambdaTransfer     LambdaOperationKind = "transfer"
+	LambdaContractCall LambdaOperationKind = "contract_call"
+	LambdaDelegation   LambdaOperationKind = "delegation"
+	LambdaOrigination  LambdaOperationKind = "origination"
+)
+
+type LambdaWarningCode string
+
+const (
+	WarningSetDelegate    LambdaWarningCode = "set_delegate"
+	WarningCreateContract LambdaWarningCode = "create_contract"
+	WarningDynamicAddress LambdaWarningCode = "dynamic_address"
+	WarningDynamicAmount  LambdaWarningCode = "dynamic_amount"
+	WarningUnboundedLoop  LambdaWarningCode = "unbounded_loop"
+	WarningDynamicCode    LambdaWarningCode = "dynamic_code"
+	WarningAlwaysFails    LambdaWarningCode = "always_fails"
+	WarningUnsupported    LambdaWarningCode = "unsupported_instruction"
+)
+
+//Static analysis result of custom lambda payload
+type LambdaReport struct {
+	//Lambda is well typed lambda unit (list operation)
+	IsTypeChecked bool `json:"is_type_checked"`
+	//Type error or malformed payload
+	Error      string            `json:"error,omitempty"`
+	Operations []LambdaOperation `json:"operations"`
+	Warnings   []LambdaWarning   `json:"warnings"`
+}
+
+type LambdaOperation struct {
+	Kind LambdaOperationKind `json:"kind"`
+	//Transfer destination or new delegate, empty when computed at runtime or delegation is withdrawn
+	Destination types.Address `json:"destination,omitempty"`
+	Entrypoint  string        `json:"entrypoint,omitempty"`
+	//Empty when computed at runtime
+	Amount *uint64 `json:"amount,omitempty"`
+	//Constant call parameter
+	Parameter json.RawMessage `json:"parameter,omitempty"`
+	//Emitted under runtime condition
+	IsConditional bool `json:"is_conditional"`
+	//Emitted inside loop
+	IsRepeated bool `json:"is_repeated"`
+}
+
+type LambdaWarning struct {
+	Code    LambdaWarningCode `json:"code"`
+	Message string            `json:"message"`
+}
+
+func (r *LambdaReport) Scan(value interface{}) (err error) {
+	if value == nil {
+		return nil
+	}
+
+	data, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("invalid type")
+	}
+
+	if len(data) == 0 {
+		return nil
+	}
+
+	err = json.Unmarshal([]byte(data), r)
+	if err != nil {
+		return fmt.Errorf("json.Unmarshal: %s", err.Error())
+	}
+
+	return nil
+}
+
+func (r LambdaReport) Value() (driver.Value, error) {
+
+	bt, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(bt), nil
+}
@@ -41,6 +41,12 @@ type Request struct {
 
 	//Internal operation nonce
 	Nonce sql.NullInt64 `gorm:"column:req_nonce" json:"-"`
+
+	//Created by cron on behalf of configured proposer
+	IsSystem bool `gorm:"column:req_is_system" json:"is_system"`
+
+	//Static analysis of custom lambda
+	LambdaReport *LambdaReport `gorm:"column:req_lambda_report" json:"lambda_report,omitempty"`
 }
 
 type StorageDiff struct {
@@ -105,6 +111,8 @@ type OperationSignatureResp struct {
 type OperationParameter struct {
 	Entrypoint string `json:"entrypoint"`
 	Value      string `json:"value,omitempty"`
+	//Omitted if simulation is not possible
+	Estimate *OperationEstimate `json:"estimate,omitempty"`
 }
 
 //Indexer Tezos operation
@@ -130,6 +138,12 @@ type TransactionOperation struct {
 	InternalOperations uint64          `gorm:"column:InternalOperations"`
 }
 
+//Transfer operation with address of called asset contract
+type AssetTransferOperation struct {
+	TransactionOperation
+	Asset types.Address `gorm:"column:Address"`
+}
+
 type RevealOperation struct {
 	TezosOperation
 	PublicKey types.PubKey `gorm:"column:PublicKey"`
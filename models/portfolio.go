@@ -0,0 +1,67 @@
+package models
+
+import (
+	"tezosign/types"
+
+	"github.com/wedancedalot/decimal"
+)
+
+type PortfolioItemKind string
+
+const (
+	PortfolioItemXTZ     PortfolioItemKind = "xtz"
+	PortfolioItemToken   PortfolioItemKind = "token"
+	PortfolioItemVesting PortfolioItemKind = "vesting"
+)
+
+type PortfolioItem struct {
+	Kind    PortfolioItemKind `json:"kind"`
+	Address types.Address     `json:"address"`
+	Name    string            `json:"name,omitempty"`
+	Ticker  string            `json:"ticker,omitempty"`
+	TokenID *uint64           `json:"token_id,omitempty"`
+	//Balance in whole units
+	Balance decimal.Decimal `json:"balance"`
+	//XTZ per unit, not set for tokens without DEX price
+	Price    *decimal.Decimal           `json:"price,omitempty"`
+	XTZValue decimal.Decimal            `json:"xtz_value"`
+	Fiat     map[string]decimal.Decimal `json:"fiat"`
+}
+
+type PortfolioChange struct {
+	XTZ     decimal.Decimal `json:"xtz"`
+	Percent decimal.Decimal `json:"percent"`
+}
+
+type Portfolio struct {
+	Address   types.Address              `json:"address"`
+	Items     []PortfolioItem            `json:"items"`
+	TotalXTZ  decimal.Decimal            `json:"total_xtz"`
+	TotalFiat map[string]decimal.Decimal `json:"total_fiat"`
+
+	//Empty while history is not sampled and for non owners, snapshots contain owner totals
+	Change24h *PortfolioChange `json:"change_24h,omitempty"`
+	Change7d  *PortfolioChange `json:"change_7d,omitempty"`
+}
+
+//Change from previous total value
+func NewPortfolioChange(total decimal.Decimal, previous decimal.Decimal) *PortfolioChange {
+	change := &PortfolioChange{
+		XTZ: total.Sub(previous),
+	}
+
+	if previous.Sign() > 0 {
+		change.Percent = change.XTZ.Div(previous).Mul(decimal.New(100, 0)).Truncate(2)
+	}
+
+	return change
+}
+
+//Sampled total value of contract
+type PortfolioSnapshot struct {
+	ID         uint64              `gorm:"column:pfs_id;primaryKey" json:"-"`
+	ContractID uint64              `gorm:"column:ctr_id" json:"-"`
+	TotalXTZ   decimal.Decimal     `gorm:"column:pfs_total_xtz" json:"total_xtz"`
+	TotalUSD   decimal.Decimal     `gorm:"column:pfs_total_usd" json:"total_usd"`
+	CreatedAt  types.JSONTimestamp `gorm:"column:pfs_created_at" json:"created_at"`
+}
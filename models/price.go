@@ -0,0 +1,38 @@
+package models
+
+import (
+	"tezosign/types"
+	"time"
+
+	"github.com/wedancedalot/decimal"
+)
+
+//DEX pool discovered for asset
+type DexPool struct {
+	ID        uint64        `gorm:"column:dxp_id;primaryKey" json:"-"`
+	DEX       string        `gorm:"column:dxp_dex" json:"dex"`
+	Address   types.Address `gorm:"column:dxp_address" json:"address"`
+	Asset     types.Address `gorm:"column:dxp_asset" json:"-"`
+	TokenID   uint64        `gorm:"column:dxp_token_id" json:"-"`
+	UpdatedAt time.Time     `gorm:"column:dxp_updated_at" json:"-"`
+}
+
+//Aggregated asset price in XTZ
+type AssetPriceSnapshot struct {
+	ID      uint64        `gorm:"column:aps_id;primaryKey" json:"-"`
+	Asset   types.Address `gorm:"column:aps_asset" json:"asset"`
+	TokenID uint64        `gorm:"column:aps_token_id" json:"token_id"`
+	//XTZ per whole token
+	Price decimal.Decimal `gorm:"column:aps_price" json:"price"`
+	//XTZ locked on counter sides of pools
+	Liquidity  decimal.Decimal     `gorm:"column:aps_liquidity" json:"liquidity"`
+	PoolsCount uint64              `gorm:"column:aps_pools" json:"pools_count"`
+	CreatedAt  types.JSONTimestamp `gorm:"column:aps_created_at" json:"created_at"`
+}
+
+type AssetPrice struct {
+	AssetPriceSnapshot
+	//Price in fiat currencies by Tezos quote
+	Fiat  map[string]decimal.Decimal `json:"fiat"`
+	Pools []DexPool                  `json:"pools"`
+}
@@ -12,3 +12,15 @@ type Quote struct {
 	Jpy   decimal.Decimal `gorm:"column:Jpy" json:"jpy"`
 	Krw   decimal.Decimal `gorm:"column:Krw" json:"krw"`
 }
+
+//Fiat prices of XTZ amount
+func (q Quote) Convert(amount decimal.Decimal) map[string]decimal.Decimal {
+	return map[string]decimal.Decimal{
+		"btc": amount.Mul(q.BTC),
+		"eur": amount.Mul(q.Eur),
+		"usd": amount.Mul(q.Usd),
+		"cny": amount.Mul(q.Cny),
+		"jpy": amount.Mul(q.Jpy),
+		"krw": amount.Mul(q.Krw),
+	}
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+type RateLimitBucket struct {
+	Key       string    `gorm:"column:rlb_key;primaryKey"`
+	Tokens    float64   `gorm:"column:rlb_tokens"`
+	UpdatedAt time.Time `gorm:"column:rlb_updated_at"`
+	//Time when bucket will be refilled completely
+	FullAt time.Time `gorm:"column:rlb_full_at"`
+}
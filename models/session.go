@@ -0,0 +1,58 @@
+package models
+
+import (
+	"tezosign/types"
+	"time"
+)
+
+type Session struct {
+	ID     uint64       `gorm:"column:ses_id;primaryKey" json:"-"`
+	UUID   string       `gorm:"column:ses_uuid" json:"id"`
+	PubKey types.PubKey `gorm:"column:ses_pubkey" json:"-"`
+	//Current refresh token of session
+	RefreshToken string `gorm:"column:ses_refresh_token" json:"-"`
+	//Current access token id
+	JTI       string `gorm:"column:ses_jti" json:"-"`
+	UserAgent string `gorm:"column:ses_user_agent" json:"user_agent"`
+	IP        string `gorm:"column:ses_ip" json:"ip"`
+
+	CreatedAt  types.JSONTimestamp `gorm:"column:ses_created_at" json:"created_at"`
+	LastUsedAt types.JSONTimestamp `gorm:"column:ses_last_used_at" json:"last_used_at"`
+	ExpiresAt  types.JSONTimestamp `gorm:"column:ses_expires_at" json:"expires_at"`
+	RevokedAt  *time.Time          `gorm:"column:ses_revoked_at" json:"-"`
+
+	IsCurrent bool `gorm:"-" json:"is_current"`
+}
+
+func (s Session) IsRevoked() bool {
+	return s.RevokedAt != nil
+}
+
+//Client info of session
+type SessionMeta struct {
+	UserAgent string
+	IP        string
+}
+
+const maxUserAgentLength = 255
+
+func (m SessionMeta) Normalize() SessionMeta {
+	if len(m.UserAgent) > maxUserAgentLength {
+		m.UserAgent = m.UserAgent[:maxUserAgentLength]
+	}
+
+	return m
+}
+
+//Access tokens deny-list item, all tokens of revoked session are denied
+type RevokedToken struct {
+	ID          uint64    `gorm:"column:rvt_id;primaryKey"`
+	SessionUUID string    `gorm:"column:rvt_session_uuid"`
+	ExpiresAt   time.Time `gorm:"column:rvt_expires_at"`
+}
+
+type AccessTokenClaims struct {
+	PubKey    types.PubKey
+	SessionID string
+	JTI       string
+}
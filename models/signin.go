@@ -0,0 +1,138 @@
+package models
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"tezosign/types"
+	"time"
+
+	"blockwatch.cc/tzindex/micheline"
+)
+
+const (
+	//Beacon signPayload MICHELINE signing type expects packed data
+	michelinePackPrefix byte = 0x05
+
+	signInMessagePrefix    = "Tezos Signed Message: "
+	signInMessageStatement = " wants you to sign in with your Tezos account:"
+
+	signInFieldDomain     = "Domain"
+	signInFieldNetwork    = "Network"
+	signInFieldNonce      = "Nonce"
+	signInFieldIssuedAt   = "Issued At"
+	signInFieldExpiration = "Expiration Time"
+)
+
+//Human readable Sign-In with Tezos message
+type SignInMessage struct {
+	Domain    string
+	Address   types.Address
+	Network   Network
+	Nonce     string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+func (m SignInMessage) String() string {
+	lines := []string{
+		signInMessagePrefix + m.Domain + signInMessageStatement,
+		string(m.Address),
+		"",
+		signInField(signInFieldDomain, m.Domain),
+		signInField(signInFieldNetwork, string(m.Network)),
+		signInField(signInFieldNonce, m.Nonce),
+		signInField(signInFieldIssuedAt, m.IssuedAt.UTC().Format(time.RFC3339)),
+		signInField(signInFieldExpiration, m.ExpiresAt.UTC().Format(time.RFC3339)),
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func signInField(name, value string) string {
+	return fmt.Sprintf("%s: %s", name, value)
+}
+
+func (m SignInMessage) Expired() bool {
+	return m.ExpiresAt.Before(time.Now())
+}
+
+func ParseSignInMessage(message string) (m SignInMessage, err error) {
+	lines := strings.Split(message, "\n")
+	if len(lines) != 8 || lines[2] != "" {
+		return m, fmt.Errorf("wrong message format")
+	}
+
+	header := lines[0]
+	if !strings.HasPrefix(header, signInMessagePrefix) || !strings.HasSuffix(header, signInMessageStatement) {
+		return m, fmt.Errorf("wrong message header")
+	}
+	m.Domain = strings.TrimSuffix(strings.TrimPrefix(header, signInMessagePrefix), signInMessageStatement)
+
+	m.Address = types.Address(lines[1])
+	err = m.Address.Validate()
+	if err != nil {
+		return m, err
+	}
+
+	fields := map[string]string{}
+	for _, line := range lines[3:] {
+		parts := strings.SplitN(line, ": ", 2)
+		if len(parts) != 2 {
+			return m, fmt.Errorf("wrong message field")
+		}
+		fields[parts[0]] = parts[1]
+	}
+
+	if fields[signInFieldDomain] != m.Domain {
+		return m, fmt.Errorf("domain mismatch")
+	}
+
+	m.Network = Network(fields[signInFieldNetwork])
+	m.Nonce = fields[signInFieldNonce]
+
+	m.IssuedAt, err = time.Parse(time.RFC3339, fields[signInFieldIssuedAt])
+	if err != nil {
+		return m, fmt.Errorf("wrong issued at")
+	}
+
+	m.ExpiresAt, err = time.Parse(time.RFC3339, fields[signInFieldExpiration])
+	if err != nil {
+		return m, fmt.Errorf("wrong expiration time")
+	}
+
+	return m, nil
+}
+
+//Pack message as Micheline string with 05 prefix
+func PackSignInMessage(message string) (AuthTokenPayload, error) {
+	bt, err := micheline.Prim{Type: micheline.PrimString, String: message}.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+
+	return AuthTokenPayload(hex.EncodeToString(append([]byte{michelinePackPrefix}, bt...))), nil
+}
+
+func UnpackSignInMessage(payload AuthTokenPayload) (message string, err error) {
+	bt, err := payload.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+
+	if len(bt) == 0 || bt[0] != michelinePackPrefix {
+		return "", fmt.Errorf("wrong pack prefix")
+	}
+
+	var prim micheline.Prim
+	err = prim.UnmarshalBinary(bt[1:])
+	if err != nil {
+		return "", err
+	}
+
+	if prim.Type != micheline.PrimString {
+		return "", fmt.Errorf("payload is not a string")
+	}
+
+	return prim.String, nil
+}
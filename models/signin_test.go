@@ -0,0 +1,94 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_SignInMessage(t *testing.T) {
+	issuedAt := time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	message := SignInMessage{
+		Domain:    "tzsign.example.com",
+		Address:   "tz1boE6s8tS3pcxetHuaAPZWzHicMa39jSfj",
+		Network:   NetworkMain,
+		Nonce:     "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+		IssuedAt:  issuedAt,
+		ExpiresAt: issuedAt.Add(10 * time.Minute),
+	}
+
+	payload, err := PackSignInMessage(message.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !payload.IsMicheline() || !strings.HasPrefix(string(payload), "0501") {
+		t.Errorf("wrong payload prefix: %s", payload)
+	}
+
+	if err = payload.Validate(); err != nil {
+		t.Errorf("validate: %v", err)
+	}
+
+	unpacked, err := UnpackSignInMessage(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := ParseSignInMessage(unpacked)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parsed != message {
+		t.Errorf("parsed: %+v | expected: %+v", parsed, message)
+	}
+}
+
+func Test_ParseSignInMessage(t *testing.T) {
+	valid := SignInMessage{
+		Domain:    "tzsign.example.com",
+		Address:   "tz1boE6s8tS3pcxetHuaAPZWzHicMa39jSfj",
+		Network:   NetworkMain,
+		Nonce:     "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+		IssuedAt:  time.Now(),
+		ExpiresAt: time.Now(),
+	}.String()
+
+	testCases := []struct {
+		name    string
+		message string
+		wantErr bool
+	}{
+		{
+			name:    "Valid message",
+			message: valid,
+			wantErr: false,
+		},
+		{
+			name:    "Legacy payload",
+			message: string(NewAuthTokenPayload("6ba7b810-9dad-11d1-80b4-00c04fd430c8")),
+			wantErr: true,
+		},
+		{
+			name:    "Domain mismatch",
+			message: strings.Replace(valid, "Domain: tzsign.example.com", "Domain: evil.com", 1),
+			wantErr: true,
+		},
+		{
+			name:    "Wrong address",
+			message: strings.Replace(valid, "tz1boE6s8tS3pcxetHuaAPZWzHicMa39jSfj", "tz1wrong", 1),
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			_, gotErr := ParseSignInMessage(test.message)
+			if test.wantErr != (gotErr != nil) {
+				t.Errorf("wantErr: %t | err: %v", test.wantErr, gotErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"tezosign/types"
+	"time"
+)
+
+//Cached TZIP-21 token metadata
+type TokenMetadata struct {
+	ID      uint64        `gorm:"column:tmd_id;primaryKey" json:"-"`
+	Address types.Address `gorm:"column:tmd_address" json:"address"`
+	TokenID uint64        `gorm:"column:tmd_token_id" json:"token_id"`
+	//Off-chain metadata URI from token_info "" key
+	URI  string            `gorm:"column:tmd_uri" json:"uri,omitempty"`
+	Info TokenMetadataInfo `gorm:"column:tmd_info" json:"metadata"`
+
+	UpdatedAt     types.JSONTimestamp `gorm:"column:tmd_updated_at" json:"updated_at"`
+	NextRefreshAt types.JSONTimestamp `gorm:"column:tmd_next_refresh_at" json:"-"`
+}
+
+func (TokenMetadata) TableName() string {
+	return "token_metadata"
+}
+
+func (m TokenMetadata) IsStale() bool {
+	return time.Time(m.NextRefreshAt).Before(time.Now())
+}
+
+type TokenMetadataInfo struct {
+	Name            string           `json:"name,omitempty"`
+	Symbol          string           `json:"symbol,omitempty"`
+	Decimals        uint8            `json:"decimals"`
+	Description     string           `json:"description,omitempty"`
+	ThumbnailURI    string           `json:"thumbnail_uri,omitempty"`
+	DisplayURI      string           `json:"display_uri,omitempty"`
+	ArtifactURI     string           `json:"artifact_uri,omitempty"`
+	IsBooleanAmount bool             `json:"is_boolean_amount,omitempty"`
+	Tags            []string         `json:"tags,omitempty"`
+	Formats         []TokenFormat    `json:"formats,omitempty"`
+	Attributes      []TokenAttribute `json:"attributes,omitempty"`
+}
+
+type TokenFormat struct {
+	URI        string          `json:"uri,omitempty"`
+	Hash       string          `json:"hash,omitempty"`
+	MimeType   string          `json:"mimeType,omitempty"`
+	FileSize   uint64          `json:"fileSize,omitempty"`
+	FileName   string          `json:"fileName,omitempty"`
+	Duration   string          `json:"duration,omitempty"`
+	Dimensions *TokenFormatUnit `json:"dimensions,omitempty"`
+	DataRate   *TokenFormatUnit `json:"dataRate,omitempty"`
+}
+
+type TokenFormatUnit struct {
+	Value string `json:"value"`
+	Unit  string `json:"unit"`
+}
+
+type TokenAttribute struct {
+	Name  string          `json:"name"`
+	Value json.RawMessage `json:"value"`
+	Type  string          `json:"type,omitempty"`
+}
+
+func (i *TokenMetadataInfo) Scan(value interface{}) (err error) {
+	if value == nil {
+		return nil
+	}
+	data, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("invalid type")
+	}
+
+	if len(data) == 0 {
+		return nil
+	}
+
+	err = json.Unmarshal([]byte(data), i)
+	if err != nil {
+		return fmt.Errorf("json.Unmarshal: %s", err.Error())
+	}
+
+	return nil
+}
+
+func (i TokenMetadataInfo) Value() (driver.Value, error) {
+	bt, err := json.Marshal(i)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(bt), nil
+}
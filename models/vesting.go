@@ -11,12 +11,42 @@ import (
 //              (pair %schedule (timestamp %epoch)
 //              (pair (nat %secondsPerTick) (nat %tokensPerTick)))))
 
+type VestingTemplate string
+
+const (
+	//resources/vesting.tz
+	VestingTemplateTicks VestingTemplate = "ticks"
+	//resources/vesting_cliff.tz
+	VestingTemplateCliff VestingTemplate = "cliff"
+	//resources/vesting_revocable.tz
+	VestingTemplateRevocable VestingTemplate = "revocable"
+	//resources/vesting_shared.tz
+	VestingTemplateShared VestingTemplate = "shared"
+)
+
+type VestingBeneficiary struct {
+	Address types.Address `json:"address"`
+	Share   uint64        `json:"share"`
+
+	//Used only in resp
+	OpenedAmount uint64 `json:"opened_amount,omitempty"`
+}
+
 type VestingContractStorageRequest struct {
-	VestingAddress types.Address `json:"vesting_address"`
-	DelegateAdmin  types.Address `json:"delegate_admin"`
-	Timestamp      int64         `json:"timestamp"`
-	SecondsPerTick uint64        `json:"seconds_per_tick"`
-	TokensPerTick  uint64        `json:"tokens_per_tick"`
+	//Empty for ticks template
+	Template       VestingTemplate `json:"template,omitempty"`
+	VestingAddress types.Address   `json:"vesting_address,omitempty"`
+	DelegateAdmin  types.Address   `json:"delegate_admin"`
+	Timestamp      int64           `json:"timestamp"`
+	SecondsPerTick uint64          `json:"seconds_per_tick"`
+	TokensPerTick  uint64          `json:"tokens_per_tick"`
+
+	//Cliff template
+	Cliff int64 `json:"cliff,omitempty"`
+	//Revocable template
+	RevocationAdmin types.Address `json:"revocation_admin,omitempty"`
+	//Shared template
+	Beneficiaries []VestingBeneficiary `json:"beneficiaries,omitempty"`
 
 	//Used only in resp
 	VestedAmount uint64 `json:"vested_amount"`
@@ -24,8 +54,45 @@ type VestingContractStorageRequest struct {
 
 func (v VestingContractStorageRequest) Validate() (err error) {
 
-	if err = v.VestingAddress.Validate(); err != nil {
-		return err
+	switch v.Template {
+	case "", VestingTemplateTicks:
+	case VestingTemplateCliff:
+		if v.Cliff < v.Timestamp {
+			return errors.New("cliff")
+		}
+	case VestingTemplateRevocable:
+		if err = v.RevocationAdmin.Validate(); err != nil {
+			return err
+		}
+	case VestingTemplateShared:
+		if len(v.Beneficiaries) == 0 {
+			return errors.New("beneficiaries")
+		}
+
+		unique := make(map[types.Address]bool, len(v.Beneficiaries))
+		for i := range v.Beneficiaries {
+			if err = v.Beneficiaries[i].Address.Validate(); err != nil {
+				return err
+			}
+
+			if v.Beneficiaries[i].Share == 0 {
+				return errors.New("beneficiary share")
+			}
+
+			if unique[v.Beneficiaries[i].Address] {
+				return errors.New("duplicate beneficiary")
+			}
+			unique[v.Beneficiaries[i].Address] = true
+		}
+	default:
+		return errors.New("template")
+	}
+
+	//Shared template pays to beneficiaries
+	if v.Template != VestingTemplateShared {
+		if err = v.VestingAddress.Validate(); err != nil {
+			return err
+		}
 	}
 
 	if err = v.DelegateAdmin.Validate(); err != nil {
@@ -66,6 +133,7 @@ func (v VestingContractOperation) Validate() (err error) {
 				return err
 			}
 		}
+	case VestingRevoke:
 	default:
 		return errors.New("wrong type")
 	}
@@ -74,8 +142,9 @@ func (v VestingContractOperation) Validate() (err error) {
 }
 
 type VestingContractInfo struct {
-	Balance  uint64        `json:"balance"`
-	Delegate types.Address `json:"delegate"`
+	Balance    uint64          `json:"balance"`
+	Delegate   types.Address   `json:"delegate"`
+	Delegation *DelegationInfo `json:"delegation,omitempty"`
 
 	OpenedBalance uint64 `json:"opened_balance"`
 	//Init from storage
@@ -103,3 +172,114 @@ func (v Vesting) Validate() (err error) {
 
 	return nil
 }
+
+type VestingScheduleGranularity string
+
+const (
+	VestingScheduleTick  VestingScheduleGranularity = "tick"
+	VestingScheduleDay   VestingScheduleGranularity = "day"
+	VestingScheduleMonth VestingScheduleGranularity = "month"
+)
+
+func (g VestingScheduleGranularity) Validate() error {
+	switch g {
+	case VestingScheduleTick, VestingScheduleDay, VestingScheduleMonth:
+		return nil
+	default:
+		return errors.New("granularity")
+	}
+}
+
+//Unlock of single tick or ticks aggregated by period
+type VestingUnlock struct {
+	//Time of last tick in period
+	Time types.JSONTimestamp `json:"time"`
+	//Period start for aggregated unlocks
+	PeriodStart *types.JSONTimestamp `json:"period_start,omitempty"`
+	Ticks       uint64               `json:"ticks"`
+	Amount      uint64               `json:"amount"`
+	Cumulative  uint64               `json:"cumulative"`
+	IsOpened    bool                 `json:"is_opened"`
+}
+
+type VestingSchedule struct {
+	Granularity VestingScheduleGranularity `json:"granularity"`
+	//Locked and opened not vested amount
+	Balance       uint64              `json:"balance"`
+	OpenedBalance uint64              `json:"opened_balance"`
+	FinalTick     uint64              `json:"final_tick"`
+	FullyVestedAt types.JSONTimestamp `json:"fully_vested_at"`
+	Unlocks       []VestingUnlock     `json:"unlocks"`
+}
+
+type VestingScheduleParams struct {
+	CommonParams
+	Granularity VestingScheduleGranularity
+}
+
+//Auto claim rule, claim is proposed when any enabled condition is met
+type VestingClaimRule struct {
+	ID        uint64 `gorm:"column:vcr_id;primaryKey" json:"-"`
+	VestingID uint64 `gorm:"column:vst_id" json:"-"`
+	//Opened amount in mutez, 0 disables condition
+	MinAmount uint64 `gorm:"column:vcr_min_amount" json:"min_amount"`
+	//Days since last claim, 0 disables condition
+	IntervalDays uint64               `gorm:"column:vcr_interval_days" json:"interval_days"`
+	IsActive     bool                 `gorm:"column:vcr_is_active" json:"is_active"`
+	LastClaimAt  *types.JSONTimestamp `gorm:"column:vcr_last_claim_at" json:"last_claim_at,omitempty"`
+	//Hash of last proposed request
+	RequestHash *string             `gorm:"column:vcr_req_hash" json:"-"`
+	CreatedAt   types.JSONTimestamp `gorm:"column:vcr_created_at" json:"-"`
+	UpdatedAt   types.JSONTimestamp `gorm:"column:vcr_updated_at" json:"-"`
+
+	//Read from vestings
+	VestingAddress types.Address `gorm:"column:vst_address;->" json:"vesting_address"`
+	ContractID     uint64        `gorm:"column:ctr_id;->" json:"-"`
+}
+
+func (r VestingClaimRule) Validate() (err error) {
+	if err = r.VestingAddress.Validate(); err != nil {
+		return err
+	}
+
+	if r.MinAmount == 0 && r.IntervalDays == 0 {
+		return errors.New("empty rule")
+	}
+
+	return nil
+}
+
+//Applied vest or setDelegate call of vesting contract
+type VestingOperation struct {
+	ID             uint64        `gorm:"column:vop_id;primaryKey" json:"-"`
+	VestingAddress types.Address `gorm:"column:vop_vesting_address" json:"-"`
+	Type           ActionType    `gorm:"column:vop_type" json:"type"`
+	Ticks          uint64        `gorm:"column:vop_ticks" json:"ticks,omitempty"`
+	//Vested amount in mutez
+	Amount uint64 `gorm:"column:vop_amount" json:"amount,omitempty"`
+	//New delegate, empty on delegate removal
+	Delegate  types.Address       `gorm:"column:vop_delegate" json:"delegate"`
+	Level     uint64              `gorm:"column:vop_level" json:"level"`
+	OpHash    string              `gorm:"column:vop_op_hash" json:"tx_id"`
+	Nonce     sql.NullInt64       `gorm:"column:vop_nonce" json:"-"`
+	Timestamp types.JSONTimestamp `gorm:"column:vop_timestamp" json:"timestamp"`
+}
+
+//On-chain vested counter compared with recorded vest calls
+type VestingReconciliation struct {
+	VestedTicks    uint64 `json:"vested_ticks"`
+	RecordedTicks  uint64 `json:"recorded_ticks"`
+	VestedAmount   uint64 `json:"vested_amount"`
+	RecordedAmount uint64 `json:"recorded_amount"`
+	IsConsistent   bool   `json:"is_consistent"`
+}
+
+func NewVestingReconciliation(vestedTicks, recordedTicks, tokensPerTick uint64) VestingReconciliation {
+	return VestingReconciliation{
+		VestedTicks:    vestedTicks,
+		RecordedTicks:  recordedTicks,
+		VestedAmount:   vestedTicks * tokensPerTick,
+		RecordedAmount: recordedTicks * tokensPerTick,
+		IsConsistent:   vestedTicks == recordedTicks,
+	}
+}
@@ -18,6 +18,7 @@ type (
 
 	Repo interface {
 		GetAssetsList(contract uint64, isOwner, isActive, isAll bool) (assets []models.Asset, err error)
+		GetSuggestedAssets(contract uint64) (assets []models.Asset, err error)
 		GetAsset(contract uint64, assetAddress types.Address, tokenID *uint64) (assets models.Asset, isFound bool, err error)
 		CreateAsset(asset models.Asset) (err error)
 		UpdateAsset(asset models.Asset) (err error)
@@ -84,10 +85,25 @@ func (r *Repository) GetAssetsList(contractID uint64, isOwner, isActive, isAll b
 	return assets, nil
 }
 
+func (r *Repository) GetSuggestedAssets(contractID uint64) (assets []models.Asset, err error) {
+	err = r.db.Model(models.Asset{}).
+		Where("ctr_id = ? AND ast_is_suggested IS TRUE", contractID).
+		Order("ast_updated_at desc").
+		Find(&assets).Error
+	if err != nil {
+		return assets, err
+	}
+	return assets, nil
+}
+
 func (r *Repository) EnableContractAsset(assetID uint64) (err error) {
+	//Owner decision resolves suggestion
 	err = r.db.Model(&models.Asset{ID: assetID}).
-		Update("ast_is_active", true).
-		Update("ast_updated_at", time.Now()).
+		Updates(map[string]interface{}{
+			"ast_is_active":    true,
+			"ast_is_suggested": false,
+			"ast_updated_at":   time.Now(),
+		}).
 		Error
 	if err != nil {
 		return err
@@ -96,9 +112,13 @@ func (r *Repository) EnableContractAsset(assetID uint64) (err error) {
 }
 
 func (r *Repository) DisableContractAsset(assetID uint64) (err error) {
+	//Owner decision resolves suggestion
 	err = r.db.Model(&models.Asset{ID: assetID}).
-		Update("ast_is_active", false).
-		Update("ast_updated_at", time.Now()).
+		Updates(map[string]interface{}{
+			"ast_is_active":    false,
+			"ast_is_suggested": false,
+			"ast_updated_at":   time.Now(),
+		}).
 		Error
 	if err != nil {
 		return err
@@ -19,7 +19,7 @@ type (
 		CreateAuthToken(authToken models.AuthToken) (err error)
 		GetAuthToken(token string) (authToken models.AuthToken, isFound bool, err error)
 		GetActiveTokenByPubKeyAndType(address types.PubKey, tokenType models.TokenType) (authToken models.AuthToken, isFound bool, err error)
-		MarkAsUsedAuthToken(id uint64) (err error)
+		MarkAsUsedAuthToken(id uint64) (isMarked bool, err error)
 	}
 )
 
@@ -69,14 +69,15 @@ func (r *Repository) CreateAuthToken(authToken models.AuthToken) (err error) {
 	return nil
 }
 
-func (r *Repository) MarkAsUsedAuthToken(id uint64) (err error) {
-	err = r.db.
+//MarkAsUsedAuthToken marks an unused token as used and reports whether it was unused before
+func (r *Repository) MarkAsUsedAuthToken(id uint64) (isMarked bool, err error) {
+	db := r.db.
 		Model(models.AuthToken{}).
-		Where("atn_id = ?", id).
-		Update("atn_is_used", true).Error
-	if err != nil {
-		return err
+		Where("atn_id = ? and atn_is_used = false", id).
+		Update("atn_is_used", true)
+	if db.Error != nil {
+		return false, db.Error
 	}
 
-	return nil
+	return db.RowsAffected > 0, nil
 }
@@ -0,0 +1,60 @@
+package balance
+
+import (
+	"tezosign/models"
+	"tezosign/types"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:generate mockgen -source ./balance.go -destination ./mock_balance/main.go Repo
+type (
+	// Repository is the token balances cache repo implementation.
+	Repository struct {
+		db *gorm.DB
+	}
+
+	Repo interface {
+		GetHolderBalances(holder types.Address) (balances []models.HolderTokenBalance, err error)
+		SaveHolderBalances(balances []models.HolderTokenBalance) (err error)
+	}
+)
+
+// New creates an instance of repository using the provided db.
+func New(db *gorm.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+func (r *Repository) GetHolderBalances(holder types.Address) (balances []models.HolderTokenBalance, err error) {
+	err = r.db.Model(models.HolderTokenBalance{}).
+		Where("tbl_holder = ?", holder).
+		Find(&balances).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return balances, nil
+}
+
+//Insert or refresh balances
+func (r *Repository) SaveHolderBalances(balances []models.HolderTokenBalance) (err error) {
+	if len(balances) == 0 {
+		return nil
+	}
+
+	err = r.db.
+		Model(models.HolderTokenBalance{}).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "tbl_holder"}, {Name: "tbl_asset"}, {Name: "tbl_token_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"tbl_balance", "tbl_updated_at"}),
+		}).
+		Create(&balances).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
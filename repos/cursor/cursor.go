@@ -0,0 +1,63 @@
+package cursor
+
+import (
+	"errors"
+	"tezosign/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:generate mockgen -source ./cursor.go -destination ./mock_cursor/main.go Repo
+type (
+	// Repository is the sync cursors repo implementation.
+	Repository struct {
+		db *gorm.DB
+	}
+
+	Repo interface {
+		GetCursor(name string) (cursor models.SyncCursor, isFound bool, err error)
+		SaveCursor(name string, level uint64) (err error)
+	}
+)
+
+// New creates an instance of repository using the provided db.
+func New(db *gorm.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+func (r *Repository) GetCursor(name string) (cursor models.SyncCursor, isFound bool, err error) {
+	err = r.db.Model(models.SyncCursor{}).
+		Where("scr_name = ?", name).
+		First(&cursor).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return cursor, false, nil
+		}
+		return cursor, false, err
+	}
+
+	return cursor, true, nil
+}
+
+func (r *Repository) SaveCursor(name string, level uint64) (err error) {
+	err = r.db.
+		Model(models.SyncCursor{}).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "scr_name"}},
+			DoUpdates: clause.AssignmentColumns([]string{"scr_level", "scr_updated_at"}),
+		}).
+		Create(&models.SyncCursor{
+			Name:      name,
+			Level:     level,
+			UpdatedAt: time.Now(),
+		}).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
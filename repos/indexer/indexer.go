@@ -1,8 +1,11 @@
 package indexer
 
 import (
+	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"tezosign/models"
 	"tezosign/types"
 
@@ -18,16 +21,25 @@ type (
 
 	Repo interface {
 		GetContractOperations(contract types.Address, blockLevel uint64, entrypoint string) ([]models.TransactionOperation, error)
+		GetTransfersTo(accounts []string, fromLevel, toLevel uint64) ([]models.AssetTransferOperation, error)
+		GetFirstActivityLevel(accounts []string) (level uint64, isFound bool, err error)
 		GetContractRevealOperation(contract types.Address) (models.RevealOperation, bool, error)
 		GetContractOriginationOperation(txID string) (tx models.OriginationOperation, isFound bool, err error)
 
 		GetContractStorage(address types.Address) (storage models.Storage, isFound bool, err error)
 		GetContractStorageChange(address types.Address, level uint64) (storage []models.Storage, err error)
 		GetContractsStoragesContainsKey(contracts []string, key string) ([]string, error)
+		GetContractsStoragesMentioning(address types.Address, creators []string, codeHashes []int64, limit int) ([]types.Address, error)
+		GetContractOrigin(address types.Address) (origin models.ContractOrigin, isFound bool, err error)
 		GetContractScript(address types.Address) (script models.Script, isFound bool, err error)
 		GetAccount(address types.Address) (account models.Account, isFound bool, err error)
 		GetAccountByID(id uint64) (account models.Account, isFound bool, err error)
 
+		GetActiveBakers(level uint64, params models.CommonParams) (bakers []models.Baker, err error)
+		GetIncomingTransfersSummary(targetID uint64, senderIDs []uint64, fromLevel uint64) (summary models.TransfersSummary, err error)
+
+		GetBigMapKey(ptr int64, keyHash string) (key models.BigMapKey, isFound bool, err error)
+
 		GetLastBlock() (block models.Block, err error)
 		GetTezosQuote() (models.Quote, error)
 	}
@@ -60,6 +72,58 @@ func (r *Repository) GetContractOperations(contract types.Address, blockLevel ui
 	return operations, nil
 }
 
+//Applied FA1.2 and FA2 transfer calls to one of accounts.
+//Recipient is stored only in parameters, it is matched by jsonb containment of transfer fields
+func (r *Repository) GetTransfersTo(accounts []string, fromLevel, toLevel uint64) (operations []models.AssetTransferOperation, err error) {
+	if len(accounts) == 0 {
+		return operations, nil
+	}
+
+	patterns := make([]interface{}, 0, 2*len(accounts))
+	for _, account := range accounts {
+		fa12, err := json.Marshal(map[string]string{"to": account})
+		if err != nil {
+			return operations, err
+		}
+
+		fa2, err := json.Marshal([]map[string][]map[string]string{{"txs": {{"to_": account}}}})
+		if err != nil {
+			return operations, err
+		}
+
+		patterns = append(patterns, string(fa12), string(fa2))
+	}
+
+	err = r.db.Select(`"TransactionOps".*, a."Address"`).
+		Table("TransactionOps").
+		Joins(`LEFT JOIN "Accounts" a on "TargetId" = a."Id"`).
+		Where(`"Entrypoint" = ? AND "Status" = ?`, "transfer", models.OperationStatusApplied).
+		Where(`"Level" > ? AND "Level" <= ?`, fromLevel, toLevel).
+		Where("("+strings.TrimSuffix(strings.Repeat(`"JsonParameters" @> ?::jsonb OR `, len(patterns)), " OR ")+")", patterns...).
+		Order(`"TransactionOps"."Id" asc`).
+		Find(&operations).Error
+	if err != nil {
+		return operations, err
+	}
+
+	return operations, nil
+}
+
+//Level of the earliest account creation
+func (r *Repository) GetFirstActivityLevel(accounts []string) (level uint64, isFound bool, err error) {
+	var firstLevel sql.NullInt64
+	err = r.db.
+		Table("Accounts").
+		Select(`MIN("FirstLevel")`).
+		Where(`"Address" IN ?`, accounts).
+		Row().Scan(&firstLevel)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return uint64(firstLevel.Int64), firstLevel.Valid, nil
+}
+
 func (r *Repository) GetContractRevealOperation(address types.Address) (tx models.RevealOperation, isFound bool, err error) {
 	//TODO use single Account table
 	err = r.db.Select("*").
@@ -144,6 +208,45 @@ func (r *Repository) GetContractsStoragesContainsKey(contracts []string, key str
 	return resp, nil
 }
 
+//Contracts which current storage contains address
+//Contracts originated by one of creators or with one of code hashes which storages contain address
+func (r *Repository) GetContractsStoragesMentioning(address types.Address, creators []string, codeHashes []int64, limit int) (resp []types.Address, err error) {
+	if len(creators) == 0 && len(codeHashes) == 0 {
+		return nil, nil
+	}
+
+	err = r.db.Select(`a."Address"`).
+		Table("Storages").
+		Joins(`JOIN "Accounts" a on a."Id" = "ContractId"`).
+		Joins(`LEFT JOIN "Accounts" c on c."Id" = a."CreatorId"`).
+		Where(`"Current" IS TRUE AND a."Address" <> ?`, address.String()).
+		Where(`(c."Address" IN ? OR a."CodeHash" IN ?)`, creators, codeHashes).
+		Where(`"JsonValue"::text LIKE ?`, "%"+address.String()+"%").
+		Limit(limit).
+		Find(&resp).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (r *Repository) GetContractOrigin(address types.Address) (origin models.ContractOrigin, isFound bool, err error) {
+	err = r.db.Select(`a."Address", a."Balance", a."CodeHash", c."Address" AS "CreatorAddress"`).
+		Table(`"Accounts" a`).
+		Joins(`LEFT JOIN "Accounts" c on c."Id" = a."CreatorId"`).
+		Where(`a."Address" = ?`, address.String()).
+		Take(&origin).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return origin, false, nil
+		}
+		return origin, false, err
+	}
+
+	return origin, true, nil
+}
+
 func (r *Repository) GetContractScript(address types.Address) (script models.Script, isFound bool, err error) {
 	err = r.db.Select("*").
 		Table("Scripts").
@@ -211,3 +314,53 @@ func (r *Repository) GetLastBlock() (block models.Block, err error) {
 
 	return block, nil
 }
+
+//Delegates which are not deactivated at level ordered by staking balance
+func (r *Repository) GetActiveBakers(level uint64, params models.CommonParams) (bakers []models.Baker, err error) {
+	err = r.db.
+		Table("Accounts").
+		Where(`"Type" = ? AND "DeactivationLevel" > ?`, models.AccountTypeDelegate, level).
+		Order(`"StakingBalance" desc`).
+		Limit(params.Limit).
+		Offset(params.Offset).
+		Find(&bakers).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return bakers, nil
+}
+
+//Applied incoming transfers sent by one of senders
+func (r *Repository) GetIncomingTransfersSummary(targetID uint64, senderIDs []uint64, fromLevel uint64) (summary models.TransfersSummary, err error) {
+	if len(senderIDs) == 0 {
+		return summary, nil
+	}
+
+	err = r.db.
+		Select(`COALESCE(SUM(t."Amount"), 0) AS amount, COUNT(t."Id") AS count`).
+		Table(`"TransactionOps" t`).
+		Where(`t."TargetId" = ? AND t."Status" = ? AND t."Level" >= ?`, targetID, models.OperationStatusApplied, fromLevel).
+		Where(`t."SenderId" IN ?`, senderIDs).
+		Scan(&summary).Error
+	if err != nil {
+		return summary, err
+	}
+
+	return summary, nil
+}
+
+func (r *Repository) GetBigMapKey(ptr int64, keyHash string) (key models.BigMapKey, isFound bool, err error) {
+	err = r.db.
+		Table("BigMapKeys").
+		Where(`"BigMapPtr" = ? AND "KeyHash" = ? AND "Active" IS TRUE`, ptr, keyHash).
+		First(&key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return key, false, nil
+		}
+		return key, false, err
+	}
+
+	return key, true, nil
+}
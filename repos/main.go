@@ -5,8 +5,15 @@ import (
 	"fmt"
 	"tezosign/repos/asset"
 	"tezosign/repos/auth"
+	"tezosign/repos/balance"
 	"tezosign/repos/contract"
+	"tezosign/repos/cursor"
 	"tezosign/repos/indexer"
+	"tezosign/repos/metadata"
+	"tezosign/repos/portfolio"
+	"tezosign/repos/price"
+	"tezosign/repos/ratelimit"
+	"tezosign/repos/session"
 	"tezosign/repos/vesting"
 
 	"github.com/sirupsen/logrus"
@@ -63,13 +70,42 @@ func (u *Provider) GetVesting() vesting.Repo {
 	return vesting.New(u.getDB())
 }
 
+func (u *Provider) GetSession() session.Repo {
+	return session.New(u.getDB())
+}
+
+func (u *Provider) GetRateLimit() ratelimit.Repo {
+	return ratelimit.New(u.getDB())
+}
+
+func (u *Provider) GetCursor() cursor.Repo {
+	return cursor.New(u.getDB())
+}
+
+func (u *Provider) GetPrice() price.Repo {
+	return price.New(u.getDB())
+}
+
+func (u *Provider) GetPortfolio() portfolio.Repo {
+	return portfolio.New(u.getDB())
+}
+
+func (u *Provider) GetMetadata() metadata.Repo {
+	return metadata.New(u.getDB())
+}
+
+func (u *Provider) GetBalance() balance.Repo {
+	return balance.New(u.getDB())
+}
+
 //Indexer repo should use indexer connection
 func (u *Provider) GetIndexer() indexer.Repo {
 	return indexer.New(u.getDB())
 }
 
-func (u *Provider) Start(ctx context.Context) {
-	u.tx = u.db.Begin()
+func (u *Provider) Start(ctx context.Context) error {
+	u.tx = u.db.WithContext(ctx).Begin()
+	return u.tx.Error
 }
 
 func (u *Provider) RollbackUnlessCommitted() {
@@ -0,0 +1,60 @@
+package metadata
+
+import (
+	"errors"
+	"tezosign/models"
+	"tezosign/types"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:generate mockgen -source ./metadata.go -destination ./mock_metadata/main.go Repo
+type (
+	// Repository is the token metadata cache repo implementation.
+	Repository struct {
+		db *gorm.DB
+	}
+
+	Repo interface {
+		GetTokenMetadata(address types.Address, tokenID uint64) (metadata models.TokenMetadata, isFound bool, err error)
+		SaveTokenMetadata(metadata models.TokenMetadata) (err error)
+	}
+)
+
+// New creates an instance of repository using the provided db.
+func New(db *gorm.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+func (r *Repository) GetTokenMetadata(address types.Address, tokenID uint64) (metadata models.TokenMetadata, isFound bool, err error) {
+	err = r.db.Model(models.TokenMetadata{}).
+		Where("tmd_address = ? and tmd_token_id = ?", address, tokenID).
+		First(&metadata).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return metadata, false, nil
+		}
+		return metadata, false, err
+	}
+
+	return metadata, true, nil
+}
+
+//Insert or refresh cached metadata
+func (r *Repository) SaveTokenMetadata(metadata models.TokenMetadata) (err error) {
+	err = r.db.
+		Model(models.TokenMetadata{}).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "tmd_address"}, {Name: "tmd_token_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"tmd_uri", "tmd_info", "tmd_updated_at", "tmd_next_refresh_at"}),
+		}).
+		Create(&metadata).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
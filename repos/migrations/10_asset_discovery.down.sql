@@ -0,0 +1,4 @@
+DROP TABLE sync_cursors;
+
+alter table assets
+	drop column ast_is_suggested;
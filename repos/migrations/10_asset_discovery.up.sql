@@ -0,0 +1,11 @@
+alter table assets
+	add ast_is_suggested bool default FALSE not null;
+
+create table sync_cursors
+(
+	scr_name varchar not null
+		constraint sync_cursors_pk
+			primary key,
+    scr_level int not null,
+    scr_updated_at timestamp without time zone default now() not null
+);
@@ -0,0 +1,3 @@
+DROP TABLE asset_price_snapshots;
+
+DROP TABLE dex_pools;
@@ -0,0 +1,30 @@
+create table dex_pools
+(
+	dxp_id serial not null
+		constraint dex_pools_pk
+			primary key,
+    dxp_dex varchar not null,
+    dxp_address varchar(36) not null,
+    dxp_asset varchar(36) not null,
+    dxp_token_id numeric(20) default 0 not null,
+    dxp_updated_at timestamp without time zone default now() not null
+);
+
+create unique index dex_pools_dxp_address_dxp_asset_dxp_token_id_uindex
+	on dex_pools (dxp_address, dxp_asset, dxp_token_id);
+
+create table asset_price_snapshots
+(
+	aps_id serial not null
+		constraint asset_price_snapshots_pk
+			primary key,
+    aps_asset varchar(36) not null,
+    aps_token_id numeric(20) default 0 not null,
+    aps_price numeric not null,
+    aps_liquidity numeric not null,
+    aps_pools int not null,
+    aps_created_at timestamp without time zone default now() not null
+);
+
+create index asset_price_snapshots_aps_asset_aps_token_id_aps_created_at_index
+	on asset_price_snapshots (aps_asset, aps_token_id, aps_created_at);
@@ -0,0 +1 @@
+DROP TABLE portfolio_snapshots;
@@ -0,0 +1,15 @@
+create table portfolio_snapshots
+(
+	pfs_id serial not null
+		constraint portfolio_snapshots_pk
+			primary key,
+    ctr_id int not null
+		constraint portfolio_snapshots_contracts_ctr_id_fk
+			references contracts,
+    pfs_total_xtz numeric not null,
+    pfs_total_usd numeric not null,
+    pfs_created_at timestamp without time zone default now() not null
+);
+
+create index portfolio_snapshots_ctr_id_pfs_created_at_index
+	on portfolio_snapshots (ctr_id, pfs_created_at);
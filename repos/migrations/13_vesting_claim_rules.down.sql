@@ -0,0 +1,4 @@
+DROP TABLE vesting_claim_rules;
+
+alter table requests
+	drop column req_is_system;
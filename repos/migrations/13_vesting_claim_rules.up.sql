@@ -0,0 +1,23 @@
+alter table requests
+	add req_is_system bool default FALSE not null;
+
+create table vesting_claim_rules
+(
+	vcr_id serial not null
+		constraint vesting_claim_rules_pk
+			primary key,
+    vst_id int not null
+		constraint vesting_claim_rules_vestings_vst_id_fk
+			references vestings
+				on delete cascade,
+    vcr_min_amount bigint default 0 not null,
+    vcr_interval_days int default 0 not null,
+    vcr_is_active bool default TRUE not null,
+    vcr_last_claim_at timestamp without time zone,
+    vcr_req_hash varchar(32),
+    vcr_created_at timestamp without time zone default now() not null,
+    vcr_updated_at timestamp without time zone default now() not null
+);
+
+create unique index vesting_claim_rules_vst_id_uindex
+	on vesting_claim_rules (vst_id);
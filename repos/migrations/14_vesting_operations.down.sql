@@ -0,0 +1 @@
+DROP TABLE vesting_operations;
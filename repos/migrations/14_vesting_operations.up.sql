@@ -0,0 +1,21 @@
+create table vesting_operations
+(
+	vop_id serial not null
+		constraint vesting_operations_pk
+			primary key,
+    vop_vesting_address varchar(36) not null,
+    vop_type varchar not null,
+    vop_ticks bigint default 0 not null,
+    vop_amount bigint default 0 not null,
+    vop_delegate varchar(36) default '' not null,
+    vop_level int not null,
+    vop_op_hash varchar(51) not null,
+    vop_nonce int,
+    vop_timestamp timestamp without time zone not null
+);
+
+create unique index vesting_operations_op_uindex
+	on vesting_operations (vop_op_hash, vop_vesting_address, coalesce(vop_nonce, -1));
+
+create index vesting_operations_vop_vesting_address_vop_level_index
+	on vesting_operations (vop_vesting_address, vop_level);
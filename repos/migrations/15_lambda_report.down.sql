@@ -0,0 +1,2 @@
+alter table requests
+	drop column req_lambda_report;
@@ -0,0 +1,2 @@
+alter table requests
+	add req_lambda_report text;
@@ -0,0 +1,3 @@
+alter index revoked_tokens_rvt_session_uuid_uindex rename to revoked_tokens_rvt_jti_uindex;
+
+alter table revoked_tokens rename column rvt_session_uuid to rvt_jti;
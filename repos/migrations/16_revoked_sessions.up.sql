@@ -0,0 +1,3 @@
+alter table revoked_tokens rename column rvt_jti to rvt_session_uuid;
+
+alter index revoked_tokens_rvt_jti_uindex rename to revoked_tokens_rvt_session_uuid_uindex;
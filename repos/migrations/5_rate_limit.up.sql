@@ -0,0 +1,12 @@
+create table rate_limit_buckets
+(
+	rlb_key varchar not null
+		constraint rate_limit_buckets_pk
+			primary key,
+    rlb_tokens double precision not null,
+    rlb_updated_at timestamp without time zone not null,
+    rlb_full_at timestamp without time zone not null
+);
+
+create index rate_limit_buckets_rlb_full_at_index
+	on rate_limit_buckets (rlb_full_at);
@@ -0,0 +1,2 @@
+DROP TABLE revoked_tokens;
+DROP TABLE sessions;
\ No newline at end of file
@@ -0,0 +1,37 @@
+create table sessions
+(
+	ses_id serial not null
+		constraint sessions_pk
+			primary key,
+    ses_uuid varchar(36) not null,
+    ses_pubkey varchar(55) not null,
+    ses_refresh_token varchar(64) not null,
+    ses_jti varchar(36) not null,
+    ses_user_agent varchar(255) not null,
+    ses_ip varchar(45) not null,
+    ses_created_at timestamp without time zone default now() not null,
+    ses_last_used_at timestamp without time zone default now() not null,
+    ses_expires_at timestamp without time zone not null,
+    ses_revoked_at timestamp without time zone
+);
+
+create unique index sessions_ses_uuid_uindex
+	on sessions (ses_uuid);
+
+create unique index sessions_ses_refresh_token_uindex
+	on sessions (ses_refresh_token);
+
+create index sessions_ses_pubkey_index
+	on sessions (ses_pubkey);
+
+create table revoked_tokens
+(
+	rvt_id serial not null
+		constraint revoked_tokens_pk
+			primary key,
+    rvt_jti varchar(36) not null,
+    rvt_expires_at timestamp without time zone not null
+);
+
+create unique index revoked_tokens_rvt_jti_uindex
+	on revoked_tokens (rvt_jti);
@@ -0,0 +1,2 @@
+alter table auth_tokens
+	drop column atn_payload;
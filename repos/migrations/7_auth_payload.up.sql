@@ -0,0 +1,2 @@
+alter table auth_tokens
+	add atn_payload text;
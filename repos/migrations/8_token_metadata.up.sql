@@ -0,0 +1,15 @@
+create table token_metadata
+(
+	tmd_id serial not null
+		constraint token_metadata_pk
+			primary key,
+    tmd_address varchar(36) not null,
+    tmd_token_id numeric(20) default 0 not null,
+    tmd_uri varchar,
+    tmd_info jsonb not null,
+    tmd_updated_at timestamp without time zone default now() not null,
+    tmd_next_refresh_at timestamp without time zone not null
+);
+
+create unique index token_metadata_tmd_address_tmd_token_id_uindex
+	on token_metadata (tmd_address, tmd_token_id);
@@ -0,0 +1 @@
+DROP TABLE holder_token_balances;
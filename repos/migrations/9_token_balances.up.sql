@@ -0,0 +1,14 @@
+create table holder_token_balances
+(
+	tbl_id serial not null
+		constraint holder_token_balances_pk
+			primary key,
+    tbl_holder varchar(36) not null,
+    tbl_asset varchar(36) not null,
+    tbl_token_id numeric(20) default 0 not null,
+    tbl_balance numeric not null,
+    tbl_updated_at timestamp without time zone default now() not null
+);
+
+create unique index holder_token_balances_tbl_holder_tbl_asset_tbl_token_id_uindex
+	on holder_token_balances (tbl_holder, tbl_asset, tbl_token_id);
@@ -0,0 +1,74 @@
+package portfolio
+
+import (
+	"errors"
+	"tezosign/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:generate mockgen -source ./portfolio.go -destination ./mock_portfolio/main.go Repo
+type (
+	// Repository is the portfolio snapshots repo implementation.
+	Repository struct {
+		db *gorm.DB
+	}
+
+	Repo interface {
+		GetSnapshotBefore(contractID uint64, before time.Time) (snapshot models.PortfolioSnapshot, isFound bool, err error)
+		GetSnapshots(contractID uint64, params models.CommonParams) (snapshots []models.PortfolioSnapshot, err error)
+		SaveSnapshots(snapshots []models.PortfolioSnapshot) (err error)
+	}
+)
+
+// New creates an instance of repository using the provided db.
+func New(db *gorm.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+//Latest snapshot sampled before time
+func (r *Repository) GetSnapshotBefore(contractID uint64, before time.Time) (snapshot models.PortfolioSnapshot, isFound bool, err error) {
+	err = r.db.Model(models.PortfolioSnapshot{}).
+		Where("ctr_id = ? AND pfs_created_at <= ?", contractID, before).
+		Order("pfs_created_at desc").
+		First(&snapshot).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return snapshot, false, nil
+		}
+		return snapshot, false, err
+	}
+
+	return snapshot, true, nil
+}
+
+func (r *Repository) GetSnapshots(contractID uint64, params models.CommonParams) (snapshots []models.PortfolioSnapshot, err error) {
+	err = r.db.Model(models.PortfolioSnapshot{}).
+		Where("ctr_id = ?", contractID).
+		Order("pfs_created_at desc").
+		Limit(params.Limit).
+		Offset(params.Offset).
+		Find(&snapshots).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshots, nil
+}
+
+func (r *Repository) SaveSnapshots(snapshots []models.PortfolioSnapshot) (err error) {
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	err = r.db.Model(models.PortfolioSnapshot{}).
+		Create(&snapshots).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package price
+
+import (
+	"errors"
+	"tezosign/models"
+	"tezosign/types"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:generate mockgen -source ./price.go -destination ./mock_price/main.go Repo
+type (
+	// Repository is the asset prices repo implementation.
+	Repository struct {
+		db *gorm.DB
+	}
+
+	Repo interface {
+		GetAssetPools(asset types.Address, tokenID uint64) (pools []models.DexPool, err error)
+		SavePools(pools []models.DexPool) (err error)
+
+		GetLastPriceSnapshot(asset types.Address, tokenID uint64) (snapshot models.AssetPriceSnapshot, isFound bool, err error)
+		GetLastPriceSnapshots() (snapshots []models.AssetPriceSnapshot, err error)
+		GetPriceSnapshots(asset types.Address, tokenID uint64, params models.CommonParams) (snapshots []models.AssetPriceSnapshot, err error)
+		SavePriceSnapshots(snapshots []models.AssetPriceSnapshot) (err error)
+	}
+)
+
+// New creates an instance of repository using the provided db.
+func New(db *gorm.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+func (r *Repository) GetAssetPools(asset types.Address, tokenID uint64) (pools []models.DexPool, err error) {
+	err = r.db.Model(models.DexPool{}).
+		Where("dxp_asset = ? AND dxp_token_id = ?", asset, tokenID).
+		Find(&pools).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return pools, nil
+}
+
+func (r *Repository) SavePools(pools []models.DexPool) (err error) {
+	if len(pools) == 0 {
+		return nil
+	}
+
+	err = r.db.
+		Model(models.DexPool{}).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "dxp_address"}, {Name: "dxp_asset"}, {Name: "dxp_token_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"dxp_dex", "dxp_updated_at"}),
+		}).
+		Create(&pools).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repository) GetLastPriceSnapshot(asset types.Address, tokenID uint64) (snapshot models.AssetPriceSnapshot, isFound bool, err error) {
+	err = r.db.Model(models.AssetPriceSnapshot{}).
+		Where("aps_asset = ? AND aps_token_id = ?", asset, tokenID).
+		Order("aps_created_at desc").
+		First(&snapshot).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return snapshot, false, nil
+		}
+		return snapshot, false, err
+	}
+
+	return snapshot, true, nil
+}
+
+//Latest snapshot of each asset
+func (r *Repository) GetLastPriceSnapshots() (snapshots []models.AssetPriceSnapshot, err error) {
+	err = r.db.Model(models.AssetPriceSnapshot{}).
+		Select("DISTINCT ON (aps_asset, aps_token_id) *").
+		Order("aps_asset, aps_token_id, aps_created_at desc").
+		Find(&snapshots).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshots, nil
+}
+
+func (r *Repository) GetPriceSnapshots(asset types.Address, tokenID uint64, params models.CommonParams) (snapshots []models.AssetPriceSnapshot, err error) {
+	err = r.db.Model(models.AssetPriceSnapshot{}).
+		Where("aps_asset = ? AND aps_token_id = ?", asset, tokenID).
+		Order("aps_created_at desc").
+		Limit(params.Limit).
+		Offset(params.Offset).
+		Find(&snapshots).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshots, nil
+}
+
+func (r *Repository) SavePriceSnapshots(snapshots []models.AssetPriceSnapshot) (err error) {
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	err = r.db.Model(models.AssetPriceSnapshot{}).
+		Create(&snapshots).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"tezosign/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:generate mockgen -source ./ratelimit.go -destination ./mock_ratelimit/main.go Repo
+type (
+	// Repository is the rate limit buckets repo implementation.
+	Repository struct {
+		db *gorm.DB
+	}
+
+	Repo interface {
+		GetOrCreateBucketForUpdate(bucket models.RateLimitBucket) (models.RateLimitBucket, error)
+		UpdateBucket(bucket models.RateLimitBucket) (err error)
+		DeleteFullBuckets(before time.Time) (count int64, err error)
+	}
+)
+
+// New creates an instance of repository using the provided db.
+func New(db *gorm.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+//Should be called inside transaction
+func (r *Repository) GetOrCreateBucketForUpdate(bucket models.RateLimitBucket) (resp models.RateLimitBucket, err error) {
+	err = r.db.Model(models.RateLimitBucket{}).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&bucket).Error
+	if err != nil {
+		return resp, err
+	}
+
+	err = r.db.Model(models.RateLimitBucket{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("rlb_key = ?", bucket.Key).
+		First(&resp).Error
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+func (r *Repository) UpdateBucket(bucket models.RateLimitBucket) (err error) {
+	err = r.db.Model(&models.RateLimitBucket{Key: bucket.Key}).
+		Updates(map[string]interface{}{
+			"rlb_tokens":     bucket.Tokens,
+			"rlb_updated_at": bucket.UpdatedAt,
+			"rlb_full_at":    bucket.FullAt,
+		}).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repository) DeleteFullBuckets(before time.Time) (count int64, err error) {
+	db := r.db.
+		Where("rlb_full_at < ?", before).
+		Delete(&models.RateLimitBucket{})
+	if db.Error != nil {
+		return 0, db.Error
+	}
+
+	return db.RowsAffected, nil
+}
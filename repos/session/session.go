@@ -0,0 +1,152 @@
+package session
+
+import (
+	"errors"
+	"tezosign/models"
+	"tezosign/types"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:generate mockgen -source ./session.go -destination ./mock_session/main.go Repo
+type (
+	// Repository is the sessions repo implementation.
+	Repository struct {
+		db *gorm.DB
+	}
+
+	Repo interface {
+		CreateSession(session models.Session) (err error)
+		UpdateSession(session models.Session) (err error)
+		GetSession(uuid string) (session models.Session, isFound bool, err error)
+		GetSessionByRefreshToken(refreshToken string) (session models.Session, isFound bool, err error)
+		GetActiveSessionsByPubKey(pubKey types.PubKey) (sessions []models.Session, err error)
+		RevokeSession(id uint64) (err error)
+
+		CreateRevokedToken(token models.RevokedToken) (err error)
+		IsSessionRevoked(uuid string) (isRevoked bool, err error)
+		DeleteExpiredRevokedTokens(before time.Time) (count int64, err error)
+	}
+)
+
+// New creates an instance of repository using the provided db.
+func New(db *gorm.DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+func (r *Repository) CreateSession(session models.Session) (err error) {
+	err = r.db.
+		Model(models.Session{}).
+		Create(&session).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repository) UpdateSession(session models.Session) (err error) {
+	err = r.db.Model(&models.Session{ID: session.ID}).
+		Updates(models.Session{
+			RefreshToken: session.RefreshToken,
+			JTI:          session.JTI,
+			UserAgent:    session.UserAgent,
+			IP:           session.IP,
+			LastUsedAt:   session.LastUsedAt,
+			ExpiresAt:    session.ExpiresAt,
+		}).
+		Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) GetSession(uuid string) (session models.Session, isFound bool, err error) {
+	err = r.db.Model(models.Session{}).
+		Where("ses_uuid = ?", uuid).
+		First(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return session, false, nil
+		}
+		return session, false, err
+	}
+
+	return session, true, nil
+}
+
+func (r *Repository) GetSessionByRefreshToken(refreshToken string) (session models.Session, isFound bool, err error) {
+	err = r.db.Model(models.Session{}).
+		Where("ses_refresh_token = ?", refreshToken).
+		First(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return session, false, nil
+		}
+		return session, false, err
+	}
+
+	return session, true, nil
+}
+
+func (r *Repository) GetActiveSessionsByPubKey(pubKey types.PubKey) (sessions []models.Session, err error) {
+	err = r.db.Model(models.Session{}).
+		Where("ses_pubkey = ? and ses_revoked_at IS NULL and ses_expires_at > now()", pubKey).
+		Order("ses_last_used_at desc").
+		Find(&sessions).Error
+	if err != nil {
+		return sessions, err
+	}
+
+	return sessions, nil
+}
+
+func (r *Repository) RevokeSession(id uint64) (err error) {
+	err = r.db.Model(&models.Session{ID: id}).
+		Update("ses_revoked_at", time.Now()).
+		Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) CreateRevokedToken(token models.RevokedToken) (err error) {
+	err = r.db.
+		Model(models.RevokedToken{}).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&token).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repository) IsSessionRevoked(uuid string) (isRevoked bool, err error) {
+	var count int64
+	err = r.db.Model(models.RevokedToken{}).
+		Where("rvt_session_uuid = ? and rvt_expires_at > now()", uuid).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (r *Repository) DeleteExpiredRevokedTokens(before time.Time) (count int64, err error) {
+	db := r.db.
+		Where("rvt_expires_at < ?", before).
+		Delete(&models.RevokedToken{})
+	if db.Error != nil {
+		return 0, db.Error
+	}
+
+	return db.RowsAffected, nil
+}
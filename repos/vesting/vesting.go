@@ -4,8 +4,10 @@ import (
 	"errors"
 	"tezosign/models"
 	"tezosign/types"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 //go:generate mockgen -source ./asset.go -destination ./mock_asset/main.go Repo
@@ -21,6 +23,17 @@ type (
 		CreateVesting(asset models.Vesting) (err error)
 		UpdateVesting(asset models.Vesting) (err error)
 		DeleteContractVesting(vestingID uint64) (err error)
+
+		GetClaimRules(contractID uint64) (rules []models.VestingClaimRule, err error)
+		GetActiveClaimRules() (rules []models.VestingClaimRule, err error)
+		SaveClaimRule(rule models.VestingClaimRule) (err error)
+		UpdateClaimRuleRequest(ruleID uint64, requestHash string, claimAt time.Time) (err error)
+		DeleteClaimRule(vestingID uint64) (err error)
+
+		GetVestingAddresses() (addresses []types.Address, err error)
+		GetOperations(vestingAddress types.Address, params models.CommonParams) (operations []models.VestingOperation, err error)
+		GetVestedTicks(vestingAddress types.Address) (ticks uint64, err error)
+		SaveOperations(operations []models.VestingOperation) (err error)
 	}
 )
 
@@ -90,3 +103,133 @@ func (r *Repository) GetVesting(contract uint64, vestingAddress types.Address) (
 
 	return vesting, true, nil
 }
+
+func (r *Repository) claimRulesQuery() *gorm.DB {
+	return r.db.Model(models.VestingClaimRule{}).
+		Select("vesting_claim_rules.*, vst_address, vestings.ctr_id").
+		Joins("JOIN vestings ON vestings.vst_id = vesting_claim_rules.vst_id")
+}
+
+func (r *Repository) GetClaimRules(contractID uint64) (rules []models.VestingClaimRule, err error) {
+	err = r.claimRulesQuery().
+		Where("vestings.ctr_id = ?", contractID).
+		Order("vcr_id desc").
+		Find(&rules).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func (r *Repository) GetActiveClaimRules() (rules []models.VestingClaimRule, err error) {
+	err = r.claimRulesQuery().
+		Where("vcr_is_active").
+		Order("vcr_id").
+		Find(&rules).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+//SaveClaimRule creates or replaces vesting rule conditions
+func (r *Repository) SaveClaimRule(rule models.VestingClaimRule) (err error) {
+	rule.UpdatedAt = types.JSONTimestamp(time.Now())
+	rule.CreatedAt = rule.UpdatedAt
+
+	err = r.db.
+		Model(models.VestingClaimRule{}).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "vst_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"vcr_min_amount", "vcr_interval_days", "vcr_is_active", "vcr_updated_at"}),
+		}).
+		Create(&rule).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repository) UpdateClaimRuleRequest(ruleID uint64, requestHash string, claimAt time.Time) (err error) {
+	err = r.db.Model(&models.VestingClaimRule{ID: ruleID}).
+		Updates(map[string]interface{}{
+			"vcr_req_hash":      requestHash,
+			"vcr_last_claim_at": claimAt,
+			"vcr_updated_at":    time.Now(),
+		}).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repository) DeleteClaimRule(vestingID uint64) (err error) {
+	err = r.db.
+		Where("vst_id = ?", vestingID).
+		Delete(&models.VestingClaimRule{}).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+//Distinct addresses of vestings registered by any contract
+func (r *Repository) GetVestingAddresses() (addresses []types.Address, err error) {
+	err = r.db.Model(models.Vesting{}).
+		Distinct("vst_address").
+		Order("vst_address").
+		Pluck("vst_address", &addresses).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return addresses, nil
+}
+
+func (r *Repository) GetOperations(vestingAddress types.Address, params models.CommonParams) (operations []models.VestingOperation, err error) {
+	err = r.db.Model(models.VestingOperation{}).
+		Where("vop_vesting_address = ?", vestingAddress).
+		Order("vop_level desc, vop_id desc").
+		Limit(params.Limit).
+		Offset(params.Offset).
+		Find(&operations).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return operations, nil
+}
+
+//Sum of recorded vest ticks
+func (r *Repository) GetVestedTicks(vestingAddress types.Address) (ticks uint64, err error) {
+	err = r.db.Model(models.VestingOperation{}).
+		Select("coalesce(sum(vop_ticks), 0)").
+		Where("vop_vesting_address = ? AND vop_type = ?", vestingAddress, models.VestingVest).
+		Scan(&ticks).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return ticks, nil
+}
+
+func (r *Repository) SaveOperations(operations []models.VestingOperation) (err error) {
+	if len(operations) == 0 {
+		return nil
+	}
+
+	err = r.db.
+		Model(models.VestingOperation{}).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&operations).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
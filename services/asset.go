@@ -1,14 +1,15 @@
 package services
 
 import (
-	"context"
 	"database/sql"
 	"tezosign/common/apperrors"
+	"tezosign/common/log"
 	"tezosign/models"
 	"tezosign/services/contract"
 	"tezosign/types"
 
 	"github.com/wedancedalot/decimal"
+	"go.uber.org/zap"
 )
 
 const (
@@ -60,43 +61,23 @@ func (s *ServiceFacade) AssetsExchangeRates(userPubKey types.PubKey, contractAdd
 		return assetsRates, err
 	}
 
+	prices, err := s.lastAssetPrices()
+	if err != nil {
+		return assetsRates, err
+	}
+
 	//Init map
 	assetsRates = make(map[string]interface{}, len(assets))
 
 	for i := range assets {
+		price, ok := prices[assetTokenRef(assets[i])]
 		//Skip assets not presented on Exchange
-		if assets[i].DexterAddress == nil {
+		if !ok || price.Sign() <= 0 {
 			continue
 		}
 
-		script, err := s.rpcClient.Script(context.Background(), *assets[i].DexterAddress)
-		if err != nil {
-			return assetsRates, err
-		}
-
-		eps, err := contract.InitAnnotsEntrypoints(script.Code.Storage)
-		if err != nil {
-			return assetsRates, err
-		}
-
-		tokenPool, err := contract.GetDexterContractTokenPool(eps, script.Storage)
-		if err != nil {
-			return assetsRates, err
-		}
-
-		//In mutez
-		xtzPool, err := contract.GetDexterContractXTZPool(eps, script.Storage)
-		if err != nil {
-			return assetsRates, err
-		}
-
-		tPool := decimal.NewFromBigInt(tokenPool, -int32(assets[i].Scale))
-
-		xPool := decimal.NewFromBigInt(xtzPool, -TezosPrecision)
-
-		price := tPool.Div(xPool)
-
-		assetsRates[assets[i].Ticker] = price.Truncate(TruncatePrecision)
+		//Tokens per XTZ
+		assetsRates[assets[i].Ticker] = decimal.New(1, 0).Div(price).Truncate(TruncatePrecision)
 	}
 
 	return
@@ -130,6 +111,13 @@ func (s *ServiceFacade) ContractAsset(userPubKey types.PubKey, contractAddress t
 		return asset, apperrors.New(apperrors.ErrBadParam, "not FA asset")
 	}
 
+	//Name, ticker and scale can be omitted if token metadata presented
+	s.fillAssetFromMetadata(&reqAsset)
+
+	if err = reqAsset.Validate(); err != nil {
+		return asset, apperrors.New(apperrors.ErrBadRequest, err.Error())
+	}
+
 	assetRepo := s.repoProvider.GetAsset()
 	asset, isFound, err = assetRepo.GetAsset(contract.ID, reqAsset.Address, reqAsset.TokenID)
 	if err != nil {
@@ -278,7 +266,7 @@ func (s *ServiceFacade) AssetsIncomeOperations() (count uint64, err error) {
 		contractsMap[contracts[i].Address] = contracts[i]
 	}
 
-	networkID, err := s.rpcClient.ChainID(context.Background())
+	networkID, err := s.rpcClient.ChainID(s.ctx)
 	if err != nil {
 		return count, err
 	}
@@ -296,65 +284,8 @@ func (s *ServiceFacade) AssetsIncomeOperations() (count uint64, err error) {
 	return count, err
 }
 
-func (s *ServiceFacade) GetAssetMetadata(assetID types.Address, tokenID int64) (resp interface{}, err error) {
-
-	indexerRepo := s.indexerRepoProvider.GetIndexer()
-
-	script, isFound, err := indexerRepo.GetContractScript(assetID)
-	if err != nil {
-		return resp, err
-	}
-
-	if !isFound {
-		return resp, apperrors.New(apperrors.ErrNotFound, "script")
-	}
-
-	e, err := contract.InitAnnotsEntrypoints(script.StorageSchema.MichelinePrim())
-	if err != nil {
-		return resp, err
-	}
-
-	entrypoint, ok := e[contract.MetaDataEntrypoint]
-	if !ok {
-		return resp, apperrors.New(apperrors.ErrBadRequest, "token_metadata")
-	}
-
-	storage, isFound, err := indexerRepo.GetContractStorage(assetID)
-	if err != nil {
-		return resp, err
-	}
-
-	if !isFound {
-		return resp, apperrors.New(apperrors.ErrNotFound, "storage")
-	}
-
-	value, err := contract.GetStorageValue(entrypoint, storage.RawValue.MichelinePrim())
-	if err != nil {
-		return resp, err
-	}
-
-	bigMap := value.Int.Int64()
-
-	hash, err := contract.GetBigMapKeyHash(tokenID)
-	if err != nil {
-		return resp, err
-	}
-
-	bigMapValue, isFound, err := s.rpcClient.BigMapKey(context.Background(), bigMap, hash)
-	if err != nil {
-		return resp, err
-	}
-
-	if !isFound {
-		return resp, apperrors.New(apperrors.ErrNotFound, "big_map value")
-	}
-
-	v, err := contract.ParseMetadata(bigMapValue)
-	if err != nil {
-		return resp, err
-	}
-
-	return v, nil
+func (s *ServiceFacade) GetAssetMetadata(assetID types.Address, tokenID uint64) (resp models.TokenMetadata, err error) {
+	return s.GetTokenMetadata(assetID, tokenID)
 }
 
 func (s *ServiceFacade) processAssetOperations(contractsMap map[types.Address]models.Contract, networkID string, asset models.Asset) (count uint64, err error) {
@@ -380,11 +311,19 @@ func (s *ServiceFacade) processAssetOperations(contractsMap map[types.Address]mo
 		asset.LastOperationBlockLevel = block.Level
 	}
 
-	s.repoProvider.Start(context.Background())
+	err = s.repoProvider.Start(s.ctx)
+	if err != nil {
+		return count, err
+	}
 	defer s.repoProvider.RollbackUnlessCommitted()
 
 	for j := range assetOperations {
-		txs := contract.AssetOperation(assetOperations[j].RawParameters.MichelinePrim(), asset.ContractType)
+		txs, err := contract.AssetOperation(assetOperations[j].RawParameters.MichelinePrim(), asset.ContractType)
+		if err != nil {
+			log.Ctx(s.ctx).Warn("skip asset operation", zap.String("operation", assetOperations[j].OpHash), zap.Error(err))
+			asset.LastOperationBlockLevel = assetOperations[j].Level
+			continue
+		}
 
 		transferUnits := groupOperations(asset.TokenID, contractsMap, txs)
 		for contractAddress, transfers := range transferUnits {
@@ -484,20 +423,3 @@ func groupOperations(tokenID *uint64, contractsMap map[types.Address]models.Cont
 
 	return transferUnits
 }
-
-func (s *ServiceFacade) getContractTokensBalancesMap(contractAddress types.Address) (tokensMap map[types.Address][]models.TokenBalance, err error) {
-
-	balances, err := getAccountTokensBalance(contractAddress, s.net)
-	if err != nil {
-		return tokensMap, err
-	}
-
-	//TODO process total
-
-	tokensMap = make(map[types.Address][]models.TokenBalance, len(balances.Balances))
-	for i := range balances.Balances {
-		tokensMap[balances.Balances[i].Asset] = append(tokensMap[balances.Balances[i].Asset], balances.Balances[i].TokenBalance)
-	}
-
-	return tokensMap, nil
-}
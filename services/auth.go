@@ -1,6 +1,7 @@
 package services
 
 import (
+	"fmt"
 	"tezosign/common/apperrors"
 	"tezosign/conf"
 	"tezosign/models"
@@ -12,34 +13,75 @@ import (
 
 const expirationTime = 10 * time.Minute
 
-func (s *ServiceFacade) AuthRequest(req models.AuthTokenReq) (resp models.AuthTokenResp, err error) {
+func (s *ServiceFacade) AuthRequest(req models.AuthTokenReq, domain string) (resp models.AuthTokenResp, err error) {
 	authRepo := s.repoProvider.GetAuth()
 
-	activeToken, isFound, err := authRepo.GetActiveTokenByPubKeyAndType(req.PubKey, models.TypeAuth)
+	format := req.Format
+	if format == "" {
+		format = models.AuthPayloadFormatLegacy
+	}
+
+	activeToken, isFound, err := authRepo.GetActiveTokenByPubKeyAndType(req.PubKey, format.TokenType())
 	if err != nil {
 		return resp, err
 	}
 
 	//Already exist active auth request
 	if isFound {
-		resp.Token = models.NewAuthTokenPayload(activeToken.Data)
-		return resp, nil
+		return authTokenResp(activeToken, format)
 	}
 
-	reqUUID := uuid.NewV4()
-
-	err = authRepo.CreateAuthToken(models.AuthToken{
+	authToken := models.AuthToken{
 		PubKey:    req.PubKey,
-		Type:      models.TypeAuth,
-		Data:      reqUUID.String(),
+		Type:      format.TokenType(),
+		Data:      uuid.NewV4().String(),
 		IsUsed:    false,
 		ExpiresAt: time.Now().Add(expirationTime),
-	})
+	}
+
+	if format == models.AuthPayloadFormatMicheline {
+		address, err := req.PubKey.Address()
+		if err != nil {
+			return resp, apperrors.New(apperrors.ErrBadParam, "pub_key")
+		}
+
+		message := models.SignInMessage{
+			Domain:    domain,
+			Address:   address,
+			Network:   s.net,
+			Nonce:     authToken.Data,
+			IssuedAt:  time.Now().Truncate(time.Second),
+			ExpiresAt: authToken.ExpiresAt.Truncate(time.Second),
+		}.String()
+
+		authToken.Payload = &message
+	}
+
+	err = authRepo.CreateAuthToken(authToken)
 	if err != nil {
 		return
 	}
 
-	resp.Token = models.NewAuthTokenPayload(reqUUID.String())
+	return authTokenResp(authToken, format)
+}
+
+func authTokenResp(authToken models.AuthToken, format models.AuthPayloadFormat) (resp models.AuthTokenResp, err error) {
+	resp.PayloadType = format
+
+	if format != models.AuthPayloadFormatMicheline {
+		resp.Token = models.NewAuthTokenPayload(authToken.Data)
+		return resp, nil
+	}
+
+	if authToken.Payload == nil {
+		return resp, fmt.Errorf("empty auth token payload")
+	}
+
+	resp.Message = *authToken.Payload
+	resp.Token, err = models.PackSignInMessage(resp.Message)
+	if err != nil {
+		return resp, err
+	}
 
 	return resp, nil
 }
@@ -50,21 +92,49 @@ type AuthResponce struct {
 	EncodedCookie string `json:"-"`
 }
 
-func (s *ServiceFacade) Auth(req models.AuthSignature) (resp AuthResponce, err error) {
+func (s *ServiceFacade) Auth(req models.AuthSignature, domain string, meta models.SessionMeta) (resp AuthResponce, err error) {
+
+	var (
+		tokenData string
+		tokenType = models.TypeAuth
+		message   string
+	)
+
+	if req.Payload.IsMicheline() {
+		message, err = models.UnpackSignInMessage(req.Payload)
+		if err != nil {
+			return resp, apperrors.New(apperrors.ErrBadParam, "payload")
+		}
+
+		signIn, err := models.ParseSignInMessage(message)
+		if err != nil {
+			return resp, apperrors.New(apperrors.ErrBadParam, "payload")
+		}
+
+		err = s.checkSignIn(signIn, domain)
+		if err != nil {
+			return resp, err
+		}
+
+		tokenData = signIn.Nonce
+		tokenType = models.TypeAuthMicheline
+	} else {
+		tokenData = req.Payload.Token()
+	}
 
 	//Check that token in correct format
-	_, err = uuid.FromString(req.Payload.Token())
+	_, err = uuid.FromString(tokenData)
 	if err != nil {
 		return resp, apperrors.New(apperrors.ErrBadParam, "auth token wrong format")
 	}
 
 	authRepo := s.repoProvider.GetAuth()
 	//Get token
-	authToken, isFound, err := authRepo.GetAuthToken(req.Payload.Token())
+	authToken, isFound, err := authRepo.GetAuthToken(tokenData)
 	if err != nil {
 		return resp, err
 	}
-	if !isFound {
+	if !isFound || authToken.Type != tokenType {
 		return resp, apperrors.New(apperrors.ErrBadParam, "token")
 	}
 	if authToken.IsUsed {
@@ -75,6 +145,11 @@ func (s *ServiceFacade) Auth(req models.AuthSignature) (resp AuthResponce, err e
 		return resp, apperrors.New(apperrors.ErrBadParam, "auth token already expired")
 	}
 
+	//Signed message should be exactly the issued one
+	if tokenType == models.TypeAuthMicheline && (authToken.Payload == nil || *authToken.Payload != message) {
+		return resp, apperrors.New(apperrors.ErrBadParam, "payload")
+	}
+
 	payload, err := req.Payload.MarshalBinary()
 	if err != nil {
 		return resp, err
@@ -91,16 +166,20 @@ func (s *ServiceFacade) Auth(req models.AuthSignature) (resp AuthResponce, err e
 		return resp, apperrors.New(apperrors.ErrBadParam, "signature")
 	}
 
-	//Generate jwt
-	accessToken, refreshToken, encodedCookie, err := s.generateAuthData(authToken.PubKey)
+	//Mark as used, concurrent sign in with the same token is rejected
+	isMarked, err := authRepo.MarkAsUsedAuthToken(authToken.ID)
 	if err != nil {
 		return resp, err
 	}
 
-	//Mark as used
-	err = authRepo.MarkAsUsedAuthToken(authToken.ID)
+	if !isMarked {
+		return resp, apperrors.New(apperrors.ErrBadParam, "auth token already used")
+	}
+
+	//Generate jwt for new session
+	accessToken, refreshToken, encodedCookie, err := s.generateAuthData(authToken.PubKey, models.Session{}, meta)
 	if err != nil {
-		return
+		return resp, err
 	}
 
 	return AuthResponce{
@@ -110,7 +189,24 @@ func (s *ServiceFacade) Auth(req models.AuthSignature) (resp AuthResponce, err e
 	}, nil
 }
 
-func (s *ServiceFacade) RefreshAuthSession(oldRefreshToken string) (resp AuthResponce, err error) {
+//checkSignIn requires message bound to configured domain and service network
+func (s *ServiceFacade) checkSignIn(signIn models.SignInMessage, domain string) error {
+	if domain == "" || signIn.Domain != domain {
+		return apperrors.New(apperrors.ErrBadParam, "domain")
+	}
+
+	if signIn.Network != s.net {
+		return apperrors.New(apperrors.ErrBadParam, "network")
+	}
+
+	if signIn.Expired() {
+		return apperrors.New(apperrors.ErrBadParam, "auth token already expired")
+	}
+
+	return nil
+}
+
+func (s *ServiceFacade) RefreshAuthSession(oldRefreshToken string, meta models.SessionMeta) (resp AuthResponce, err error) {
 	authRepo := s.repoProvider.GetAuth()
 
 	token, isFound, err := authRepo.GetAuthToken(oldRefreshToken)
@@ -118,16 +214,42 @@ func (s *ServiceFacade) RefreshAuthSession(oldRefreshToken string) (resp AuthRes
 		return resp, err
 	}
 
-	if !isFound || token.Expired() || token.IsUsed {
+	if !isFound || token.Expired() || token.IsUsed || token.Type != models.TypeRefresh {
 		return resp, apperrors.New(apperrors.ErrBadParam, "refresh_token")
 	}
 
-	err = authRepo.MarkAsUsedAuthToken(token.ID)
+	err = s.repoProvider.Start(s.ctx)
 	if err != nil {
 		return resp, err
 	}
+	defer s.repoProvider.RollbackUnlessCommitted()
 
-	accessToken, refreshToken, encodedCookie, err := s.generateAuthData(token.PubKey)
+	//Only one of concurrent refreshes with the same token marks it
+	isMarked, err := s.repoProvider.GetAuth().MarkAsUsedAuthToken(token.ID)
+	if err != nil {
+		return resp, err
+	}
+
+	if !isMarked {
+		return resp, apperrors.New(apperrors.ErrBadParam, "refresh_token")
+	}
+
+	//Refresh tokens issued before sessions are not linked, new session will be created
+	session, isFound, err := s.repoProvider.GetSession().GetSessionByRefreshToken(oldRefreshToken)
+	if err != nil {
+		return resp, err
+	}
+
+	if isFound && session.IsRevoked() {
+		return resp, apperrors.New(apperrors.ErrBadParam, "refresh_token")
+	}
+
+	accessToken, refreshToken, encodedCookie, err := s.generateAuthData(token.PubKey, session, meta)
+	if err != nil {
+		return resp, err
+	}
+
+	err = s.repoProvider.Commit()
 	if err != nil {
 		return resp, err
 	}
@@ -139,6 +261,10 @@ func (s *ServiceFacade) RefreshAuthSession(oldRefreshToken string) (resp AuthRes
 	return resp, nil
 }
 
+func (s *ServiceFacade) JWKS() models.JWKS {
+	return s.auth.JWKS()
+}
+
 func (s *ServiceFacade) Logout(value string) (err error) {
 
 	tokens, err := s.auth.DecodeSessionCookie(value)
@@ -162,31 +288,81 @@ func (s *ServiceFacade) Logout(value string) (err error) {
 		return nil
 	}
 
-	err = authRepo.MarkAsUsedAuthToken(token.ID)
+	err = s.repoProvider.Start(s.ctx)
 	if err != nil {
 		return err
 	}
+	defer s.repoProvider.RollbackUnlessCommitted()
 
-	return nil
+	authRepo = s.repoProvider.GetAuth()
+
+	_, err = authRepo.MarkAsUsedAuthToken(token.ID)
+	if err != nil {
+		return err
+	}
+
+	session, isFound, err := s.repoProvider.GetSession().GetSessionByRefreshToken(refreshToken)
+	if err != nil {
+		return err
+	}
+
+	if isFound && !session.IsRevoked() {
+		err = s.revokeSession(session)
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.repoProvider.Commit()
 }
 
-func (s *ServiceFacade) generateAuthData(userPubKey types.PubKey) (accessToken string, refreshToken string, encodedCookie string, err error) {
-	accessToken, refreshToken, err = s.auth.GenerateAuthTokens(userPubKey)
+//Issue tokens for session, new session is created if session.ID is empty
+func (s *ServiceFacade) generateAuthData(userPubKey types.PubKey, session models.Session, meta models.SessionMeta) (accessToken string, refreshToken string, encodedCookie string, err error) {
+	isNewSession := session.ID == 0
+	if isNewSession {
+		session = models.Session{
+			UUID:      uuid.NewV4().String(),
+			PubKey:    userPubKey,
+			CreatedAt: types.JSONTimestamp(time.Now()),
+		}
+	}
+
+	accessToken, refreshToken, jti, err := s.auth.GenerateAuthTokens(userPubKey, session.UUID)
 	if err != nil {
 		return "", "", "", err
 	}
 
+	expiresAt := time.Now().Add(conf.TtlRefreshToken * time.Second)
+
 	//Save refresh token
 	err = s.repoProvider.GetAuth().CreateAuthToken(models.AuthToken{
 		PubKey:    userPubKey,
 		Data:      refreshToken,
 		Type:      models.TypeRefresh,
-		ExpiresAt: time.Now().Add(conf.TtlRefreshToken * time.Second),
+		ExpiresAt: expiresAt,
 	})
 	if err != nil {
 		return "", "", "", err
 	}
 
+	meta = meta.Normalize()
+	session.RefreshToken = refreshToken
+	session.JTI = jti
+	session.UserAgent = meta.UserAgent
+	session.IP = meta.IP
+	session.LastUsedAt = types.JSONTimestamp(time.Now())
+	session.ExpiresAt = types.JSONTimestamp(expiresAt)
+
+	sessionRepo := s.repoProvider.GetSession()
+	if isNewSession {
+		err = sessionRepo.CreateSession(session)
+	} else {
+		err = sessionRepo.UpdateSession(session)
+	}
+	if err != nil {
+		return "", "", "", err
+	}
+
 	tokens := map[string]string{
 		"access_token":  accessToken,
 		"refresh_token": refreshToken,
@@ -1,8 +1,6 @@
 package auth
 
 import (
-	"crypto/ecdsa"
-	"crypto/x509"
 	"encoding/hex"
 	"fmt"
 	"net/http"
@@ -19,8 +17,7 @@ import (
 )
 
 type Auth struct {
-	privateKey   *ecdsa.PrivateKey
-	pubKey       *ecdsa.PublicKey
+	keys         keySet
 	secureCookie *securecookie.SecureCookie
 	network      models.Network
 }
@@ -29,16 +26,13 @@ const (
 	authorizationHeader = "Authorization"
 	UserPubKeyHeader    = "user_pubkey"
 	networkHeader       = "network"
+	sessionHeader       = "sid"
+	jtiHeader           = "jti"
 )
 
 func NewAuthProvider(authConf conf.Auth, network models.Network) (*Auth, error) {
 
-	bt, err := hex.DecodeString(authConf.AuthKey)
-	if err != nil {
-		return nil, err
-	}
-
-	privKey, err := x509.ParseECPrivateKey(bt)
+	keys, err := newKeySet(authConf)
 	if err != nil {
 		return nil, err
 	}
@@ -58,36 +52,47 @@ func NewAuthProvider(authConf conf.Auth, network models.Network) (*Auth, error)
 
 	sc := securecookie.New(hashKey, blockKey)
 
-	return &Auth{privateKey: privKey, pubKey: &privKey.PublicKey, secureCookie: sc, network: network}, nil
+	return &Auth{keys: keys, secureCookie: sc, network: network}, nil
 }
 
-func (a *Auth) GenerateAuthTokens(pubkey types.PubKey) (accessToken, refreshToken string, err error) {
-	accessToken, err = a.generateAccessToken(pubkey)
+func (a *Auth) GenerateAuthTokens(pubkey types.PubKey, sessionID string) (accessToken, refreshToken, jti string, err error) {
+	jti = uuid.NewV4().String()
+
+	accessToken, err = a.generateAccessToken(pubkey, sessionID, jti)
 	if err != nil {
-		return "", "", err
+		return "", "", "", err
 	}
 
 	refreshToken, err = a.generateRefreshToken(pubkey)
 	if err != nil {
-		return "", "", err
+		return "", "", "", err
 	}
 
-	return accessToken, refreshToken, nil
+	return accessToken, refreshToken, jti, nil
 }
 
-func (a *Auth) generateAccessToken(pubkey types.PubKey) (accessToken string, err error) {
+func (a *Auth) generateAccessToken(pubkey types.PubKey, sessionID, jti string) (accessToken string, err error) {
 	if err = pubkey.Validate(); err != nil {
 		return "", err
 	}
 
+	key, err := a.keys.signingKey(time.Now())
+	if err != nil {
+		return "", err
+	}
+
 	// create the jwt token
-	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+	token := jwt.NewWithClaims(key.method, jwt.MapClaims{
 		UserPubKeyHeader: pubkey.String(),
 		networkHeader:    a.network,
+		sessionHeader:    sessionID,
+		jtiHeader:        jti,
 		"exp":            time.Now().Add(time.Second * conf.TtlJWT).Unix(),
 	})
 
-	accessToken, err = token.SignedString(a.privateKey)
+	token.Header[kidHeader] = key.id
+
+	accessToken, err = token.SignedString(key.privateKey)
 	if err != nil {
 		return "", err
 	}
@@ -122,36 +127,52 @@ func (a *Auth) DecodeSessionCookie(cookie string) (map[string]string, error) {
 	return value, nil
 }
 
-func (a *Auth) CheckSignatureAndGetUserPubKey(r *http.Request) (string, error) {
+func (a *Auth) CheckSignatureAndGetClaims(r *http.Request) (resp models.AccessTokenClaims, err error) {
 	authHeader := strings.SplitN(r.Header.Get(authorizationHeader), " ", 2)
 	if len(authHeader) != 2 {
-		return "", apperrors.New(apperrors.ErrBadAuth)
+		return resp, apperrors.New(apperrors.ErrBadAuth)
 	}
 
 	token, claims, err := a.ParseAndCheckToken(authHeader[1])
 	if err != nil {
-		return "", apperrors.New(apperrors.ErrBadJwt)
+		return resp, apperrors.New(apperrors.ErrBadJwt)
 	}
 
 	if token == nil {
-		return "", apperrors.New(apperrors.ErrBadJwt)
+		return resp, apperrors.New(apperrors.ErrBadJwt)
 	}
 
 	err = token.Claims.Valid()
 	if err != nil {
-		return "", apperrors.New(apperrors.ErrBadJwt)
+		return resp, apperrors.New(apperrors.ErrBadJwt)
 	}
 
 	if network, ok := claims[networkHeader].(string); !ok || network != string(a.network) {
-		return "", apperrors.New(apperrors.ErrBadJwt)
+		return resp, apperrors.New(apperrors.ErrBadJwt)
+	}
+
+	userPubKey, ok := claims[UserPubKeyHeader].(string)
+	if !ok || userPubKey == "" {
+		return resp, apperrors.New(apperrors.ErrBadJwt)
 	}
 
-	userPubKey, ok := claims[UserPubKeyHeader]
-	if !ok || userPubKey.(string) == "" {
-		return "", apperrors.New(apperrors.ErrBadJwt)
+	//Tokens without session can not be revoked
+	sessionID, _ := claims[sessionHeader].(string)
+	jti, _ := claims[jtiHeader].(string)
+	if sessionID == "" || jti == "" {
+		return resp, apperrors.New(apperrors.ErrBadJwt)
 	}
 
-	return userPubKey.(string), nil
+	return models.AccessTokenClaims{
+		PubKey:    types.PubKey(userPubKey),
+		SessionID: sessionID,
+		JTI:       jti,
+	}, nil
+}
+
+//Public keys for access tokens verification
+func (a *Auth) JWKS() models.JWKS {
+	return a.keys.jwks(time.Now())
 }
 
 func (a *Auth) ParseAndCheckToken(t string) (*jwt.Token, jwt.MapClaims, error) {
@@ -162,7 +183,14 @@ func (a *Auth) ParseAndCheckToken(t string) (*jwt.Token, jwt.MapClaims, error) {
 			return nil, fmt.Errorf("Bad JWT method")
 		}
 
-		return a.pubKey, nil
+		kid, _ := token.Header[kidHeader].(string)
+
+		pubKey, ok := a.keys.verificationKey(kid, time.Now())
+		if !ok {
+			return nil, fmt.Errorf("Unknown JWT key %s", kid)
+		}
+
+		return pubKey, nil
 	})
 
 	if err != nil {
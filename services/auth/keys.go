@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"tezosign/conf"
+	"tezosign/models"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+//Tokens without kid header are verified by the key configured in conf.Auth.AuthKey
+const defaultKeyID = "default"
+
+const kidHeader = "kid"
+
+type signingKey struct {
+	id         string
+	privateKey *ecdsa.PrivateKey
+	method     *jwt.SigningMethodECDSA
+	verifyOnly bool
+	activeFrom time.Time
+	//Zero for no limit
+	expiresAt time.Time
+}
+
+type keySet []signingKey
+
+func newKeySet(authConf conf.Auth) (keys keySet, err error) {
+	//AuthKey is the oldest key, it signs tokens until any configured key becomes active
+	if authConf.AuthKey != "" {
+		privKey, method, err := parsePrivateKey(authConf.AuthKey)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, signingKey{
+			id:         defaultKeyID,
+			privateKey: privKey,
+			method:     method,
+		})
+	}
+
+	for _, key := range authConf.Keys {
+		if key.ID == defaultKeyID && authConf.AuthKey != "" {
+			return nil, fmt.Errorf("auth key id %s is reserved", defaultKeyID)
+		}
+
+		privKey, method, err := parsePrivateKey(key.Key)
+		if err != nil {
+			return nil, fmt.Errorf("auth key %s: %s", key.ID, err.Error())
+		}
+
+		sk := signingKey{
+			id:         key.ID,
+			privateKey: privKey,
+			method:     method,
+			verifyOnly: key.VerifyOnly,
+			activeFrom: time.Unix(key.ActiveFrom, 0),
+		}
+
+		if key.ExpiresAt != 0 {
+			sk.expiresAt = time.Unix(key.ExpiresAt, 0)
+		}
+
+		keys = append(keys, sk)
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("auth keys not presented")
+	}
+
+	return keys, nil
+}
+
+func parsePrivateKey(hexKey string) (privKey *ecdsa.PrivateKey, method *jwt.SigningMethodECDSA, err error) {
+	bt, err := hex.DecodeString(hexKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	privKey, err = x509.ParseECPrivateKey(bt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	//JWT algorithm is defined by key curve
+	switch privKey.Curve.Params().BitSize {
+	case 256:
+		method = jwt.SigningMethodES256
+	case 384:
+		method = jwt.SigningMethodES384
+	case 521:
+		method = jwt.SigningMethodES512
+	default:
+		return nil, nil, fmt.Errorf("unsupported curve %s", privKey.Curve.Params().Name)
+	}
+
+	return privKey, method, nil
+}
+
+func (k signingKey) expired(now time.Time) bool {
+	return !k.expiresAt.IsZero() && !now.Before(k.expiresAt)
+}
+
+//Newest started not expired key signs tokens
+func (ks keySet) signingKey(now time.Time) (key signingKey, err error) {
+	isFound := false
+	for _, k := range ks {
+		if k.verifyOnly || k.expired(now) || k.activeFrom.After(now) {
+			continue
+		}
+
+		if !isFound || k.activeFrom.After(key.activeFrom) {
+			key = k
+			isFound = true
+		}
+	}
+
+	if !isFound {
+		return key, fmt.Errorf("active signing key not found")
+	}
+
+	return key, nil
+}
+
+//Keys which are not yet active are published for verification in advance
+func (ks keySet) verificationKey(kid string, now time.Time) (*ecdsa.PublicKey, bool) {
+	if kid == "" {
+		kid = defaultKeyID
+	}
+
+	for _, k := range ks {
+		if k.id == kid && !k.expired(now) {
+			return &k.privateKey.PublicKey, true
+		}
+	}
+
+	return nil, false
+}
+
+func (ks keySet) jwks(now time.Time) (resp models.JWKS) {
+	resp.Keys = []models.JWK{}
+	for _, k := range ks {
+		if k.expired(now) {
+			continue
+		}
+
+		params := k.privateKey.Curve.Params()
+		size := (params.BitSize + 7) / 8
+
+		resp.Keys = append(resp.Keys, models.JWK{
+			KeyType:   "EC",
+			Curve:     params.Name,
+			X:         base64.RawURLEncoding.EncodeToString(padBytes(k.privateKey.X.Bytes(), size)),
+			Y:         base64.RawURLEncoding.EncodeToString(padBytes(k.privateKey.Y.Bytes(), size)),
+			KeyID:     k.id,
+			Use:       "sig",
+			Algorithm: k.method.Alg(),
+		})
+	}
+
+	return resp
+}
+
+//Left pad coordinate to curve size
+func padBytes(bt []byte, size int) []byte {
+	if len(bt) >= size {
+		return bt
+	}
+
+	padded := make([]byte, size)
+	copy(padded[size-len(bt):], bt)
+	return padded
+}
@@ -0,0 +1,162 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/hex"
+	"testing"
+	"tezosign/conf"
+	"time"
+)
+
+func generateHexKey(t *testing.T) string {
+	return generateCurveHexKey(t, elliptic.P256())
+}
+
+func generateCurveHexKey(t *testing.T, curve elliptic.Curve) string {
+	privKey, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bt, err := x509.MarshalECPrivateKey(privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return hex.EncodeToString(bt)
+}
+
+func Test_KeySetSigningKey(t *testing.T) {
+	now := time.Unix(1614556800, 0)
+
+	legacyKey := generateHexKey(t)
+
+	testCases := []struct {
+		name    string
+		conf    conf.Auth
+		expID   string
+		wantErr bool
+	}{
+		{
+			name:  "Single legacy key",
+			conf:  conf.Auth{AuthKey: legacyKey},
+			expID: defaultKeyID,
+		},
+		{
+			name: "Newest started key",
+			conf: conf.Auth{
+				AuthKey: legacyKey,
+				Keys: []conf.AuthKey{
+					{ID: "old", Key: generateHexKey(t), ActiveFrom: now.Unix() - 200},
+					{ID: "current", Key: generateHexKey(t), ActiveFrom: now.Unix() - 100},
+					{ID: "next", Key: generateHexKey(t), ActiveFrom: now.Unix() + 100},
+				},
+			},
+			expID: "current",
+		},
+		{
+			name: "Verify only and expired keys skipped",
+			conf: conf.Auth{
+				Keys: []conf.AuthKey{
+					{ID: "current", Key: generateHexKey(t), ActiveFrom: now.Unix() - 300},
+					{ID: "verify", Key: generateHexKey(t), VerifyOnly: true, ActiveFrom: now.Unix() - 100},
+					{ID: "expired", Key: generateHexKey(t), ActiveFrom: now.Unix() - 200, ExpiresAt: now.Unix()},
+				},
+			},
+			expID: "current",
+		},
+		{
+			name: "Legacy key signs until rotation",
+			conf: conf.Auth{
+				AuthKey: legacyKey,
+				Keys: []conf.AuthKey{
+					{ID: "next", Key: generateHexKey(t), ActiveFrom: now.Unix() + 100},
+				},
+			},
+			expID: defaultKeyID,
+		},
+		{
+			name: "No active keys",
+			conf: conf.Auth{
+				Keys: []conf.AuthKey{
+					{ID: "next", Key: generateHexKey(t), ActiveFrom: now.Unix() + 100},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			keys, err := newKeySet(test.conf)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			key, gotErr := keys.signingKey(now)
+			if test.wantErr != (gotErr != nil) || (gotErr == nil && key.id != test.expID) {
+				t.Errorf("wantErr: %t | err: %v | key: %s", test.wantErr, gotErr, key.id)
+			}
+		})
+	}
+}
+
+func Test_KeySetVerificationKey(t *testing.T) {
+	now := time.Unix(1614556800, 0)
+
+	keys, err := newKeySet(conf.Auth{
+		AuthKey: generateHexKey(t),
+		Keys: []conf.AuthKey{
+			{ID: "current", Key: generateHexKey(t), ActiveFrom: now.Unix() - 100},
+			{ID: "next", Key: generateHexKey(t), ActiveFrom: now.Unix() + 100},
+			{ID: "expired", Key: generateHexKey(t), ActiveFrom: now.Unix() - 200, ExpiresAt: now.Unix() - 1},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for kid, expFound := range map[string]bool{
+		"":        true,
+		"current": true,
+		"next":    true,
+		"expired": false,
+		"unknown": false,
+	} {
+		if _, isFound := keys.verificationKey(kid, now); isFound != expFound {
+			t.Errorf("kid: %s | found: %t", kid, isFound)
+		}
+	}
+
+	if jwks := keys.jwks(now); len(jwks.Keys) != 3 {
+		t.Errorf("jwks keys: %d", len(jwks.Keys))
+	}
+}
+
+func Test_KeySetAlgorithm(t *testing.T) {
+	keys, err := newKeySet(conf.Auth{
+		Keys: []conf.AuthKey{
+			{ID: "p256", Key: generateCurveHexKey(t, elliptic.P256())},
+			{ID: "p384", Key: generateCurveHexKey(t, elliptic.P384())},
+			{ID: "p521", Key: generateCurveHexKey(t, elliptic.P521())},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expAlgs := map[string]string{"p256": "ES256", "p384": "ES384", "p521": "ES512"}
+	for _, jwk := range keys.jwks(time.Now()).Keys {
+		if jwk.Algorithm != expAlgs[jwk.KeyID] {
+			t.Errorf("kid: %s | alg: %s", jwk.KeyID, jwk.Algorithm)
+		}
+	}
+
+	_, err = newKeySet(conf.Auth{AuthKey: generateCurveHexKey(t, elliptic.P224())})
+	if err == nil {
+		t.Error("P-224 key is accepted")
+	}
+}
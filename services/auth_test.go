@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"tezosign/models"
+	"time"
+)
+
+func Test_CheckSignIn(t *testing.T) {
+	const domain = "tzsign.example.com"
+
+	s := New(context.Background(), nil, nil, nil, nil, models.NetworkMain)
+
+	valid := models.SignInMessage{
+		Domain:    domain,
+		Address:   "tz1dBT7PKeSDbPK1No7KNhTvrr3XoLe8vKLH",
+		Network:   models.NetworkMain,
+		Nonce:     "3f4e2c1a-8b7d-4e6f-9a0b-1c2d3e4f5a6b",
+		IssuedAt:  time.Now(),
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+
+	phishing := valid
+	phishing.Domain = "tzsign.example.com.evil.io"
+
+	otherNetwork := valid
+	otherNetwork.Network = models.NetworkEdo
+
+	expired := valid
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+
+	testCases := []struct {
+		name    string
+		signIn  models.SignInMessage
+		domain  string
+		isValid bool
+	}{
+		{name: "Valid", signIn: valid, domain: domain, isValid: true},
+		{name: "Other domain", signIn: phishing, domain: domain},
+		{name: "Domain not configured", signIn: valid},
+		{name: "Other network", signIn: otherNetwork, domain: domain},
+		{name: "Expired", signIn: expired, domain: domain},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			err := s.checkSignIn(test.signIn, test.domain)
+			if (err == nil) != test.isValid {
+				t.Errorf("err: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,344 @@
+package services
+
+import (
+	"math/big"
+	"tezosign/common/apperrors"
+	"tezosign/common/log"
+	"tezosign/conf"
+	"tezosign/models"
+	"tezosign/services/contract"
+	"tezosign/types"
+	"time"
+
+	"blockwatch.cc/tzindex/micheline"
+	"github.com/wedancedalot/decimal"
+	"go.uber.org/zap"
+)
+
+const (
+	contractsBatchSize      = 100
+	defaultTokenBalancesTTL = 5 * time.Minute
+)
+
+type assetLedger struct {
+	ledger contract.Ledger
+	bigMap int64
+	err    error
+}
+
+//ledgerReader reads token balances from asset ledger big maps, layout is detected once per asset contract
+type ledgerReader struct {
+	s       *ServiceFacade
+	ledgers map[types.Address]assetLedger
+}
+
+func (s *ServiceFacade) newLedgerReader() *ledgerReader {
+	return &ledgerReader{
+		s:       s,
+		ledgers: map[types.Address]assetLedger{},
+	}
+}
+
+func (r *ledgerReader) assetLedger(asset types.Address) (l assetLedger) {
+	l, ok := r.ledgers[asset]
+	if ok {
+		return l
+	}
+
+	defer func() {
+		r.ledgers[asset] = l
+	}()
+
+	storageSchema, storage, err := r.storage(asset)
+	if err != nil {
+		l.err = err
+		return l
+	}
+
+	l.ledger, l.err = contract.DetectLedger(storageSchema)
+	if l.err != nil {
+		return l
+	}
+
+	bigMap, err := contract.GetStorageValue(contract.Entrypoint{Branch: l.ledger.Path}, storage)
+	if err != nil {
+		l.err = err
+		return l
+	}
+
+	if bigMap.Int == nil {
+		l.err = apperrors.New(apperrors.ErrBadParam, "ledger big_map")
+		return l
+	}
+
+	l.bigMap = bigMap.Int.Int64()
+
+	return l
+}
+
+func (r *ledgerReader) storage(asset types.Address) (storageSchema *micheline.Prim, storage *micheline.Prim, err error) {
+	if r.s.cfg.TokenBalances.Source == conf.TokenBalancesSourceRPC {
+		script, err := r.s.rpcClient.Script(r.s.ctx, asset.String())
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return script.Code.Storage, script.Storage, nil
+	}
+
+	script, storageValue, err := r.s.getContractScriptAndStorage(asset)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return script.StorageSchema.MichelinePrim(), storageValue.RawValue.MichelinePrim(), nil
+}
+
+func (r *ledgerReader) balance(asset models.Asset, holder types.Address) (balance *big.Int, err error) {
+	l := r.assetLedger(asset.Address)
+	if l.err != nil {
+		return nil, l.err
+	}
+
+	var tokenID uint64
+	if asset.TokenID != nil {
+		tokenID = *asset.TokenID
+	}
+
+	hash, err := l.ledger.KeyHash(holder, tokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	value, isFound, err := r.bigMapValue(l.bigMap, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	//Holder not presented in ledger
+	if !isFound {
+		return big.NewInt(0), nil
+	}
+
+	return l.ledger.Balance(holder, value)
+}
+
+func (r *ledgerReader) bigMapValue(bigMap int64, hash string) (value *micheline.Prim, isFound bool, err error) {
+	if r.s.cfg.TokenBalances.Source == conf.TokenBalancesSourceRPC {
+		data, isFound, err := r.s.rpcClient.BigMapKey(r.s.ctx, bigMap, hash)
+		if err != nil || !isFound {
+			return nil, isFound, err
+		}
+
+		value = &micheline.Prim{}
+		err = value.UnmarshalJSON(data)
+		if err != nil {
+			return nil, false, err
+		}
+
+		return value, true, nil
+	}
+
+	key, isFound, err := r.s.indexerRepoProvider.GetIndexer().GetBigMapKey(bigMap, hash)
+	if err != nil || !isFound {
+		return nil, isFound, err
+	}
+
+	return key.RawValue.MichelinePrim(), true, nil
+}
+
+//Active assets of contract
+func (s *ServiceFacade) holderAssets(holder types.Address) (assets []models.Asset, err error) {
+	c, isFound, err := s.repoProvider.GetContract().GetContract(holder)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isFound {
+		return nil, apperrors.New(apperrors.ErrNotFound, "contract")
+	}
+
+	return s.repoProvider.GetAsset().GetAssetsList(c.ID, true, true, false)
+}
+
+//Read balances of assets from ledgers, failed assets are logged and returned separately
+func (s *ServiceFacade) readHolderBalances(reader *ledgerReader, holder types.Address, assets []models.Asset) (balances []models.HolderTokenBalance, failed []models.Asset) {
+	now := time.Now()
+	balances = make([]models.HolderTokenBalance, 0, len(assets))
+	for i := range assets {
+		balance, err := reader.balance(assets[i], holder)
+		if err != nil {
+			log.Ctx(s.ctx).Warn("read token balance failed", zap.String("contract", holder.String()), zap.String("asset", assets[i].Address.String()), zap.Error(err))
+			failed = append(failed, assets[i])
+			continue
+		}
+
+		balances = append(balances, models.HolderTokenBalance{
+			Holder:    holder,
+			Asset:     assets[i].Address,
+			TokenID:   assetTokenID(assets[i]),
+			Balance:   decimal.NewFromBigInt(balance, 0),
+			UpdatedAt: now,
+		})
+	}
+
+	return balances, failed
+}
+
+//Read balances of contract assets from ledgers and save them to cache
+func (s *ServiceFacade) refreshHolderBalances(reader *ledgerReader, holder types.Address) (balances []models.HolderTokenBalance, err error) {
+	assets, err := s.holderAssets(holder)
+	if err != nil {
+		return nil, err
+	}
+
+	balances, _ = s.readHolderBalances(reader, holder, assets)
+
+	err = s.repoProvider.GetBalance().SaveHolderBalances(balances)
+	if err != nil {
+		return nil, err
+	}
+
+	return balances, nil
+}
+
+func assetTokenID(asset models.Asset) uint64 {
+	if asset.TokenID == nil {
+		return 0
+	}
+
+	return *asset.TokenID
+}
+
+func (s *ServiceFacade) tokenBalancesTTL() time.Duration {
+	if s.cfg.TokenBalances.TTL > 0 {
+		return time.Duration(s.cfg.TokenBalances.TTL) * time.Second
+	}
+
+	return defaultTokenBalancesTTL
+}
+
+//RefreshTokenBalances updates cached balances of all contracts
+func (s *ServiceFacade) RefreshTokenBalances() (count uint64, err error) {
+	reader := s.newLedgerReader()
+	contractRepo := s.repoProvider.GetContract()
+
+	for offset := 0; ; offset += contractsBatchSize {
+		contracts, err := contractRepo.GetContractsList(contractsBatchSize, offset)
+		if err != nil {
+			return count, err
+		}
+
+		for i := range contracts {
+			balances, err := s.refreshHolderBalances(reader, contracts[i].Address)
+			if err != nil {
+				log.Ctx(s.ctx).Error("refresh token balances failed", zap.String("contract", contracts[i].Address.String()), zap.Error(err))
+				continue
+			}
+
+			count += uint64(len(balances))
+		}
+
+		if len(contracts) < contractsBatchSize {
+			return count, nil
+		}
+	}
+}
+
+//Cached balances are refreshed per asset when missing or older than TTL
+func (s *ServiceFacade) getContractTokensBalancesMap(contractAddress types.Address) (tokensMap map[types.Address][]models.TokenBalance, err error) {
+	assets, err := s.holderAssets(contractAddress)
+	if err != nil {
+		return tokensMap, err
+	}
+
+	cached, err := s.repoProvider.GetBalance().GetHolderBalances(contractAddress)
+	if err != nil {
+		return tokensMap, err
+	}
+
+	type tokenKey struct {
+		asset   types.Address
+		tokenID uint64
+	}
+
+	cachedMap := make(map[tokenKey]models.HolderTokenBalance, len(cached))
+	for i := range cached {
+		cachedMap[tokenKey{asset: cached[i].Asset, tokenID: cached[i].TokenID}] = cached[i]
+	}
+
+	staleBefore := time.Now().Add(-s.tokenBalancesTTL())
+
+	var stale []models.Asset
+	balances := make([]models.HolderTokenBalance, 0, len(assets))
+	for i := range assets {
+		balance, ok := cachedMap[tokenKey{asset: assets[i].Address, tokenID: assetTokenID(assets[i])}]
+		if !ok || balance.UpdatedAt.Before(staleBefore) {
+			stale = append(stale, assets[i])
+			continue
+		}
+
+		balances = append(balances, balance)
+	}
+
+	refreshed, failed := s.readHolderBalances(s.newLedgerReader(), contractAddress, stale)
+
+	err = s.repoProvider.GetBalance().SaveHolderBalances(refreshed)
+	if err != nil {
+		return tokensMap, err
+	}
+
+	balances = append(balances, refreshed...)
+
+	var remoteMap map[types.Address][]models.TokenBalance
+	for i := range failed {
+		//Outdated balance is better than none
+		if balance, ok := cachedMap[tokenKey{asset: failed[i].Address, tokenID: assetTokenID(failed[i])}]; ok {
+			balances = append(balances, balance)
+			continue
+		}
+
+		if !s.cfg.TokenBalances.RemoteFallback {
+			continue
+		}
+
+		if remoteMap == nil {
+			remoteMap, err = s.getRemoteTokensBalancesMap(contractAddress)
+			if err != nil {
+				log.Ctx(s.ctx).Warn("remote token balances failed", zap.String("contract", contractAddress.String()), zap.Error(err))
+				remoteMap = map[types.Address][]models.TokenBalance{}
+			}
+		}
+
+		for _, remote := range remoteMap[failed[i].Address] {
+			if remote.TokenId == assetTokenID(failed[i]) {
+				balances = append(balances, models.HolderTokenBalance{Asset: failed[i].Address, TokenID: remote.TokenId, Balance: remote.Balance})
+			}
+		}
+	}
+
+	tokensMap = make(map[types.Address][]models.TokenBalance, len(balances))
+	for i := range balances {
+		tokensMap[balances[i].Asset] = append(tokensMap[balances[i].Asset], models.TokenBalance{
+			Balance: balances[i].Balance,
+			TokenId: balances[i].TokenID,
+		})
+	}
+
+	return tokensMap, nil
+}
+
+func (s *ServiceFacade) getRemoteTokensBalancesMap(contractAddress types.Address) (tokensMap map[types.Address][]models.TokenBalance, err error) {
+
+	balances, err := getAccountTokensBalance(s.ctx, contractAddress, s.net)
+	if err != nil {
+		return tokensMap, err
+	}
+
+	tokensMap = make(map[types.Address][]models.TokenBalance, len(balances.Balances))
+	for i := range balances.Balances {
+		tokensMap[balances.Balances[i].Asset] = append(tokensMap[balances.Balances[i].Asset], balances.Balances[i].TokenBalance)
+	}
+
+	return tokensMap, nil
+}
@@ -1,16 +1,25 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"tezosign/models"
 	"tezosign/types"
+	"time"
 )
 
 //TODO make as URL
-const betterCallDevAccountAPI = "https://api.better-call.dev/v1/account/%s/%s/token_balances?offset=0&size=10"
+const (
+	betterCallDevAccountAPI = "https://api.better-call.dev/v1/account/%s/%s/token_balances?offset=%d&size=%d"
+	betterCallDevPageSize   = 10
+	betterCallDevTimeout    = 10 * time.Second
+)
+
+//Page request is also bounded by caller context
+var bcdClient = &http.Client{Timeout: betterCallDevTimeout}
 
 var bcdNetworks = map[models.Network]string{
 	models.NetworkMain:     "mainnet",
@@ -18,16 +27,42 @@ var bcdNetworks = map[models.Network]string{
 	models.NetworkFlorence: "florencenet",
 }
 
-func getAccountTokensBalance(account types.Address, network models.Network) (balances models.AssetBalances, err error) {
-	resp, err := http.Get(fmt.Sprintf(betterCallDevAccountAPI, bcdNetworks[network], account.String()))
+func getAccountTokensBalance(ctx context.Context, account types.Address, network models.Network) (balances models.AssetBalances, err error) {
+	bcdNetwork, ok := bcdNetworks[network]
+	if !ok {
+		return balances, fmt.Errorf("network %s not supported by better-call.dev", network)
+	}
+
+	for offset := 0; ; offset += betterCallDevPageSize {
+		page, err := getAccountTokensBalancePage(ctx, account, bcdNetwork, offset)
+		if err != nil {
+			return balances, err
+		}
+
+		balances.Total = page.Total
+		balances.Balances = append(balances.Balances, page.Balances...)
+
+		if len(page.Balances) == 0 || uint64(len(balances.Balances)) >= page.Total {
+			return balances, nil
+		}
+	}
+}
+
+func getAccountTokensBalancePage(ctx context.Context, account types.Address, bcdNetwork string, offset int) (balances models.AssetBalances, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(betterCallDevAccountAPI, bcdNetwork, account.String(), offset, betterCallDevPageSize), nil)
 	if err != nil {
 		return balances, err
 	}
 
+	resp, err := bcdClient.Do(req)
+	if err != nil {
+		return balances, err
+	}
+	defer resp.Body.Close()
+
 	if resp.StatusCode != http.StatusOK {
 		return balances, fmt.Errorf("Not OK status code: %d", resp.StatusCode)
 	}
-	defer resp.Body.Close()
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
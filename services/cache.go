@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"tezosign/models"
+	"tezosign/repos/indexer"
+	"tezosign/services/cache"
+	"tezosign/types"
+
+	"blockwatch.cc/tzindex/micheline"
+)
+
+//Cache buckets
+const (
+	cacheChainID        = "chain_id"
+	cacheScript         = "script"
+	cacheTokenMetadata  = "token_metadata"
+	cacheRPCScript      = "rpc_script"
+	cacheBalance        = "balance"
+	cacheStorage        = "storage"
+	cacheBigMapKey      = "big_map_key"
+	cacheAccount        = "account"
+	cacheIndexerStorage = "indexer_storage"
+)
+
+//WithCache puts network cache in front of rpc and indexer
+func (s *ServiceFacade) WithCache(c *cache.Cache) *ServiceFacade {
+	if c == nil {
+		return s
+	}
+
+	s.cache = c
+	s.rpcClient = cachedRPC{RPCProvider: s.rpcClient, cache: c}
+	s.indexerRepoProvider = cachedIndexerProvider{IndexerProvider: s.indexerRepoProvider, cache: c}
+
+	return s
+}
+
+//cachedRPC caches chain ID and head state, operation simulation and counters are always requested from node
+type cachedRPC struct {
+	RPCProvider
+	cache *cache.Cache
+}
+
+func (r cachedRPC) ChainID(ctx context.Context) (chainID string, err error) {
+	if value, ok := r.cache.Get(cacheChainID, ""); ok {
+		return value.(string), nil
+	}
+
+	chainID, err = r.RPCProvider.ChainID(ctx)
+	if err != nil {
+		return chainID, err
+	}
+
+	r.cache.Set(cacheChainID, "", chainID)
+
+	return chainID, nil
+}
+
+//Script is head scoped, rpc script contains storage
+func (r cachedRPC) Script(ctx context.Context, address string) (script micheline.Script, err error) {
+	if value, ok := r.cache.Get(cacheRPCScript, address); ok {
+		return value.(micheline.Script), nil
+	}
+
+	script, err = r.RPCProvider.Script(ctx, address)
+	if err != nil {
+		return script, err
+	}
+
+	r.cache.SetHeadScoped(cacheRPCScript, address, script)
+
+	return script, nil
+}
+
+func (r cachedRPC) Storage(ctx context.Context, contractAddress string) (storage string, err error) {
+	if value, ok := r.cache.Get(cacheStorage, contractAddress); ok {
+		return value.(string), nil
+	}
+
+	storage, err = r.RPCProvider.Storage(ctx, contractAddress)
+	if err != nil {
+		return storage, err
+	}
+
+	r.cache.SetHeadScoped(cacheStorage, contractAddress, storage)
+
+	return storage, nil
+}
+
+func (r cachedRPC) Balance(ctx context.Context, address string) (balance int64, err error) {
+	if value, ok := r.cache.Get(cacheBalance, address); ok {
+		return value.(int64), nil
+	}
+
+	balance, err = r.RPCProvider.Balance(ctx, address)
+	if err != nil {
+		return balance, err
+	}
+
+	r.cache.SetHeadScoped(cacheBalance, address, balance)
+
+	return balance, nil
+}
+
+type bigMapValue struct {
+	value   []byte
+	isFound bool
+}
+
+//BigMapKey caches missed keys too
+func (r cachedRPC) BigMapKey(ctx context.Context, bigMapID int64, keyHash string) (value []byte, isFound bool, err error) {
+	key := fmt.Sprintf("%d/%s", bigMapID, keyHash)
+	if cached, ok := r.cache.Get(cacheBigMapKey, key); ok {
+		return cached.(bigMapValue).value, cached.(bigMapValue).isFound, nil
+	}
+
+	value, isFound, err = r.RPCProvider.BigMapKey(ctx, bigMapID, keyHash)
+	if err != nil {
+		return value, isFound, err
+	}
+
+	r.cache.SetHeadScoped(cacheBigMapKey, key, bigMapValue{value: value, isFound: isFound})
+
+	return value, isFound, nil
+}
+
+type cachedIndexerProvider struct {
+	IndexerProvider
+	cache *cache.Cache
+}
+
+func (p cachedIndexerProvider) GetIndexer() indexer.Repo {
+	return cachedIndexer{Repo: p.IndexerProvider.GetIndexer(), cache: p.cache}
+}
+
+//cachedIndexer caches found entities only, contract may be not indexed yet
+type cachedIndexer struct {
+	indexer.Repo
+	cache *cache.Cache
+}
+
+func (r cachedIndexer) GetContractScript(address types.Address) (script models.Script, isFound bool, err error) {
+	if value, ok := r.cache.Get(cacheScript, address.String()); ok {
+		return value.(models.Script), true, nil
+	}
+
+	script, isFound, err = r.Repo.GetContractScript(address)
+	if err != nil || !isFound {
+		return script, isFound, err
+	}
+
+	r.cache.Set(cacheScript, address.String(), script)
+
+	return script, true, nil
+}
+
+func (r cachedIndexer) GetContractStorage(address types.Address) (storage models.Storage, isFound bool, err error) {
+	if value, ok := r.cache.Get(cacheIndexerStorage, address.String()); ok {
+		return value.(models.Storage), true, nil
+	}
+
+	storage, isFound, err = r.Repo.GetContractStorage(address)
+	if err != nil || !isFound {
+		return storage, isFound, err
+	}
+
+	r.cache.SetHeadScoped(cacheIndexerStorage, address.String(), storage)
+
+	return storage, true, nil
+}
+
+func (r cachedIndexer) GetAccount(address types.Address) (account models.Account, isFound bool, err error) {
+	if value, ok := r.cache.Get(cacheAccount, address.String()); ok {
+		return value.(models.Account), true, nil
+	}
+
+	account, isFound, err = r.Repo.GetAccount(address)
+	if err != nil || !isFound {
+		return account, isFound, err
+	}
+
+	r.cache.SetHeadScoped(cacheAccount, address.String(), account)
+
+	return account, true, nil
+}
+
+//CacheStats returns network cache hit/miss counters by bucket
+func (s *ServiceFacade) CacheStats() map[string]cache.Stats {
+	return s.cache.Stats()
+}
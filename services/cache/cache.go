@@ -0,0 +1,238 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	defaultHeadTTL    = 30 * time.Second
+	defaultBucketSize = 10000
+)
+
+//Cache keeps network values in named buckets.
+//Immutable entries live until evicted as least recently used, head scoped entries live until new head or TTL, nil cache is always empty
+type Cache struct {
+	mu         sync.Mutex
+	buckets    map[string]*bucket
+	headTTL    time.Duration
+	bucketSize int
+	head       int64
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+type bucket struct {
+	entries map[string]*list.Element
+	//Most recently used entries first
+	order  *list.List
+	hits   uint64
+	misses uint64
+}
+
+type entry struct {
+	key   string
+	value interface{}
+	//Zero for immutable value
+	expiresAt   time.Time
+	isHeadScope bool
+}
+
+type Stats struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+	Size   int    `json:"size"`
+}
+
+//New creates cache with bucketSize entries limit per bucket
+func New(headTTL time.Duration, bucketSize int) *Cache {
+	if headTTL <= 0 {
+		headTTL = defaultHeadTTL
+	}
+
+	if bucketSize <= 0 {
+		bucketSize = defaultBucketSize
+	}
+
+	return &Cache{
+		buckets:    map[string]*bucket{},
+		headTTL:    headTTL,
+		bucketSize: bucketSize,
+		done:       make(chan struct{}),
+	}
+}
+
+func (c *Cache) Get(bucketName, key string) (value interface{}, isFound bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b := c.bucket(bucketName)
+
+	elem, isFound := b.entries[key]
+	if isFound {
+		e := elem.Value.(*entry)
+		if !e.expiresAt.IsZero() && time.Now().After(e.expiresAt) {
+			b.remove(elem)
+			isFound = false
+		}
+	}
+
+	if !isFound {
+		b.misses++
+		return nil, false
+	}
+
+	b.hits++
+	b.order.MoveToFront(elem)
+
+	return elem.Value.(*entry).value, true
+}
+
+//Set stores immutable value
+func (c *Cache) Set(bucketName, key string, value interface{}) {
+	c.set(bucketName, key, entry{key: key, value: value})
+}
+
+//SetHeadScoped stores value valid until new head
+func (c *Cache) SetHeadScoped(bucketName, key string, value interface{}) {
+	if c == nil {
+		return
+	}
+
+	c.set(bucketName, key, entry{key: key, value: value, expiresAt: time.Now().Add(c.headTTL), isHeadScope: true})
+}
+
+//SetUntil stores value with own expiration
+func (c *Cache) SetUntil(bucketName, key string, value interface{}, expiresAt time.Time) {
+	c.set(bucketName, key, entry{key: key, value: value, expiresAt: expiresAt})
+}
+
+func (c *Cache) set(bucketName, key string, e entry) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b := c.bucket(bucketName)
+
+	if elem, ok := b.entries[key]; ok {
+		elem.Value = &e
+		b.order.MoveToFront(elem)
+		return
+	}
+
+	b.entries[key] = b.order.PushFront(&e)
+
+	//Evict least recently used entries
+	for b.order.Len() > c.bucketSize {
+		b.remove(b.order.Back())
+	}
+}
+
+//bucket must be called under lock
+func (c *Cache) bucket(name string) *bucket {
+	b, ok := c.buckets[name]
+	if !ok {
+		b = &bucket{entries: map[string]*list.Element{}, order: list.New()}
+		c.buckets[name] = b
+	}
+
+	return b
+}
+
+func (b *bucket) remove(elem *list.Element) {
+	b.order.Remove(elem)
+	delete(b.entries, elem.Value.(*entry).key)
+}
+
+//OnHead drops head scoped and expired entries when head level grows
+func (c *Cache) OnHead(level int64) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if level <= c.head {
+		return
+	}
+	c.head = level
+
+	now := time.Now()
+	for _, b := range c.buckets {
+		for elem := b.order.Front(); elem != nil; {
+			next := elem.Next()
+			e := elem.Value.(*entry)
+			if e.isHeadScope || (!e.expiresAt.IsZero() && now.After(e.expiresAt)) {
+				b.remove(elem)
+			}
+			elem = next
+		}
+	}
+}
+
+//WatchHead polls head level until Close
+func (c *Cache) WatchHead(period time.Duration, headLevel func(ctx context.Context) (int64, error)) {
+	go func() {
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), period)
+			level, err := headLevel(ctx)
+			cancel()
+
+			//Unknown head entries are expired by TTL
+			if err == nil {
+				c.OnHead(level)
+			}
+
+			select {
+			case <-c.done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (c *Cache) Close() {
+	if c == nil {
+		return
+	}
+
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+}
+
+//Stats returns hit/miss counters by bucket
+func (c *Cache) Stats() map[string]Stats {
+	stats := map[string]Stats{}
+	if c == nil {
+		return stats
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for name, b := range c.buckets {
+		stats[name] = Stats{
+			Hits:   b.hits,
+			Misses: b.misses,
+			Size:   len(b.entries),
+		}
+	}
+
+	return stats
+}
@@ -0,0 +1,151 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	c := New(time.Hour, 0)
+
+	c.Set("chain_id", "", "NetXdQprcVkpaWU")
+	c.SetHeadScoped("balance", "tz1", int64(100))
+	c.SetUntil("metadata", "expired", "token", time.Now().Add(-time.Second))
+	c.SetUntil("metadata", "fresh", "token", time.Now().Add(time.Hour))
+
+	testCases := []struct {
+		bucket  string
+		key     string
+		isFound bool
+	}{
+		{bucket: "chain_id", key: "", isFound: true},
+		{bucket: "balance", key: "tz1", isFound: true},
+		{bucket: "balance", key: "tz2"},
+		{bucket: "metadata", key: "expired"},
+		{bucket: "metadata", key: "fresh", isFound: true},
+		{bucket: "unknown", key: ""},
+	}
+
+	for _, test := range testCases {
+		if _, isFound := c.Get(test.bucket, test.key); isFound != test.isFound {
+			t.Errorf("%s/%s: isFound %t", test.bucket, test.key, isFound)
+		}
+	}
+
+	stats := c.Stats()
+	if stats["balance"] != (Stats{Hits: 1, Misses: 1, Size: 1}) || stats["unknown"].Misses != 1 {
+		t.Errorf("stats: %+v", stats)
+	}
+
+	//New head drops head scoped and expired entries
+	c.OnHead(10)
+
+	if _, isFound := c.Get("balance", "tz1"); isFound {
+		t.Error("head scoped value is kept on new head")
+	}
+
+	if _, isFound := c.Get("chain_id", ""); !isFound {
+		t.Error("immutable value is dropped on new head")
+	}
+
+	if stats = c.Stats(); stats["metadata"].Size != 1 {
+		t.Errorf("expired entry is kept: %+v", stats["metadata"])
+	}
+
+	//Same head keeps values
+	c.SetHeadScoped("balance", "tz1", int64(100))
+	c.OnHead(10)
+
+	if _, isFound := c.Get("balance", "tz1"); !isFound {
+		t.Error("head scoped value is dropped on same head")
+	}
+}
+
+func TestCacheHeadTTL(t *testing.T) {
+	c := New(time.Millisecond, 0)
+	c.SetHeadScoped("balance", "tz1", int64(100))
+
+	time.Sleep(5 * time.Millisecond)
+
+	if _, isFound := c.Get("balance", "tz1"); isFound {
+		t.Error("expired head scoped value is returned")
+	}
+}
+
+func TestCacheWatchHead(t *testing.T) {
+	c := New(time.Hour, 0)
+	defer c.Close()
+
+	c.SetHeadScoped("balance", "tz1", int64(100))
+
+	levels := make(chan int64, 1)
+	c.WatchHead(time.Millisecond, func(ctx context.Context) (int64, error) {
+		select {
+		case level := <-levels:
+			return level, nil
+		default:
+			return 0, errors.New("node is down")
+		}
+	})
+
+	levels <- 1
+
+	for i := 0; i < 100; i++ {
+		if _, isFound := c.Get("balance", "tz1"); !isFound {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	t.Error("head scoped value is kept after head change")
+}
+
+func TestNilCache(t *testing.T) {
+	var c *Cache
+
+	c.Set("chain_id", "", "NetXdQprcVkpaWU")
+	c.SetHeadScoped("balance", "tz1", int64(100))
+	c.OnHead(1)
+	c.Close()
+
+	if _, isFound := c.Get("chain_id", ""); isFound || len(c.Stats()) != 0 {
+		t.Error("nil cache is not empty")
+	}
+}
+
+func TestCacheBucketSize(t *testing.T) {
+	c := New(time.Hour, 2)
+
+	c.Set("metadata", "KT1a", "a")
+	c.Set("metadata", "KT1b", "b")
+	//Used entry moves ahead of eviction
+	c.Get("metadata", "KT1a")
+	c.Set("metadata", "KT1c", "c")
+	//Other buckets have own limit
+	c.Set("chain_id", "", "NetXdQprcVkpaWU")
+
+	testCases := []struct {
+		bucket  string
+		key     string
+		isFound bool
+	}{
+		{bucket: "metadata", key: "KT1a", isFound: true},
+		{bucket: "metadata", key: "KT1b"},
+		{bucket: "metadata", key: "KT1c", isFound: true},
+		{bucket: "chain_id", key: "", isFound: true},
+	}
+
+	for _, test := range testCases {
+		if _, isFound := c.Get(test.bucket, test.key); isFound != test.isFound {
+			t.Errorf("%s/%s: isFound %t", test.bucket, test.key, isFound)
+		}
+	}
+
+	//Overwrite keeps size
+	c.Set("metadata", "KT1a", "a2")
+	if value, _ := c.Get("metadata", "KT1a"); value != "a2" || c.Stats()["metadata"].Size != 2 {
+		t.Errorf("overwrite: %v %+v", value, c.Stats()["metadata"])
+	}
+}
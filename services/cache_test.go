@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"tezosign/models"
+	"tezosign/repos/indexer"
+	"tezosign/services/cache"
+	"tezosign/types"
+	"time"
+)
+
+//countingRPC counts node requests, other RPCProvider methods are not used
+type countingRPC struct {
+	RPCProvider
+	calls int
+	err   error
+}
+
+func (r *countingRPC) ChainID(ctx context.Context) (string, error) {
+	r.calls++
+	return "NetXdQprcVkpaWU", r.err
+}
+
+func (r *countingRPC) Balance(ctx context.Context, address string) (int64, error) {
+	r.calls++
+	return 100, r.err
+}
+
+func (r *countingRPC) BigMapKey(ctx context.Context, bigMapID int64, keyHash string) ([]byte, bool, error) {
+	r.calls++
+	return nil, false, r.err
+}
+
+type countingIndexer struct {
+	indexer.Repo
+	calls   int
+	isFound bool
+}
+
+func (r *countingIndexer) GetIndexer() indexer.Repo {
+	return r
+}
+
+func (r *countingIndexer) GetContractScript(address types.Address) (models.Script, bool, error) {
+	r.calls++
+	return models.Script{Current: true}, r.isFound, nil
+}
+
+func (r *countingIndexer) GetAccount(address types.Address) (models.Account, bool, error) {
+	r.calls++
+	return models.Account{Balance: 100}, r.isFound, nil
+}
+
+func Test_CachedRPC(t *testing.T) {
+	rpc := &countingRPC{}
+	c := cache.New(time.Hour, 0)
+	s := New(context.Background(), nil, nil, rpc, nil, models.NetworkMain).WithCache(c)
+
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		s.rpcClient.ChainID(ctx)
+		s.rpcClient.Balance(ctx, "tz1VSUr8wwNhLAzempoch5d6hLRiTh8Cjcjb")
+		s.rpcClient.BigMapKey(ctx, 1, "expru")
+	}
+
+	if rpc.calls != 3 {
+		t.Errorf("node calls: %d", rpc.calls)
+	}
+
+	//Balance and big map keys are refetched on new head
+	c.OnHead(1)
+	s.rpcClient.ChainID(ctx)
+	s.rpcClient.Balance(ctx, "tz1VSUr8wwNhLAzempoch5d6hLRiTh8Cjcjb")
+	s.rpcClient.BigMapKey(ctx, 1, "expru")
+
+	if rpc.calls != 5 {
+		t.Errorf("node calls after new head: %d", rpc.calls)
+	}
+
+	//Errors are not cached
+	failing := &countingRPC{err: errors.New("node is down")}
+	s = New(context.Background(), nil, nil, failing, nil, models.NetworkMain).WithCache(cache.New(time.Hour, 0))
+	s.rpcClient.Balance(ctx, "tz1VSUr8wwNhLAzempoch5d6hLRiTh8Cjcjb")
+	s.rpcClient.Balance(ctx, "tz1VSUr8wwNhLAzempoch5d6hLRiTh8Cjcjb")
+
+	if failing.calls != 2 {
+		t.Errorf("failed node calls: %d", failing.calls)
+	}
+
+	stats := c.Stats()
+	if stats[cacheChainID] != (cache.Stats{Hits: 3, Misses: 1, Size: 1}) || stats[cacheBalance].Misses != 2 {
+		t.Errorf("stats: %+v", stats)
+	}
+}
+
+func Test_CachedIndexer(t *testing.T) {
+	c := cache.New(time.Hour, 0)
+	const address types.Address = "KT1LAuGLiaCF9A72qZtFvVhyzzNFg86fwFnV"
+
+	//Not indexed contract is requested again
+	repo := &countingIndexer{}
+	s := New(context.Background(), nil, repo, nil, nil, models.NetworkMain).WithCache(c)
+	s.indexerRepoProvider.GetIndexer().GetContractScript(address)
+	s.indexerRepoProvider.GetIndexer().GetContractScript(address)
+
+	if repo.calls != 2 {
+		t.Errorf("missed script calls: %d", repo.calls)
+	}
+
+	repo.isFound = true
+	for i := 0; i < 3; i++ {
+		s.indexerRepoProvider.GetIndexer().GetContractScript(address)
+		s.indexerRepoProvider.GetIndexer().GetAccount(address)
+	}
+
+	if repo.calls != 4 {
+		t.Errorf("indexer calls: %d", repo.calls)
+	}
+
+	//Script is immutable, account is head scoped
+	c.OnHead(1)
+	s.indexerRepoProvider.GetIndexer().GetContractScript(address)
+	account, isFound, _ := s.indexerRepoProvider.GetIndexer().GetAccount(address)
+
+	if repo.calls != 5 || !isFound || account.Balance != 100 {
+		t.Errorf("indexer calls after new head: %d", repo.calls)
+	}
+}
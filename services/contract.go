@@ -1,7 +1,6 @@
 package services
 
 import (
-	"context"
 	"crypto"
 	"crypto/ecdsa"
 	"crypto/ed25519"
@@ -9,6 +8,7 @@ import (
 	"fmt"
 	"math/big"
 	"tezosign/common/apperrors"
+	"tezosign/common/log"
 	"tezosign/models"
 	"tezosign/services/contract"
 	"tezosign/types"
@@ -20,6 +20,7 @@ import (
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/pkg/errors"
 	"github.com/tendermint/tendermint/crypto/secp256k1"
+	"go.uber.org/zap"
 	"golang.org/x/crypto/blake2b"
 )
 
@@ -139,16 +140,26 @@ func (s *ServiceFacade) ContractInfo(contractID types.Address) (resp models.Cont
 		return resp, apperrors.New(apperrors.ErrNotFound, "contract")
 	}
 
+	delegation, err := s.getDelegationInfo(acc)
+	if err != nil {
+		return resp, err
+	}
+
 	return models.ContractInfo{
-		Address:   contractID,
-		Balance:   acc.Balance,
-		Threshold: storage.Threshold(),
-		Counter:   storage.Counter(),
-		Owners:    owners,
+		Address:    contractID,
+		Balance:    acc.Balance,
+		Threshold:  storage.Threshold(),
+		Counter:    storage.Counter(),
+		Owners:     owners,
+		Delegation: delegation,
 	}, nil
 }
 
 func (s *ServiceFacade) ContractOperation(userPubKey types.PubKey, req models.ContractOperationRequest) (resp models.Request, err error) {
+	return s.contractOperation(userPubKey, req, false)
+}
+
+func (s *ServiceFacade) contractOperation(userPubKey types.PubKey, req models.ContractOperationRequest, isSystem bool) (resp models.Request, err error) {
 	isOwner, err := s.GetUserAllowance(userPubKey, req.ContractID)
 	if err != nil {
 		return resp, err
@@ -164,11 +175,18 @@ func (s *ServiceFacade) ContractOperation(userPubKey types.PubKey, req models.Co
 		return resp, err
 	}
 
-	chainID, err := s.rpcClient.ChainID(context.Background())
+	chainID, err := s.rpcClient.ChainID(s.ctx)
 	if err != nil {
 		return resp, err
 	}
 
+	if req.Type == models.ContractCall {
+		req.CustomPayload, err = s.contractCallPayload(req)
+		if err != nil {
+			return resp, err
+		}
+	}
+
 	//Specific checks
 	err = s.checkOperation(req)
 	if err != nil {
@@ -194,6 +212,14 @@ func (s *ServiceFacade) ContractOperation(userPubKey types.PubKey, req models.Co
 		counter = pendingNonce.Int64 + 1
 	}
 
+	//Pending operations change storage before this one is executed
+	if s.cfg.Payloads.Simulation && counter == storage.Counter() {
+		err = s.simulateContractOperation(chainID, counter, req)
+		if err != nil {
+			return resp, err
+		}
+	}
+
 	//TODO change format
 	operationID := operationID(fmt.Sprintf("nonce%dnetwork%scontract%spayload%x", counter, chainID, req.ContractID, req))
 
@@ -211,6 +237,13 @@ func (s *ServiceFacade) ContractOperation(userPubKey types.PubKey, req models.Co
 		NetworkID:  chainID,
 		Status:     models.StatusPending,
 		CreatedAt:  types.JSONTimestamp(time.Now()),
+		IsSystem:   isSystem,
+	}
+
+	//Signers should know what custom lambda does
+	if req.Type == models.CustomPayload || req.Type == models.ContractCall {
+		report := contract.AnalyseCustomPayload(req.CustomPayload)
+		request.LambdaReport = &report
 	}
 
 	//Create new
@@ -227,7 +260,7 @@ func (s *ServiceFacade) ContractOperation(userPubKey types.PubKey, req models.Co
 func (s *ServiceFacade) checkOperation(req models.ContractOperationRequest) (err error) {
 	switch req.Type {
 	//Check account balance
-	case models.Transfer:
+	case models.Transfer, models.ContractCall:
 		acc, isFound, err := s.indexerRepoProvider.GetIndexer().GetAccount(req.ContractID)
 		if err != nil {
 			return err
@@ -258,7 +291,7 @@ func (s *ServiceFacade) checkOperation(req models.ContractOperationRequest) (err
 		}
 
 		//TODO check FA balance
-	case models.VestingSetDelegate, models.VestingVest:
+	case models.VestingSetDelegate, models.VestingVest, models.VestingRevoke:
 		//Сheck contract for vesting type
 		vestingStorage, err := s.getVestingContractStorage(req.VestingID)
 		if err != nil {
@@ -273,6 +306,10 @@ func (s *ServiceFacade) checkOperation(req models.ContractOperationRequest) (err
 			return apperrors.New(apperrors.ErrNotAllowed, "not enough ticks")
 		}
 
+		if req.Type == models.VestingRevoke && (vestingStorage.Template != models.VestingTemplateRevocable || vestingStorage.RevocationAdmin != req.ContractID) {
+			return apperrors.New(apperrors.ErrNotAllowed, "msig is not revocation admin")
+		}
+
 	}
 
 	return nil
@@ -320,13 +357,28 @@ func (s *ServiceFacade) BuildContractOperationToSign(userPubKey types.PubKey, tx
 		return resp, errors.New("Empty operation counter")
 	}
 
+	script, isFound, err := s.indexerRepoProvider.GetIndexer().GetContractScript(contractModel.Address)
+	if err != nil {
+		return resp, err
+	}
+
+	if !isFound {
+		return resp, apperrors.New(apperrors.ErrNotFound, "contract")
+	}
+
+	//Actions layout is taken from deployed contract
+	actions, err := contract.NewMultisigActions(script.ParameterSchema.MichelinePrim())
+	if err != nil {
+		return resp, err
+	}
+
 	counter := *operationReq.Counter
 	var signPayload types.Payload
 	var payloadJson string
 	if payloadType == models.TypeReject {
-		signPayload, payloadJson, err = contract.BuildRejectSignPayload(operationReq.NetworkID, counter, contractModel.Address)
+		signPayload, payloadJson, err = contract.BuildRejectSignPayload(operationReq.NetworkID, counter, actions, contractModel.Address)
 	} else {
-		signPayload, payloadJson, err = contract.BuildContractSignPayload(operationReq.NetworkID, counter, operationReq.Info)
+		signPayload, payloadJson, err = contract.BuildContractSignPayload(operationReq.NetworkID, counter, actions, operationReq.Info)
 	}
 	if err != nil {
 		return resp, err
@@ -391,10 +443,34 @@ func (s *ServiceFacade) BuildContractOperation(userPubKey types.PubKey, txID str
 		return resp, err
 	}
 
-	return models.OperationParameter{
+	resp = models.OperationParameter{
 		Entrypoint: entrypoint,
 		Value:      string(rawTx),
-	}, nil
+	}
+
+	estimate, balance, err := s.estimateContractOperation(userPubKey, contr.Address, resp)
+	if err != nil {
+		if isEstimationSkipped(err) {
+			log.Ctx(s.ctx).Warn("operation estimation skipped", zap.String("contract", contr.Address.String()), zap.Error(err))
+			return resp, nil
+		}
+
+		//Script failure or limits exceeded, operation will not be applied
+		var failure models.OperationFailure
+		if errors.As(err, &failure) {
+			return resp, apperrors.New(apperrors.ErrBadParam, failure.Error())
+		}
+
+		return resp, err
+	}
+
+	if balance < estimate.Fee+estimate.Burn {
+		return resp, apperrors.New(apperrors.ErrNotAllowed, "not enough balance for fee")
+	}
+
+	resp.Estimate = &estimate
+
+	return resp, nil
 }
 
 func (s *ServiceFacade) CheckContractOrigination(txID string) (contract types.Address, err error) {
@@ -615,6 +691,44 @@ func (s *ServiceFacade) getContractScriptAndStorage(contractID types.Address) (s
 	return script, storage, nil
 }
 
+//Execute contract code with operation parameter, signatures are not verified
+func (s *ServiceFacade) simulateContractOperation(chainID string, counter int64, req models.ContractOperationRequest) error {
+	script, storage, err := s.getContractScriptAndStorage(req.ContractID)
+	if err != nil {
+		return err
+	}
+
+	env := contract.ScriptEnv{Now: time.Now().Unix()}
+
+	acc, isFound, err := s.indexerRepoProvider.GetIndexer().GetAccount(req.ContractID)
+	if err != nil {
+		return err
+	}
+
+	if isFound {
+		env.Balance = acc.Balance
+	}
+
+	_, err = contract.SimulateContractOperation(micheline.Script{
+		Code: &micheline.Code{
+			Param:   script.ParameterSchema.MichelinePrim(),
+			Storage: script.StorageSchema.MichelinePrim(),
+			Code:    script.CodeSchema.MichelinePrim(),
+		},
+		Storage: storage.RawValue.MichelinePrim(),
+	}, chainID, counter, req, env)
+	if err != nil {
+		if failure, ok := err.(contract.ScriptFailure); ok {
+			return apperrors.New(apperrors.ErrBadParam, failure.Error())
+		}
+
+		//Interpreter covers instructions subset only
+		log.Ctx(s.ctx).Warn("operation simulation skipped", zap.String("contract", req.ContractID.String()), zap.Error(err))
+	}
+
+	return nil
+}
+
 func operationID(payload string) string {
 	return fmt.Sprintf("%x", md5.Sum([]byte(payload)))
 }
@@ -1,47 +1,133 @@
 package contract
 
 import (
+	"errors"
 	"tezosign/models"
 	"tezosign/types"
 
 	"blockwatch.cc/tzindex/micheline"
 )
 
-func AssetOperation(prim *micheline.Prim, assetType models.AssetType) (transfers []models.TransferUnit) {
+var errWrongTransferParams = errors.New("wrong transfer params")
+
+var (
+	//pair (address :from) (pair (address :to) (nat :value))
+	fa12TransferType = typeOf(micheline.T_PAIR, typeOf(micheline.T_ADDRESS), typeOf(micheline.T_PAIR, typeOf(micheline.T_ADDRESS), typeOf(micheline.T_NAT)))
+	//list (pair %from_ address (list %txs (pair address (pair nat nat))))
+	fa2TransferType = typeOf(micheline.T_LIST, typeOf(micheline.T_PAIR, typeOf(micheline.T_ADDRESS),
+		typeOf(micheline.T_LIST, typeOf(micheline.T_PAIR, typeOf(micheline.T_ADDRESS), typeOf(micheline.T_PAIR, typeOf(micheline.T_NAT), typeOf(micheline.T_NAT))))))
+)
+
+func typeOf(opCode micheline.OpCode, args ...*micheline.Prim) *micheline.Prim {
+	return &micheline.Prim{Type: types.PrimTypeFromArgs(len(args), false), OpCode: opCode, Args: args}
+}
+
+//AssetOperation parses FA1.2 or FA2 transfer params, n-ary pairs and comb literals are accepted
+func AssetOperation(prim *micheline.Prim, assetType models.AssetType) (transfers []models.TransferUnit, err error) {
+	if prim == nil {
+		return nil, errWrongTransferParams
+	}
+
+	transferType := fa12TransferType
+	if assetType == models.TypeFA2 {
+		transferType = fa2TransferType
+	}
+
+	prim, err = NormalizeCombValue(transferType, prim)
+	if err != nil {
+		return nil, errWrongTransferParams
+	}
 
-	//TODO add validations
 	if assetType == models.TypeFA2 {
+		//list (pair %from_ address (list %txs (pair address (pair nat nat))))
 		for i := range prim.Args {
-			//List
+			if !isPrimPair(prim.Args[i]) {
+				return nil, errWrongTransferParams
+			}
+
+			from, err := primAddress(prim.Args[i].Args[0])
+			if err != nil {
+				return nil, err
+			}
+
 			txs := make([]models.Tx, len(prim.Args[i].Args[1].Args))
 			for j, arg := range prim.Args[i].Args[1].Args {
+				if !isPrimPair(arg) || !isPrimPair(arg.Args[1]) || !isPrimInt(arg.Args[1].Args[0]) || !isPrimInt(arg.Args[1].Args[1]) {
+					return nil, errWrongTransferParams
+				}
+
+				to, err := primAddress(arg.Args[0])
+				if err != nil {
+					return nil, err
+				}
+
 				txs[j] = models.Tx{
-					To:      types.Address(arg.Args[0].String),
+					To:      to,
 					TokenID: arg.Args[1].Args[0].Int.Uint64(),
 					Amount:  arg.Args[1].Args[1].Int.Uint64(),
 				}
 			}
-			//prim.Args[i].Args[1]
+
 			transfers = append(transfers, models.TransferUnit{
-				From: types.Address(prim.Args[0].String),
-				Txs:  nil,
+				From: from,
+				Txs:  txs,
 			})
 		}
 
-		return transfers
+		return transfers, nil
+	}
+
+	//pair (address :from) (pair (address :to) (nat :value))
+	if !isPrimPair(prim) || !isPrimPair(prim.Args[1]) || !isPrimInt(prim.Args[1].Args[1]) {
+		return nil, errWrongTransferParams
+	}
+
+	from, err := primAddress(prim.Args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := primAddress(prim.Args[1].Args[0])
+	if err != nil {
+		return nil, err
 	}
 
 	transfers = []models.TransferUnit{
 		{
-			From: types.Address(prim.Args[0].String),
+			From: from,
 			Txs: []models.Tx{
 				{
-					To:     types.Address(prim.Args[1].Args[0].String),
+					To:     to,
 					Amount: prim.Args[1].Args[1].Int.Uint64(),
 				},
 			},
 		},
 	}
 
-	return transfers
+	return transfers, nil
+}
+
+func isPrimPair(p *micheline.Prim) bool {
+	return p != nil && len(p.Args) == 2
+}
+
+func isPrimInt(p *micheline.Prim) bool {
+	return p != nil && p.Type == micheline.PrimInt && p.Int != nil
+}
+
+//Address from optimized or readable value
+func primAddress(p *micheline.Prim) (address types.Address, err error) {
+	switch p.Type {
+	case micheline.PrimBytes:
+		err = address.UnmarshalBinary(p.Bytes)
+		if err != nil {
+			return address, err
+		}
+	case micheline.PrimString:
+		address = types.Address(p.String)
+	default:
+		return address, errors.New("wrong address value")
+	}
+
+	return address, nil
 }
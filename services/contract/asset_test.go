@@ -0,0 +1,94 @@
+package contract
+
+import (
+	"math/big"
+	"testing"
+	"tezosign/models"
+	"tezosign/types"
+
+	"blockwatch.cc/tzindex/micheline"
+)
+
+func Test_AssetOperation(t *testing.T) {
+	from := types.Address("tz1boE6s8tS3pcxetHuaAPZWzHicMa39jSfj")
+	to := types.Address("KT1K9gCRgaLRFKTErYt1wVxA3Frb9FjasjTV")
+
+	toBytes, err := to.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	str := func(v types.Address) *micheline.Prim {
+		return &micheline.Prim{Type: micheline.PrimString, OpCode: micheline.T_STRING, String: v.String()}
+	}
+	nat := func(v int64) *micheline.Prim {
+		return &micheline.Prim{Type: micheline.PrimInt, OpCode: micheline.T_INT, Int: big.NewInt(v)}
+	}
+	pair := func(l, r *micheline.Prim) *micheline.Prim {
+		return &micheline.Prim{Type: micheline.PrimBinary, OpCode: micheline.D_PAIR, Args: []*micheline.Prim{l, r}}
+	}
+
+	testCases := []struct {
+		name      string
+		assetType models.AssetType
+		prim      *micheline.Prim
+		expTx     models.Tx
+		wantErr   bool
+	}{
+		{
+			name:      "FA1.2",
+			assetType: models.TypeFA12,
+			prim:      pair(str(from), pair(str(to), nat(10))),
+			expTx:     models.Tx{To: to, Amount: 10},
+		},
+		{
+			name:      "FA2 optimized address",
+			assetType: models.TypeFA2,
+			prim: &micheline.Prim{Type: micheline.PrimSequence, Args: []*micheline.Prim{
+				pair(str(from), &micheline.Prim{Type: micheline.PrimSequence, Args: []*micheline.Prim{
+					pair(&micheline.Prim{Type: micheline.PrimBytes, OpCode: micheline.T_BYTES, Bytes: toBytes}, pair(nat(3), nat(7))),
+				}}),
+			}},
+			expTx: models.Tx{To: to, TokenID: 3, Amount: 7},
+		},
+		{
+			name:      "FA2 n-ary pairs",
+			assetType: models.TypeFA2,
+			prim: &micheline.Prim{Type: micheline.PrimSequence, Args: []*micheline.Prim{
+				pair(str(from), &micheline.Prim{Type: micheline.PrimSequence, Args: []*micheline.Prim{
+					{Type: micheline.PrimVariadicAnno, OpCode: micheline.D_PAIR, Args: []*micheline.Prim{str(to), nat(3), nat(7)}},
+				}}),
+			}},
+			expTx: models.Tx{To: to, TokenID: 3, Amount: 7},
+		},
+		{
+			name:      "FA1.2 comb literal",
+			assetType: models.TypeFA12,
+			prim:      &micheline.Prim{Type: micheline.PrimSequence, Args: []*micheline.Prim{str(from), str(to), nat(10)}},
+			expTx:     models.Tx{To: to, Amount: 10},
+		},
+		{
+			name:      "Not transfer params",
+			assetType: models.TypeFA12,
+			prim:      pair(str(from), nat(1)),
+			wantErr:   true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			transfers, err := AssetOperation(test.prim, test.assetType)
+			if test.wantErr != (err != nil) {
+				t.Fatalf("wantErr: %t | err: %v", test.wantErr, err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if len(transfers) != 1 || transfers[0].From != from || len(transfers[0].Txs) != 1 || transfers[0].Txs[0] != test.expTx {
+				t.Errorf("transfers: %+v", transfers)
+			}
+		})
+	}
+}
@@ -0,0 +1,522 @@
+package contract
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"tezosign/types"
+	"time"
+
+	"blockwatch.cc/tzindex/micheline"
+)
+
+const defaultEntrypoint = "default"
+
+//ContractEntrypoints returns parameter types by entrypoint annotation, default is root type if not annotated
+func ContractEntrypoints(paramSchema *micheline.Prim) (entrypoints map[string]*micheline.Prim, err error) {
+	if paramSchema == nil {
+		return nil, errors.New("empty parameter schema")
+	}
+
+	root := paramSchema
+	if root.OpCode == micheline.K_PARAMETER {
+		if len(root.Args) == 0 {
+			return nil, errors.New("wrong parameter schema")
+		}
+		root = root.Args[0]
+	}
+
+	entrypoints = map[string]*micheline.Prim{}
+
+	resolver, err := NewEntrypointResolver(root)
+	if err != nil {
+		return nil, err
+	}
+
+	//Entrypoints are field annotated branches of root or
+	e, err := resolver.Entrypoints()
+	if err != nil {
+		return nil, err
+	}
+
+	for name, entrypoint := range e {
+		entrypoints[name] = entrypoint.Prim
+	}
+
+	if _, ok := entrypoints[defaultEntrypoint]; !ok {
+		entrypoints[defaultEntrypoint] = root
+	}
+
+	return entrypoints, nil
+}
+
+//ContractCallLambda converts JSON parameter by entrypoint type and builds lambda calling target contract
+//{DROP; NIL operation; PUSH address; CONTRACT %entrypoint type; IF_NONE {PUSH string; FAILWITH} {}; PUSH mutez; PUSH type value; TRANSFER_TOKENS; CONS}
+func ContractCallLambda(paramSchema *micheline.Prim, target types.Address, entrypoint string, amount uint64, parameter json.RawMessage) (lambda *micheline.Prim, err error) {
+	entrypoints, err := ContractEntrypoints(paramSchema)
+	if err != nil {
+		return lambda, err
+	}
+
+	paramType, ok := entrypoints[entrypoint]
+	if !ok {
+		return lambda, fmt.Errorf("entrypoint %s not found", entrypoint)
+	}
+
+	value, err := ConvertParameter(paramType, parameter)
+	if err != nil {
+		return lambda, err
+	}
+
+	encodedTarget, err := target.MarshalBinary()
+	if err != nil {
+		return lambda, err
+	}
+
+	contractInstr := &micheline.Prim{Type: micheline.PrimUnary, OpCode: micheline.I_CONTRACT, Args: []*micheline.Prim{stripAnnots(paramType)}}
+	if entrypoint != defaultEntrypoint {
+		contractInstr.Type = micheline.PrimUnaryAnno
+		contractInstr.Anno = []string{"%" + entrypoint}
+	}
+
+	lambda = &micheline.Prim{
+		Type: micheline.PrimSequence,
+		Args: []*micheline.Prim{
+			{Type: micheline.PrimNullary, OpCode: micheline.I_DROP},
+			{Type: micheline.PrimUnary, OpCode: micheline.I_NIL, Args: []*micheline.Prim{
+				{Type: micheline.PrimNullary, OpCode: micheline.T_OPERATION},
+			}},
+			{Type: micheline.PrimBinary, OpCode: micheline.I_PUSH, Args: []*micheline.Prim{
+				{Type: micheline.PrimNullary, OpCode: micheline.T_ADDRESS},
+				{Type: micheline.PrimBytes, Bytes: encodedTarget},
+			}},
+			contractInstr,
+			{Type: micheline.PrimBinary, OpCode: micheline.I_IF_NONE, Args: []*micheline.Prim{
+				{Type: micheline.PrimSequence, Args: []*micheline.Prim{
+					{Type: micheline.PrimBinary, OpCode: micheline.I_PUSH, Args: []*micheline.Prim{
+						{Type: micheline.PrimNullary, OpCode: micheline.T_STRING},
+						{Type: micheline.PrimString, String: "bad entrypoint"},
+					}},
+					{Type: micheline.PrimNullary, OpCode: micheline.I_FAILWITH},
+				}},
+				{Type: micheline.PrimSequence, Args: []*micheline.Prim{}},
+			}},
+			{Type: micheline.PrimBinary, OpCode: micheline.I_PUSH, Args: []*micheline.Prim{
+				{Type: micheline.PrimNullary, OpCode: micheline.T_MUTEZ},
+				{Type: micheline.PrimInt, Int: new(big.Int).SetUint64(amount)},
+			}},
+			{Type: micheline.PrimBinary, OpCode: micheline.I_PUSH, Args: []*micheline.Prim{stripAnnots(paramType), value}},
+			{Type: micheline.PrimNullary, OpCode: micheline.I_TRANSFER_TOKENS},
+			{Type: micheline.PrimNullary, OpCode: micheline.I_CONS},
+		},
+	}
+
+	return lambda, nil
+}
+
+//Types without literal values
+func isPushable(t *micheline.Prim) bool {
+	switch t.OpCode {
+	case micheline.T_CONTRACT, micheline.T_BIG_MAP, micheline.T_OPERATION, micheline.T_TICKET,
+		micheline.T_SAPLING_STATE, micheline.T_SAPLING_TRANSACTION, micheline.T_NEVER:
+		return false
+	case micheline.T_LAMBDA:
+		return true
+	}
+
+	for i := range t.Args {
+		if !isPushable(t.Args[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+//Type copy for PUSH and CONTRACT instructions
+func stripAnnots(t *micheline.Prim) *micheline.Prim {
+	clone := t.Clone()
+
+	var strip func(p *micheline.Prim)
+	strip = func(p *micheline.Prim) {
+		if len(p.Anno) > 0 {
+			p.Anno = nil
+			switch p.Type {
+			case micheline.PrimNullaryAnno, micheline.PrimUnaryAnno, micheline.PrimBinaryAnno:
+				p.Type--
+			}
+		}
+
+		for i := range p.Args {
+			strip(p.Args[i])
+		}
+	}
+	strip(clone)
+
+	return clone
+}
+
+//ConvertParameter type checks JSON value described by EntrypointSchema and converts it to Micheline with n-ary combs
+func ConvertParameter(t *micheline.Prim, value json.RawMessage) (prim *micheline.Prim, err error) {
+	if !isPushable(t) {
+		return nil, fmt.Errorf("%s parameter is not supported", t.OpCode)
+	}
+
+	if len(value) == 0 {
+		value = json.RawMessage("null")
+	}
+
+	prim, err = convertValue(t, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return FoldComb(prim), nil
+}
+
+func convertValue(t *micheline.Prim, value json.RawMessage) (prim *micheline.Prim, err error) {
+	switch t.OpCode {
+	case micheline.T_UNIT:
+		if string(value) != "null" {
+			return nil, errors.New("unit value should be null")
+		}
+
+		return &micheline.Prim{Type: micheline.PrimNullary, OpCode: micheline.D_UNIT}, nil
+	case micheline.T_BOOL:
+		var b bool
+		if err = json.Unmarshal(value, &b); err != nil {
+			return nil, errors.New("wrong bool value")
+		}
+
+		opCode := micheline.D_FALSE
+		if b {
+			opCode = micheline.D_TRUE
+		}
+
+		return &micheline.Prim{Type: micheline.PrimNullary, OpCode: opCode}, nil
+	case micheline.T_INT, micheline.T_NAT, micheline.T_MUTEZ:
+		i, err := parseInt(value)
+		if err != nil {
+			return nil, err
+		}
+
+		if t.OpCode != micheline.T_INT && i.Sign() < 0 {
+			return nil, fmt.Errorf("negative %s value", t.OpCode)
+		}
+
+		return &micheline.Prim{Type: micheline.PrimInt, Int: i}, nil
+	case micheline.T_TIMESTAMP:
+		var s string
+		if err = json.Unmarshal(value, &s); err == nil {
+			if ts, err := time.Parse(time.RFC3339, s); err == nil {
+				return &micheline.Prim{Type: micheline.PrimInt, Int: big.NewInt(ts.Unix())}, nil
+			}
+		}
+
+		//Unix seconds
+		i, err := parseInt(value)
+		if err != nil {
+			return nil, errors.New("wrong timestamp value")
+		}
+
+		return &micheline.Prim{Type: micheline.PrimInt, Int: i}, nil
+	case micheline.T_BYTES:
+		var s string
+		if err = json.Unmarshal(value, &s); err != nil {
+			return nil, errors.New("wrong bytes value")
+		}
+
+		bt, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+		if err != nil {
+			return nil, errors.New("wrong bytes value")
+		}
+
+		return &micheline.Prim{Type: micheline.PrimBytes, Bytes: bt}, nil
+	case micheline.T_STRING, micheline.T_ADDRESS, micheline.T_KEY_HASH, micheline.T_KEY, micheline.T_SIGNATURE, micheline.T_CHAIN_ID:
+		var s string
+		if err = json.Unmarshal(value, &s); err != nil {
+			return nil, fmt.Errorf("wrong %s value", t.OpCode)
+		}
+
+		switch t.OpCode {
+		case micheline.T_ADDRESS, micheline.T_KEY_HASH:
+			err = types.Address(s).Validate()
+		case micheline.T_KEY:
+			err = types.PubKey(s).Validate()
+		case micheline.T_SIGNATURE, micheline.T_CHAIN_ID:
+			if s == "" {
+				err = fmt.Errorf("empty %s value", t.OpCode)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return &micheline.Prim{Type: micheline.PrimString, String: s}, nil
+	case micheline.T_OPTION:
+		if string(value) == "null" {
+			return &micheline.Prim{Type: micheline.PrimNullary, OpCode: micheline.D_NONE}, nil
+		}
+
+		arg, err := convertValue(t.Args[0], value)
+		if err != nil {
+			return nil, err
+		}
+
+		return &micheline.Prim{Type: micheline.PrimUnary, OpCode: micheline.D_SOME, Args: []*micheline.Prim{arg}}, nil
+	case micheline.T_LIST, micheline.T_SET:
+		var items []json.RawMessage
+		if err = json.Unmarshal(value, &items); err != nil {
+			return nil, fmt.Errorf("wrong %s value", t.OpCode)
+		}
+
+		prim = &micheline.Prim{Type: micheline.PrimSequence, Args: make([]*micheline.Prim, len(items))}
+		for i := range items {
+			prim.Args[i], err = convertValue(t.Args[0], items[i])
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if t.OpCode == micheline.T_SET {
+			err = sortByKey(t.Args[0], prim.Args, func(elt *micheline.Prim) *micheline.Prim { return elt })
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return prim, nil
+	case micheline.T_MAP:
+		var items []struct {
+			Key   json.RawMessage `json:"key"`
+			Value json.RawMessage `json:"value"`
+		}
+		if err = json.Unmarshal(value, &items); err != nil {
+			return nil, errors.New("wrong map value")
+		}
+
+		prim = &micheline.Prim{Type: micheline.PrimSequence, Args: make([]*micheline.Prim, len(items))}
+		for i := range items {
+			key, err := convertValue(t.Args[0], items[i].Key)
+			if err != nil {
+				return nil, err
+			}
+
+			val, err := convertValue(t.Args[1], items[i].Value)
+			if err != nil {
+				return nil, err
+			}
+
+			prim.Args[i] = &micheline.Prim{Type: micheline.PrimBinary, OpCode: micheline.D_ELT, Args: []*micheline.Prim{key, val}}
+		}
+
+		err = sortByKey(t.Args[0], prim.Args, func(elt *micheline.Prim) *micheline.Prim { return elt.Args[0] })
+		if err != nil {
+			return nil, err
+		}
+
+		return prim, nil
+	case micheline.T_LAMBDA:
+		prim = &micheline.Prim{}
+		if err = prim.UnmarshalJSON(value); err != nil {
+			return nil, errors.New("wrong lambda value")
+		}
+
+		return prim, nil
+	case micheline.T_PAIR:
+		return convertPair(t, value)
+	case micheline.T_OR:
+		return convertOr(t, value)
+	default:
+		return nil, fmt.Errorf("%s parameter is not supported", t.OpCode)
+	}
+}
+
+//Set and map literals require strictly ascending keys, compared in binary form like beneficiariesPrim
+func sortByKey(keyType *micheline.Prim, elts []*micheline.Prim, key func(elt *micheline.Prim) *micheline.Prim) (err error) {
+	keys := make(map[*micheline.Prim]*micheline.Prim, len(elts))
+	for _, elt := range elts {
+		keys[elt], err = normalizeValue(keyType, key(elt))
+		if err != nil {
+			return err
+		}
+	}
+
+	sort.SliceStable(elts, func(i, j int) bool {
+		cmp, cmpErr := compareValues(keys[elts[i]], keys[elts[j]])
+		if cmpErr != nil && err == nil {
+			err = cmpErr
+		}
+		return cmp < 0
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := 1; i < len(elts); i++ {
+		cmp, err := compareValues(keys[elts[i-1]], keys[elts[i]])
+		if err != nil {
+			return err
+		}
+
+		if cmp == 0 {
+			return errors.New("duplicate key")
+		}
+	}
+
+	return nil
+}
+
+//Pair value is array of leaves or object by leaves annotations
+func convertPair(t *micheline.Prim, value json.RawMessage) (prim *micheline.Prim, err error) {
+	leaves := pairLeaves(t, true)
+	values := make([]json.RawMessage, len(leaves))
+
+	names, isNamed := leavesNames(leaves)
+
+	var object map[string]json.RawMessage
+	if err = json.Unmarshal(value, &values); err == nil {
+		if len(values) != len(leaves) {
+			return nil, fmt.Errorf("pair requires %d values", len(leaves))
+		}
+	} else if err = json.Unmarshal(value, &object); err == nil && isNamed {
+		for i := range names {
+			v, ok := object[names[i]]
+			if !ok {
+				return nil, fmt.Errorf("missing %s value", names[i])
+			}
+			values[i] = v
+		}
+	} else {
+		return nil, errors.New("wrong pair value")
+	}
+
+	index := 0
+	return buildPairValue(t, values, &index, true)
+}
+
+func buildPairValue(t *micheline.Prim, values []json.RawMessage, index *int, isTop bool) (prim *micheline.Prim, err error) {
+	if t.OpCode != micheline.T_PAIR || (!isTop && t.HasVarAnno()) {
+		prim, err = convertValue(t, values[*index])
+		*index++
+		return prim, err
+	}
+
+	prim = &micheline.Prim{Type: micheline.PrimBinary, OpCode: micheline.D_PAIR, Args: make([]*micheline.Prim, len(t.Args))}
+	if len(t.Args) > 2 {
+		prim.Type = micheline.PrimVariadicAnno
+	}
+
+	for i := range t.Args {
+		prim.Args[i], err = buildPairValue(t.Args[i], values, index, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return prim, nil
+}
+
+//Or value is object with single branch key
+func convertOr(t *micheline.Prim, value json.RawMessage) (prim *micheline.Prim, err error) {
+	var object map[string]json.RawMessage
+	if err = json.Unmarshal(value, &object); err != nil || len(object) != 1 {
+		return nil, errors.New("or value requires single branch")
+	}
+
+	for _, branch := range orBranches(t, true, "") {
+		v, ok := object[branch.name]
+		if !ok {
+			continue
+		}
+
+		prim, err = convertValue(branch.prim, v)
+		if err != nil {
+			return nil, err
+		}
+
+		//Wrap by Left/Right from leaf to root
+		for i := len(branch.path) - 1; i >= 0; i-- {
+			prim = &micheline.Prim{Type: micheline.PrimUnary, OpCode: branch.path[i], Args: []*micheline.Prim{prim}}
+		}
+
+		return prim, nil
+	}
+
+	return nil, errors.New("unknown or branch")
+}
+
+//Nested pairs without field annotations are flattened, top pair is always expanded
+func pairLeaves(t *micheline.Prim, isTop bool) (leaves []*micheline.Prim) {
+	if t.OpCode != micheline.T_PAIR || (!isTop && t.HasVarAnno()) {
+		return []*micheline.Prim{t}
+	}
+
+	for i := range t.Args {
+		leaves = append(leaves, pairLeaves(t.Args[i], false)...)
+	}
+
+	return leaves
+}
+
+func leavesNames(leaves []*micheline.Prim) (names []string, isNamed bool) {
+	names = make([]string, len(leaves))
+	unique := make(map[string]bool, len(leaves))
+	for i := range leaves {
+		names[i] = leaves[i].GetFieldAnnoAny()
+		if names[i] == "" || unique[names[i]] {
+			return names, false
+		}
+		unique[names[i]] = true
+	}
+
+	return names, true
+}
+
+type orBranch struct {
+	//Annotation or Left/Right path letters
+	name string
+	path []micheline.OpCode
+	prim *micheline.Prim
+}
+
+//Nested ors without field annotations are flattened, top or is always expanded
+func orBranches(t *micheline.Prim, isTop bool, pathName string) (branches []orBranch) {
+	if t.OpCode != micheline.T_OR || (!isTop && t.HasVarAnno()) {
+		name := t.GetVarAnno()
+		if name == "" {
+			name = pathName
+		}
+
+		return []orBranch{{name: name, prim: t}}
+	}
+
+	for i, opCode := range []micheline.OpCode{micheline.D_LEFT, micheline.D_RIGHT} {
+		for _, branch := range orBranches(t.Args[i], false, pathName+opCode.String()[:1]) {
+			branch.path = append([]micheline.OpCode{opCode}, branch.path...)
+			branches = append(branches, branch)
+		}
+	}
+
+	return branches
+}
+
+func parseInt(value json.RawMessage) (*big.Int, error) {
+	text := string(value)
+
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		text = s
+	}
+
+	i, ok := new(big.Int).SetString(text, 10)
+	if !ok {
+		return nil, errors.New("wrong int value")
+	}
+
+	return i, nil
+}
@@ -0,0 +1,155 @@
+package contract
+
+import (
+	"sort"
+	"tezosign/models"
+
+	"blockwatch.cc/tzindex/micheline"
+)
+
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+//ContractEntrypointsSchema describes entrypoints parameters accepted by ConvertParameter
+func ContractEntrypointsSchema(paramSchema *micheline.Prim) (resp []models.ContractEntrypoint, err error) {
+	entrypoints, err := ContractEntrypoints(paramSchema)
+	if err != nil {
+		return resp, err
+	}
+
+	resp = make([]models.ContractEntrypoint, 0, len(entrypoints))
+	for name, t := range entrypoints {
+		paramType, err := stripAnnots(t).MarshalJSON()
+		if err != nil {
+			return resp, err
+		}
+
+		schema := EntrypointSchema(t)
+		schema["$schema"] = jsonSchemaDraft
+
+		resp = append(resp, models.ContractEntrypoint{
+			Name:       name,
+			IsCallable: isPushable(t),
+			Type:       paramType,
+			Schema:     schema,
+		})
+	}
+
+	sort.Slice(resp, func(i, j int) bool {
+		return resp[i].Name < resp[j].Name
+	})
+
+	return resp, nil
+}
+
+//EntrypointSchema builds JSON schema of entrypoint parameter, format keeps michelson type
+func EntrypointSchema(t *micheline.Prim) map[string]interface{} {
+	return typeSchema(t, true)
+}
+
+func typeSchema(t *micheline.Prim, isRoot bool) (schema map[string]interface{}) {
+	schema = map[string]interface{}{
+		"format": t.OpCode.String(),
+	}
+
+	if name := t.GetFieldAnnoAny(); name != "" && !isRoot {
+		schema["title"] = name
+	}
+
+	switch t.OpCode {
+	case micheline.T_UNIT:
+		schema["type"] = "null"
+	case micheline.T_BOOL:
+		schema["type"] = "boolean"
+	case micheline.T_INT:
+		schema["type"] = []string{"integer", "string"}
+		schema["pattern"] = "^-?[0-9]+$"
+	case micheline.T_NAT, micheline.T_MUTEZ:
+		schema["type"] = []string{"integer", "string"}
+		schema["pattern"] = "^[0-9]+$"
+		schema["minimum"] = 0
+	case micheline.T_TIMESTAMP:
+		schema["type"] = []string{"integer", "string"}
+		schema["description"] = "RFC3339 or unix seconds"
+	case micheline.T_BYTES:
+		schema["type"] = "string"
+		schema["pattern"] = "^(0x)?([0-9a-fA-F]{2})*$"
+	case micheline.T_STRING, micheline.T_ADDRESS, micheline.T_KEY_HASH, micheline.T_KEY, micheline.T_SIGNATURE, micheline.T_CHAIN_ID:
+		schema["type"] = "string"
+	case micheline.T_OPTION:
+		schema["oneOf"] = []interface{}{
+			map[string]interface{}{"type": "null"},
+			typeSchema(t.Args[0], false),
+		}
+	case micheline.T_LIST, micheline.T_SET:
+		schema["type"] = "array"
+		schema["items"] = typeSchema(t.Args[0], false)
+		if t.OpCode == micheline.T_SET {
+			schema["uniqueItems"] = true
+		}
+	case micheline.T_MAP, micheline.T_BIG_MAP:
+		schema["type"] = "array"
+		schema["items"] = map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"key":   typeSchema(t.Args[0], false),
+				"value": typeSchema(t.Args[1], false),
+			},
+			"required": []string{"key", "value"},
+		}
+	case micheline.T_LAMBDA:
+		schema["type"] = []string{"object", "array"}
+		schema["description"] = "Micheline JSON code"
+	case micheline.T_PAIR:
+		leaves := pairLeaves(t, true)
+		items := make([]interface{}, len(leaves))
+		for i := range leaves {
+			items[i] = typeSchema(leaves[i], false)
+		}
+
+		arraySchema := map[string]interface{}{
+			"type":     "array",
+			"items":    items,
+			"minItems": len(leaves),
+			"maxItems": len(leaves),
+		}
+
+		names, isNamed := leavesNames(leaves)
+		if !isNamed {
+			for k, v := range arraySchema {
+				schema[k] = v
+			}
+			break
+		}
+
+		properties := make(map[string]interface{}, len(leaves))
+		for i := range leaves {
+			properties[names[i]] = items[i]
+		}
+
+		schema["oneOf"] = []interface{}{
+			map[string]interface{}{
+				"type":                 "object",
+				"properties":           properties,
+				"required":             names,
+				"additionalProperties": false,
+			},
+			arraySchema,
+		}
+	case micheline.T_OR:
+		branches := orBranches(t, true, "")
+		oneOf := make([]interface{}, len(branches))
+		for i := range branches {
+			oneOf[i] = map[string]interface{}{
+				"type":                 "object",
+				"properties":           map[string]interface{}{branches[i].name: typeSchema(branches[i].prim, false)},
+				"required":             []string{branches[i].name},
+				"additionalProperties": false,
+			}
+		}
+		schema["oneOf"] = oneOf
+	default:
+		schema["description"] = "not supported"
+	}
+
+	return schema
+}
@@ -0,0 +1,213 @@
+package contract
+
+import (
+	"encoding/json"
+	"testing"
+
+	"blockwatch.cc/tzindex/micheline"
+)
+
+const testParamSchema = `{"prim":"parameter","args":[{"prim":"or","args":[{"prim":"pair","args":[{"prim":"address","annots":[":from"]},{"prim":"pair","args":[{"prim":"address","annots":[":to"]},{"prim":"nat","annots":[":value"]}]}],"annots":["%transfer"]},{"prim":"or","args":[{"prim":"nat","annots":["%mint"]},{"prim":"unit","annots":["%pause"]}]}]}]}`
+
+func testPrim(t *testing.T, data string) *micheline.Prim {
+	prim := &micheline.Prim{}
+	if err := prim.UnmarshalJSON([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+
+	return prim
+}
+
+func Test_ConvertParameter(t *testing.T) {
+	paramSchema := testPrim(t, testParamSchema)
+	entrypoints, err := ContractEntrypoints(paramSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entrypoints) != 4 {
+		t.Fatalf("entrypoints: %v", entrypoints)
+	}
+
+	testCases := []struct {
+		name      string
+		paramType *micheline.Prim
+		value     string
+		expResult string
+		wantErr   bool
+	}{
+		{
+			name:      "Pair object",
+			paramType: entrypoints["transfer"],
+			value:     `{"from":"tz1NkT6YCFS3mDo6kfaMFKFrRiA7w2o5dkWp","to":"KT1NtGnEjacAkBph7k9HWVrN38PoYjcXTxdY","value":"10"}`,
+			expResult: `{"args":[{"string":"tz1NkT6YCFS3mDo6kfaMFKFrRiA7w2o5dkWp"},{"string":"KT1NtGnEjacAkBph7k9HWVrN38PoYjcXTxdY"},{"int":"10"}],"prim":"Pair"}`,
+		},
+		{
+			name:      "Pair array",
+			paramType: entrypoints["transfer"],
+			value:     `["tz1NkT6YCFS3mDo6kfaMFKFrRiA7w2o5dkWp","KT1NtGnEjacAkBph7k9HWVrN38PoYjcXTxdY",10]`,
+			expResult: `{"args":[{"string":"tz1NkT6YCFS3mDo6kfaMFKFrRiA7w2o5dkWp"},{"string":"KT1NtGnEjacAkBph7k9HWVrN38PoYjcXTxdY"},{"int":"10"}],"prim":"Pair"}`,
+		},
+		{
+			name:      "Pair missing field",
+			paramType: entrypoints["transfer"],
+			value:     `{"from":"tz1NkT6YCFS3mDo6kfaMFKFrRiA7w2o5dkWp","value":"10"}`,
+			wantErr:   true,
+		},
+		{
+			name:      "Wrong address",
+			paramType: entrypoints["transfer"],
+			value:     `["tz1","KT1NtGnEjacAkBph7k9HWVrN38PoYjcXTxdY",10]`,
+			wantErr:   true,
+		},
+		{
+			name:      "Negative nat",
+			paramType: entrypoints["mint"],
+			value:     `-5`,
+			wantErr:   true,
+		},
+		{
+			name:      "Unit",
+			paramType: entrypoints["pause"],
+			expResult: `{"prim":"Unit"}`,
+		},
+		{
+			name:      "Or branch",
+			paramType: entrypoints["default"],
+			value:     `{"mint":5}`,
+			expResult: `{"args":[{"args":[{"int":"5"}],"prim":"Left"}],"prim":"Right"}`,
+		},
+		{
+			name:      "Unknown or branch",
+			paramType: entrypoints["default"],
+			value:     `{"burn":5}`,
+			wantErr:   true,
+		},
+		{
+			name:      "Option map",
+			paramType: testPrim(t, `{"prim":"option","args":[{"prim":"map","args":[{"prim":"string"},{"prim":"bytes"}]}]}`),
+			value:     `[{"key":"a","value":"0x0aff"}]`,
+			expResult: `{"args":[[{"args":[{"string":"a"},{"bytes":"0aff"}],"prim":"Elt"}]],"prim":"Some"}`,
+		},
+		{
+			name:      "Unsorted map",
+			paramType: testPrim(t, `{"prim":"map","args":[{"prim":"address"},{"prim":"nat"}]}`),
+			value:     `[{"key":"tz1NkT6YCFS3mDo6kfaMFKFrRiA7w2o5dkWp","value":1},{"key":"KT1NtGnEjacAkBph7k9HWVrN38PoYjcXTxdY","value":2}]`,
+			expResult: `[{"args":[{"string":"tz1NkT6YCFS3mDo6kfaMFKFrRiA7w2o5dkWp"},{"int":"1"}],"prim":"Elt"},{"args":[{"string":"KT1NtGnEjacAkBph7k9HWVrN38PoYjcXTxdY"},{"int":"2"}],"prim":"Elt"}]`,
+		},
+		{
+			name:      "Unsorted set",
+			paramType: testPrim(t, `{"prim":"set","args":[{"prim":"address"}]}`),
+			value:     `["KT1NtGnEjacAkBph7k9HWVrN38PoYjcXTxdY","tz1NkT6YCFS3mDo6kfaMFKFrRiA7w2o5dkWp"]`,
+			expResult: `[{"string":"tz1NkT6YCFS3mDo6kfaMFKFrRiA7w2o5dkWp"},{"string":"KT1NtGnEjacAkBph7k9HWVrN38PoYjcXTxdY"}]`,
+		},
+		{
+			name:      "Unsorted nat set",
+			paramType: testPrim(t, `{"prim":"set","args":[{"prim":"nat"}]}`),
+			value:     `[10,2,7]`,
+			expResult: `[{"int":"2"},{"int":"7"},{"int":"10"}]`,
+		},
+		{
+			name:      "Duplicate map key",
+			paramType: testPrim(t, `{"prim":"map","args":[{"prim":"string"},{"prim":"nat"}]}`),
+			value:     `[{"key":"a","value":1},{"key":"a","value":2}]`,
+			wantErr:   true,
+		},
+		{
+			name:      "Duplicate set element",
+			paramType: testPrim(t, `{"prim":"set","args":[{"prim":"nat"}]}`),
+			value:     `[1,1]`,
+			wantErr:   true,
+		},
+		{
+			name:      "None",
+			paramType: testPrim(t, `{"prim":"option","args":[{"prim":"nat"}]}`),
+			value:     `null`,
+			expResult: `{"prim":"None"}`,
+		},
+		{
+			name:      "List of timestamps",
+			paramType: testPrim(t, `{"prim":"list","args":[{"prim":"timestamp"}]}`),
+			value:     `["2021-01-01T00:00:00Z",1609459200]`,
+			expResult: `[{"int":"1609459200"},{"int":"1609459200"}]`,
+		},
+		{
+			name:      "Not pushable",
+			paramType: testPrim(t, `{"prim":"contract","args":[{"prim":"unit"}]}`),
+			value:     `"KT1NtGnEjacAkBph7k9HWVrN38PoYjcXTxdY"`,
+			wantErr:   true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			prim, err := ConvertParameter(test.paramType, json.RawMessage(test.value))
+			if test.wantErr != (err != nil) {
+				t.Fatalf("wantErr: %t | err: %v", test.wantErr, err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			result, err := prim.MarshalJSON()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(result) != test.expResult {
+				t.Errorf("result: %s", result)
+			}
+		})
+	}
+}
+
+func Test_ContractCallLambda(t *testing.T) {
+	paramSchema := testPrim(t, testParamSchema)
+
+	lambda, err := ContractCallLambda(paramSchema, "KT1NtGnEjacAkBph7k9HWVrN38PoYjcXTxdY", "mint", 100, json.RawMessage(`"5"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := lambda.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expResult := `[{"prim":"DROP"},{"args":[{"prim":"operation"}],"prim":"NIL"},{"args":[{"prim":"address"},{"bytes":"019ce13845659ff2582555ec08dc322007f6493e8000"}],"prim":"PUSH"},{"annots":["%mint"],"args":[{"prim":"nat"}],"prim":"CONTRACT"},{"args":[[{"args":[{"prim":"string"},{"string":"bad entrypoint"}],"prim":"PUSH"},{"prim":"FAILWITH"}],[]],"prim":"IF_NONE"},{"args":[{"prim":"mutez"},{"int":"100"}],"prim":"PUSH"},{"args":[{"prim":"nat"},{"int":"5"}],"prim":"PUSH"},{"prim":"TRANSFER_TOKENS"},{"prim":"CONS"}]`
+	if string(result) != expResult {
+		t.Errorf("result: %s", result)
+	}
+
+	_, err = ContractCallLambda(paramSchema, "KT1NtGnEjacAkBph7k9HWVrN38PoYjcXTxdY", "burn", 0, nil)
+	if err == nil {
+		t.Error("unknown entrypoint")
+	}
+}
+
+func Test_ContractEntrypointsSchema(t *testing.T) {
+	entrypoints, err := ContractEntrypointsSchema(testPrim(t, testParamSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := make([]string, len(entrypoints))
+	for i := range entrypoints {
+		names[i] = entrypoints[i].Name
+	}
+
+	if len(names) != 4 || names[0] != "default" || names[1] != "mint" || names[2] != "pause" || names[3] != "transfer" {
+		t.Fatalf("entrypoints: %v", names)
+	}
+
+	schema, err := json.Marshal(entrypoints[3].Schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expSchema := `{"$schema":"http://json-schema.org/draft-07/schema#","format":"pair","oneOf":[{"additionalProperties":false,"properties":{"from":{"format":"address","title":"from","type":"string"},"to":{"format":"address","title":"to","type":"string"},"value":{"format":"nat","minimum":0,"pattern":"^[0-9]+$","title":"value","type":["integer","string"]}},"required":["from","to","value"],"type":"object"},{"items":[{"format":"address","title":"from","type":"string"},{"format":"address","title":"to","type":"string"},{"format":"nat","minimum":0,"pattern":"^[0-9]+$","title":"value","type":["integer","string"]}],"maxItems":3,"minItems":3,"type":"array"}]}`
+	if string(schema) != expSchema {
+		t.Errorf("schema: %s", schema)
+	}
+}
@@ -0,0 +1,163 @@
+package contract
+
+import (
+	"errors"
+	"tezosign/types"
+
+	"blockwatch.cc/tzindex/micheline"
+)
+
+//NormalizeComb converts n-ary pair types and values to right combs of binary pairs
+func NormalizeComb(p *micheline.Prim) *micheline.Prim {
+	if p == nil {
+		return nil
+	}
+
+	normalized := *p
+	if len(p.Args) == 0 {
+		return &normalized
+	}
+
+	args := p.Args
+	if isCombPair(p) {
+		//Inner pairs of comb are not annotated
+		if len(args) > 2 {
+			args = []*micheline.Prim{args[0], {Type: types.PrimTypeFromArgs(len(args)-1, false), OpCode: p.OpCode, Args: args[1:]}}
+		}
+		normalized.Type = types.PrimTypeFromArgs(len(args), len(p.Anno) > 0)
+	}
+
+	normalized.Args = make([]*micheline.Prim, len(args))
+	for i := range args {
+		normalized.Args[i] = NormalizeComb(args[i])
+	}
+
+	return &normalized
+}
+
+//NormalizeCombValue converts value to right combs of binary pairs by its type, including comb literals {a; b; c}
+func NormalizeCombValue(t, v *micheline.Prim) (*micheline.Prim, error) {
+	if t == nil || v == nil {
+		return nil, errors.New("empty value")
+	}
+
+	t = NormalizeComb(t)
+
+	switch t.OpCode {
+	case micheline.T_PAIR:
+		if v.Type == micheline.PrimSequence {
+			v = &micheline.Prim{Type: micheline.PrimVariadicAnno, OpCode: micheline.D_PAIR, Args: v.Args}
+		}
+
+		left, right, err := combSplit(v)
+		if err != nil || len(t.Args) != 2 {
+			return nil, errors.New("pair value expected")
+		}
+
+		left, err = NormalizeCombValue(t.Args[0], left)
+		if err != nil {
+			return nil, err
+		}
+
+		right, err = NormalizeCombValue(t.Args[1], right)
+		if err != nil {
+			return nil, err
+		}
+
+		return pairPrim(left, right), nil
+	case micheline.T_OPTION, micheline.T_OR:
+		if (v.OpCode != micheline.D_SOME && v.OpCode != micheline.D_LEFT && v.OpCode != micheline.D_RIGHT) || len(v.Args) != 1 {
+			return v, nil
+		}
+
+		argType := t.Args[0]
+		if v.OpCode == micheline.D_RIGHT && len(t.Args) == 2 {
+			argType = t.Args[1]
+		}
+
+		arg, err := NormalizeCombValue(argType, v.Args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		return valuePrim(v.OpCode, arg), nil
+	case micheline.T_LIST, micheline.T_SET, micheline.T_MAP, micheline.T_BIG_MAP:
+		//Big map value is id
+		if v.Type != micheline.PrimSequence {
+			return v, nil
+		}
+
+		elems := make([]*micheline.Prim, len(v.Args))
+		for i, elem := range v.Args {
+			var err error
+			if t.OpCode == micheline.T_LIST || t.OpCode == micheline.T_SET {
+				elems[i], err = NormalizeCombValue(t.Args[0], elem)
+			} else {
+				elems[i], err = normalizeCombElt(t, elem)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return &micheline.Prim{Type: micheline.PrimSequence, Args: elems}, nil
+	}
+
+	return v, nil
+}
+
+func normalizeCombElt(t, elt *micheline.Prim) (*micheline.Prim, error) {
+	if elt.OpCode != micheline.D_ELT || len(elt.Args) != 2 || len(t.Args) != 2 {
+		return nil, errors.New("map element expected")
+	}
+
+	key, err := NormalizeCombValue(t.Args[0], elt.Args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := NormalizeCombValue(t.Args[1], elt.Args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return &micheline.Prim{Type: micheline.PrimBinary, OpCode: micheline.D_ELT, Args: []*micheline.Prim{key, value}}, nil
+}
+
+//FoldComb converts right combs of binary pairs back to n-ary pairs, annotated inner pairs are kept
+func FoldComb(p *micheline.Prim) *micheline.Prim {
+	if p == nil {
+		return nil
+	}
+
+	folded := *p
+	if len(p.Args) == 0 {
+		return &folded
+	}
+
+	folded.Args = make([]*micheline.Prim, len(p.Args))
+	for i := range p.Args {
+		folded.Args[i] = FoldComb(p.Args[i])
+	}
+
+	if !isCombPair(p) || len(folded.Args) != 2 {
+		return &folded
+	}
+
+	last := folded.Args[1]
+	if last.OpCode == p.OpCode && len(last.Anno) == 0 && len(last.Args) >= 2 {
+		folded.Args = append(folded.Args[:1], last.Args...)
+		folded.Type = types.PrimTypeFromArgs(len(folded.Args), len(p.Anno) > 0)
+	}
+
+	return &folded
+}
+
+func isCombPair(p *micheline.Prim) bool {
+	switch p.Type {
+	case micheline.PrimBinary, micheline.PrimBinaryAnno, micheline.PrimVariadicAnno:
+		return p.OpCode == micheline.T_PAIR || p.OpCode == micheline.D_PAIR
+	}
+
+	return false
+}
@@ -0,0 +1,174 @@
+package contract
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"tezosign/models"
+	"tezosign/services/converter"
+	"tezosign/types"
+
+	"blockwatch.cc/tzindex/micheline"
+)
+
+func parseMichelson(t *testing.T, src string) *micheline.Prim {
+	t.Helper()
+
+	p, err := converter.ParseMichelson(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return p
+}
+
+func Test_NormalizeComb(t *testing.T) {
+	testCases := []struct {
+		name   string
+		src    string
+		binary string
+		folded string
+	}{
+		{name: "Type", src: "pair (nat %a) (string %b) (bytes %c)", binary: "pair (nat %a) (pair (string %b) (bytes %c))"},
+		{name: "Value", src: "Pair 1 2 3 4", binary: "Pair 1 (Pair 2 (Pair 3 4))"},
+		{name: "Annotated", src: "pair %p nat nat nat", binary: "pair %p nat (pair nat nat)"},
+		{name: "Nested", src: "list (pair nat (pair nat nat nat) nat)", binary: "list (pair nat (pair (pair nat (pair nat nat)) nat))"},
+		{name: "Binary", src: "pair nat (pair %inner nat nat)", binary: "pair nat (pair %inner nat nat)"},
+		{name: "Sequence", src: "{ 1 ; 2 ; 3 }", binary: "{ 1 ; 2 ; 3 }"},
+		{name: "Not comb", src: "Pair (Pair 1 2) 3", binary: "Pair (Pair 1 2) 3"},
+		{name: "Binary comb", src: "Pair 1 (Pair 2 3)", binary: "Pair 1 (Pair 2 3)", folded: "Pair 1 2 3"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			src := parseMichelson(t, test.src)
+
+			normalized := NormalizeComb(src)
+			if got := converter.PrintMichelson(normalized); got != test.binary {
+				t.Errorf("normalized: %s, want %s", got, test.binary)
+			}
+
+			//Source is not changed
+			if got := converter.PrintMichelson(src); got != test.src {
+				t.Errorf("source changed: %s", got)
+			}
+
+			folded := test.folded
+			if folded == "" {
+				folded = test.src
+			}
+
+			if got := converter.PrintMichelson(FoldComb(normalized)); got != folded {
+				t.Errorf("folded: %s, want %s", got, folded)
+			}
+		})
+	}
+}
+
+func Test_NormalizeCombValue(t *testing.T) {
+	testCases := []struct {
+		name    string
+		typ     string
+		value   string
+		binary  string
+		wantErr bool
+	}{
+		{name: "Comb literal", typ: "pair nat nat nat", value: "{ 1 ; 2 ; 3 }", binary: "Pair 1 (Pair 2 3)"},
+		{name: "N-ary pair", typ: "pair nat (pair nat nat)", value: "Pair 1 2 3", binary: "Pair 1 (Pair 2 3)"},
+		{name: "List", typ: "list (pair nat string)", value: `{ { 1 ; "a" } ; Pair 2 "b" }`, binary: `{ Pair 1 "a" ; Pair 2 "b" }`},
+		{name: "Map", typ: "map nat (pair nat nat nat)", value: "{ Elt 1 { 1 ; 2 ; 3 } }", binary: "{ Elt 1 (Pair 1 (Pair 2 3)) }"},
+		{name: "Big map id", typ: "big_map nat (pair nat nat nat)", value: "7", binary: "7"},
+		{name: "Option", typ: "option (pair nat nat nat)", value: "Some { 1 ; 2 ; 3 }", binary: "Some (Pair 1 (Pair 2 3))"},
+		{name: "Or", typ: "or nat (pair nat nat nat)", value: "Right (Pair 1 2 3)", binary: "Right (Pair 1 (Pair 2 3))"},
+		{name: "Short literal", typ: "pair nat nat nat", value: "{ 1 ; 2 }", wantErr: true},
+		{name: "Not pair", typ: "pair nat nat", value: "1", wantErr: true},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := NormalizeCombValue(parseMichelson(t, test.typ), parseMichelson(t, test.value))
+			if test.wantErr != (err != nil) {
+				t.Fatalf("wantErr: %t | err: %v", test.wantErr, err)
+			}
+
+			if !test.wantErr && converter.PrintMichelson(got) != test.binary {
+				t.Errorf("got %s, want %s", converter.PrintMichelson(got), test.binary)
+			}
+		})
+	}
+}
+
+func Test_CombStorageContainers(t *testing.T) {
+	const key = "0x005ffdd5422addf020a689a1660e1e8c5a0247ed5bfd7ea4f4194b1a2d9f8129cb"
+
+	storageType := parseMichelson(t, "storage (pair (nat %counter) (nat %threshold) (list %keys key))")
+
+	for _, storage := range []string{
+		fmt.Sprintf("Pair 5 2 { %s }", key),
+		fmt.Sprintf("{ 5 ; 2 ; { %s } }", key),
+		fmt.Sprintf("Pair 5 (Pair 2 { %s })", key),
+	} {
+		t.Run(storage, func(t *testing.T) {
+			c, err := NewContractStorageContainer(micheline.Script{Code: &micheline.Code{Storage: storageType}, Storage: parseMichelson(t, storage)})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if c.Counter() != 5 || c.Threshold() != 2 || len(c.PubKeys()) != 1 || c.PubKeys()[0] != "edpkuNVuqdPhCsrYqkq21qW2hYTSZWMjQQjfyogoPZ2AfqCmonziNh" {
+				t.Errorf("storage: %+v", c)
+			}
+		})
+	}
+
+	target, err := types.Address("tz1NkT6YCFS3mDo6kfaMFKFrRiA7w2o5dkWp").MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	admin, err := types.Address("KT1LAuGLiaCF9A72qZtFvVhyzzNFg86fwFnV").MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vestingType := parseMichelson(t, "pair (pair %wrapped (address %target) (address %delegateAdmin)) (nat %vested) (pair %schedule (timestamp %epoch) (nat %secondsPerTick) (nat %tokensPerTick))")
+	vestingStorage := parseMichelson(t, fmt.Sprintf("{ Pair 0x%x 0x%x ; 3 ; { 123 ; 10 ; 5 } }", target, admin))
+
+	c, err := NewVestingContractStorageContainer(micheline.Script{Code: &micheline.Code{Storage: vestingType}, Storage: vestingStorage})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.storage = nil
+	expected := VestingContractStorageContainer{
+		Template:       models.VestingTemplateTicks,
+		VestingAddress: "tz1NkT6YCFS3mDo6kfaMFKFrRiA7w2o5dkWp",
+		DelegateAdmin:  "KT1LAuGLiaCF9A72qZtFvVhyzzNFg86fwFnV",
+		VestedTicks:    3,
+		Timestamp:      123,
+		SecondsPerTick: 10,
+		TokensPerTick:  5,
+	}
+	if !reflect.DeepEqual(c, expected) {
+		t.Errorf("storage: %+v", c)
+	}
+}
+
+func Test_CombEntrypoints(t *testing.T) {
+	e, err := InitAnnotsEntrypoints(parseMichelson(t, "pair (nat %a) (nat %b) (pair %c (nat %d) (nat %e) (nat %f))"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	storage := parseMichelson(t, "Pair 1 2 3 4 5")
+
+	for name, expected := range map[string]int64{"a": 1, "b": 2, "d": 3, "e": 4, "f": 5} {
+		value, err := GetStorageValue(e[name], storage)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if value.Int == nil || value.Int.Int64() != expected {
+			t.Errorf("%s: got %+v, want %d", name, value, expected)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package contract
+
+import (
+	"fmt"
+	"math/big"
+	"tezosign/models"
+	"tezosign/types"
+
+	"blockwatch.cc/tzindex/micheline"
+	"github.com/anchorageoss/tezosprotocol/v2"
+)
+
+const (
+	//Signature is not checked by run_operation
+	runOperationSignature = "sigUHx32f9wesZ1n2BWpixXz4AQaZggEtchaQNHYGRCoWNAXx45WGW2ua3apUUUAGMLPwAU41QoaFCzVSL61VaessLg4YbbP"
+
+	transactionKind = "transaction"
+	appliedStatus   = "applied"
+
+	//Same safety margin as tezos-client
+	gasLimitReserve = 100
+
+	//Branch and signature bytes of forged operation
+	operationOverheadSize = 32 + 64
+)
+
+//BuildRunOperationRequest builds multisig call from source with hard limits of protocol
+func BuildRunOperationRequest(chainID, branch string, source, destination types.Address, counter int64, constants models.ProtocolConstants, param models.OperationParameter) models.RunOperationRequest {
+	return models.RunOperationRequest{
+		Operation: models.RunOperation{
+			Branch: branch,
+			Contents: []models.RunTransaction{{
+				Kind:         transactionKind,
+				Source:       source.String(),
+				Counter:      counter + 1,
+				GasLimit:     constants.HardGasLimitPerOperation,
+				StorageLimit: constants.HardStorageLimitPerOperation,
+				Destination:  destination.String(),
+				Parameters: &models.TransactionParameters{
+					Entrypoint: param.Entrypoint,
+					Value:      []byte(param.Value),
+				},
+			}},
+			Signature: runOperationSignature,
+		},
+		ChainID: chainID,
+	}
+}
+
+//EstimateOperation sums simulated consumption including internal operations and computes minimal baker fee for tx with estimated limits
+func EstimateOperation(tx models.RunTransaction, result models.RunOperationResult, constants models.ProtocolConstants) (estimate models.OperationEstimate, err error) {
+	if len(result.Contents) == 0 {
+		return estimate, fmt.Errorf("empty run_operation result")
+	}
+
+	var milligas, storage int64
+	for _, content := range result.Contents {
+		results := []models.OperationResult{content.Metadata.OperationResult}
+		for _, internal := range content.Metadata.InternalOperationResults {
+			results = append(results, internal.Result)
+		}
+
+		for _, res := range results {
+			if res.Status != appliedStatus {
+				return estimate, operationFailure(res)
+			}
+
+			if res.ConsumedMilligas > 0 {
+				milligas += res.ConsumedMilligas
+			} else {
+				milligas += res.ConsumedGas * 1000
+			}
+
+			storage += res.PaidStorageSizeDiff + constants.OriginationSize*int64(len(res.OriginatedContracts))
+			if res.AllocatedDestinationContract {
+				storage += constants.OriginationSize
+			}
+		}
+	}
+
+	estimate = models.OperationEstimate{
+		GasLimit:     (milligas+999)/1000 + gasLimitReserve,
+		StorageLimit: storage,
+		Burn:         storage * constants.CostPerByte,
+	}
+
+	if estimate.GasLimit > constants.HardGasLimitPerOperation {
+		return estimate, models.OperationFailure{Reason: "gas limit exceeded"}
+	}
+
+	if estimate.StorageLimit > constants.HardStorageLimitPerOperation {
+		return estimate, models.OperationFailure{Reason: "storage limit exceeded"}
+	}
+
+	tx.GasLimit = estimate.GasLimit
+	tx.StorageLimit = estimate.StorageLimit
+
+	//Fee changes forged size, repeat until fixed point
+	for {
+		tx.Fee = estimate.Fee
+
+		size, err := forgedSize(tx)
+		if err != nil {
+			return estimate, err
+		}
+
+		fee := tezosprotocol.ComputeMinimumFee(big.NewInt(estimate.GasLimit), big.NewInt(size)).Int64()
+		if fee <= estimate.Fee {
+			break
+		}
+
+		estimate.Fee = fee
+	}
+
+	return estimate, nil
+}
+
+func operationFailure(res models.OperationResult) error {
+	if len(res.Errors) == 0 {
+		return models.OperationFailure{Reason: fmt.Sprintf("operation %s", res.Status)}
+	}
+
+	//Last error is the most specific one
+	return models.OperationFailure{Reason: fmt.Sprintf("operation %s: %s", res.Status, res.Errors[len(res.Errors)-1].ID)}
+}
+
+func forgedSize(tx models.RunTransaction) (size int64, err error) {
+	transaction := &tezosprotocol.Transaction{
+		Source:       tezosprotocol.ContractID(tx.Source),
+		Fee:          big.NewInt(tx.Fee),
+		Counter:      big.NewInt(tx.Counter),
+		GasLimit:     big.NewInt(tx.GasLimit),
+		StorageLimit: big.NewInt(tx.StorageLimit),
+		Amount:       big.NewInt(tx.Amount),
+		Destination:  tezosprotocol.ContractID(tx.Destination),
+	}
+
+	if tx.Parameters != nil {
+		value := &micheline.Prim{}
+		err = value.UnmarshalJSON(tx.Parameters.Value)
+		if err != nil {
+			return size, err
+		}
+
+		bt, err := value.MarshalBinary()
+		if err != nil {
+			return size, err
+		}
+
+		entrypoint, err := tezosprotocol.NewNamedEntrypoint(tx.Parameters.Entrypoint)
+		if err != nil {
+			return size, err
+		}
+
+		raw := tezosprotocol.TransactionParametersValueRawBytes(bt)
+		transaction.Parameters = &tezosprotocol.TransactionParameters{Entrypoint: entrypoint, Value: &raw}
+	}
+
+	bt, err := transaction.MarshalBinary()
+	if err != nil {
+		return size, err
+	}
+
+	return int64(len(bt)) + operationOverheadSize, nil
+}
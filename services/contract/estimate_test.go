@@ -0,0 +1,106 @@
+package contract
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"tezosign/models"
+
+	"github.com/anchorageoss/tezosprotocol/v2"
+)
+
+const testRunOperationResult = `{"contents":[{"kind":"transaction","metadata":{
+	"operation_result":{"status":"applied","consumed_gas":"25000","consumed_milligas":"24999500","paid_storage_size_diff":"10"},
+	"internal_operation_results":[
+		{"kind":"transaction","result":{"status":"applied","consumed_gas":"1428","consumed_milligas":"1427100","allocated_destination_contract":true}},
+		{"kind":"origination","result":{"status":"applied","consumed_milligas":"2000000","originated_contracts":["KT1LAuGLiaCF9A72qZtFvVhyzzNFg86fwFnV"],"paid_storage_size_diff":"300"}}
+	]}}]}`
+
+const testFailedOperationResult = `{"contents":[{"kind":"transaction","metadata":{
+	"operation_result":{"status":"failed","errors":[{"kind":"temporary","id":"proto.008-PtEdo2Zk.michelson_v1.runtime_error"},{"kind":"temporary","id":"proto.008-PtEdo2Zk.michelson_v1.script_rejected"}]}}}]}`
+
+func Test_EstimateOperation(t *testing.T) {
+	constants := models.ProtocolConstants{
+		HardGasLimitPerOperation:     1040000,
+		HardStorageLimitPerOperation: 60000,
+		CostPerByte:                  250,
+		OriginationSize:              257,
+	}
+
+	req := BuildRunOperationRequest("NetXdQprcVkpaWU", "BLockGenesisGenesisGenesisGenesisGenesisf79b5d1CoW2", "tz1VSUr8wwNhLAzempoch5d6hLRiTh8Cjcjb", "KT1LAuGLiaCF9A72qZtFvVhyzzNFg86fwFnV", 41, constants, models.OperationParameter{
+		Entrypoint: MainEntrypoint,
+		Value:      `{"prim":"Pair","args":[{"int":"1"},[{"prim":"None"}]]}`,
+	})
+
+	tx := req.Operation.Contents[0]
+	if tx.Counter != 42 || tx.GasLimit != constants.HardGasLimitPerOperation || tx.StorageLimit != constants.HardStorageLimitPerOperation {
+		t.Errorf("request tx: %+v", tx)
+	}
+
+	testCases := []struct {
+		name    string
+		result  string
+		limits  models.ProtocolConstants
+		exp     models.OperationEstimate
+		wantErr bool
+	}{
+		{
+			name:   "Applied",
+			result: testRunOperationResult,
+			limits: constants,
+			//24999500 + 1427100 + 2000000 milligas, 10 + 257 + 300 + 257 bytes
+			exp: models.OperationEstimate{GasLimit: 28427 + gasLimitReserve, StorageLimit: 824, Burn: 824 * 250},
+		},
+		{name: "Failed", result: testFailedOperationResult, limits: constants, wantErr: true},
+		{name: "Empty", result: `{"contents":[]}`, limits: constants, wantErr: true},
+		{name: "Storage limit", result: testRunOperationResult, limits: models.ProtocolConstants{HardGasLimitPerOperation: 1040000, HardStorageLimitPerOperation: 800, OriginationSize: 257}, wantErr: true},
+		{name: "Gas limit", result: testRunOperationResult, limits: models.ProtocolConstants{HardGasLimitPerOperation: 20000, HardStorageLimitPerOperation: 60000, OriginationSize: 257}, wantErr: true},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			var result models.RunOperationResult
+			err := json.Unmarshal([]byte(test.result), &result)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			estimate, err := EstimateOperation(tx, result, test.limits)
+			if test.wantErr != (err != nil) {
+				t.Fatalf("wantErr: %t | err: %v", test.wantErr, err)
+			}
+
+			if test.wantErr {
+				return
+			}
+
+			if estimate.GasLimit != test.exp.GasLimit || estimate.StorageLimit != test.exp.StorageLimit || estimate.Burn != test.exp.Burn {
+				t.Errorf("got %+v, want %+v", estimate, test.exp)
+			}
+
+			//Fee covers forged tx with estimated fee and limits
+			tx.Fee, tx.GasLimit, tx.StorageLimit = estimate.Fee, estimate.GasLimit, estimate.StorageLimit
+			size, err := forgedSize(tx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if minFee := tezosprotocol.ComputeMinimumFee(big.NewInt(estimate.GasLimit), big.NewInt(size)).Int64(); estimate.Fee != minFee {
+				t.Errorf("fee %d, want %d", estimate.Fee, minFee)
+			}
+		})
+	}
+}
+
+func Test_OperationFailure(t *testing.T) {
+	var result models.RunOperationResult
+	err := json.Unmarshal([]byte(testFailedOperationResult), &result)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = operationFailure(result.Contents[0].Metadata.OperationResult)
+	if err == nil || err.Error() != "operation failed: proto.008-PtEdo2Zk.michelson_v1.script_rejected" {
+		t.Errorf("got %v", err)
+	}
+}
@@ -0,0 +1,961 @@
+package contract
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"tezosign/models"
+	"tezosign/types"
+	"time"
+
+	"blockwatch.cc/tzindex/micheline"
+	"github.com/anchorageoss/tezosprotocol/v2"
+	"golang.org/x/crypto/blake2b"
+)
+
+//Upper bound of executed instructions
+const maxScriptSteps = 100000
+
+//Deterministic stand-ins of chain context
+type ScriptEnv struct {
+	//Base58 chain id
+	ChainID string
+	Self    types.Address
+	Sender  types.Address
+	Source  types.Address
+	Amount  uint64
+	Balance uint64
+	//Unix seconds
+	Now int64
+	//Replaces signature verification, any signature is accepted if not set
+	CheckSignature func(key, signature, message []byte) bool
+}
+
+type ScriptOperation struct {
+	Kind        models.LambdaOperationKind
+	Destination types.Address
+	Entrypoint  string
+	Amount      uint64
+	Parameter   *micheline.Prim
+}
+
+type ScriptResult struct {
+	Operations []ScriptOperation
+	Storage    *micheline.Prim
+}
+
+//Script terminated by FAILWITH
+type ScriptFailure struct {
+	Value *micheline.Prim
+}
+
+func (e ScriptFailure) Error() string {
+	value, err := e.Value.MarshalJSON()
+	if err != nil {
+		return "script failed"
+	}
+
+	return fmt.Sprintf("script failed with %s", value)
+}
+
+//Operation values of interpreter are indexes of emitted operations
+type scriptRunner struct {
+	env        ScriptEnv
+	chainID    []byte
+	operations []ScriptOperation
+	steps      int
+	//Bodies of lambdas passed type check
+	lambdas map[*micheline.Prim]bool
+}
+
+//TypecheckScript checks contract code against its parameter and storage types
+func TypecheckScript(code *micheline.Code) error {
+	paramType, storageType, body, err := scriptSections(code)
+	if err != nil {
+		return err
+	}
+
+	a := &lambdaAnalyser{selfType: paramType}
+
+	stack, isFailed, err := a.exec(body, symStack{{typ: symType(micheline.T_PAIR, paramType, storageType)}})
+	if err != nil {
+		return err
+	}
+
+	if isFailed {
+		return nil
+	}
+
+	if a.isPartial {
+		return errors.New("some value types are not inferred")
+	}
+
+	if len(stack) != 1 || !typeEqual(stack[0].typ, symType(micheline.T_PAIR, symType(micheline.T_LIST, symType(micheline.T_OPERATION)), storageType)) {
+		return errors.New("script result is not pair of operations list and storage")
+	}
+
+	return nil
+}
+
+//typecheckLambda checks lambda body against its parameter and result types
+func typecheckLambda(lambdaType, body *micheline.Prim) error {
+	a := &lambdaAnalyser{}
+
+	stack, isFailed, err := a.exec(body, symStack{{typ: typeArg(lambdaType, 0)}})
+	if err != nil || isFailed {
+		return err
+	}
+
+	if len(stack) != 1 || !typeEqual(stack[0].typ, typeArg(lambdaType, 1)) {
+		return errors.New("lambda result does not match its type")
+	}
+
+	return nil
+}
+
+//RunScript executes contract code with parameter against storage
+func RunScript(code *micheline.Code, parameter, storage *micheline.Prim, env ScriptEnv) (result ScriptResult, err error) {
+	paramType, storageType, body, err := scriptSections(code)
+	if err != nil {
+		return result, err
+	}
+
+	parameter, err = normalizeValue(paramType, parameter)
+	if err != nil {
+		return result, fmt.Errorf("parameter: %s", err.Error())
+	}
+
+	storage, err = normalizeValue(storageType, storage)
+	if err != nil {
+		return result, fmt.Errorf("storage: %s", err.Error())
+	}
+
+	r := &scriptRunner{env: env, lambdas: map[*micheline.Prim]bool{}}
+	if env.ChainID != "" {
+		_, r.chainID, err = tezosprotocol.Base58CheckDecode(env.ChainID)
+		if err != nil {
+			return result, fmt.Errorf("chain id: %s", err.Error())
+		}
+	}
+
+	stack, err := r.exec(body, symStack{{typ: symType(micheline.T_PAIR, paramType, storageType), value: pairPrim(parameter, storage)}})
+	if err != nil {
+		return result, err
+	}
+
+	if len(stack) != 1 || stack[0].typ == nil || !typeEqual(stack[0].typ, symType(micheline.T_PAIR, symType(micheline.T_LIST, symType(micheline.T_OPERATION)), storageType)) {
+		return result, errors.New("script result is not pair of operations list and storage")
+	}
+
+	operations, storage, err := combSplit(stack[0].value)
+	if err != nil {
+		return result, err
+	}
+
+	result.Storage = storage
+	result.Operations = make([]ScriptOperation, len(operations.Args))
+	for i, op := range operations.Args {
+		result.Operations[i] = r.operations[op.Int.Int64()]
+	}
+
+	return result, nil
+}
+
+//scriptSections unwraps parameter, storage and code keywords
+func scriptSections(code *micheline.Code) (paramType, storageType, body *micheline.Prim, err error) {
+	if code == nil || code.Param == nil || code.Storage == nil || code.Code == nil {
+		return nil, nil, nil, errors.New("wrong script")
+	}
+
+	unwrap := func(p *micheline.Prim) *micheline.Prim {
+		if p.OpCode >= micheline.K_PARAMETER && p.OpCode <= micheline.K_CODE && len(p.Args) == 1 {
+			return p.Args[0]
+		}
+		return p
+	}
+
+	return unwrap(code.Param), unwrap(code.Storage), unwrap(code.Code), nil
+}
+
+func (r *scriptRunner) exec(code *micheline.Prim, s symStack) (_ symStack, err error) {
+	if code.Type != micheline.PrimSequence {
+		r.steps++
+		if r.steps > maxScriptSteps {
+			return s, errors.New("steps limit exceeded")
+		}
+
+		return r.instruction(code, s)
+	}
+
+	for _, instr := range code.Args {
+		s, err = r.exec(instr, s)
+		if err != nil {
+			return s, err
+		}
+	}
+
+	return s, nil
+}
+
+func (r *scriptRunner) instruction(instr *micheline.Prim, s symStack) (_ symStack, err error) {
+	if len(instr.Args) < argsCount(instr.OpCode) {
+		return s, fmt.Errorf("%s: wrong instruction arguments", instr.OpCode)
+	}
+
+	isApplied, err := s.manipulate(instr)
+	if isApplied {
+		return s, err
+	}
+
+	args, err := s.pop(instr, popCount(instr.OpCode))
+	if err != nil {
+		return s, err
+	}
+
+	err = checkRunOperands(instr, args)
+	if err != nil {
+		return s, err
+	}
+
+	switch instr.OpCode {
+	case micheline.I_DIP:
+		n, code := 1, instr.Args[0]
+		if len(instr.Args) > 1 {
+			n, code = intArg(instr, 1), instr.Args[1]
+		}
+
+		if n < 0 || n > len(s) {
+			return s, fmt.Errorf("%s: stack underflow", instr.OpCode)
+		}
+
+		protected := append(symStack{}, s[len(s)-n:]...)
+		s, err = r.exec(code, append(symStack{}, s[:len(s)-n]...))
+		if err != nil {
+			return s, err
+		}
+		s = append(s, protected...)
+	case micheline.I_PUSH:
+		v, err := normalizeValue(instr.Args[0], instr.Args[1])
+		if err != nil {
+			return s, fmt.Errorf("%s: %s", instr.OpCode, err.Error())
+		}
+		s.push(symValue{typ: instr.Args[0], value: v})
+	case micheline.I_UNIT:
+		s.push(symValue{typ: symType(micheline.T_UNIT), value: &micheline.Prim{Type: micheline.PrimNullary, OpCode: micheline.D_UNIT}})
+	case micheline.I_NIL, micheline.I_EMPTY_SET, micheline.I_EMPTY_MAP, micheline.I_EMPTY_BIG_MAP:
+		typ := map[micheline.OpCode]*micheline.Prim{
+			micheline.I_NIL:           symType(micheline.T_LIST, instr.Args...),
+			micheline.I_EMPTY_SET:     symType(micheline.T_SET, instr.Args...),
+			micheline.I_EMPTY_MAP:     symType(micheline.T_MAP, instr.Args...),
+			micheline.I_EMPTY_BIG_MAP: symType(micheline.T_BIG_MAP, instr.Args...),
+		}[instr.OpCode]
+		s.push(symValue{typ: typ, value: &micheline.Prim{Type: micheline.PrimSequence, Args: []*micheline.Prim{}}})
+	case micheline.I_NONE:
+		s.push(symValue{typ: symType(micheline.T_OPTION, instr.Args[0]), value: &micheline.Prim{Type: micheline.PrimNullary, OpCode: micheline.D_NONE}})
+	case micheline.I_SOME:
+		s.push(symValue{typ: symType(micheline.T_OPTION, args[0].typ), value: valuePrim(micheline.D_SOME, args[0].value)})
+	case micheline.I_LEFT:
+		s.push(symValue{typ: symType(micheline.T_OR, args[0].typ, instr.Args[0]), value: valuePrim(micheline.D_LEFT, args[0].value)})
+	case micheline.I_RIGHT:
+		s.push(symValue{typ: symType(micheline.T_OR, instr.Args[0], args[0].typ), value: valuePrim(micheline.D_RIGHT, args[0].value)})
+	case micheline.I_GET:
+		//Right comb access
+		if len(instr.Args) > 0 {
+			args, err = s.pop(instr, 1)
+			if err != nil {
+				return s, err
+			}
+
+			typ, errType := combGet(args[0].typ, intArg(instr, 0))
+			prim, errValue := combGet(args[0].value, intArg(instr, 0))
+			if errType != nil || errValue != nil {
+				return s, fmt.Errorf("%s: wrong comb access", instr.OpCode)
+			}
+			s.push(symValue{typ: typ, value: prim})
+			break
+		}
+
+		args, err = s.pop(instr, 2)
+		if err == nil {
+			err = checkRunOperands(instr, args)
+		}
+		if err != nil {
+			return s, err
+		}
+
+		value := &micheline.Prim{Type: micheline.PrimNullary, OpCode: micheline.D_NONE}
+		index, isFound, err := findKey(args[1].value, args[0].value)
+		if err != nil {
+			return s, fmt.Errorf("%s: %s", instr.OpCode, err.Error())
+		}
+		if isFound {
+			value = valuePrim(micheline.D_SOME, args[1].value.Args[index].Args[1])
+		}
+		s.push(symValue{typ: symType(micheline.T_OPTION, typeArg(args[1].typ, 1)), value: value})
+	case micheline.I_MEM:
+		_, isFound, err := findKey(args[1].value, args[0].value)
+		if err != nil {
+			return s, fmt.Errorf("%s: %s", instr.OpCode, err.Error())
+		}
+		s.push(boolValue(isFound))
+	case micheline.I_UPDATE:
+		args, err = s.pop(instr, 3)
+		if err == nil {
+			err = checkRunOperands(instr, args)
+		}
+		if err != nil {
+			return s, err
+		}
+
+		collection, err := updateCollection(args[2], args[0].value, args[1].value)
+		if err != nil {
+			return s, fmt.Errorf("%s: %s", instr.OpCode, err.Error())
+		}
+		s.push(symValue{typ: args[2].typ, value: collection})
+	case micheline.I_SIZE:
+		size := len(args[0].value.Args)
+		switch args[0].value.Type {
+		case micheline.PrimString:
+			size = len(args[0].value.String)
+		case micheline.PrimBytes:
+			size = len(args[0].value.Bytes)
+		}
+		s.push(natValue(big.NewInt(int64(size))))
+	case micheline.I_CONS:
+		list := &micheline.Prim{Type: micheline.PrimSequence, Args: append([]*micheline.Prim{args[0].value}, args[1].value.Args...)}
+		s.push(symValue{typ: args[1].typ, value: list})
+	case micheline.I_IF:
+		return r.exec(instr.Args[branchIndex(args[0].value.OpCode == micheline.D_TRUE)], s)
+	case micheline.I_IF_NONE:
+		if args[0].value.OpCode == micheline.D_NONE {
+			return r.exec(instr.Args[0], s)
+		}
+
+		s.push(symValue{typ: typeArg(args[0].typ, 0), value: args[0].value.Args[0]})
+		return r.exec(instr.Args[1], s)
+	case micheline.I_IF_LEFT:
+		index := branchIndex(args[0].value.OpCode == micheline.D_LEFT)
+		s.push(symValue{typ: typeArg(args[0].typ, index), value: args[0].value.Args[0]})
+		return r.exec(instr.Args[index], s)
+	case micheline.I_IF_CONS:
+		if len(args[0].value.Args) == 0 {
+			return r.exec(instr.Args[1], s)
+		}
+
+		s.push(
+			symValue{typ: args[0].typ, value: &micheline.Prim{Type: micheline.PrimSequence, Args: args[0].value.Args[1:]}},
+			symValue{typ: typeArg(args[0].typ, 0), value: args[0].value.Args[0]},
+		)
+		return r.exec(instr.Args[0], s)
+	case micheline.I_ITER:
+		elemType := typeArg(args[0].typ, 0)
+		if args[0].typ.OpCode == micheline.T_MAP {
+			elemType = symType(micheline.T_PAIR, args[0].typ.Args...)
+		}
+
+		for _, elem := range args[0].value.Args {
+			if elem.OpCode == micheline.D_ELT {
+				elem = pairPrim(elem.Args[0], elem.Args[1])
+			}
+
+			s, err = r.exec(instr.Args[0], append(s, symValue{typ: elemType, value: elem}))
+			if err != nil {
+				return s, err
+			}
+		}
+	case micheline.I_LOOP:
+		for args[0].value.OpCode == micheline.D_TRUE {
+			s, err = r.exec(instr.Args[0], s)
+			if err != nil {
+				return s, err
+			}
+
+			args, err = s.pop(instr, 1)
+			if err == nil {
+				err = checkRunOperands(instr, args)
+			}
+			if err != nil {
+				return s, err
+			}
+		}
+	case micheline.I_LOOP_LEFT:
+		for args[0].value.OpCode == micheline.D_LEFT {
+			s, err = r.exec(instr.Args[0], append(s, symValue{typ: typeArg(args[0].typ, 0), value: args[0].value.Args[0]}))
+			if err != nil {
+				return s, err
+			}
+
+			args, err = s.pop(instr, 1)
+			if err == nil {
+				err = checkRunOperands(instr, args)
+			}
+			if err != nil {
+				return s, err
+			}
+		}
+		s.push(symValue{typ: typeArg(args[0].typ, 1), value: args[0].value.Args[0]})
+	case micheline.I_FAILWITH:
+		return s, ScriptFailure{Value: args[0].value}
+	case micheline.I_LAMBDA:
+		s.push(symValue{typ: symType(micheline.T_LAMBDA, instr.Args[0], instr.Args[1]), value: instr.Args[2]})
+	case micheline.I_EXEC:
+		err = r.typecheckLambda(instr, args[1])
+		if err != nil {
+			return s, err
+		}
+
+		result, err := r.exec(args[1].value, symStack{args[0]})
+		if err != nil {
+			return s, err
+		}
+
+		if len(result) != 1 || !typeEqual(result[0].typ, typeArg(args[1].typ, 1)) {
+			return s, fmt.Errorf("%s: lambda result does not match its type", instr.OpCode)
+		}
+		s.push(result[0])
+	case micheline.I_CONTRACT:
+		target := args[0].value.Bytes
+		if entrypoint := instr.GetVarAnno(); entrypoint != "" && entrypoint != defaultEntrypoint {
+			target = append(append([]byte{}, target[:addressBinaryLength]...), entrypoint...)
+		}
+
+		contract := valuePrim(micheline.D_SOME, &micheline.Prim{Type: micheline.PrimBytes, Bytes: target})
+		//Implicit accounts accept unit only
+		if target[0] == 0 && (len(target) > addressBinaryLength || instr.Args[0].OpCode != micheline.T_UNIT) {
+			contract = &micheline.Prim{Type: micheline.PrimNullary, OpCode: micheline.D_NONE}
+		}
+		s.push(symValue{typ: symType(micheline.T_OPTION, symType(micheline.T_CONTRACT, instr.Args[0])), value: contract})
+	case micheline.I_IMPLICIT_ACCOUNT:
+		address := &micheline.Prim{Type: micheline.PrimBytes, Bytes: append([]byte{0}, args[0].value.Bytes...)}
+		s.push(symValue{typ: symType(micheline.T_CONTRACT, symType(micheline.T_UNIT)), value: address})
+	case micheline.I_ADDRESS:
+		s.push(symValue{typ: symType(micheline.T_ADDRESS), value: args[0].value})
+	case micheline.I_TRANSFER_TOKENS:
+		op, err := transferOperation(args[0], args[1], args[2])
+		if err != nil {
+			return s, fmt.Errorf("%s: %s", instr.OpCode, err.Error())
+		}
+		s.push(r.emit(op))
+	case micheline.I_SET_DELEGATE:
+		op := ScriptOperation{Kind: models.LambdaDelegation}
+		if args[0].value.OpCode == micheline.D_SOME {
+			op.Destination, err = keyHashValue(args[0].value.Args[0])
+			if err != nil {
+				return s, fmt.Errorf("%s: %s", instr.OpCode, err.Error())
+			}
+		}
+		s.push(r.emit(op))
+	case micheline.I_AMOUNT, micheline.I_BALANCE:
+		amount := r.env.Amount
+		if instr.OpCode == micheline.I_BALANCE {
+			amount = r.env.Balance
+		}
+		s.push(symValue{typ: symType(micheline.T_MUTEZ), value: &micheline.Prim{Type: micheline.PrimInt, Int: new(big.Int).SetUint64(amount)}})
+	case micheline.I_NOW:
+		s.push(symValue{typ: symType(micheline.T_TIMESTAMP), value: &micheline.Prim{Type: micheline.PrimInt, Int: big.NewInt(r.env.Now)}})
+	case micheline.I_SENDER, micheline.I_SOURCE, micheline.I_SELF_ADDRESS:
+		address := map[micheline.OpCode]types.Address{
+			micheline.I_SENDER:       r.env.Sender,
+			micheline.I_SOURCE:       r.env.Source,
+			micheline.I_SELF_ADDRESS: r.env.Self,
+		}[instr.OpCode]
+
+		prim, err := addressPrim(address)
+		if err != nil {
+			return s, fmt.Errorf("%s: %s", instr.OpCode, err.Error())
+		}
+		s.push(symValue{typ: symType(micheline.T_ADDRESS), value: prim})
+	case micheline.I_CHAIN_ID:
+		s.push(symValue{typ: symType(micheline.T_CHAIN_ID), value: &micheline.Prim{Type: micheline.PrimBytes, Bytes: r.chainID}})
+	case micheline.I_COMPARE:
+		cmp, err := compareValues(args[0].value, args[1].value)
+		if err != nil {
+			return s, fmt.Errorf("%s: %s", instr.OpCode, err.Error())
+		}
+		s.push(symValue{typ: symType(micheline.T_INT), value: &micheline.Prim{Type: micheline.PrimInt, Int: big.NewInt(int64(cmp))}})
+	case micheline.I_EQ, micheline.I_NEQ, micheline.I_LT, micheline.I_GT, micheline.I_LE, micheline.I_GE:
+		sign := args[0].value.Int.Sign()
+		s.push(boolValue(map[micheline.OpCode]bool{
+			micheline.I_EQ:  sign == 0,
+			micheline.I_NEQ: sign != 0,
+			micheline.I_LT:  sign < 0,
+			micheline.I_GT:  sign > 0,
+			micheline.I_LE:  sign <= 0,
+			micheline.I_GE:  sign >= 0,
+		}[instr.OpCode]))
+	case micheline.I_NOT:
+		if args[0].value.Type != micheline.PrimInt {
+			s.push(boolValue(args[0].value.OpCode == micheline.D_FALSE))
+			break
+		}
+		s.push(symValue{typ: symType(micheline.T_INT), value: &micheline.Prim{Type: micheline.PrimInt, Int: new(big.Int).Not(args[0].value.Int)}})
+	case micheline.I_AND, micheline.I_OR, micheline.I_XOR:
+		x, y := args[0].value, args[1].value
+		if x.Type != micheline.PrimInt {
+			a, b := x.OpCode == micheline.D_TRUE, y.OpCode == micheline.D_TRUE
+			s.push(boolValue(map[micheline.OpCode]bool{
+				micheline.I_AND: a && b,
+				micheline.I_OR:  a || b,
+				micheline.I_XOR: a != b,
+			}[instr.OpCode]))
+			break
+		}
+
+		result := new(big.Int)
+		switch instr.OpCode {
+		case micheline.I_AND:
+			result.And(x.Int, y.Int)
+		case micheline.I_OR:
+			result.Or(x.Int, y.Int)
+		case micheline.I_XOR:
+			result.Xor(x.Int, y.Int)
+		}
+		s.push(symValue{typ: args[1].typ, value: &micheline.Prim{Type: micheline.PrimInt, Int: result}})
+	case micheline.I_ADD, micheline.I_SUB, micheline.I_MUL:
+		v, err := arithmetic(instr, symValue{typ: args[0].typ, value: args[0].value}, symValue{typ: args[1].typ, value: args[1].value})
+		if err != nil {
+			return s, err
+		}
+
+		if v.typ == nil || v.value == nil {
+			return s, fmt.Errorf("%s: %s", instr.OpCode, "mutez underflow")
+		}
+
+		if v.typ.OpCode == micheline.T_MUTEZ && (!v.value.Int.IsInt64()) {
+			return s, fmt.Errorf("%s: %s", instr.OpCode, "mutez overflow")
+		}
+		s.push(symValue{typ: v.typ, value: v.value})
+	case micheline.I_EDIV:
+		typ, err := edivType(instr, args[0].typ, args[1].typ)
+		if err != nil {
+			return s, err
+		}
+
+		result := &micheline.Prim{Type: micheline.PrimNullary, OpCode: micheline.D_NONE}
+		if args[1].value.Int.Sign() != 0 {
+			quotient, remainder := new(big.Int).DivMod(args[0].value.Int, args[1].value.Int, new(big.Int))
+			result = valuePrim(micheline.D_SOME, pairPrim(
+				&micheline.Prim{Type: micheline.PrimInt, Int: quotient},
+				&micheline.Prim{Type: micheline.PrimInt, Int: remainder},
+			))
+		}
+		s.push(symValue{typ: typ, value: result})
+	case micheline.I_ABS:
+		s.push(natValue(new(big.Int).Abs(args[0].value.Int)))
+	case micheline.I_ISNAT:
+		result := &micheline.Prim{Type: micheline.PrimNullary, OpCode: micheline.D_NONE}
+		if args[0].value.Int.Sign() >= 0 {
+			result = valuePrim(micheline.D_SOME, args[0].value)
+		}
+		s.push(symValue{typ: symType(micheline.T_OPTION, symType(micheline.T_NAT)), value: result})
+	case micheline.I_INT:
+		s.push(symValue{typ: symType(micheline.T_INT), value: args[0].value})
+	case micheline.I_NEG:
+		s.push(symValue{typ: symType(micheline.T_INT), value: &micheline.Prim{Type: micheline.PrimInt, Int: new(big.Int).Neg(args[0].value.Int)}})
+	case micheline.I_PACK:
+		bt, err := args[0].value.MarshalBinary()
+		if err != nil {
+			return s, fmt.Errorf("%s: %s", instr.OpCode, err.Error())
+		}
+		s.push(bytesValue(append([]byte{TextWatermark}, bt...)))
+	case micheline.I_BLAKE2B:
+		hash := blake2b.Sum256(args[0].value.Bytes)
+		s.push(bytesValue(hash[:]))
+	case micheline.I_SHA256:
+		hash := sha256.Sum256(args[0].value.Bytes)
+		s.push(bytesValue(hash[:]))
+	case micheline.I_SHA512:
+		hash := sha512.Sum512(args[0].value.Bytes)
+		s.push(bytesValue(hash[:]))
+	case micheline.I_CHECK_SIGNATURE:
+		isValid := true
+		if r.env.CheckSignature != nil {
+			isValid = r.env.CheckSignature(args[0].value.Bytes, args[1].value.Bytes, args[2].value.Bytes)
+		}
+		s.push(boolValue(isValid))
+	default:
+		return s, unsupportedError{opCode: instr.OpCode}
+	}
+	if err != nil {
+		return s, err
+	}
+
+	return s, nil
+}
+
+func (r *scriptRunner) emit(op ScriptOperation) symValue {
+	r.operations = append(r.operations, op)
+
+	return symValue{
+		typ:   symType(micheline.T_OPERATION),
+		value: &micheline.Prim{Type: micheline.PrimInt, Int: big.NewInt(int64(len(r.operations) - 1))},
+	}
+}
+
+func transferOperation(param, amount, target symValue) (op ScriptOperation, err error) {
+	op = ScriptOperation{Kind: models.LambdaContractCall, Amount: amount.value.Int.Uint64(), Parameter: param.value}
+
+	op.Destination, err = primAddress(&micheline.Prim{Type: micheline.PrimBytes, Bytes: target.value.Bytes[:addressBinaryLength]})
+	if err != nil {
+		return op, err
+	}
+	op.Entrypoint = string(target.value.Bytes[addressBinaryLength:])
+
+	if op.Entrypoint == "" && param.value.OpCode == micheline.D_UNIT {
+		op.Kind, op.Parameter = models.LambdaTransfer, nil
+	}
+
+	return op, nil
+}
+
+//typecheckLambda checks lambda body once before first execution
+func (r *scriptRunner) typecheckLambda(instr *micheline.Prim, lambda symValue) error {
+	if r.lambdas[lambda.value] {
+		return nil
+	}
+
+	err := typecheckLambda(lambda.typ, lambda.value)
+	if err != nil {
+		return fmt.Errorf("%s: %s", instr.OpCode, err.Error())
+	}
+	r.lambdas[lambda.value] = true
+
+	return nil
+}
+
+//checkRunOperands requires typed values in addition to common operand checks
+func checkRunOperands(instr *micheline.Prim, args []symValue) error {
+	for i := range args {
+		if args[i].typ == nil || args[i].value == nil {
+			return fmt.Errorf("%s: untyped value", instr.OpCode)
+		}
+	}
+
+	return checkOperands(instr, args)
+}
+
+func boolValue(value bool) symValue {
+	opCode := micheline.D_FALSE
+	if value {
+		opCode = micheline.D_TRUE
+	}
+
+	return symValue{typ: symType(micheline.T_BOOL), value: &micheline.Prim{Type: micheline.PrimNullary, OpCode: opCode}}
+}
+
+func natValue(value *big.Int) symValue {
+	return symValue{typ: symType(micheline.T_NAT), value: &micheline.Prim{Type: micheline.PrimInt, Int: value}}
+}
+
+func bytesValue(value []byte) symValue {
+	return symValue{typ: symType(micheline.T_BYTES), value: &micheline.Prim{Type: micheline.PrimBytes, Bytes: value}}
+}
+
+//findKey looks for set element or map key
+func findKey(collection, key *micheline.Prim) (index int, isFound bool, err error) {
+	for i, elem := range collection.Args {
+		if elem.OpCode == micheline.D_ELT {
+			elem = elem.Args[0]
+		}
+
+		cmp, err := compareValues(elem, key)
+		if err != nil {
+			return 0, false, err
+		}
+
+		if cmp == 0 {
+			return i, true, nil
+		}
+	}
+
+	return 0, false, nil
+}
+
+//updateCollection sets or removes key of set or map keeping keys order
+func updateCollection(collection symValue, key, value *micheline.Prim) (*micheline.Prim, error) {
+	isSet := collection.typ.OpCode == micheline.T_SET
+
+	var elem *micheline.Prim
+	switch {
+	case isSet && value.OpCode == micheline.D_TRUE:
+		elem = key
+	case !isSet && value.OpCode == micheline.D_SOME:
+		elem = &micheline.Prim{Type: micheline.PrimBinary, OpCode: micheline.D_ELT, Args: []*micheline.Prim{key, value.Args[0]}}
+	}
+
+	elems := make([]*micheline.Prim, 0, len(collection.value.Args)+1)
+	for _, current := range collection.value.Args {
+		currentKey := current
+		if current.OpCode == micheline.D_ELT {
+			currentKey = current.Args[0]
+		}
+
+		cmp, err := compareValues(currentKey, key)
+		if err != nil {
+			return nil, err
+		}
+
+		if cmp > 0 && elem != nil {
+			elems, elem = append(elems, elem), nil
+		}
+
+		if cmp != 0 {
+			elems = append(elems, current)
+		}
+	}
+
+	if elem != nil {
+		elems = append(elems, elem)
+	}
+
+	return &micheline.Prim{Type: micheline.PrimSequence, Args: elems}, nil
+}
+
+//compareValues compares normalized comparable values
+func compareValues(x, y *micheline.Prim) (int, error) {
+	switch {
+	case x.Type == micheline.PrimInt && y.Type == micheline.PrimInt:
+		return x.Int.Cmp(y.Int), nil
+	case x.Type == micheline.PrimString && y.Type == micheline.PrimString:
+		return strings.Compare(x.String, y.String), nil
+	case x.Type == micheline.PrimBytes && y.Type == micheline.PrimBytes:
+		return bytes.Compare(x.Bytes, y.Bytes), nil
+	}
+
+	//Constructors order False < True, None < Some, Left < Right
+	rank := map[micheline.OpCode]int{
+		micheline.D_FALSE: 0, micheline.D_TRUE: 1,
+		micheline.D_NONE: 0, micheline.D_SOME: 1,
+		micheline.D_LEFT: 0, micheline.D_RIGHT: 1,
+	}
+
+	xRank, isXRanked := rank[x.OpCode]
+	yRank, isYRanked := rank[y.OpCode]
+	if isXRanked && isYRanked && xRank != yRank {
+		return xRank - yRank, nil
+	}
+
+	if x.OpCode != y.OpCode || len(x.Args) != len(y.Args) {
+		return 0, errors.New("values are not comparable")
+	}
+
+	switch x.OpCode {
+	case micheline.D_UNIT, micheline.D_TRUE, micheline.D_FALSE, micheline.D_NONE:
+		return 0, nil
+	case micheline.D_SOME, micheline.D_LEFT, micheline.D_RIGHT, micheline.D_PAIR:
+		for i := range x.Args {
+			cmp, err := compareValues(x.Args[i], y.Args[i])
+			if err != nil || cmp != 0 {
+				return cmp, err
+			}
+		}
+		return 0, nil
+	}
+
+	return 0, errors.New("values are not comparable")
+}
+
+//normalizeValue type checks value and converts it to optimized binary form
+func normalizeValue(t, v *micheline.Prim) (*micheline.Prim, error) {
+	if t == nil || v == nil {
+		return nil, errors.New("empty value")
+	}
+
+	switch t.OpCode {
+	case micheline.T_INT, micheline.T_NAT, micheline.T_MUTEZ, micheline.T_TIMESTAMP:
+		if t.OpCode == micheline.T_TIMESTAMP && v.Type == micheline.PrimString {
+			timestamp, err := time.Parse(time.RFC3339, v.String)
+			if err != nil {
+				return nil, fmt.Errorf("wrong timestamp %s", v.String)
+			}
+			return &micheline.Prim{Type: micheline.PrimInt, Int: big.NewInt(timestamp.Unix())}, nil
+		}
+
+		if !isPrimInt(v) {
+			return nil, fmt.Errorf("%s value expected", t.OpCode)
+		}
+
+		if (t.OpCode == micheline.T_NAT || t.OpCode == micheline.T_MUTEZ) && v.Int.Sign() < 0 {
+			return nil, fmt.Errorf("negative %s value", t.OpCode)
+		}
+
+		if t.OpCode == micheline.T_MUTEZ && v.Int.Cmp(big.NewInt(math.MaxInt64)) > 0 {
+			return nil, errors.New("mutez overflow")
+		}
+
+		return &micheline.Prim{Type: micheline.PrimInt, Int: new(big.Int).Set(v.Int)}, nil
+	case micheline.T_STRING:
+		if v.Type != micheline.PrimString {
+			return nil, errors.New("string value expected")
+		}
+		return &micheline.Prim{Type: micheline.PrimString, String: v.String}, nil
+	case micheline.T_BYTES:
+		if v.Type != micheline.PrimBytes {
+			return nil, errors.New("bytes value expected")
+		}
+		return &micheline.Prim{Type: micheline.PrimBytes, Bytes: v.Bytes}, nil
+	case micheline.T_UNIT:
+		if v.OpCode != micheline.D_UNIT {
+			return nil, errors.New("unit value expected")
+		}
+		return &micheline.Prim{Type: micheline.PrimNullary, OpCode: micheline.D_UNIT}, nil
+	case micheline.T_BOOL:
+		if v.OpCode != micheline.D_TRUE && v.OpCode != micheline.D_FALSE {
+			return nil, errors.New("bool value expected")
+		}
+		return &micheline.Prim{Type: micheline.PrimNullary, OpCode: v.OpCode}, nil
+	case micheline.T_ADDRESS:
+		address, err := optimizedBytes(v, func(value string) ([]byte, error) {
+			parts := strings.SplitN(value, "%", 2)
+			bt, err := types.Address(parts[0]).MarshalBinary()
+			if err != nil || len(parts) == 1 {
+				return bt, err
+			}
+			return append(bt, parts[1]...), nil
+		})
+		if err == nil && len(address.Bytes) < addressBinaryLength {
+			return nil, errors.New("wrong address length")
+		}
+		return address, err
+	case micheline.T_KEY_HASH:
+		keyHash, err := optimizedBytes(v, func(value string) ([]byte, error) {
+			bt, err := types.Address(value).MarshalBinary()
+			if err != nil {
+				return nil, err
+			}
+			if bt[0] != 0 {
+				return nil, errors.New("implicit account expected")
+			}
+			return bt[1:], nil
+		})
+		if err == nil && len(keyHash.Bytes) != addressBinaryLength-1 {
+			return nil, errors.New("wrong key hash length")
+		}
+		return keyHash, err
+	case micheline.T_KEY:
+		return optimizedBytes(v, func(value string) ([]byte, error) {
+			return types.PubKey(value).MarshalBinary()
+		})
+	case micheline.T_SIGNATURE:
+		return optimizedBytes(v, func(value string) ([]byte, error) {
+			return types.Signature(value).MarshalBinary()
+		})
+	case micheline.T_CHAIN_ID:
+		return optimizedBytes(v, func(value string) ([]byte, error) {
+			_, bt, err := tezosprotocol.Base58CheckDecode(value)
+			return bt, err
+		})
+	case micheline.T_OPTION:
+		switch {
+		case v.OpCode == micheline.D_NONE:
+			return &micheline.Prim{Type: micheline.PrimNullary, OpCode: micheline.D_NONE}, nil
+		case v.OpCode == micheline.D_SOME && len(v.Args) == 1 && len(t.Args) == 1:
+			value, err := normalizeValue(t.Args[0], v.Args[0])
+			if err != nil {
+				return nil, err
+			}
+			return valuePrim(micheline.D_SOME, value), nil
+		}
+		return nil, errors.New("option value expected")
+	case micheline.T_OR:
+		if (v.OpCode != micheline.D_LEFT && v.OpCode != micheline.D_RIGHT) || len(v.Args) != 1 || len(t.Args) != 2 {
+			return nil, errors.New("or value expected")
+		}
+
+		value, err := normalizeValue(t.Args[branchIndex(v.OpCode == micheline.D_LEFT)], v.Args[0])
+		if err != nil {
+			return nil, err
+		}
+		return valuePrim(v.OpCode, value), nil
+	case micheline.T_PAIR:
+		leftType, rightType, err := combSplit(t)
+		if err != nil {
+			return nil, err
+		}
+
+		//Comb literal {a; b; c}
+		if v.Type == micheline.PrimSequence {
+			v = &micheline.Prim{Type: micheline.PrimVariadicAnno, OpCode: micheline.D_PAIR, Args: v.Args}
+		}
+
+		leftValue, rightValue, err := combSplit(v)
+		if err != nil {
+			return nil, errors.New("pair value expected")
+		}
+
+		left, err := normalizeValue(leftType, leftValue)
+		if err != nil {
+			return nil, err
+		}
+
+		right, err := normalizeValue(rightType, rightValue)
+		if err != nil {
+			return nil, err
+		}
+		return pairPrim(left, right), nil
+	case micheline.T_LIST, micheline.T_SET:
+		if v.Type != micheline.PrimSequence || len(t.Args) != 1 {
+			return nil, errors.New("sequence value expected")
+		}
+
+		elems := make([]*micheline.Prim, len(v.Args))
+		for i := range v.Args {
+			elem, err := normalizeValue(t.Args[0], v.Args[i])
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = elem
+		}
+		return &micheline.Prim{Type: micheline.PrimSequence, Args: elems}, nil
+	case micheline.T_MAP:
+		if v.Type != micheline.PrimSequence || len(t.Args) != 2 {
+			return nil, errors.New("map value expected")
+		}
+
+		elems := make([]*micheline.Prim, len(v.Args))
+		for i, elt := range v.Args {
+			if elt.OpCode != micheline.D_ELT || len(elt.Args) != 2 {
+				return nil, errors.New("map element expected")
+			}
+
+			key, err := normalizeValue(t.Args[0], elt.Args[0])
+			if err != nil {
+				return nil, err
+			}
+
+			value, err := normalizeValue(t.Args[1], elt.Args[1])
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = &micheline.Prim{Type: micheline.PrimBinary, OpCode: micheline.D_ELT, Args: []*micheline.Prim{key, value}}
+		}
+		return &micheline.Prim{Type: micheline.PrimSequence, Args: elems}, nil
+	case micheline.T_LAMBDA:
+		if v.Type != micheline.PrimSequence {
+			return nil, errors.New("lambda code expected")
+		}
+		return v, nil
+	}
+
+	return nil, fmt.Errorf("%s values are not supported", t.OpCode)
+}
+
+func optimizedBytes(v *micheline.Prim, decode func(value string) ([]byte, error)) (*micheline.Prim, error) {
+	switch v.Type {
+	case micheline.PrimBytes:
+		return &micheline.Prim{Type: micheline.PrimBytes, Bytes: v.Bytes}, nil
+	case micheline.PrimString:
+		bt, err := decode(v.String)
+		if err != nil {
+			return nil, err
+		}
+		return &micheline.Prim{Type: micheline.PrimBytes, Bytes: bt}, nil
+	}
+
+	return nil, errors.New("string or bytes value expected")
+}
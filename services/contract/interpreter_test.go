@@ -0,0 +1,513 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"tezosign/models"
+	"tezosign/types"
+
+	"blockwatch.cc/tzindex/micheline"
+)
+
+const testChainID = "NetXjD3HPJJjmcd"
+
+func testScriptCode(t *testing.T, file string) *micheline.Code {
+	bt, err := ioutil.ReadFile("../../resources/" + file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code := &micheline.Code{}
+	err = code.UnmarshalJSON(bt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return code
+}
+
+func Test_TypecheckScript(t *testing.T) {
+	for _, file := range []string{"contract.json", "vesting.json", "vesting_cliff.json", "vesting_revocable.json", "vesting_shared.json"} {
+		t.Run(file, func(t *testing.T) {
+			err := TypecheckScript(testScriptCode(t, file))
+			if err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	code := testScriptCode(t, "vesting.json")
+	code.Code = &micheline.Prim{Type: micheline.PrimSequence, Args: []*micheline.Prim{
+		{Type: micheline.PrimNullary, OpCode: micheline.I_CDR},
+	}}
+
+	if TypecheckScript(code) == nil {
+		t.Error("wrong result type is not detected")
+	}
+}
+
+func Test_SimulateContractOperation(t *testing.T) {
+	const contractID = "KT1LAuGLiaCF9A72qZtFvVhyzzNFg86fwFnV"
+
+	keys := []types.PubKey{
+		"edpkuEZ8FpnCWY2mNUpNYaF4GH3zZuCYKoNjZJPvtoKEki2ZfbFPbS",
+		"edpkuNVuqdPhCsrYqkq21qW2hYTSZWMjQQjfyogoPZ2AfqCmonziNh",
+		"edpkv13wgJVsEQGiQmw6M2gt9SCu55ajuZDiS9Xyxq375tBUtv8Fjh",
+	}
+
+	storageJSON, err := BuildContractStorage(2, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	storage := &micheline.Prim{}
+	err = storage.UnmarshalJSON(storageJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	script := micheline.Script{Code: testScriptCode(t, "contract.json"), Storage: storage}
+
+	testCases := []struct {
+		name        string
+		counter     int64
+		req         models.ContractOperationRequest
+		operations  []ScriptOperation
+		isSignedBad bool
+		err         string
+	}{
+		{
+			name: "transfer",
+			req:  models.ContractOperationRequest{Type: models.Transfer, To: "tz1dBT7PKeSDbPK1No7KNhTvrr3XoLe8vKLH", Amount: 1010},
+			operations: []ScriptOperation{
+				{Kind: models.LambdaTransfer, Destination: "tz1dBT7PKeSDbPK1No7KNhTvrr3XoLe8vKLH", Amount: 1010},
+			},
+		},
+		{
+			name: "delegation",
+			req:  models.ContractOperationRequest{Type: models.Delegation, To: "tz3Mo3gHekQhCmykfnC58ecqJLXrjMKzkF2Q"},
+			operations: []ScriptOperation{
+				{Kind: models.LambdaDelegation, Destination: "tz3Mo3gHekQhCmykfnC58ecqJLXrjMKzkF2Q"},
+			},
+		},
+		{
+			name: "fa transfer",
+			req: models.ContractOperationRequest{Type: models.FATransfer, AssetID: "KT1NtGnEjacAkBph7k9HWVrN38PoYjcXTxdY", TransferList: []models.TransferUnit{
+				{Txs: []models.Tx{{To: "tz1dBT7PKeSDbPK1No7KNhTvrr3XoLe8vKLH", Amount: 110}}},
+			}},
+			operations: []ScriptOperation{
+				{Kind: models.LambdaContractCall, Destination: "KT1NtGnEjacAkBph7k9HWVrN38PoYjcXTxdY", Entrypoint: "transfer"},
+			},
+		},
+		{
+			name: "vesting vest",
+			req:  models.ContractOperationRequest{Type: models.VestingVest, VestingID: "KT1NtGnEjacAkBph7k9HWVrN38PoYjcXTxdY", Ticks: 5},
+			operations: []ScriptOperation{
+				{Kind: models.LambdaContractCall, Destination: "KT1NtGnEjacAkBph7k9HWVrN38PoYjcXTxdY", Entrypoint: "vest"},
+			},
+		},
+		{
+			name: "custom payload",
+			req:  models.ContractOperationRequest{Type: models.CustomPayload, CustomPayload: "[" + testNilOps + "," + testKeyHash + "," + testTransfer + "]"},
+			operations: []ScriptOperation{
+				{Kind: models.LambdaTransfer, Destination: "tz1VSUr8wwNhLAzempoch5d6hLRiTh8Cjcjb", Amount: 1000},
+			},
+		},
+		{
+			name: "storage update",
+			req:  models.ContractOperationRequest{Type: models.StorageUpdate, Threshold: 1, Keys: keys[:1]},
+		},
+		{
+			name:    "wrong counter",
+			counter: 1,
+			req:     models.ContractOperationRequest{Type: models.Transfer, To: "tz1dBT7PKeSDbPK1No7KNhTvrr3XoLe8vKLH", Amount: 1010},
+			err:     "Counters do not match.",
+		},
+		{
+			name: "zero transfer",
+			req:  models.ContractOperationRequest{Type: models.Transfer, To: "tz1dBT7PKeSDbPK1No7KNhTvrr3XoLe8vKLH"},
+			err:  "Zero value transfer",
+		},
+		{
+			name:        "bad signature",
+			req:         models.ContractOperationRequest{Type: models.Transfer, To: "tz1dBT7PKeSDbPK1No7KNhTvrr3XoLe8vKLH", Amount: 1010},
+			isSignedBad: true,
+			err:         "script failed",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.req.ContractID = contractID
+
+			payload, _, err := BuildContractSignPayload(testChainID, tc.counter, testMultisigActions(t), tc.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			message, err := payload.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			signatures := 0
+			env := ScriptEnv{CheckSignature: func(key, signature, signed []byte) bool {
+				signatures++
+				return !tc.isSignedBad && bytes.Equal(signed, message)
+			}}
+
+			result, err := SimulateContractOperation(script, testChainID, tc.counter, tc.req, env)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if signatures != 2 {
+				t.Errorf("checked signatures: got %d, want 2", signatures)
+			}
+
+			if len(result.Operations) != len(tc.operations) {
+				t.Fatalf("operations: got %+v, want %+v", result.Operations, tc.operations)
+			}
+
+			for i, op := range result.Operations {
+				op.Parameter = nil
+				if op != tc.operations[i] {
+					t.Errorf("operation %d: got %+v, want %+v", i, op, tc.operations[i])
+				}
+			}
+
+			counter, err := combGet(result.Storage, 1)
+			if err != nil || counter.Int.Cmp(big.NewInt(tc.counter+1)) != 0 {
+				t.Errorf("counter is not incremented: %+v", result.Storage)
+			}
+
+			if tc.req.Type == models.StorageUpdate {
+				threshold, err := combGet(result.Storage, 3)
+				if err != nil || threshold.Int.Int64() != 1 {
+					t.Errorf("threshold is not updated: %+v", result.Storage)
+				}
+			}
+		})
+	}
+}
+
+func Test_RunVestingScript(t *testing.T) {
+	storageJSON, err := BuildVestingContractStorage("tz1NkT6YCFS3mDo6kfaMFKFrRiA7w2o5dkWp", "KT1LAuGLiaCF9A72qZtFvVhyzzNFg86fwFnV", 100, 10, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	storage := &micheline.Prim{}
+	err = storage.UnmarshalJSON(storageJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vest := func(ticks int64) *micheline.Prim {
+		return valuePrim(micheline.D_RIGHT, valuePrim(micheline.D_RIGHT, &micheline.Prim{Type: micheline.PrimInt, Int: big.NewInt(ticks)}))
+	}
+
+	testCases := []struct {
+		name   string
+		ticks  int64
+		now    int64
+		amount uint64
+		err    string
+	}{
+		{name: "opened ticks", ticks: 3, now: 135, amount: 15},
+		{name: "not opened ticks", ticks: 4, now: 135, err: "script failed"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := RunScript(testScriptCode(t, "vesting.json"), vest(tc.ticks), storage, ScriptEnv{Now: tc.now, Balance: 1000})
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(result.Operations) != 1 || result.Operations[0].Amount != tc.amount || result.Operations[0].Destination != "tz1NkT6YCFS3mDo6kfaMFKFrRiA7w2o5dkWp" {
+				got, _ := json.Marshal(result.Operations)
+				t.Errorf("unexpected operations %s", got)
+			}
+
+			vested, err := combGet(result.Storage, 3)
+			if err != nil || vested.Int.Int64() != tc.ticks {
+				t.Errorf("vested counter is not updated: %+v", result.Storage)
+			}
+		})
+	}
+}
+
+func Test_RunVestingTemplates(t *testing.T) {
+	const (
+		target          = "tz1NkT6YCFS3mDo6kfaMFKFrRiA7w2o5dkWp"
+		delegateAdmin   = "KT1LAuGLiaCF9A72qZtFvVhyzzNFg86fwFnV"
+		revocationAdmin = "KT1NtGnEjacAkBph7k9HWVrN38PoYjcXTxdY"
+	)
+
+	beneficiaries := []models.VestingBeneficiary{
+		{Address: "tz1dBT7PKeSDbPK1No7KNhTvrr3XoLe8vKLH", Share: 1},
+		{Address: "KT1NtGnEjacAkBph7k9HWVrN38PoYjcXTxdY", Share: 3},
+	}
+
+	nat := func(value int64) *micheline.Prim {
+		return &micheline.Prim{Type: micheline.PrimInt, Int: big.NewInt(value)}
+	}
+
+	unit := &micheline.Prim{Type: micheline.PrimNullary, OpCode: micheline.D_UNIT}
+
+	vest := func(ticks int64) *micheline.Prim {
+		return valuePrim(micheline.D_RIGHT, valuePrim(micheline.D_RIGHT, nat(ticks)))
+	}
+
+	//(or (nat %vest) (unit %revoke)) nested into common entrypoints
+	revocableVest := func(ticks int64) *micheline.Prim {
+		return valuePrim(micheline.D_RIGHT, valuePrim(micheline.D_RIGHT, valuePrim(micheline.D_LEFT, nat(ticks))))
+	}
+	revoke := valuePrim(micheline.D_RIGHT, valuePrim(micheline.D_RIGHT, valuePrim(micheline.D_RIGHT, unit)))
+
+	request := func(template models.VestingTemplate) models.VestingContractStorageRequest {
+		return models.VestingContractStorageRequest{
+			Template:        template,
+			VestingAddress:  target,
+			DelegateAdmin:   delegateAdmin,
+			RevocationAdmin: revocationAdmin,
+			Beneficiaries:   beneficiaries,
+			Timestamp:       100,
+			Cliff:           200,
+			SecondsPerTick:  10,
+			TokensPerTick:   5,
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		file     string
+		template models.VestingTemplate
+		param    *micheline.Prim
+		env      ScriptEnv
+		//Destination to amount
+		payments map[types.Address]uint64
+		err      string
+	}{
+		{
+			name:     "Cliff not passed",
+			file:     "vesting_cliff.json",
+			template: models.VestingTemplateCliff,
+			param:    vest(3),
+			env:      ScriptEnv{Now: 135, Balance: 1000},
+			err:      "cliff",
+		},
+		{
+			name:     "Cliff passed",
+			file:     "vesting_cliff.json",
+			template: models.VestingTemplateCliff,
+			param:    vest(12),
+			env:      ScriptEnv{Now: 225, Balance: 1000},
+			payments: map[types.Address]uint64{target: 60},
+		},
+		{
+			name:     "Cliff passed not opened ticks",
+			file:     "vesting_cliff.json",
+			template: models.VestingTemplateCliff,
+			param:    vest(13),
+			env:      ScriptEnv{Now: 225, Balance: 1000},
+			err:      "script failed",
+		},
+		{
+			name:     "Revocable vest",
+			file:     "vesting_revocable.json",
+			template: models.VestingTemplateRevocable,
+			param:    revocableVest(3),
+			env:      ScriptEnv{Now: 135, Balance: 1000},
+			payments: map[types.Address]uint64{target: 15},
+		},
+		{
+			name:     "Revoke",
+			file:     "vesting_revocable.json",
+			template: models.VestingTemplateRevocable,
+			param:    revoke,
+			env:      ScriptEnv{Now: 135, Balance: 1000, Sender: revocationAdmin},
+			payments: map[types.Address]uint64{revocationAdmin: 1000},
+		},
+		{
+			name:     "Revoke not by admin",
+			file:     "vesting_revocable.json",
+			template: models.VestingTemplateRevocable,
+			param:    revoke,
+			env:      ScriptEnv{Now: 135, Balance: 1000, Sender: delegateAdmin},
+			err:      "not revocation admin",
+		},
+		{
+			name:     "Shared vest",
+			file:     "vesting_shared.json",
+			template: models.VestingTemplateShared,
+			param:    vest(3),
+			env:      ScriptEnv{Now: 135, Balance: 1000},
+			//15 split 1:3, remainder stays on contract
+			payments: map[types.Address]uint64{beneficiaries[0].Address: 3, beneficiaries[1].Address: 11},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			storageJSON, err := BuildVestingStorage(request(tc.template))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			storage := &micheline.Prim{}
+			err = storage.UnmarshalJSON(storageJSON)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := RunScript(testScriptCode(t, tc.file), tc.param, storage, tc.env)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			payments := map[types.Address]uint64{}
+			for _, op := range result.Operations {
+				payments[op.Destination] += op.Amount
+			}
+
+			if !reflect.DeepEqual(payments, tc.payments) {
+				got, _ := json.Marshal(result.Operations)
+				t.Errorf("unexpected operations %s", got)
+			}
+		})
+	}
+}
+
+//Script executing lambda unit (list operation) passed as parameter
+const testLambdaScript = `[{"prim":"parameter","args":[{"prim":"lambda","args":[{"prim":"unit"},{"prim":"list","args":[{"prim":"operation"}]}]}]},` +
+	`{"prim":"storage","args":[{"prim":"unit"}]},` +
+	`{"prim":"code","args":[[{"prim":"CAR"},{"prim":"UNIT"},{"prim":"EXEC"},{"prim":"UNIT"},{"prim":"SWAP"},{"prim":"PAIR"}]]}]`
+
+func Test_RunLambdaScript(t *testing.T) {
+	code := &micheline.Code{}
+	err := code.UnmarshalJSON([]byte(testLambdaScript))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = TypecheckScript(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name       string
+		payload    string
+		operations int
+		err        string
+	}{
+		{name: "transfer", payload: "[" + testNilOps + "," + testKeyHash + "," + testTransfer + "]", operations: 1},
+		{name: "comparison of chain id", payload: `[{"prim":"DROP"},{"prim":"CHAIN_ID"},{"prim":"LE"}]`, err: "unexpected chain_id value"},
+		{name: "unpair arity overflow", payload: `[{"prim":"UNPAIR","args":[{"int":"4611686018427387904"}]}]`, err: "UNPAIR"},
+		{name: "unpair beyond comb", payload: `[{"prim":"DROP"},{"prim":"PUSH","args":[{"prim":"pair","args":[{"prim":"nat"},{"prim":"nat"}]},{"prim":"Pair","args":[{"int":"1"},{"int":"2"}]}]},{"prim":"UNPAIR","args":[{"int":"3"}]}]`, err: "comb of 2 values"},
+		{name: "wrong cast", payload: `[{"prim":"DROP"},{"prim":"PUSH","args":[{"prim":"nat"},{"int":"1"}]},{"prim":"CAST","args":[{"prim":"string"}]}]`, err: "CAST"},
+		{name: "short address", payload: `[{"prim":"DROP"},{"prim":"PUSH","args":[{"prim":"address"},{"bytes":""}]},{"prim":"CONTRACT","args":[{"prim":"unit"}]}]`, err: "wrong address length"},
+		{name: "wrong result", payload: `[{"prim":"DROP"},{"prim":"UNIT"}]`, err: "lambda result"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lambda := &micheline.Prim{}
+			err := lambda.UnmarshalJSON([]byte(tc.payload))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := RunScript(code, lambda, &micheline.Prim{Type: micheline.PrimNullary, OpCode: micheline.D_UNIT}, ScriptEnv{ChainID: testChainID})
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(result.Operations) != tc.operations {
+				t.Errorf("operations: got %d, want %d", len(result.Operations), tc.operations)
+			}
+		})
+	}
+}
+
+//Test_RunRandomLambdas checks that random instruction sequences are rejected with errors instead of panics
+func Test_RunRandomLambdas(t *testing.T) {
+	instructions := []string{
+		`{"prim":"DROP"}`, `{"prim":"DROP","args":[{"int":"2"}]}`, `{"prim":"DUP"}`, `{"prim":"DUP","args":[{"int":"3"}]}`, `{"prim":"SWAP"}`,
+		`{"prim":"DIG","args":[{"int":"2"}]}`, `{"prim":"DUG","args":[{"int":"2"}]}`, `{"prim":"DIP","args":[[{"prim":"DROP"}]]}`,
+		`{"prim":"PAIR"}`, `{"prim":"PAIR","args":[{"int":"3"}]}`, `{"prim":"UNPAIR"}`, `{"prim":"UNPAIR","args":[{"int":"3"}]}`,
+		`{"prim":"UNPAIR","args":[{"int":"4611686018427387904"}]}`, `{"prim":"CAR"}`, `{"prim":"CDR"}`, `{"prim":"GET","args":[{"int":"3"}]}`,
+		`{"prim":"UNIT"}`, `{"prim":"CHAIN_ID"}`, `{"prim":"NOW"}`, `{"prim":"AMOUNT"}`, `{"prim":"SENDER"}`,
+		`{"prim":"PUSH","args":[{"prim":"int"},{"int":"-1"}]}`, `{"prim":"PUSH","args":[{"prim":"nat"},{"int":"0"}]}`,
+		`{"prim":"PUSH","args":[{"prim":"string"},{"string":"a"}]}`, `{"prim":"PUSH","args":[{"prim":"bytes"},{"bytes":""}]}`,
+		`{"prim":"PUSH","args":[{"prim":"mutez"},{"int":"1"}]}`, testKeyHash,
+		`{"prim":"NIL","args":[{"prim":"operation"}]}`, `{"prim":"NONE","args":[{"prim":"key_hash"}]}`, `{"prim":"SOME"}`,
+		`{"prim":"LEFT","args":[{"prim":"nat"}]}`, `{"prim":"RIGHT","args":[{"prim":"int"}]}`,
+		`{"prim":"EMPTY_MAP","args":[{"prim":"nat"},{"prim":"int"}]}`, `{"prim":"EMPTY_SET","args":[{"prim":"nat"}]}`,
+		`{"prim":"EQ"}`, `{"prim":"LE"}`, `{"prim":"NOT"}`, `{"prim":"AND"}`, `{"prim":"OR"}`, `{"prim":"ADD"}`, `{"prim":"SUB"}`, `{"prim":"MUL"}`,
+		`{"prim":"EDIV"}`, `{"prim":"ABS"}`, `{"prim":"NEG"}`, `{"prim":"ISNAT"}`, `{"prim":"INT"}`, `{"prim":"SIZE"}`, `{"prim":"CONS"}`,
+		`{"prim":"COMPARE"}`, `{"prim":"MEM"}`, `{"prim":"GET"}`, `{"prim":"UPDATE"}`, `{"prim":"PACK"}`, `{"prim":"BLAKE2B"}`, `{"prim":"CHECK_SIGNATURE"}`,
+		`{"prim":"IF","args":[[],[]]}`, `{"prim":"IF_NONE","args":[[],[]]}`, `{"prim":"IF_LEFT","args":[[],[]]}`, `{"prim":"IF_CONS","args":[[],[]]}`,
+		`{"prim":"ITER","args":[[{"prim":"DROP"}]]}`, `{"prim":"LOOP","args":[[]]}`, `{"prim":"LOOP_LEFT","args":[[]]}`,
+		`{"prim":"CONTRACT","args":[{"prim":"unit"}]}`, `{"prim":"IMPLICIT_ACCOUNT"}`, `{"prim":"ADDRESS"}`, `{"prim":"SET_DELEGATE"}`,
+		`{"prim":"TRANSFER_TOKENS"}`, `{"prim":"LAMBDA","args":[{"prim":"unit"},{"prim":"unit"},[]]}`, `{"prim":"EXEC"}`,
+		`{"prim":"CAST","args":[{"prim":"int"}]}`,
+	}
+
+	unit := symValue{typ: symType(micheline.T_UNIT), value: &micheline.Prim{Type: micheline.PrimNullary, OpCode: micheline.D_UNIT}}
+
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 20000; i++ {
+		body := make([]string, 1+random.Intn(12))
+		for j := range body {
+			body[j] = instructions[random.Intn(len(instructions))]
+		}
+
+		lambda := &micheline.Prim{}
+		err := lambda.UnmarshalJSON([]byte("[" + strings.Join(body, ",") + "]"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		AnalyseLambda(lambda)
+
+		r := &scriptRunner{lambdas: map[*micheline.Prim]bool{}}
+		_, _ = r.exec(lambda, symStack{unit})
+	}
+}
@@ -0,0 +1,1129 @@
+package contract
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"tezosign/models"
+	"tezosign/types"
+
+	"blockwatch.cc/tzindex/micheline"
+)
+
+const (
+	//Upper bound of ITER unrolling over constant collections
+	maxUnrolledIterations = 64
+	//Upper bound of nested EXEC of constant lambdas
+	maxLambdaDepth = 8
+	//Binary address without entrypoint suffix
+	addressBinaryLength = 22
+)
+
+//Stack value of symbolic execution
+type symValue struct {
+	//Michelson type, nil when unknown
+	typ *micheline.Prim
+	//Constant value, nil when computed at runtime
+	value *micheline.Prim
+	//Target of contract and option contract values
+	contract *symContract
+	//Emitted operations held by value
+	operations []int
+}
+
+type symContract struct {
+	address    types.Address
+	entrypoint string
+	isDynamic  bool
+}
+
+//Top of stack is the last element
+type symStack []symValue
+
+type unsupportedError struct {
+	opCode micheline.OpCode
+}
+
+func (e unsupportedError) Error() string {
+	return fmt.Sprintf("unsupported instruction %s", e.opCode)
+}
+
+type lambdaAnalyser struct {
+	operations []models.LambdaOperation
+	warnings   []models.LambdaWarning
+	//Some stack types are unknown
+	isPartial bool
+	//Nesting level of runtime conditions
+	conditional int
+	//Nesting level of loops
+	repeated    int
+	lambdaDepth int
+	//Parameter type of analysed script, SELF is forbidden in lambdas
+	selfType *micheline.Prim
+}
+
+//AnalyseCustomPayload parses lambda payload and analyses it
+func AnalyseCustomPayload(payload types.Payload) models.LambdaReport {
+	lambda, err := parseCustomPayload(payload)
+	if err != nil {
+		return models.LambdaReport{
+			Error:      fmt.Sprintf("wrong lambda: %s", err.Error()),
+			Operations: []models.LambdaOperation{},
+			Warnings:   []models.LambdaWarning{},
+		}
+	}
+
+	return AnalyseLambda(lambda)
+}
+
+//AnalyseLambda type checks lambda unit (list operation) and symbolically enumerates emitted operations
+func AnalyseLambda(lambda *micheline.Prim) (report models.LambdaReport) {
+	a := &lambdaAnalyser{}
+
+	stack, isFailed, err := a.exec(lambda, symStack{{typ: symType(micheline.T_UNIT), value: &micheline.Prim{Type: micheline.PrimNullary, OpCode: micheline.D_UNIT}}})
+	if err == nil && !isFailed {
+		err = a.checkResult(stack)
+	}
+
+	report.Operations = a.operations
+
+	var unsupported unsupportedError
+	switch {
+	case errors.As(err, &unsupported):
+		a.warn(models.WarningUnsupported, fmt.Sprintf("analysis stopped at %s", unsupported.opCode))
+	case err != nil:
+		report.Error = err.Error()
+	case isFailed:
+		report.IsTypeChecked = true
+		report.Operations = []models.LambdaOperation{}
+		a.warn(models.WarningAlwaysFails, "lambda always fails")
+	default:
+		report.IsTypeChecked = !a.isPartial
+		//Only operations of result list are emitted
+		report.Operations = make([]models.LambdaOperation, len(stack[0].operations))
+		for i, id := range stack[0].operations {
+			report.Operations[i] = a.operations[id]
+		}
+	}
+
+	if report.Operations == nil {
+		report.Operations = []models.LambdaOperation{}
+	}
+
+	report.Warnings = a.warnings
+	if report.Warnings == nil {
+		report.Warnings = []models.LambdaWarning{}
+	}
+
+	return report
+}
+
+func (a *lambdaAnalyser) checkResult(stack symStack) error {
+	if len(stack) != 1 {
+		return fmt.Errorf("lambda leaves %d stack values instead of list operation", len(stack))
+	}
+
+	if stack[0].typ == nil {
+		a.isPartial = true
+		return nil
+	}
+
+	if !typeEqual(stack[0].typ, symType(micheline.T_LIST, symType(micheline.T_OPERATION))) {
+		return errors.New("lambda result is not list operation")
+	}
+
+	return nil
+}
+
+func (a *lambdaAnalyser) warn(code models.LambdaWarningCode, message string) {
+	for i := range a.warnings {
+		if a.warnings[i].Code == code && a.warnings[i].Message == message {
+			return
+		}
+	}
+
+	a.warnings = append(a.warnings, models.LambdaWarning{Code: code, Message: message})
+}
+
+func (a *lambdaAnalyser) unknown(typ *micheline.Prim) symValue {
+	if typ == nil {
+		a.isPartial = true
+	}
+
+	return symValue{typ: typ}
+}
+
+func (a *lambdaAnalyser) emit(op models.LambdaOperation) symValue {
+	op.IsConditional = a.conditional > 0
+	op.IsRepeated = a.repeated > 0
+	a.operations = append(a.operations, op)
+
+	return symValue{typ: symType(micheline.T_OPERATION), operations: []int{len(a.operations) - 1}}
+}
+
+func (a *lambdaAnalyser) exec(code *micheline.Prim, stack symStack) (_ symStack, isFailed bool, err error) {
+	if code.Type != micheline.PrimSequence {
+		return a.instruction(code, stack)
+	}
+
+	for _, instr := range code.Args {
+		stack, isFailed, err = a.exec(instr, stack)
+		if err != nil || isFailed {
+			return stack, isFailed, err
+		}
+	}
+
+	return stack, false, nil
+}
+
+//instruction applies single instruction to stack copy
+func (a *lambdaAnalyser) instruction(instr *micheline.Prim, stack symStack) (_ symStack, isFailed bool, err error) {
+	s := append(symStack{}, stack...)
+
+	if len(instr.Args) < argsCount(instr.OpCode) {
+		return s, false, fmt.Errorf("%s: wrong instruction arguments", instr.OpCode)
+	}
+
+	isApplied, err := s.manipulate(instr)
+	if isApplied {
+		return s, false, err
+	}
+
+	args, err := s.pop(instr, popCount(instr.OpCode))
+	if err == nil {
+		err = checkOperands(instr, args)
+	}
+	if err != nil {
+		return s, false, err
+	}
+
+	switch instr.OpCode {
+	case micheline.I_DIP:
+		n, code := 1, instr.Args[0]
+		if len(instr.Args) > 1 {
+			n, code = intArg(instr, 1), instr.Args[1]
+		}
+
+		if n < 0 || n > len(s) {
+			return s, false, fmt.Errorf("%s: stack underflow", instr.OpCode)
+		}
+
+		protected := append(symStack{}, s[len(s)-n:]...)
+		s, isFailed, err = a.exec(code, s[:len(s)-n])
+		if err != nil || isFailed {
+			return s, isFailed, err
+		}
+		s = append(s, protected...)
+	case micheline.I_PUSH:
+		_, err = normalizeValue(instr.Args[0], instr.Args[1])
+		if err != nil {
+			return s, false, fmt.Errorf("%s: %s", instr.OpCode, err.Error())
+		}
+		s.push(symValue{typ: instr.Args[0], value: instr.Args[1]})
+	case micheline.I_UNIT:
+		s.push(symValue{typ: symType(micheline.T_UNIT), value: &micheline.Prim{Type: micheline.PrimNullary, OpCode: micheline.D_UNIT}})
+	case micheline.I_NIL:
+		s.push(symValue{typ: symType(micheline.T_LIST, instr.Args[0]), value: &micheline.Prim{Type: micheline.PrimSequence, Args: []*micheline.Prim{}}})
+	case micheline.I_NONE:
+		s.push(symValue{typ: symType(micheline.T_OPTION, instr.Args[0]), value: &micheline.Prim{Type: micheline.PrimNullary, OpCode: micheline.D_NONE}})
+	case micheline.I_EMPTY_MAP, micheline.I_EMPTY_BIG_MAP:
+		typ := symType(micheline.T_MAP, instr.Args...)
+		if instr.OpCode == micheline.I_EMPTY_BIG_MAP {
+			typ.OpCode = micheline.T_BIG_MAP
+		}
+		s.push(symValue{typ: typ, value: &micheline.Prim{Type: micheline.PrimSequence, Args: []*micheline.Prim{}}})
+	case micheline.I_EMPTY_SET:
+		s.push(symValue{typ: symType(micheline.T_SET, instr.Args[0]), value: &micheline.Prim{Type: micheline.PrimSequence, Args: []*micheline.Prim{}}})
+	case micheline.I_SOME:
+		s.push(symValue{
+			typ:        symType(micheline.T_OPTION, args[0].typ),
+			value:      valuePrim(micheline.D_SOME, args[0].value),
+			contract:   args[0].contract,
+			operations: args[0].operations,
+		})
+	case micheline.I_LEFT, micheline.I_RIGHT:
+		typ := symType(micheline.T_OR, args[0].typ, instr.Args[0])
+		value := valuePrim(micheline.D_LEFT, args[0].value)
+		if instr.OpCode == micheline.I_RIGHT {
+			typ = symType(micheline.T_OR, instr.Args[0], args[0].typ)
+			value = valuePrim(micheline.D_RIGHT, args[0].value)
+		}
+		s.push(symValue{typ: typ, value: value, operations: args[0].operations})
+	case micheline.I_CONS:
+		list := symValue{typ: args[1].typ, operations: unionOperations(args[0].operations, args[1].operations)}
+		if list.typ == nil {
+			list.typ = symType(micheline.T_LIST, args[0].typ)
+		}
+		if args[0].value != nil && args[1].value != nil {
+			list.value = &micheline.Prim{Type: micheline.PrimSequence, Args: append([]*micheline.Prim{args[0].value}, args[1].value.Args...)}
+		}
+		s.push(list)
+	case micheline.I_IF:
+		taken := -1
+		if args[0].value != nil {
+			taken = branchIndex(args[0].value.OpCode == micheline.D_TRUE)
+		}
+
+		return a.branches(instr, s, s, taken)
+	case micheline.I_IF_NONE:
+		some := symValue{contract: args[0].contract, operations: args[0].operations}
+		some.typ = typeArg(args[0].typ, 0)
+		some.value = valueArg(args[0].value, micheline.D_SOME, 0)
+
+		taken := -1
+		if args[0].value != nil {
+			taken = branchIndex(args[0].value.OpCode == micheline.D_NONE)
+		}
+
+		return a.branches(instr, s, append(append(symStack{}, s...), some), taken)
+	case micheline.I_IF_LEFT:
+		left := symValue{typ: typeArg(args[0].typ, 0), value: valueArg(args[0].value, micheline.D_LEFT, 0), operations: args[0].operations}
+		right := symValue{typ: typeArg(args[0].typ, 1), value: valueArg(args[0].value, micheline.D_RIGHT, 0), operations: args[0].operations}
+
+		taken := -1
+		if args[0].value != nil {
+			taken = branchIndex(args[0].value.OpCode == micheline.D_LEFT)
+		}
+
+		return a.branches(instr, append(append(symStack{}, s...), left), append(append(symStack{}, s...), right), taken)
+	case micheline.I_IF_CONS:
+		head := symValue{typ: typeArg(args[0].typ, 0), operations: args[0].operations}
+		tail := symValue{typ: args[0].typ, operations: args[0].operations}
+
+		taken := -1
+		if args[0].value != nil {
+			taken = 1
+			if len(args[0].value.Args) > 0 {
+				taken = 0
+				head.value = args[0].value.Args[0]
+				tail.value = &micheline.Prim{Type: micheline.PrimSequence, Args: args[0].value.Args[1:]}
+			}
+		}
+
+		return a.branches(instr, append(append(symStack{}, s...), tail, head), s, taken)
+	case micheline.I_ITER:
+		return a.iter(instr, s, args[0])
+	case micheline.I_MAP:
+		return a.mapCollection(instr, s, args[0])
+	case micheline.I_LOOP:
+		return a.loop(instr, s, args[0])
+	case micheline.I_LOOP_LEFT:
+		return a.loopLeft(instr, s, args[0])
+	case micheline.I_FAILWITH, micheline.I_NEVER:
+		return s, true, nil
+	case micheline.I_LAMBDA:
+		s.push(symValue{typ: symType(micheline.T_LAMBDA, instr.Args[0], instr.Args[1]), value: instr.Args[2]})
+	case micheline.I_EXEC:
+		if args[1].value == nil || a.lambdaDepth >= maxLambdaDepth {
+			a.warn(models.WarningDynamicCode, "lambda computed at runtime is executed")
+			s.push(a.unknown(typeArg(args[1].typ, 1)))
+			break
+		}
+
+		a.lambdaDepth++
+		result, isFailed, err := a.exec(args[1].value, symStack{args[0]})
+		a.lambdaDepth--
+		if err != nil || isFailed {
+			return s, isFailed, err
+		}
+
+		if len(result) != 1 {
+			return s, false, fmt.Errorf("%s: lambda leaves %d stack values", instr.OpCode, len(result))
+		}
+		s.push(result[0])
+	case micheline.I_APPLY:
+		var typ *micheline.Prim
+		if args[1].typ != nil {
+			_, right, err := pairTypes(instr, typeArg(args[1].typ, 0))
+			if err != nil {
+				return s, false, err
+			}
+			typ = symType(micheline.T_LAMBDA, right, typeArg(args[1].typ, 1))
+		}
+		s.push(a.unknown(typ))
+	case micheline.I_CONTRACT:
+		contract, err := contractTarget(args[0].value, addressValue)
+		if err != nil {
+			return s, false, fmt.Errorf("%s: %s", instr.OpCode, err.Error())
+		}
+		if entrypoint := instr.GetVarAnno(); entrypoint != "" {
+			contract.entrypoint = entrypoint
+		}
+
+		s.push(symValue{typ: symType(micheline.T_OPTION, symType(micheline.T_CONTRACT, instr.Args[0])), contract: contract})
+	case micheline.I_IMPLICIT_ACCOUNT:
+		contract, err := contractTarget(args[0].value, keyHashValue)
+		if err != nil {
+			return s, false, fmt.Errorf("%s: %s", instr.OpCode, err.Error())
+		}
+
+		s.push(symValue{typ: symType(micheline.T_CONTRACT, symType(micheline.T_UNIT)), contract: contract})
+	case micheline.I_ADDRESS:
+		address := symValue{typ: symType(micheline.T_ADDRESS)}
+		if args[0].contract != nil && !args[0].contract.isDynamic {
+			address.value = &micheline.Prim{Type: micheline.PrimString, String: args[0].contract.address.String()}
+		}
+		s.push(address)
+	case micheline.I_TRANSFER_TOKENS:
+		op, err := a.transfer(instr, args)
+		if err != nil {
+			return s, false, err
+		}
+		s.push(op)
+	case micheline.I_SET_DELEGATE:
+		a.warn(models.WarningSetDelegate, "lambda changes multisig delegate")
+
+		op := models.LambdaOperation{Kind: models.LambdaDelegation}
+		switch {
+		case args[0].value == nil:
+			a.warn(models.WarningDynamicAddress, "delegate is computed at runtime")
+		case args[0].value.OpCode == micheline.D_SOME && len(args[0].value.Args) == 1:
+			op.Destination, err = keyHashValue(args[0].value.Args[0])
+			if err != nil {
+				return s, false, fmt.Errorf("%s: %s", instr.OpCode, err.Error())
+			}
+		}
+		s.push(a.emit(op))
+	case micheline.I_CREATE_CONTRACT:
+		a.warn(models.WarningCreateContract, "lambda originates new contract")
+
+		op := models.LambdaOperation{Kind: models.LambdaOrigination, Amount: a.amount(args[1])}
+		s.push(symValue{typ: symType(micheline.T_ADDRESS)}, a.emit(op))
+	case micheline.I_SELF:
+		if a.selfType == nil {
+			return s, false, errors.New("SELF is forbidden in lambda")
+		}
+		s.push(symValue{typ: symType(micheline.T_CONTRACT, a.selfType)})
+	case micheline.I_AMOUNT, micheline.I_BALANCE:
+		s.push(symValue{typ: symType(micheline.T_MUTEZ)})
+	case micheline.I_NOW:
+		s.push(symValue{typ: symType(micheline.T_TIMESTAMP)})
+	case micheline.I_SENDER, micheline.I_SOURCE, micheline.I_SELF_ADDRESS:
+		s.push(symValue{typ: symType(micheline.T_ADDRESS)})
+	case micheline.I_CHAIN_ID:
+		s.push(symValue{typ: symType(micheline.T_CHAIN_ID)})
+	case micheline.I_LEVEL:
+		s.push(symValue{typ: symType(micheline.T_NAT)})
+	case micheline.I_ADD, micheline.I_SUB, micheline.I_MUL:
+		v, err := arithmetic(instr, args[0], args[1])
+		if err != nil {
+			return s, false, err
+		}
+		if v.typ == nil {
+			a.isPartial = true
+		}
+		s.push(v)
+	case micheline.I_EDIV:
+		typ, err := edivType(instr, args[0].typ, args[1].typ)
+		if err != nil {
+			return s, false, err
+		}
+		s.push(a.unknown(typ))
+	case micheline.I_COMPARE:
+		s.push(symValue{typ: symType(micheline.T_INT)})
+	case micheline.I_EQ, micheline.I_NEQ, micheline.I_LT, micheline.I_GT, micheline.I_LE, micheline.I_GE:
+		s.push(symValue{typ: symType(micheline.T_BOOL)})
+	case micheline.I_NOT:
+		typ := symType(micheline.T_INT)
+		if args[0].typ != nil && args[0].typ.OpCode == micheline.T_BOOL {
+			typ = args[0].typ
+		}
+		s.push(symValue{typ: typ})
+	case micheline.I_AND, micheline.I_OR, micheline.I_XOR, micheline.I_LSL, micheline.I_LSR:
+		typ := symType(micheline.T_NAT)
+		if args[0].typ != nil && args[0].typ.OpCode == micheline.T_BOOL {
+			typ = args[0].typ
+		}
+		s.push(symValue{typ: typ})
+	case micheline.I_ABS, micheline.I_SIZE:
+		s.push(symValue{typ: symType(micheline.T_NAT)})
+	case micheline.I_INT, micheline.I_NEG:
+		s.push(symValue{typ: symType(micheline.T_INT)})
+	case micheline.I_ISNAT:
+		s.push(symValue{typ: symType(micheline.T_OPTION, symType(micheline.T_NAT))})
+	case micheline.I_MEM:
+		s.push(symValue{typ: symType(micheline.T_BOOL)})
+	case micheline.I_GET:
+		//Right comb access
+		if len(instr.Args) > 0 {
+			args, err = s.pop(instr, 1)
+			if err != nil {
+				return s, false, err
+			}
+
+			v := symValue{operations: args[0].operations}
+			if args[0].typ != nil {
+				v.typ, err = combGet(args[0].typ, intArg(instr, 0))
+				if err != nil {
+					return s, false, fmt.Errorf("%s: %s", instr.OpCode, err.Error())
+				}
+			}
+			if args[0].value != nil && args[0].value.OpCode == micheline.D_PAIR {
+				v.value, _ = combGet(args[0].value, intArg(instr, 0))
+			}
+			if v.typ == nil {
+				a.isPartial = true
+			}
+			s.push(v)
+			break
+		}
+
+		args, err = s.pop(instr, 2)
+		if err == nil {
+			err = checkOperands(instr, args)
+		}
+		if err != nil {
+			return s, false, err
+		}
+		s.push(a.unknown(symType(micheline.T_OPTION, typeArg(args[1].typ, 1))))
+	case micheline.I_UPDATE:
+		if len(instr.Args) > 0 {
+			return s, false, unsupportedError{opCode: instr.OpCode}
+		}
+
+		args, err = s.pop(instr, 3)
+		if err == nil {
+			err = checkOperands(instr, args)
+		}
+		if err != nil {
+			return s, false, err
+		}
+		s.push(a.unknown(args[2].typ))
+	case micheline.I_CONCAT:
+		args, err = s.pop(instr, 1)
+		if err != nil {
+			return s, false, err
+		}
+
+		if args[0].typ != nil && args[0].typ.OpCode == micheline.T_LIST {
+			s.push(a.unknown(typeArg(args[0].typ, 0)))
+			break
+		}
+
+		_, err = s.pop(instr, 1)
+		if err != nil {
+			return s, false, err
+		}
+		s.push(a.unknown(args[0].typ))
+	case micheline.I_SLICE:
+		s.push(a.unknown(symType(micheline.T_OPTION, args[2].typ)))
+	case micheline.I_PACK, micheline.I_BLAKE2B, micheline.I_SHA256, micheline.I_SHA512, micheline.I_KECCAK, micheline.I_SHA3:
+		s.push(symValue{typ: symType(micheline.T_BYTES)})
+	case micheline.I_UNPACK:
+		s.push(symValue{typ: symType(micheline.T_OPTION, instr.Args[0])})
+	case micheline.I_HASH_KEY:
+		s.push(symValue{typ: symType(micheline.T_KEY_HASH)})
+	case micheline.I_CHECK_SIGNATURE:
+		s.push(symValue{typ: symType(micheline.T_BOOL)})
+	default:
+		return s, false, unsupportedError{opCode: instr.OpCode}
+	}
+	if err != nil {
+		return s, false, err
+	}
+
+	return s, false, nil
+}
+
+//argsCount returns number of required instruction arguments
+func argsCount(opCode micheline.OpCode) int {
+	switch opCode {
+	case micheline.I_NIL, micheline.I_NONE, micheline.I_EMPTY_SET, micheline.I_LEFT, micheline.I_RIGHT,
+		micheline.I_CONTRACT, micheline.I_UNPACK, micheline.I_CAST, micheline.I_DIP,
+		micheline.I_ITER, micheline.I_MAP, micheline.I_LOOP, micheline.I_LOOP_LEFT:
+		return 1
+	case micheline.I_PUSH, micheline.I_EMPTY_MAP, micheline.I_EMPTY_BIG_MAP,
+		micheline.I_IF, micheline.I_IF_NONE, micheline.I_IF_LEFT, micheline.I_IF_CONS:
+		return 2
+	case micheline.I_LAMBDA, micheline.I_CREATE_CONTRACT:
+		return 3
+	}
+
+	return 0
+}
+
+func branchIndex(isFirst bool) int {
+	if isFirst {
+		return 0
+	}
+
+	return 1
+}
+
+//popCount returns number of consumed values of fixed arity instructions
+func popCount(opCode micheline.OpCode) int {
+	switch opCode {
+	case micheline.I_SOME, micheline.I_LEFT, micheline.I_RIGHT,
+		micheline.I_IF, micheline.I_IF_NONE, micheline.I_IF_LEFT, micheline.I_IF_CONS,
+		micheline.I_ITER, micheline.I_MAP, micheline.I_LOOP, micheline.I_LOOP_LEFT, micheline.I_FAILWITH,
+		micheline.I_CONTRACT, micheline.I_IMPLICIT_ACCOUNT, micheline.I_ADDRESS, micheline.I_SET_DELEGATE,
+		micheline.I_EQ, micheline.I_NEQ, micheline.I_LT, micheline.I_GT, micheline.I_LE, micheline.I_GE,
+		micheline.I_NOT, micheline.I_ABS, micheline.I_SIZE, micheline.I_INT, micheline.I_NEG, micheline.I_ISNAT,
+		micheline.I_PACK, micheline.I_UNPACK, micheline.I_BLAKE2B, micheline.I_SHA256, micheline.I_SHA512,
+		micheline.I_KECCAK, micheline.I_SHA3, micheline.I_HASH_KEY:
+		return 1
+	case micheline.I_CONS, micheline.I_EXEC, micheline.I_APPLY, micheline.I_ADD, micheline.I_SUB, micheline.I_MUL,
+		micheline.I_EDIV, micheline.I_COMPARE, micheline.I_AND, micheline.I_OR, micheline.I_XOR,
+		micheline.I_LSL, micheline.I_LSR, micheline.I_MEM:
+		return 2
+	case micheline.I_TRANSFER_TOKENS, micheline.I_CREATE_CONTRACT, micheline.I_SLICE, micheline.I_CHECK_SIGNATURE:
+		return 3
+	}
+
+	return 0
+}
+
+func (a *lambdaAnalyser) transfer(instr *micheline.Prim, args []symValue) (symValue, error) {
+	param, amount, target := args[0], args[1], args[2]
+
+	op := models.LambdaOperation{Kind: models.LambdaContractCall, Amount: a.amount(amount)}
+
+	if target.contract == nil || target.contract.isDynamic {
+		a.warn(models.WarningDynamicAddress, "transfer destination is computed at runtime")
+	} else {
+		op.Destination = target.contract.address
+		op.Entrypoint = target.contract.entrypoint
+	}
+
+	isDefault := op.Entrypoint == "" || op.Entrypoint == defaultEntrypoint
+	if isDefault && param.typ != nil && param.typ.OpCode == micheline.T_UNIT {
+		op.Kind = models.LambdaTransfer
+		op.Entrypoint = ""
+	}
+
+	if op.Kind == models.LambdaContractCall && param.value != nil {
+		var err error
+		op.Parameter, err = param.value.MarshalJSON()
+		if err != nil {
+			return symValue{}, err
+		}
+	}
+
+	return a.emit(op), nil
+}
+
+func (a *lambdaAnalyser) amount(v symValue) *uint64 {
+	if v.value == nil || !isPrimInt(v.value) || !v.value.Int.IsUint64() {
+		a.warn(models.WarningDynamicAmount, "amount is computed at runtime")
+		return nil
+	}
+
+	amount := v.value.Int.Uint64()
+	return &amount
+}
+
+//branches analyses taken branch of constant condition or both branches
+func (a *lambdaAnalyser) branches(instr *micheline.Prim, thenStack, elseStack symStack, taken int) (symStack, bool, error) {
+	if len(instr.Args) != 2 {
+		return nil, false, fmt.Errorf("%s: wrong branches", instr.OpCode)
+	}
+
+	if taken >= 0 {
+		return a.exec(instr.Args[taken], []symStack{thenStack, elseStack}[taken])
+	}
+
+	a.conditional++
+	defer func() { a.conditional-- }()
+
+	thenResult, isThenFailed, err := a.exec(instr.Args[0], thenStack)
+	if err != nil {
+		return nil, false, err
+	}
+
+	elseResult, isElseFailed, err := a.exec(instr.Args[1], elseStack)
+	if err != nil {
+		return nil, false, err
+	}
+
+	switch {
+	case isThenFailed && isElseFailed:
+		return nil, true, nil
+	case isThenFailed:
+		return elseResult, false, nil
+	case isElseFailed:
+		return thenResult, false, nil
+	}
+
+	result, err := mergeStacks(thenResult, elseResult)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %s", instr.OpCode, err.Error())
+	}
+
+	return result, false, nil
+}
+
+func (a *lambdaAnalyser) iter(instr *micheline.Prim, s symStack, collection symValue) (symStack, bool, error) {
+	elemType := typeArg(collection.typ, 0)
+	if collection.typ != nil && collection.typ.OpCode == micheline.T_MAP {
+		elemType = symType(micheline.T_PAIR, collection.typ.Args...)
+	}
+
+	//Unroll constant collection
+	if collection.value != nil && collection.value.Type == micheline.PrimSequence && len(collection.value.Args) <= maxUnrolledIterations {
+		for _, elem := range collection.value.Args {
+			if elem.OpCode == micheline.D_ELT {
+				elem = valuePrim(micheline.D_PAIR, elem.Args...)
+			}
+
+			next, isFailed, err := a.exec(instr.Args[0], append(s, symValue{typ: elemType, value: elem}))
+			if err != nil || isFailed {
+				return next, isFailed, err
+			}
+			s = next
+		}
+
+		return s, false, nil
+	}
+
+	a.repeated++
+	defer func() { a.repeated-- }()
+
+	result, isFailed, err := a.exec(instr.Args[0], append(append(symStack{}, s...), symValue{typ: elemType, operations: collection.operations}))
+	if err != nil {
+		return s, false, err
+	}
+
+	//Body can be skipped on empty collection
+	if isFailed {
+		return s, false, nil
+	}
+
+	result, err = mergeStacks(s, result)
+	if err != nil {
+		return s, false, fmt.Errorf("%s: %s", instr.OpCode, err.Error())
+	}
+
+	return result, false, nil
+}
+
+func (a *lambdaAnalyser) mapCollection(instr *micheline.Prim, s symStack, collection symValue) (symStack, bool, error) {
+	elemType := typeArg(collection.typ, 0)
+	if collection.typ != nil && collection.typ.OpCode == micheline.T_MAP {
+		elemType = symType(micheline.T_PAIR, collection.typ.Args...)
+	}
+
+	a.repeated++
+	defer func() { a.repeated-- }()
+
+	result, isFailed, err := a.exec(instr.Args[0], append(append(symStack{}, s...), symValue{typ: elemType}))
+	if err != nil {
+		return s, false, err
+	}
+
+	if isFailed {
+		return append(s, a.unknown(collection.typ)), false, nil
+	}
+
+	if len(result) != len(s)+1 {
+		return s, false, fmt.Errorf("%s: body changes stack depth", instr.OpCode)
+	}
+
+	mapped := result[len(result)-1]
+	rest, err := mergeStacks(s, result[:len(result)-1])
+	if err != nil {
+		return s, false, fmt.Errorf("%s: %s", instr.OpCode, err.Error())
+	}
+
+	var typ *micheline.Prim
+	switch {
+	case collection.typ == nil:
+	case collection.typ.OpCode == micheline.T_MAP:
+		typ = symType(micheline.T_MAP, typeArg(collection.typ, 0), mapped.typ)
+	default:
+		typ = symType(micheline.T_LIST, mapped.typ)
+	}
+
+	return append(rest, a.unknown(typ)), false, nil
+}
+
+func (a *lambdaAnalyser) loop(instr *micheline.Prim, s symStack, condition symValue) (symStack, bool, error) {
+	if condition.value != nil && condition.value.OpCode == micheline.D_FALSE {
+		return s, false, nil
+	}
+
+	a.warn(models.WarningUnboundedLoop, "lambda contains LOOP")
+
+	a.repeated++
+	defer func() { a.repeated-- }()
+
+	result, isFailed, err := a.exec(instr.Args[0], append(symStack{}, s...))
+	if err != nil || isFailed {
+		return s, isFailed, err
+	}
+
+	if len(result) != len(s)+1 {
+		return s, false, fmt.Errorf("%s: body changes stack depth", instr.OpCode)
+	}
+
+	err = expectType(instr, result[len(result)-1], micheline.T_BOOL)
+	if err != nil {
+		return s, false, err
+	}
+
+	result, err = mergeStacks(s, result[:len(result)-1])
+	if err != nil {
+		return s, false, fmt.Errorf("%s: %s", instr.OpCode, err.Error())
+	}
+
+	return result, false, nil
+}
+
+func (a *lambdaAnalyser) loopLeft(instr *micheline.Prim, s symStack, start symValue) (symStack, bool, error) {
+	a.warn(models.WarningUnboundedLoop, "lambda contains LOOP_LEFT")
+
+	a.repeated++
+	defer func() { a.repeated-- }()
+
+	result, isFailed, err := a.exec(instr.Args[0], append(append(symStack{}, s...), symValue{typ: typeArg(start.typ, 0)}))
+	if err != nil || isFailed {
+		return s, isFailed, err
+	}
+
+	if len(result) != len(s)+1 {
+		return s, false, fmt.Errorf("%s: body changes stack depth", instr.OpCode)
+	}
+
+	next := result[len(result)-1]
+	err = expectType(instr, next, micheline.T_OR)
+	if err != nil {
+		return s, false, err
+	}
+
+	result, err = mergeStacks(s, result[:len(result)-1])
+	if err != nil {
+		return s, false, fmt.Errorf("%s: %s", instr.OpCode, err.Error())
+	}
+
+	return append(result, a.unknown(typeArg(start.typ, 1))), false, nil
+}
+
+//symType builds type, unknown argument makes whole type unknown
+func symType(opCode micheline.OpCode, args ...*micheline.Prim) *micheline.Prim {
+	for i := range args {
+		if args[i] == nil {
+			return nil
+		}
+	}
+
+	t := &micheline.Prim{OpCode: opCode, Args: args}
+	switch len(args) {
+	case 0:
+		t.Type = micheline.PrimNullary
+	case 1:
+		t.Type = micheline.PrimUnary
+	case 2:
+		t.Type = micheline.PrimBinary
+	default:
+		t.Type = micheline.PrimVariadicAnno
+	}
+
+	return t
+}
+
+//valuePrim builds constant, dynamic argument makes whole value dynamic
+func valuePrim(opCode micheline.OpCode, args ...*micheline.Prim) *micheline.Prim {
+	for i := range args {
+		if args[i] == nil {
+			return nil
+		}
+	}
+
+	p := &micheline.Prim{Type: micheline.PrimUnary, OpCode: opCode, Args: args}
+	if len(args) == 2 {
+		p.Type = micheline.PrimBinary
+	}
+
+	return p
+}
+
+func typeArg(t *micheline.Prim, i int) *micheline.Prim {
+	if t == nil || len(t.Args) <= i {
+		return nil
+	}
+
+	return t.Args[i]
+}
+
+func valueArg(v *micheline.Prim, opCode micheline.OpCode, i int) *micheline.Prim {
+	if v == nil || v.OpCode != opCode || len(v.Args) <= i {
+		return nil
+	}
+
+	return v.Args[i]
+}
+
+//pairTypes splits right comb pair type
+func pairTypes(instr *micheline.Prim, t *micheline.Prim) (left, right *micheline.Prim, err error) {
+	if t == nil {
+		return nil, nil, nil
+	}
+
+	if t.OpCode != micheline.T_PAIR {
+		return nil, nil, fmt.Errorf("%s: unexpected %s value", instr.OpCode, t.OpCode)
+	}
+
+	left, right, err = combSplit(t)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %s", instr.OpCode, err.Error())
+	}
+
+	return left, right, nil
+}
+
+//combSplit splits pair type or value, right comb of many args is folded
+func combSplit(p *micheline.Prim) (left, right *micheline.Prim, err error) {
+	if (p.OpCode != micheline.T_PAIR && p.OpCode != micheline.D_PAIR) || len(p.Args) < 2 {
+		return nil, nil, errors.New("not a pair")
+	}
+
+	if len(p.Args) == 2 {
+		return p.Args[0], p.Args[1], nil
+	}
+
+	return p.Args[0], &micheline.Prim{Type: micheline.PrimVariadicAnno, OpCode: p.OpCode, Args: p.Args[1:]}, nil
+}
+
+//combGet returns node of GET n, even n is n/2-th right subtree and odd n is left node of it
+func combGet(p *micheline.Prim, n int) (_ *micheline.Prim, err error) {
+	if n < 0 {
+		return nil, errors.New("wrong comb index")
+	}
+
+	for ; n > 1; n -= 2 {
+		_, p, err = combSplit(p)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if n == 1 {
+		p, _, err = combSplit(p)
+	}
+
+	return p, err
+}
+
+//typeEqual compares types ignoring annotations, unknown types are compatible
+func typeEqual(x, y *micheline.Prim) bool {
+	if x == nil || y == nil {
+		return true
+	}
+
+	if x.OpCode != y.OpCode {
+		return false
+	}
+
+	if x.OpCode == micheline.T_PAIR && len(x.Args) != len(y.Args) {
+		xl, xr, errX := pairTypes(x, x)
+		yl, yr, errY := pairTypes(y, y)
+		return errX == nil && errY == nil && typeEqual(xl, yl) && typeEqual(xr, yr)
+	}
+
+	if len(x.Args) != len(y.Args) {
+		return false
+	}
+
+	for i := range x.Args {
+		if !typeEqual(x.Args[i], y.Args[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func valueEqual(x, y *micheline.Prim) bool {
+	if x == nil || y == nil {
+		return false
+	}
+
+	xBytes, errX := x.MarshalJSON()
+	yBytes, errY := y.MarshalJSON()
+
+	return errX == nil && errY == nil && bytes.Equal(xBytes, yBytes)
+}
+
+//mergeStacks joins results of alternative branches
+func mergeStacks(x, y symStack) (symStack, error) {
+	if len(x) != len(y) {
+		return nil, errors.New("branches have different stack depth")
+	}
+
+	merged := make(symStack, len(x))
+	for i := range x {
+		if !typeEqual(x[i].typ, y[i].typ) {
+			return nil, errors.New("branches have different stack types")
+		}
+
+		merged[i] = symValue{
+			typ:        x[i].typ,
+			operations: unionOperations(x[i].operations, y[i].operations),
+		}
+
+		if merged[i].typ == nil {
+			merged[i].typ = y[i].typ
+		}
+
+		if valueEqual(x[i].value, y[i].value) {
+			merged[i].value = x[i].value
+		}
+
+		switch {
+		case x[i].contract != nil && y[i].contract != nil && *x[i].contract == *y[i].contract:
+			merged[i].contract = x[i].contract
+		case x[i].contract != nil || y[i].contract != nil:
+			merged[i].contract = &symContract{isDynamic: true}
+		}
+	}
+
+	return merged, nil
+}
+
+func unionOperations(x, y []int) []int {
+	if len(y) == 0 {
+		return x
+	}
+
+	if len(x) == 0 {
+		return y
+	}
+
+	set := map[int]bool{}
+	for _, id := range append(append([]int{}, x...), y...) {
+		set[id] = true
+	}
+
+	union := make([]int, 0, len(set))
+	for id := range set {
+		union = append(union, id)
+	}
+	sort.Ints(union)
+
+	return union
+}
+
+func contractTarget(value *micheline.Prim, decode func(p *micheline.Prim) (types.Address, error)) (*symContract, error) {
+	if value == nil {
+		return &symContract{isDynamic: true}, nil
+	}
+
+	address, err := decode(value)
+	if err != nil {
+		return nil, err
+	}
+
+	contract := &symContract{address: address}
+
+	//Address can contain entrypoint suffix
+	if parts := strings.SplitN(address.String(), "%", 2); len(parts) == 2 {
+		contract.address, contract.entrypoint = types.Address(parts[0]), parts[1]
+	}
+
+	return contract, nil
+}
+
+//addressValue reads address constant with optional entrypoint suffix
+func addressValue(p *micheline.Prim) (types.Address, error) {
+	if p.Type != micheline.PrimBytes || len(p.Bytes) <= addressBinaryLength {
+		return primAddress(p)
+	}
+
+	address, err := primAddress(&micheline.Prim{Type: micheline.PrimBytes, Bytes: p.Bytes[:addressBinaryLength]})
+	if err != nil {
+		return address, err
+	}
+
+	return types.Address(fmt.Sprintf("%s%%%s", address, p.Bytes[addressBinaryLength:])), nil
+}
+
+//keyHashValue reads key hash constant as implicit account address
+func keyHashValue(p *micheline.Prim) (types.Address, error) {
+	if p.Type != micheline.PrimBytes {
+		return primAddress(p)
+	}
+
+	return primAddress(&micheline.Prim{Type: micheline.PrimBytes, Bytes: append([]byte{0}, p.Bytes...)})
+}
+
+func arithmetic(instr *micheline.Prim, x, y symValue) (v symValue, err error) {
+	v.typ, err = arithmeticType(instr, x.typ, y.typ)
+	if err != nil {
+		return v, err
+	}
+
+	if x.value == nil || y.value == nil || !isPrimInt(x.value) || !isPrimInt(y.value) {
+		return v, nil
+	}
+
+	result := new(big.Int)
+	switch instr.OpCode {
+	case micheline.I_ADD:
+		result.Add(x.value.Int, y.value.Int)
+	case micheline.I_SUB:
+		result.Sub(x.value.Int, y.value.Int)
+	case micheline.I_MUL:
+		result.Mul(x.value.Int, y.value.Int)
+	}
+
+	//Mutez underflow fails at runtime
+	if v.typ != nil && v.typ.OpCode == micheline.T_MUTEZ && result.Sign() < 0 {
+		return v, nil
+	}
+
+	v.value = &micheline.Prim{Type: micheline.PrimInt, Int: result}
+
+	return v, nil
+}
+
+func arithmeticType(instr *micheline.Prim, x, y *micheline.Prim) (*micheline.Prim, error) {
+	if x == nil || y == nil {
+		return nil, nil
+	}
+
+	isNumber := func(t *micheline.Prim) bool {
+		return t.OpCode == micheline.T_INT || t.OpCode == micheline.T_NAT
+	}
+
+	switch {
+	case x.OpCode == micheline.T_NAT && y.OpCode == micheline.T_NAT && instr.OpCode != micheline.I_SUB:
+		return symType(micheline.T_NAT), nil
+	case isNumber(x) && isNumber(y):
+		return symType(micheline.T_INT), nil
+	case x.OpCode == micheline.T_MUTEZ && y.OpCode == micheline.T_MUTEZ && instr.OpCode != micheline.I_MUL:
+		return symType(micheline.T_MUTEZ), nil
+	case instr.OpCode == micheline.I_MUL && (x.OpCode == micheline.T_MUTEZ && y.OpCode == micheline.T_NAT || x.OpCode == micheline.T_NAT && y.OpCode == micheline.T_MUTEZ):
+		return symType(micheline.T_MUTEZ), nil
+	case instr.OpCode == micheline.I_SUB && x.OpCode == micheline.T_TIMESTAMP && y.OpCode == micheline.T_TIMESTAMP:
+		return symType(micheline.T_INT), nil
+	case instr.OpCode != micheline.I_MUL && x.OpCode == micheline.T_TIMESTAMP && y.OpCode == micheline.T_INT,
+		instr.OpCode == micheline.I_ADD && x.OpCode == micheline.T_INT && y.OpCode == micheline.T_TIMESTAMP:
+		return symType(micheline.T_TIMESTAMP), nil
+	}
+
+	return nil, fmt.Errorf("%s: wrong operand types %s and %s", instr.OpCode, x.OpCode, y.OpCode)
+}
+
+func edivType(instr *micheline.Prim, x, y *micheline.Prim) (*micheline.Prim, error) {
+	if x == nil || y == nil {
+		return nil, nil
+	}
+
+	var quotient, remainder micheline.OpCode
+	switch {
+	case x.OpCode == micheline.T_NAT && y.OpCode == micheline.T_NAT:
+		quotient, remainder = micheline.T_NAT, micheline.T_NAT
+	case (x.OpCode == micheline.T_NAT || x.OpCode == micheline.T_INT) && (y.OpCode == micheline.T_NAT || y.OpCode == micheline.T_INT):
+		quotient, remainder = micheline.T_INT, micheline.T_NAT
+	case x.OpCode == micheline.T_MUTEZ && y.OpCode == micheline.T_NAT:
+		quotient, remainder = micheline.T_MUTEZ, micheline.T_MUTEZ
+	case x.OpCode == micheline.T_MUTEZ && y.OpCode == micheline.T_MUTEZ:
+		quotient, remainder = micheline.T_NAT, micheline.T_MUTEZ
+	default:
+		return nil, fmt.Errorf("%s: wrong operand types %s and %s", instr.OpCode, x.OpCode, y.OpCode)
+	}
+
+	return symType(micheline.T_OPTION, symType(micheline.T_PAIR, symType(quotient), symType(remainder))), nil
+}
@@ -0,0 +1,222 @@
+package contract
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"tezosign/models"
+	"tezosign/types"
+)
+
+const (
+	testKeyHash  = `{"prim":"PUSH","args":[{"prim":"key_hash"},{"string":"tz1VSUr8wwNhLAzempoch5d6hLRiTh8Cjcjb"}]}`
+	testNilOps   = `{"prim":"DROP"},{"prim":"NIL","args":[{"prim":"operation"}]}`
+	testTransfer = `{"prim":"IMPLICIT_ACCOUNT"},{"prim":"PUSH","args":[{"prim":"mutez"},{"int":"1000"}]},{"prim":"UNIT"},{"prim":"TRANSFER_TOKENS"},{"prim":"CONS"}`
+)
+
+func Test_AnalyseLambda(t *testing.T) {
+	amount := func(v uint64) *uint64 { return &v }
+
+	contractCall, err := ContractCallLambda(testPrim(t, testParamSchema), "KT1NtGnEjacAkBph7k9HWVrN38PoYjcXTxdY", "mint", 100, json.RawMessage(`"5"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contractCallJSON, err := contractCall.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name          string
+		payload       string
+		isTypeChecked bool
+		isError       bool
+		operations    []models.LambdaOperation
+		warnings      []models.LambdaWarningCode
+	}{
+		{
+			name:          "empty",
+			payload:       emptyOperation,
+			isTypeChecked: true,
+		},
+		{
+			name:          "transfer",
+			payload:       "[" + testNilOps + "," + testKeyHash + "," + testTransfer + "]",
+			isTypeChecked: true,
+			operations: []models.LambdaOperation{
+				{Kind: models.LambdaTransfer, Destination: "tz1VSUr8wwNhLAzempoch5d6hLRiTh8Cjcjb", Amount: amount(1000)},
+			},
+		},
+		{
+			name:          "contract call",
+			payload:       string(contractCallJSON),
+			isTypeChecked: true,
+			operations: []models.LambdaOperation{
+				{Kind: models.LambdaContractCall, Destination: "KT1NtGnEjacAkBph7k9HWVrN38PoYjcXTxdY", Entrypoint: "mint", Amount: amount(100), Parameter: json.RawMessage(`{"int":"5"}`)},
+			},
+		},
+		{
+			name:          "set delegate",
+			payload:       "[" + testNilOps + "," + testKeyHash + `,{"prim":"SOME"},{"prim":"SET_DELEGATE"},{"prim":"CONS"}]`,
+			isTypeChecked: true,
+			operations: []models.LambdaOperation{
+				{Kind: models.LambdaDelegation, Destination: "tz1VSUr8wwNhLAzempoch5d6hLRiTh8Cjcjb"},
+			},
+			warnings: []models.LambdaWarningCode{models.WarningSetDelegate},
+		},
+		{
+			name:          "dropped operation",
+			payload:       "[" + testNilOps + `,{"prim":"NONE","args":[{"prim":"key_hash"}]},{"prim":"SET_DELEGATE"},{"prim":"DROP"}]`,
+			isTypeChecked: true,
+			warnings:      []models.LambdaWarningCode{models.WarningSetDelegate},
+		},
+		{
+			name: "dynamic destination",
+			payload: "[" + testNilOps + `,{"prim":"SENDER"},{"prim":"CONTRACT","args":[{"prim":"unit"}]},` +
+				`{"prim":"IF_NONE","args":[[{"prim":"UNIT"},{"prim":"FAILWITH"}],[]]},{"prim":"BALANCE"},{"prim":"UNIT"},{"prim":"TRANSFER_TOKENS"},{"prim":"CONS"}]`,
+			isTypeChecked: true,
+			operations: []models.LambdaOperation{
+				{Kind: models.LambdaTransfer},
+			},
+			warnings: []models.LambdaWarningCode{models.WarningDynamicAmount, models.WarningDynamicAddress},
+		},
+		{
+			name: "conditional transfer",
+			payload: "[" + testNilOps + `,{"prim":"NOW"},{"prim":"PUSH","args":[{"prim":"timestamp"},{"int":"0"}]},{"prim":"COMPARE"},{"prim":"GT"},` +
+				`{"prim":"IF","args":[[` + testKeyHash + "," + testTransfer + `],[]]}]`,
+			isTypeChecked: true,
+			operations: []models.LambdaOperation{
+				{Kind: models.LambdaTransfer, Destination: "tz1VSUr8wwNhLAzempoch5d6hLRiTh8Cjcjb", Amount: amount(1000), IsConditional: true},
+			},
+		},
+		{
+			name: "unrolled iter",
+			payload: "[" + testNilOps + `,{"prim":"PUSH","args":[{"prim":"list","args":[{"prim":"key_hash"}]},[{"string":"tz1VSUr8wwNhLAzempoch5d6hLRiTh8Cjcjb"},{"string":"tz1burnburnburnburnburnburnburjAYjjX"}]]},` +
+				`{"prim":"ITER","args":[[` + testTransfer + `]]}]`,
+			isTypeChecked: true,
+			operations: []models.LambdaOperation{
+				{Kind: models.LambdaTransfer, Destination: "tz1VSUr8wwNhLAzempoch5d6hLRiTh8Cjcjb", Amount: amount(1000)},
+				{Kind: models.LambdaTransfer, Destination: "tz1burnburnburnburnburnburnburjAYjjX", Amount: amount(1000)},
+			},
+		},
+		{
+			name:          "unbounded loop",
+			payload:       "[" + testNilOps + `,{"prim":"PUSH","args":[{"prim":"bool"},{"prim":"True"}]},{"prim":"LOOP","args":[[{"prim":"PUSH","args":[{"prim":"bool"},{"prim":"False"}]}]]}]`,
+			isTypeChecked: true,
+			warnings:      []models.LambdaWarningCode{models.WarningUnboundedLoop},
+		},
+		{
+			name: "create contract",
+			payload: "[" + testNilOps + `,{"prim":"UNIT"},{"prim":"PUSH","args":[{"prim":"mutez"},{"int":"0"}]},{"prim":"NONE","args":[{"prim":"key_hash"}]},` +
+				`{"prim":"CREATE_CONTRACT","args":[{"prim":"parameter","args":[{"prim":"unit"}]},{"prim":"storage","args":[{"prim":"unit"}]},{"prim":"code","args":[[{"prim":"FAILWITH"}]]}]},` +
+				`{"prim":"SWAP"},{"prim":"DROP"},{"prim":"CONS"}]`,
+			isTypeChecked: true,
+			operations: []models.LambdaOperation{
+				{Kind: models.LambdaOrigination, Amount: amount(0)},
+			},
+			warnings: []models.LambdaWarningCode{models.WarningCreateContract},
+		},
+		{
+			name:          "always fails",
+			payload:       `[{"prim":"PUSH","args":[{"prim":"string"},{"string":"fail"}]},{"prim":"FAILWITH"}]`,
+			isTypeChecked: true,
+			warnings:      []models.LambdaWarningCode{models.WarningAlwaysFails},
+		},
+		{
+			name:    "wrong result",
+			payload: `[{"prim":"DROP"},{"prim":"PUSH","args":[{"prim":"nat"},{"int":"1"}]}]`,
+			isError: true,
+		},
+		{
+			name:    "wrong transfer parameter",
+			payload: "[" + testNilOps + "," + testKeyHash + `,{"prim":"IMPLICIT_ACCOUNT"},{"prim":"PUSH","args":[{"prim":"mutez"},{"int":"1"}]},{"prim":"PUSH","args":[{"prim":"nat"},{"int":"1"}]},{"prim":"TRANSFER_TOKENS"},{"prim":"CONS"}]`,
+			isError: true,
+		},
+		{
+			name:    "stack underflow",
+			payload: `[{"prim":"DROP"},{"prim":"DROP"}]`,
+			isError: true,
+		},
+		{
+			name:    "unpair arity overflow",
+			payload: `[{"prim":"UNPAIR","args":[{"int":"4611686018427387904"}]}]`,
+			isError: true,
+		},
+		{
+			name:    "unpair beyond comb",
+			payload: `[{"prim":"DROP"},{"prim":"PUSH","args":[{"prim":"pair","args":[{"prim":"nat"},{"prim":"nat"}]},{"prim":"Pair","args":[{"int":"1"},{"int":"2"}]}]},{"prim":"UNPAIR","args":[{"int":"1000"}]}]`,
+			isError: true,
+		},
+		{
+			name:    "comparison of chain id",
+			payload: `[{"prim":"DROP"},{"prim":"CHAIN_ID"},{"prim":"LE"}]`,
+			isError: true,
+		},
+		{
+			name:    "self",
+			payload: "[" + testNilOps + `,{"prim":"SELF"}]`,
+			isError: true,
+		},
+		{
+			name:     "unsupported instruction",
+			payload:  "[" + testNilOps + `,{"prim":"TICKET"}]`,
+			warnings: []models.LambdaWarningCode{models.WarningUnsupported},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			report := AnalyseCustomPayload(types.Payload(tc.payload))
+
+			if report.IsTypeChecked != tc.isTypeChecked {
+				t.Errorf("is type checked: got %t, error %q", report.IsTypeChecked, report.Error)
+			}
+
+			if (report.Error != "") != tc.isError {
+				t.Errorf("unexpected error %q", report.Error)
+			}
+
+			if tc.isError {
+				return
+			}
+
+			if tc.operations == nil {
+				tc.operations = []models.LambdaOperation{}
+			}
+
+			if !reflect.DeepEqual(report.Operations, tc.operations) {
+				got, _ := json.Marshal(report.Operations)
+				want, _ := json.Marshal(tc.operations)
+				t.Errorf("operations: got %s, want %s", got, want)
+			}
+
+			codes := make([]models.LambdaWarningCode, len(report.Warnings))
+			for i := range report.Warnings {
+				codes[i] = report.Warnings[i].Code
+			}
+
+			if len(codes) != len(tc.warnings) || (len(codes) > 0 && !reflect.DeepEqual(codes, tc.warnings)) {
+				t.Errorf("warnings: got %v, want %v", codes, tc.warnings)
+			}
+		})
+	}
+}
+
+func Test_AnalyseRevokeLambda(t *testing.T) {
+	lambda, err := buildVestingRevokeLambda("KT1NtGnEjacAkBph7k9HWVrN38PoYjcXTxdY")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := AnalyseLambda(lambda)
+	if !report.IsTypeChecked || len(report.Operations) != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	op := report.Operations[0]
+	if op.Kind != models.LambdaContractCall || op.Entrypoint != "revoke" || op.Destination != "KT1NtGnEjacAkBph7k9HWVrN38PoYjcXTxdY" || !strings.Contains(string(op.Parameter), "Unit") {
+		t.Errorf("unexpected operation %+v", op)
+	}
+}
@@ -0,0 +1,203 @@
+package contract
+
+import (
+	"errors"
+	"math/big"
+	"tezosign/types"
+
+	"blockwatch.cc/tzindex/micheline"
+)
+
+type LedgerLayout string
+
+const (
+	//big_map address nat
+	LedgerAddressNat LedgerLayout = "address_nat"
+	//big_map address (pair nat (map address nat))
+	LedgerAddressPair LedgerLayout = "address_pair"
+	//big_map (pair address nat) nat
+	LedgerAddressToken LedgerLayout = "address_token"
+	//big_map (pair nat address) nat
+	LedgerTokenAddress LedgerLayout = "token_address"
+	//big_map nat address
+	LedgerNFT LedgerLayout = "nft"
+
+	balanceAnnotation = "balance"
+)
+
+//Known ledger big map annotations
+var ledgerAnnotations = []string{"ledger", "balances"}
+
+type Ledger struct {
+	Layout LedgerLayout
+	//Storage path of big map
+	Path []micheline.OpCode
+	//Path of balance in pair value
+	BalancePath []micheline.OpCode
+}
+
+func DetectLedger(storageSchema *micheline.Prim) (l Ledger, err error) {
+	e, err := InitAnnotsEntrypoints(storageSchema)
+	if err != nil {
+		return l, err
+	}
+
+	for _, anno := range ledgerAnnotations {
+		entrypoint, ok := e[anno]
+		if !ok || entrypoint.OpCode != micheline.T_BIG_MAP || len(entrypoint.Prim.Args) != 2 {
+			continue
+		}
+
+		l, err = detectLedgerLayout(entrypoint.Prim.Args[0], entrypoint.Prim.Args[1])
+		if err != nil {
+			continue
+		}
+
+		l.Path = entrypoint.Branch
+		return l, nil
+	}
+
+	return l, errors.New("ledger not found")
+}
+
+func detectLedgerLayout(key, value *micheline.Prim) (l Ledger, err error) {
+	switch {
+	case key.OpCode == micheline.T_ADDRESS && isNatType(value):
+		l.Layout = LedgerAddressNat
+	case key.OpCode == micheline.T_ADDRESS && value.OpCode == micheline.T_PAIR:
+		l.Layout = LedgerAddressPair
+		l.BalancePath, err = balancePath(value)
+		if err != nil {
+			return l, err
+		}
+	case isPairOf(key, micheline.T_ADDRESS, micheline.T_NAT) && isNatType(value):
+		l.Layout = LedgerAddressToken
+	case isPairOf(key, micheline.T_NAT, micheline.T_ADDRESS) && isNatType(value):
+		l.Layout = LedgerTokenAddress
+	case key.OpCode == micheline.T_NAT && value.OpCode == micheline.T_ADDRESS:
+		l.Layout = LedgerNFT
+	default:
+		return l, errors.New("unknown ledger layout")
+	}
+
+	return l, nil
+}
+
+func isNatType(p *micheline.Prim) bool {
+	return p.OpCode == micheline.T_NAT || p.OpCode == micheline.T_INT
+}
+
+func isPairOf(p *micheline.Prim, left, right micheline.OpCode) bool {
+	return p.OpCode == micheline.T_PAIR && len(p.Args) == 2 && p.Args[0].OpCode == left && p.Args[1].OpCode == right
+}
+
+//Balance annotated nat or first nat of pair, n-ary pair is walked as right comb
+func balancePath(value *micheline.Prim) (path []micheline.OpCode, err error) {
+	leaves, paths := combLeaves(NormalizeComb(value))
+	if len(leaves) < 2 {
+		return nil, errors.New("unsupported ledger value")
+	}
+
+	index := -1
+	for i := range leaves {
+		if !isNatType(leaves[i]) {
+			continue
+		}
+
+		if leaves[i].GetFieldAnnoAny() == balanceAnnotation {
+			index = i
+			break
+		}
+
+		if index == -1 {
+			index = i
+		}
+	}
+
+	if index == -1 {
+		return nil, errors.New("ledger balance not found")
+	}
+
+	return paths[index], nil
+}
+
+//combLeaves of binary pair type with their paths, annotated inner pairs are leaves
+func combLeaves(t *micheline.Prim) (leaves []*micheline.Prim, paths [][]micheline.OpCode) {
+	var prefix []micheline.OpCode
+	for t.OpCode == micheline.T_PAIR && len(t.Args) == 2 && (len(prefix) == 0 || len(t.Anno) == 0) {
+		leaves = append(leaves, t.Args[0])
+		paths = append(paths, append(append([]micheline.OpCode{}, prefix...), micheline.D_LEFT))
+
+		prefix = append(prefix, micheline.D_RIGHT)
+		t = t.Args[1]
+	}
+
+	if len(prefix) == 0 {
+		return nil, nil
+	}
+
+	return append(leaves, t), append(paths, prefix)
+}
+
+func (l Ledger) Key(holder types.Address, tokenID uint64) (key micheline.Prim, err error) {
+	address, err := holder.MarshalBinary()
+	if err != nil {
+		return key, err
+	}
+
+	addressPrim := &micheline.Prim{Type: micheline.PrimBytes, OpCode: micheline.T_BYTES, Bytes: address}
+	tokenPrim := &micheline.Prim{Type: micheline.PrimInt, OpCode: micheline.T_INT, Int: new(big.Int).SetUint64(tokenID)}
+
+	switch l.Layout {
+	case LedgerAddressNat, LedgerAddressPair:
+		return *addressPrim, nil
+	case LedgerAddressToken:
+		return micheline.Prim{Type: micheline.PrimBinary, OpCode: micheline.D_PAIR, Args: []*micheline.Prim{addressPrim, tokenPrim}}, nil
+	case LedgerTokenAddress:
+		return micheline.Prim{Type: micheline.PrimBinary, OpCode: micheline.D_PAIR, Args: []*micheline.Prim{tokenPrim, addressPrim}}, nil
+	case LedgerNFT:
+		return *tokenPrim, nil
+	default:
+		return key, errors.New("unknown ledger layout")
+	}
+}
+
+func (l Ledger) KeyHash(holder types.Address, tokenID uint64) (hash string, err error) {
+	key, err := l.Key(holder, tokenID)
+	if err != nil {
+		return hash, err
+	}
+
+	return getPrimKeyHash(key)
+}
+
+//Balance of holder from ledger value
+func (l Ledger) Balance(holder types.Address, value *micheline.Prim) (balance *big.Int, err error) {
+	if value == nil {
+		return nil, errors.New("empty ledger value")
+	}
+
+	switch l.Layout {
+	case LedgerNFT:
+		owner, err := primAddress(value)
+		if err != nil {
+			return nil, err
+		}
+
+		if owner == holder {
+			return big.NewInt(1), nil
+		}
+		return big.NewInt(0), nil
+	case LedgerAddressPair:
+		value, err = getParamsByPath(value, l.BalancePath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if value.Type != micheline.PrimInt || value.Int == nil {
+		return nil, errors.New("wrong ledger balance value")
+	}
+
+	return value.Int, nil
+}
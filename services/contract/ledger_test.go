@@ -0,0 +1,165 @@
+package contract
+
+import (
+	"math/big"
+	"testing"
+	"tezosign/types"
+
+	"blockwatch.cc/tzindex/micheline"
+)
+
+func typePrim(opCode micheline.OpCode, anno string, args ...*micheline.Prim) *micheline.Prim {
+	p := &micheline.Prim{Type: micheline.PrimNullary, OpCode: opCode, Args: args}
+	if len(args) > 0 {
+		p.Type = micheline.PrimBinary
+	}
+
+	if anno != "" {
+		p.Type = micheline.PrimBinaryAnno
+		if len(args) == 0 {
+			p.Type = micheline.PrimNullaryAnno
+		}
+		p.Anno = []string{"%" + anno}
+	}
+
+	return p
+}
+
+//Storage (pair (big_map %<anno> key value) (nat %total_supply))
+func ledgerStorage(anno string, key, value *micheline.Prim) *micheline.Prim {
+	return typePrim(micheline.T_PAIR, "",
+		typePrim(micheline.T_BIG_MAP, anno, key, value),
+		typePrim(micheline.T_NAT, "total_supply"),
+	)
+}
+
+func Test_DetectLedger(t *testing.T) {
+	address := func() *micheline.Prim { return typePrim(micheline.T_ADDRESS, "") }
+	nat := func(anno string) *micheline.Prim { return typePrim(micheline.T_NAT, anno) }
+
+	testCases := []struct {
+		name      string
+		storage   *micheline.Prim
+		expLayout LedgerLayout
+		wantErr   bool
+	}{
+		{
+			name:      "FA1.2 address nat",
+			storage:   ledgerStorage("ledger", address(), nat("")),
+			expLayout: LedgerAddressNat,
+		},
+		{
+			name:      "FA1.2 balance with allowances",
+			storage:   ledgerStorage("balances", address(), typePrim(micheline.T_PAIR, "", typePrim(micheline.T_MAP, "approvals", address(), nat("")), nat("balance"))),
+			expLayout: LedgerAddressPair,
+		},
+		{
+			name:      "FA1.2 n-ary balance pair",
+			storage:   ledgerStorage("balances", address(), &micheline.Prim{Type: micheline.PrimVariadicAnno, OpCode: micheline.T_PAIR, Args: []*micheline.Prim{typePrim(micheline.T_MAP, "approvals", address(), nat("")), nat("nonce"), nat("balance")}}),
+			expLayout: LedgerAddressPair,
+		},
+		{
+			name:      "FA2 multi asset",
+			storage:   ledgerStorage("ledger", typePrim(micheline.T_PAIR, "", address(), nat("")), nat("")),
+			expLayout: LedgerAddressToken,
+		},
+		{
+			name:      "FA2 token first",
+			storage:   ledgerStorage("ledger", typePrim(micheline.T_PAIR, "", nat(""), address()), nat("")),
+			expLayout: LedgerTokenAddress,
+		},
+		{
+			name:      "FA2 NFT",
+			storage:   ledgerStorage("ledger", nat(""), address()),
+			expLayout: LedgerNFT,
+		},
+		{
+			name:    "Unknown annotation",
+			storage: ledgerStorage("owners", address(), nat("")),
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			ledger, gotErr := DetectLedger(test.storage)
+			if test.wantErr != (gotErr != nil) || ledger.Layout != test.expLayout {
+				t.Errorf("wantErr: %t | err: %v | layout: %s", test.wantErr, gotErr, ledger.Layout)
+			}
+
+			if gotErr != nil {
+				return
+			}
+
+			if _, err := ledger.KeyHash("tz1boE6s8tS3pcxetHuaAPZWzHicMa39jSfj", 1); err != nil {
+				t.Errorf("key hash: %v", err)
+			}
+		})
+	}
+}
+
+func Test_LedgerBalance(t *testing.T) {
+	holder := types.Address("tz1boE6s8tS3pcxetHuaAPZWzHicMa39jSfj")
+	holderBytes, err := holder.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	intPrim := func(v int64) *micheline.Prim {
+		return &micheline.Prim{Type: micheline.PrimInt, OpCode: micheline.T_INT, Int: big.NewInt(v)}
+	}
+
+	testCases := []struct {
+		name       string
+		ledger     Ledger
+		value      *micheline.Prim
+		expBalance int64
+	}{
+		{
+			name:       "Nat value",
+			ledger:     Ledger{Layout: LedgerAddressToken},
+			value:      intPrim(100),
+			expBalance: 100,
+		},
+		{
+			name:   "Pair value",
+			ledger: Ledger{Layout: LedgerAddressPair, BalancePath: []micheline.OpCode{micheline.D_RIGHT}},
+			value: &micheline.Prim{Type: micheline.PrimBinary, OpCode: micheline.D_PAIR, Args: []*micheline.Prim{
+				{Type: micheline.PrimSequence, OpCode: micheline.T_MAP},
+				intPrim(42),
+			}},
+			expBalance: 42,
+		},
+		{
+			name:   "N-ary pair value",
+			ledger: Ledger{Layout: LedgerAddressPair, BalancePath: []micheline.OpCode{micheline.D_RIGHT, micheline.D_RIGHT}},
+			value: &micheline.Prim{Type: micheline.PrimVariadicAnno, OpCode: micheline.D_PAIR, Args: []*micheline.Prim{
+				{Type: micheline.PrimSequence, OpCode: micheline.T_MAP},
+				intPrim(1),
+				intPrim(42),
+			}},
+			expBalance: 42,
+		},
+		{
+			name:       "NFT owner",
+			ledger:     Ledger{Layout: LedgerNFT},
+			value:      &micheline.Prim{Type: micheline.PrimBytes, OpCode: micheline.T_BYTES, Bytes: holderBytes},
+			expBalance: 1,
+		},
+		{
+			name:       "NFT other owner",
+			ledger:     Ledger{Layout: LedgerNFT},
+			value:      &micheline.Prim{Type: micheline.PrimString, OpCode: micheline.T_STRING, String: "KT1K9gCRgaLRFKTErYt1wVxA3Frb9FjasjTV"},
+			expBalance: 0,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			balance, err := test.ledger.Balance(holder, test.value)
+			if err != nil || balance.Int64() != test.expBalance {
+				t.Errorf("err: %v | balance: %v", err, balance)
+			}
+		})
+	}
+}
@@ -7,41 +7,50 @@ import (
 )
 
 const (
-	metadataThumbnailUri           = "thumbnailUri"
-	metadataThumbnailUriJsonNaming = "thumbnail_uri"
-
 	MetaDataEntrypoint = "tokenmetadata"
+	//TZIP-16 contract metadata big map
+	ContractMetadataEntrypoint = "metadata"
 )
 
-func ParseMetadata(data []byte) (fields map[string]interface{}, err error) {
+//Parse token_metadata big map value (pair nat (map string bytes))
+func ParseTokenInfo(data []byte) (tokenInfo map[string][]byte, err error) {
 
 	prim := &micheline.Prim{}
 
 	err = prim.UnmarshalJSON(data)
 	if err != nil {
-		return fields, err
+		return tokenInfo, err
 	}
 
 	if len(prim.Args) != 2 {
-		return fields, errors.New("wrong args len")
+		return tokenInfo, errors.New("wrong args len")
 	}
 
-	fields = map[string]interface{}{}
-	var name string
+	tokenInfo = map[string][]byte{}
 	for i := range prim.Args[1].Args {
 
 		if len(prim.Args[1].Args[i].Args) != 2 {
-			return fields, errors.New("wrong elem args len")
+			return tokenInfo, errors.New("wrong elem args len")
 		}
 
-		name = prim.Args[1].Args[i].Args[0].String
+		tokenInfo[prim.Args[1].Args[i].Args[0].String] = prim.Args[1].Args[i].Args[1].Bytes
+	}
 
-		if name == metadataThumbnailUri {
-			name = metadataThumbnailUriJsonNaming
-		}
+	return tokenInfo, nil
+}
+
+//Parse %metadata big map bytes value
+func ParseBytesValue(data []byte) (value []byte, err error) {
+	prim := &micheline.Prim{}
+
+	err = prim.UnmarshalJSON(data)
+	if err != nil {
+		return value, err
+	}
 
-		fields[name] = string(prim.Args[1].Args[i].Args[1].Bytes)
+	if prim.Type != micheline.PrimBytes {
+		return value, errors.New("wrong value type")
 	}
 
-	return fields, nil
+	return prim.Bytes, nil
 }
@@ -21,14 +21,14 @@ type Operation struct {
 	Value      *micheline.Prim `json:"value"`
 }
 
-func BuildContractSignPayload(networkID string, counter int64, operationParams models.ContractOperationRequest) (resp types.Payload, jsonResp string, err error) {
+func BuildContractSignPayload(networkID string, counter int64, actions MultisigActions, operationParams models.ContractOperationRequest) (resp types.Payload, jsonResp string, err error) {
 
 	networkArgs, err := buildNetworkMichelsonArgs(networkID, operationParams.ContractID)
 	if err != nil {
 		return resp, jsonResp, err
 	}
 
-	params, err := buildActionMichelsonArgs(counter, operationParams)
+	params, err := buildActionMichelsonArgs(counter, actions, operationParams)
 	if err != nil {
 		return resp, jsonResp, err
 	}
@@ -54,8 +54,8 @@ func BuildContractSignPayload(networkID string, counter int64, operationParams m
 	return types.Payload(hex.EncodeToString(bt)), string(operationJson), nil
 }
 
-func BuildRejectSignPayload(networkID string, counter int64, contractAddress types.Address) (resp types.Payload, respJson string, err error) {
-	return BuildContractSignPayload(networkID, counter, models.ContractOperationRequest{
+func BuildRejectSignPayload(networkID string, counter int64, actions MultisigActions, contractAddress types.Address) (resp types.Payload, respJson string, err error) {
+	return BuildContractSignPayload(networkID, counter, actions, models.ContractOperationRequest{
 		ContractID:    contractAddress,
 		Type:          models.CustomPayload,
 		CustomPayload: emptyOperation,
@@ -95,9 +95,9 @@ func buildNetworkMichelsonArgs(networkID string, contractID types.Address) (para
 	return networkArgs, nil
 }
 
-func buildActionMichelsonArgs(counter int64, operationParams models.ContractOperationRequest) (params *micheline.Prim, err error) {
+func buildActionMichelsonArgs(counter int64, actions MultisigActions, operationParams models.ContractOperationRequest) (params *micheline.Prim, err error) {
 
-	actionArgs, err := buildActionCallMichelsonArgs(operationParams)
+	actionArgs, err := buildActionCallMichelsonArgs(actions, operationParams)
 	if err != nil {
 		return params, err
 	}
@@ -121,7 +121,7 @@ func buildActionMichelsonArgs(counter int64, operationParams models.ContractOper
 	return params, nil
 }
 
-func buildActionCallMichelsonArgs(operationParams models.ContractOperationRequest) (params *micheline.Prim, err error) {
+func buildActionCallMichelsonArgs(actions MultisigActions, operationParams models.ContractOperationRequest) (params *micheline.Prim, err error) {
 
 	actionParams, err := buildActionParams(operationParams)
 	if err != nil {
@@ -129,7 +129,7 @@ func buildActionCallMichelsonArgs(operationParams models.ContractOperationReques
 	}
 
 	//Build path
-	path, err := buildMichelsonPath(operationParams.Type, actionParams)
+	path, err := buildMichelsonPath(actions, operationParams.Type, actionParams)
 	if err != nil {
 		return params, err
 	}
@@ -164,7 +164,7 @@ func Test_BuildContractSignPayload(t *testing.T) {
 					ContractID: "KT1LAuGLiaCF9A72qZtFvVhyzzNFg86fwFnV",
 					VestingID:  "KT1NtGnEjacAkBph7k9HWVrN38PoYjcXTxdY",
 					Type:       models.VestingVest,
-					Amount:     123,
+					Ticks:      123,
 				},
 			},
 
@@ -183,7 +183,21 @@ func Test_BuildContractSignPayload(t *testing.T) {
 				},
 			},
 
-			expResult: "05070707070a000000049caecab90a00000016017f1df41f643db8039663fd5eb3b025e07efbaf3d0007070000050505050508050807070a00000016019ce13845659ff2582555ec08dc322007f6493e800005050a000000160000c06b6aa5308a9a89a628ebb8234d5055bf9ba1d0",
+			expResult: "05070707070a000000049caecab90a00000016017f1df41f643db8039663fd5eb3b025e07efbaf3d0007070000050505050508050807070a00000016019ce13845659ff2582555ec08dc322007f6493e8000050505090a0000001500c06b6aa5308a9a89a628ebb8234d5055bf9ba1d0",
+			wantErr:   false,
+		},
+		{
+			name: "vesting revoke",
+			args: args{
+				networkID: "NetXjD3HPJJjmcd",
+				operationParams: models.ContractOperationRequest{
+					ContractID: "KT1LAuGLiaCF9A72qZtFvVhyzzNFg86fwFnV",
+					VestingID:  "KT1NtGnEjacAkBph7k9HWVrN38PoYjcXTxdY",
+					Type:       models.VestingRevoke,
+				},
+			},
+
+			expResult: "05070707070a000000049caecab90a00000016017f1df41f643db8039663fd5eb3b025e07efbaf3d000707000005050508020000006c0320053d036d0743036e0a00000016019ce13845659ff2582555ec08dc322007f6493e80000655036c00000007257265766f6b65072f02000000200743036801000000156e6f74207265766f6361626c652076657374696e67032702000000000743036a0000034f034d031b",
 			wantErr:   false,
 		},
 		{
@@ -233,9 +247,11 @@ func Test_BuildContractSignPayload(t *testing.T) {
 		},
 	}
 
+	actions := testMultisigActions(t)
+
 	for _, test := range testCases {
 		t.Run(test.name, func(t *testing.T) {
-			got, _, gotErr := BuildContractSignPayload(test.args.networkID, test.args.counter, test.args.operationParams)
+			got, _, gotErr := BuildContractSignPayload(test.args.networkID, test.args.counter, actions, test.args.operationParams)
 			if test.wantErr != (gotErr != nil) {
 				t.Errorf("wantErr: %t | results %s == %s | err: %v", test.wantErr, got, test.expResult, gotErr)
 			}
@@ -62,30 +62,14 @@ func buildActionParams(operationParams models.ContractOperationRequest) (actionP
 		if err != nil {
 			return actionParams, err
 		}
-	case models.CustomPayload:
-		actionParams = &micheline.Prim{}
-		if len(operationParams.CustomPayload) == 0 {
-			return actionParams, nil
-		}
-
-		//Hex payload
-		if operationParams.CustomPayload.HasPrefix() {
-			bt, err := operationParams.CustomPayload.MarshalBinary()
-			if err != nil {
-				return actionParams, err
-			}
-			if len(bt) == 0 {
-				return actionParams, nil
-			}
-			//Remove watermark
-			if bt[0] == TextWatermark {
-				bt = bt[1:]
-			}
-
-			err = actionParams.UnmarshalBinary(bt)
-		} else {
-			err = actionParams.UnmarshalJSON([]byte(operationParams.CustomPayload.String()))
+	case models.VestingRevoke:
+		actionParams, err = buildVestingRevokeLambda(operationParams.VestingID)
+		if err != nil {
+			return actionParams, err
 		}
+	//Contract call lambda is built by service from target schema
+	case models.CustomPayload, models.ContractCall:
+		actionParams, err = parseCustomPayload(operationParams.CustomPayload)
 		if err != nil {
 			return actionParams, err
 		}
@@ -260,7 +244,7 @@ func buildTransferTxsPrim(txs []models.Tx) (txsPrim *micheline.Prim, err error)
 						{
 							Type:   micheline.PrimInt,
 							OpCode: micheline.T_INT,
-							Int:    big.NewInt(int64(txs[j].TokenID)),
+							Int:    new(big.Int).SetUint64(txs[j].TokenID),
 						},
 						//Amount
 						{
@@ -344,6 +328,52 @@ func buildVestingTxPrim(operationParams models.ContractOperationRequest) (prim *
 	return prim, err
 }
 
+//Msig vesting action supports only vest and setDelegate, revoke is called by lambda
+//{DROP; NIL operation; PUSH address; CONTRACT %revoke unit; IF_NONE {PUSH string; FAILWITH} {}; PUSH mutez 0; UNIT; TRANSFER_TOKENS; CONS}
+func buildVestingRevokeLambda(vestingContract types.Address) (lambda *micheline.Prim, err error) {
+
+	encodedVestingContract, err := vestingContract.MarshalBinary()
+	if err != nil {
+		return lambda, err
+	}
+
+	lambda = &micheline.Prim{
+		Type: micheline.PrimSequence,
+		Args: []*micheline.Prim{
+			{Type: micheline.PrimNullary, OpCode: micheline.I_DROP},
+			{Type: micheline.PrimUnary, OpCode: micheline.I_NIL, Args: []*micheline.Prim{
+				{Type: micheline.PrimNullary, OpCode: micheline.T_OPERATION},
+			}},
+			{Type: micheline.PrimBinary, OpCode: micheline.I_PUSH, Args: []*micheline.Prim{
+				{Type: micheline.PrimNullary, OpCode: micheline.T_ADDRESS},
+				{Type: micheline.PrimBytes, Bytes: encodedVestingContract},
+			}},
+			{Type: micheline.PrimUnaryAnno, OpCode: micheline.I_CONTRACT, Anno: []string{"%" + revokeEntrypoint}, Args: []*micheline.Prim{
+				{Type: micheline.PrimNullary, OpCode: micheline.T_UNIT},
+			}},
+			{Type: micheline.PrimBinary, OpCode: micheline.I_IF_NONE, Args: []*micheline.Prim{
+				{Type: micheline.PrimSequence, Args: []*micheline.Prim{
+					{Type: micheline.PrimBinary, OpCode: micheline.I_PUSH, Args: []*micheline.Prim{
+						{Type: micheline.PrimNullary, OpCode: micheline.T_STRING},
+						{Type: micheline.PrimString, String: "not revocable vesting"},
+					}},
+					{Type: micheline.PrimNullary, OpCode: micheline.I_FAILWITH},
+				}},
+				{Type: micheline.PrimSequence, Args: []*micheline.Prim{}},
+			}},
+			{Type: micheline.PrimBinary, OpCode: micheline.I_PUSH, Args: []*micheline.Prim{
+				{Type: micheline.PrimNullary, OpCode: micheline.T_MUTEZ},
+				{Type: micheline.PrimInt, Int: big.NewInt(0)},
+			}},
+			{Type: micheline.PrimNullary, OpCode: micheline.I_UNIT},
+			{Type: micheline.PrimNullary, OpCode: micheline.I_TRANSFER_TOKENS},
+			{Type: micheline.PrimNullary, OpCode: micheline.I_CONS},
+		},
+	}
+
+	return lambda, nil
+}
+
 func buildDelegationPrim(paramTo types.Address) (delegationPrim *micheline.Prim, err error) {
 
 	if paramTo.IsEmpty() {
@@ -376,3 +406,30 @@ func buildDelegationPrim(paramTo types.Address) (delegationPrim *micheline.Prim,
 
 	return delegationPrim, nil
 }
+
+//parseCustomPayload decodes hex or json lambda
+func parseCustomPayload(payload types.Payload) (lambda *micheline.Prim, err error) {
+	lambda = &micheline.Prim{}
+	if len(payload) == 0 {
+		return lambda, nil
+	}
+
+	//Hex payload
+	if payload.HasPrefix() {
+		bt, err := payload.MarshalBinary()
+		if err != nil {
+			return lambda, err
+		}
+		if len(bt) == 0 {
+			return lambda, nil
+		}
+		//Remove watermark
+		if bt[0] == TextWatermark {
+			bt = bt[1:]
+		}
+
+		return lambda, lambda.UnmarshalBinary(bt)
+	}
+
+	return lambda, lambda.UnmarshalJSON([]byte(payload.String()))
+}
@@ -8,21 +8,99 @@ import (
 	"blockwatch.cc/tzindex/micheline"
 )
 
-var (
-	transferPath   = []micheline.OpCode{micheline.D_LEFT, micheline.D_LEFT, micheline.D_LEFT, micheline.D_LEFT}
-	delegationPath = []micheline.OpCode{micheline.D_LEFT, micheline.D_LEFT, micheline.D_LEFT, micheline.D_RIGHT}
-	//Transfer have branching inside
-	transferFAPath = []micheline.OpCode{micheline.D_LEFT, micheline.D_LEFT, micheline.D_RIGHT, micheline.D_LEFT}
-	//Vesting have branching inside
-	vestingPath       = []micheline.OpCode{micheline.D_LEFT, micheline.D_LEFT, micheline.D_RIGHT, micheline.D_RIGHT}
-	customPayloadPath = []micheline.OpCode{micheline.D_LEFT, micheline.D_RIGHT}
-	updateStoragePath = []micheline.OpCode{micheline.D_RIGHT}
-)
+//Multisig action branch, found by annotation of branch or of its pair fields, not annotated branch is found by type below scope
+type actionBranch struct {
+	scope  string
+	anno   string
+	opCode micheline.OpCode
+}
+
+//Action branches of resources/contract.tz main_parameter (pair (pair counter actions) sigs)
+var multisigActionBranches = map[models.ActionType]actionBranch{
+	models.Transfer:           {scope: ":direct_action", anno: ":to"},
+	models.Delegation:         {anno: ":delegation"},
+	models.FATransfer:         {anno: ":transferFA"},
+	models.FA2Transfer:        {anno: ":transferFA"},
+	models.VestingVest:        {anno: ":vesting"},
+	models.VestingSetDelegate: {anno: ":vesting"},
+	models.CustomPayload:      {scope: ":actions", opCode: micheline.T_LAMBDA},
+	models.VestingRevoke:      {scope: ":actions", opCode: micheline.T_LAMBDA},
+	models.ContractCall:       {scope: ":actions", opCode: micheline.T_LAMBDA},
+	models.StorageUpdate:      {anno: ":threshold"},
+}
+
+func (b actionBranch) match(p *micheline.Prim) bool {
+	if b.anno == "" {
+		return p.OpCode == b.opCode
+	}
+
+	if hasAnnotation(p, b.anno) {
+		return true
+	}
+
+	//Fields of not annotated pair
+	if p.OpCode != micheline.T_PAIR || len(p.Anno) > 0 {
+		return false
+	}
+
+	leaves, _ := combLeaves(p)
+	for i := range leaves {
+		if hasAnnotation(leaves[i], b.anno) {
+			return true
+		}
+	}
+
+	return false
+}
 
-func buildMichelsonPath(actionType models.ActionType, actionParams *micheline.Prim) (pathParam *micheline.Prim, err error) {
-	path, err := getPathByType(actionType)
+//MultisigActions resolves action branches by parameter schema of deployed msig contract
+type MultisigActions struct {
+	resolver EntrypointResolver
+}
+
+func NewMultisigActions(paramSchema *micheline.Prim) (a MultisigActions, err error) {
+	resolver, err := NewEntrypointResolver(paramSchema)
+	if err != nil {
+		return a, err
+	}
+
+	main, err := resolver.Resolve("%" + MainEntrypoint)
+	if err != nil {
+		return a, err
+	}
+
+	//(pair (pair (nat :counter) actions) (list :sigs (option signature)))
+	if main.OpCode != micheline.T_PAIR || len(main.Prim.Args) != 2 || main.Prim.Args[0].OpCode != micheline.T_PAIR || len(main.Prim.Args[0].Args) != 2 {
+		return a, errors.New("not msig main parameter")
+	}
+
+	a.resolver, err = NewEntrypointResolver(main.Prim.Args[0].Args[1])
 	if err != nil {
-		return pathParam, nil
+		return a, err
+	}
+
+	return a, nil
+}
+
+//Path of action branch from actions root
+func (a MultisigActions) Path(actionType models.ActionType) (path []micheline.OpCode, err error) {
+	branch, ok := multisigActionBranches[actionType]
+	if !ok {
+		return nil, fmt.Errorf("unknown action")
+	}
+
+	entrypoint, err := a.resolver.Find(branch.scope, branch.match)
+	if err != nil {
+		return nil, fmt.Errorf("%s action: %w", actionType, err)
+	}
+
+	return entrypoint.Branch, nil
+}
+
+func buildMichelsonPath(actions MultisigActions, actionType models.ActionType, actionParams *micheline.Prim) (pathParam *micheline.Prim, err error) {
+	path, err := actions.Path(actionType)
+	if err != nil {
+		return pathParam, err
 	}
 
 	pathParam = actionParams
@@ -49,8 +127,8 @@ func buildMichelsonPath(actionType models.ActionType, actionParams *micheline.Pr
 	return pathParam, err
 }
 
-func getMichelsonParamsByActionType(actionType models.ActionType, actionParams *micheline.Prim) (pathParam *micheline.Prim, err error) {
-	path, err := getPathByType(actionType)
+func getMichelsonParamsByActionType(actions MultisigActions, actionType models.ActionType, actionParams *micheline.Prim) (pathParam *micheline.Prim, err error) {
+	path, err := actions.Path(actionType)
 	if err != nil {
 		return pathParam, err
 	}
@@ -67,7 +145,7 @@ func getParamsByPath(params *micheline.Prim, path []micheline.OpCode) (pathParam
 
 	pathParam = params
 
-	var index int64
+	var left, right *micheline.Prim
 	for i := range path {
 
 		//D_LEFT or D_RIGHT prim
@@ -76,72 +154,22 @@ func getParamsByPath(params *micheline.Prim, path []micheline.OpCode) (pathParam
 			continue
 		}
 
-		//D_PAIR prim
-		//EDO format of pair --> compress to normal format
-		if pathParam.OpCode == micheline.D_PAIR && len(pathParam.Args) > 2 {
-
-			pairsCount := (len(pathParam.Args) / 2) + 1
-
-			for i := 0; i < pairsCount; i++ {
-
-				pair, err := compressPair(pathParam.Args[len(pathParam.Args)-2:])
-				if err != nil {
-					return pathParam, err
-				}
-
-				//Remove last elem
-				pathParam.Args = pathParam.Args[:len(pathParam.Args)-1]
-
-				//Replace last element
-				pathParam.Args[len(pathParam.Args)-1] = pair
-			}
+		//Comb literal {a; b; c}
+		if pathParam.Type == micheline.PrimSequence {
+			pathParam = &micheline.Prim{Type: micheline.PrimVariadicAnno, OpCode: micheline.D_PAIR, Args: pathParam.Args}
+		}
 
+		//D_PAIR prim, n-ary pair is split as right comb
+		left, right, err = combSplit(pathParam)
+		if err != nil {
+			return pathParam, err
 		}
 
-		index = 0
+		pathParam = left
 		if path[i] == micheline.D_RIGHT {
-			index = 1
+			pathParam = right
 		}
-
-		pathParam = pathParam.Args[index]
 	}
 
 	return pathParam, nil
 }
-
-func compressPair(param []*micheline.Prim) (pair *micheline.Prim, err error) {
-	if len(param) != 2 {
-		return pair, errors.New("wrong args num")
-	}
-
-	args := make([]*micheline.Prim, len(param))
-	copy(args, param)
-
-	return &micheline.Prim{
-		Type:   micheline.PrimBinary,
-		OpCode: micheline.D_PAIR,
-		Args:   args,
-	}, nil
-}
-
-func getPathByType(actionType models.ActionType) (path []micheline.OpCode, err error) {
-	switch actionType {
-	case models.Transfer:
-		path = transferPath
-	case models.Delegation:
-		path = delegationPath
-	case models.StorageUpdate:
-		path = updateStoragePath
-	case models.FATransfer, models.FA2Transfer:
-		path = transferFAPath
-	case models.VestingVest, models.VestingSetDelegate:
-		path = vestingPath
-	case models.CustomPayload:
-		path = customPayloadPath
-
-	default:
-		return nil, fmt.Errorf("unknown action")
-	}
-
-	return path, nil
-}
@@ -8,6 +8,15 @@ import (
 	"blockwatch.cc/tzindex/micheline"
 )
 
+func testMultisigActions(t *testing.T) MultisigActions {
+	actions, err := NewMultisigActions(testScriptCode(t, "contract.json").Param)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return actions
+}
+
 func Test_BuildMichelsonPath(t *testing.T) {
 	type args struct {
 		actionType   models.ActionType
@@ -35,7 +44,7 @@ func Test_BuildMichelsonPath(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			param := &micheline.Prim{}
 			_ = param.UnmarshalJSON([]byte(test.args.actionParams))
-			got, gotErr := buildMichelsonPath(test.args.actionType, param)
+			got, gotErr := buildMichelsonPath(testMultisigActions(t), test.args.actionType, param)
 			if test.wantErr != (gotErr != nil) {
 				t.Errorf("wantErr: %t | err: %v", test.wantErr, gotErr)
 			}
@@ -85,7 +94,7 @@ func Test_GetMichelsonParamsByPath(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			param := &micheline.Prim{}
 			_ = param.UnmarshalJSON([]byte(test.args.actionParams))
-			got, gotErr := getMichelsonParamsByActionType(test.args.actionType, param)
+			got, gotErr := getMichelsonParamsByActionType(testMultisigActions(t), test.args.actionType, param)
 			if test.wantErr != (gotErr != nil) {
 				t.Errorf("wantErr: %t | err: %v", test.wantErr, gotErr)
 			}
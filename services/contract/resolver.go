@@ -0,0 +1,162 @@
+package contract
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"blockwatch.cc/tzindex/micheline"
+)
+
+//Annotation path separator, annotations can contain dots
+const annotationPathSeparator = "/"
+
+//Explicit branch steps of annotation path
+const (
+	leftBranchStep  = "Left"
+	rightBranchStep = "Right"
+)
+
+//EntrypointResolver resolves annotation paths to Left/Right branches of parameter or tree
+type EntrypointResolver struct {
+	root *micheline.Prim
+}
+
+func NewEntrypointResolver(paramType *micheline.Prim) (r EntrypointResolver, err error) {
+	if paramType == nil {
+		return r, errors.New("empty parameter type")
+	}
+
+	return EntrypointResolver{root: NormalizeComb(sectionArg(paramType))}, nil
+}
+
+//Resolve converts path like ":actions/%transfer/Left" to branch from root.
+//Annotation segment matches single or branch at any depth below previous segment, name without prefix matches any annotation kind
+func (r EntrypointResolver) Resolve(path string) (entrypoint Entrypoint, err error) {
+	node := r.root
+	branch := []micheline.OpCode{}
+
+	if path != "" {
+		for _, segment := range strings.Split(path, annotationPathSeparator) {
+			switch segment {
+			case leftBranchStep, rightBranchStep:
+				if !isOrType(node) {
+					return entrypoint, fmt.Errorf("%s: not or branch", path)
+				}
+
+				isLeft := segment == leftBranchStep
+				branch = append(branch, branchOpCode(isLeft))
+				node = node.Args[branchIndex(isLeft)]
+			default:
+				matches := findAnnotatedBranches(node, segment, nil)
+				switch {
+				case len(matches) == 0:
+					return entrypoint, fmt.Errorf("%s: %s not found", path, segment)
+				case len(matches) > 1:
+					return entrypoint, fmt.Errorf("%s: %s is ambiguous", path, segment)
+				}
+
+				branch = append(branch, matches[0].Branch...)
+				node = matches[0].Prim
+			}
+		}
+	}
+
+	return Entrypoint{Branch: branch, OpCode: node.OpCode, Prim: node}, nil
+}
+
+//Find returns single or branch below path accepted by match
+func (r EntrypointResolver) Find(path string, match func(branch *micheline.Prim) bool) (entrypoint Entrypoint, err error) {
+	scope, err := r.Resolve(path)
+	if err != nil {
+		return entrypoint, err
+	}
+
+	var matches []Entrypoint
+	for _, branch := range findAnnotatedBranches(scope.Prim, "", nil) {
+		if match(branch.Prim) {
+			matches = append(matches, branch)
+		}
+	}
+
+	switch {
+	case len(matches) == 0:
+		return entrypoint, fmt.Errorf("%s: branch not found", path)
+	case len(matches) > 1:
+		return entrypoint, fmt.Errorf("%s: branch is ambiguous", path)
+	}
+
+	entrypoint = matches[0]
+	entrypoint.Branch = append(append([]micheline.OpCode{}, scope.Branch...), entrypoint.Branch...)
+
+	return entrypoint, nil
+}
+
+//Entrypoints returns field annotated or branches by annotation
+func (r EntrypointResolver) Entrypoints() (e Entrypoints, err error) {
+	e = make(Entrypoints)
+
+	for _, entrypoint := range findAnnotatedBranches(r.root, "", nil) {
+		name := entrypoint.Prim.GetVarAnno()
+		if name == "" {
+			continue
+		}
+
+		if _, ok := e[name]; ok {
+			return nil, fmt.Errorf("entrypoint %s is ambiguous", name)
+		}
+
+		entrypoint.Id = len(e)
+		e[name] = entrypoint
+	}
+
+	return e, nil
+}
+
+//findAnnotatedBranches walks nested or types, empty annotation matches every branch
+func findAnnotatedBranches(node *micheline.Prim, anno string, branch []micheline.OpCode) (matches []Entrypoint) {
+	if !isOrType(node) {
+		return nil
+	}
+
+	for i, arg := range node.Args {
+		argBranch := make([]micheline.OpCode, len(branch), len(branch)+1)
+		copy(argBranch, branch)
+		argBranch = append(argBranch, branchOpCode(i == 0))
+
+		if anno == "" || hasAnnotation(arg, anno) {
+			matches = append(matches, Entrypoint{Branch: argBranch, OpCode: arg.OpCode, Prim: arg})
+		}
+
+		matches = append(matches, findAnnotatedBranches(arg, anno, argBranch)...)
+	}
+
+	return matches
+}
+
+func hasAnnotation(p *micheline.Prim, anno string) bool {
+	for _, a := range p.Anno {
+		if a == anno {
+			return true
+		}
+
+		//Name without prefix
+		if len(a) > 1 && a[1:] == anno {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isOrType(p *micheline.Prim) bool {
+	return p.OpCode == micheline.T_OR && len(p.Args) == 2
+}
+
+func branchOpCode(isLeft bool) micheline.OpCode {
+	if isLeft {
+		return micheline.D_LEFT
+	}
+
+	return micheline.D_RIGHT
+}
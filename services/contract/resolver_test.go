@@ -0,0 +1,152 @@
+package contract
+
+import (
+	"reflect"
+	"testing"
+	"tezosign/models"
+
+	"blockwatch.cc/tzindex/micheline"
+)
+
+const testResolverParam = `parameter (or (or %admin (nat %setFee) (or :owners (address %addOwner) (address %removeOwner)))
+	(or (pair %transfer (address :to) (nat :value)) (or (unit %default) (unit :stop))))`
+
+func Test_EntrypointResolver(t *testing.T) {
+	L, R := micheline.D_LEFT, micheline.D_RIGHT
+
+	testCases := []struct {
+		name      string
+		path      string
+		expBranch []micheline.OpCode
+		expOpCode micheline.OpCode
+		wantErr   bool
+	}{
+		{name: "Root", path: "", expBranch: []micheline.OpCode{}, expOpCode: micheline.T_OR},
+		{name: "Field", path: "%transfer", expBranch: []micheline.OpCode{R, L}, expOpCode: micheline.T_PAIR},
+		{name: "Nested field", path: "%removeOwner", expBranch: []micheline.OpCode{L, R, R}, expOpCode: micheline.T_ADDRESS},
+		{name: "Type", path: ":stop", expBranch: []micheline.OpCode{R, R, R}, expOpCode: micheline.T_UNIT},
+		{name: "Without prefix", path: "owners", expBranch: []micheline.OpCode{L, R}, expOpCode: micheline.T_OR},
+		{name: "Multi level", path: "%admin/:owners/%addOwner", expBranch: []micheline.OpCode{L, R, L}, expOpCode: micheline.T_ADDRESS},
+		{name: "Branch steps", path: "%admin/Right/Left", expBranch: []micheline.OpCode{L, R, L}, expOpCode: micheline.T_ADDRESS},
+		{name: "Not found", path: "%mint", wantErr: true},
+		{name: "Not found below", path: "%transfer/%setFee", wantErr: true},
+		{name: "Not or", path: "%setFee/Left", wantErr: true},
+		//Pair fields are not or branches
+		{name: "Pair field", path: ":to", wantErr: true},
+	}
+
+	resolver, err := NewEntrypointResolver(parseMichelson(t, testResolverParam))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			entrypoint, err := resolver.Resolve(test.path)
+			if test.wantErr != (err != nil) {
+				t.Fatalf("wantErr: %t | err: %v", test.wantErr, err)
+			}
+
+			if test.wantErr {
+				return
+			}
+
+			if !reflect.DeepEqual(entrypoint.Branch, test.expBranch) || entrypoint.OpCode != test.expOpCode {
+				t.Errorf("got %v %s, want %v %s", entrypoint.Branch, entrypoint.OpCode, test.expBranch, test.expOpCode)
+			}
+		})
+	}
+}
+
+func Test_EntrypointResolverAmbiguity(t *testing.T) {
+	resolver, err := NewEntrypointResolver(parseMichelson(t, "or (nat :amount %a) (or (nat :amount %b) (pair %c (nat %d) (nat %e)))"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = resolver.Resolve(":amount"); err == nil {
+		t.Error("ambiguous annotation is resolved")
+	}
+
+	entrypoints, err := resolver.Entrypoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entrypoints) != 3 || !reflect.DeepEqual(entrypoints["b"].Branch, []micheline.OpCode{micheline.D_RIGHT, micheline.D_LEFT}) {
+		t.Errorf("entrypoints: %+v", entrypoints)
+	}
+
+	resolver, err = NewEntrypointResolver(parseMichelson(t, "or (nat %a) (or (nat %b) (unit %a))"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = resolver.Entrypoints(); err == nil {
+		t.Error("duplicated entrypoint is not detected")
+	}
+}
+
+func Test_EntrypointResolverFind(t *testing.T) {
+	resolver, err := NewEntrypointResolver(parseMichelson(t, testResolverParam))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	isUnit := func(p *micheline.Prim) bool { return p.OpCode == micheline.T_UNIT }
+	isAddress := func(p *micheline.Prim) bool { return p.OpCode == micheline.T_ADDRESS }
+
+	entrypoint, err := resolver.Find("%admin", isAddress)
+	if err == nil {
+		t.Errorf("ambiguous branch is found: %v", entrypoint.Branch)
+	}
+
+	entrypoint, err = resolver.Find("%transfer", isUnit)
+	if err == nil {
+		t.Errorf("branch is found below not or: %v", entrypoint.Branch)
+	}
+
+	entrypoint, err = resolver.Find("%admin/:owners", func(p *micheline.Prim) bool { return hasAnnotation(p, "removeOwner") })
+	if err != nil || !reflect.DeepEqual(entrypoint.Branch, []micheline.OpCode{micheline.D_LEFT, micheline.D_RIGHT, micheline.D_RIGHT}) {
+		t.Errorf("err: %v | branch: %v", err, entrypoint.Branch)
+	}
+}
+
+func Test_MultisigActionPaths(t *testing.T) {
+	L, R := micheline.D_LEFT, micheline.D_RIGHT
+
+	expPaths := map[models.ActionType][]micheline.OpCode{
+		models.Transfer:           {L, L, L, L},
+		models.Delegation:         {L, L, L, R},
+		models.FATransfer:         {L, L, R, L},
+		models.FA2Transfer:        {L, L, R, L},
+		models.VestingVest:        {L, L, R, R},
+		models.VestingSetDelegate: {L, L, R, R},
+		models.CustomPayload:      {L, R},
+		models.VestingRevoke:      {L, R},
+		models.ContractCall:       {L, R},
+		models.StorageUpdate:      {R},
+	}
+
+	actions := testMultisigActions(t)
+
+	for actionType, expPath := range expPaths {
+		path, err := actions.Path(actionType)
+		if err != nil {
+			t.Fatalf("%s: %v", actionType, err)
+		}
+
+		if !reflect.DeepEqual(path, expPath) {
+			t.Errorf("%s: got %v, want %v", actionType, path, expPath)
+		}
+	}
+
+	if _, err := actions.Path(models.IncomeTransfer); err == nil {
+		t.Error("income transfer path is resolved")
+	}
+
+	//Not msig parameter
+	if _, err := NewMultisigActions(parseMichelson(t, testResolverParam)); err == nil {
+		t.Error("actions are resolved for not msig contract")
+	}
+}
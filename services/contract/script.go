@@ -48,13 +48,23 @@ func CheckFATransferMethod(script *micheline.Script, faType models.AssetType) (o
 	return true
 }
 
-func GetBigMapKeyHash(tokenID int64) (hash string, err error) {
-	p := micheline.Prim{
+func GetBigMapKeyHash(tokenID uint64) (hash string, err error) {
+	return getPrimKeyHash(micheline.Prim{
 		Type:   micheline.PrimInt,
 		OpCode: micheline.T_INT,
-		Int:    big.NewInt(tokenID),
-	}
+		Int:    new(big.Int).SetUint64(tokenID),
+	})
+}
+
+func GetBigMapStringKeyHash(key string) (hash string, err error) {
+	return getPrimKeyHash(micheline.Prim{
+		Type:   micheline.PrimString,
+		OpCode: micheline.T_STRING,
+		String: key,
+	})
+}
 
+func getPrimKeyHash(p micheline.Prim) (hash string, err error) {
 	bt, err := p.MarshalBinary()
 	if err != nil {
 		return hash, err
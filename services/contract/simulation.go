@@ -0,0 +1,75 @@
+package contract
+
+import (
+	"errors"
+	"tezosign/models"
+
+	"blockwatch.cc/tzindex/micheline"
+)
+
+//Normalized annotation of main entrypoint
+const mainParameterEntrypoint = "mainparameter"
+
+//Placeholder of signatures checked by deterministic CHECK_SIGNATURE
+var simulationSignature = make([]byte, 64)
+
+//SimulateContractOperation runs msig contract code with operation parameter signed by threshold of owners
+func SimulateContractOperation(script micheline.Script, chainID string, counter int64, req models.ContractOperationRequest, env ScriptEnv) (result ScriptResult, err error) {
+	err = TypecheckScript(script.Code)
+	if err != nil {
+		return result, err
+	}
+
+	storage, err := NewContractStorageContainer(script)
+	if err != nil {
+		return result, err
+	}
+
+	actions, err := NewMultisigActions(script.Code.Param)
+	if err != nil {
+		return result, err
+	}
+
+	action, err := buildActionMichelsonArgs(counter, actions, req)
+	if err != nil {
+		return result, err
+	}
+
+	sigs := make([]*micheline.Prim, len(storage.PubKeys()))
+	for i := range sigs {
+		sigs[i] = &micheline.Prim{Type: micheline.PrimNullary, OpCode: micheline.D_NONE}
+		if int64(i) < storage.Threshold() {
+			sigs[i] = valuePrim(micheline.D_SOME, &micheline.Prim{Type: micheline.PrimBytes, Bytes: simulationSignature})
+		}
+	}
+
+	parameter, err := mainParameter(script.Code.Param, pairPrim(action, &micheline.Prim{Type: micheline.PrimSequence, Args: sigs}))
+	if err != nil {
+		return result, err
+	}
+
+	env.ChainID = chainID
+	env.Self = req.ContractID
+
+	return RunScript(script.Code, parameter, script.Storage, env)
+}
+
+//mainParameter wraps value into or branches of main entrypoint
+func mainParameter(paramType, value *micheline.Prim) (*micheline.Prim, error) {
+	e, err := InitAnnotsEntrypoints(paramType)
+	if err != nil {
+		return nil, err
+	}
+
+	entrypoint, ok := e[mainParameterEntrypoint]
+	if !ok {
+		return nil, errors.New("main entrypoint not found")
+	}
+
+	for i := len(entrypoint.Branch) - 1; i >= 0; i-- {
+		value = valuePrim(entrypoint.Branch[i], value)
+	}
+
+	return value, nil
+}
+
@@ -0,0 +1,335 @@
+package contract
+
+import (
+	"fmt"
+
+	"blockwatch.cc/tzindex/micheline"
+)
+
+//Upper bound of numeric arguments of stack instructions
+const maxStackArg = 1023
+
+//operandTypes lists accepted types of consumed values, top value first, nil accepts any type
+var operandTypes = map[micheline.OpCode][][]micheline.OpCode{
+	micheline.I_IF:               {{micheline.T_BOOL}},
+	micheline.I_LOOP:             {{micheline.T_BOOL}},
+	micheline.I_IF_NONE:          {{micheline.T_OPTION}},
+	micheline.I_IF_LEFT:          {{micheline.T_OR}},
+	micheline.I_LOOP_LEFT:        {{micheline.T_OR}},
+	micheline.I_IF_CONS:          {{micheline.T_LIST}},
+	micheline.I_ITER:             {{micheline.T_LIST, micheline.T_SET, micheline.T_MAP}},
+	micheline.I_MAP:              {{micheline.T_LIST, micheline.T_MAP}},
+	micheline.I_CONS:             {nil, {micheline.T_LIST}},
+	micheline.I_EXEC:             {nil, {micheline.T_LAMBDA}},
+	micheline.I_APPLY:            {nil, {micheline.T_LAMBDA}},
+	micheline.I_CONTRACT:         {{micheline.T_ADDRESS}},
+	micheline.I_IMPLICIT_ACCOUNT: {{micheline.T_KEY_HASH}},
+	micheline.I_ADDRESS:          {{micheline.T_CONTRACT}},
+	micheline.I_TRANSFER_TOKENS:  {nil, {micheline.T_MUTEZ}, {micheline.T_CONTRACT}},
+	micheline.I_SET_DELEGATE:     {{micheline.T_OPTION}},
+	micheline.I_CREATE_CONTRACT:  {{micheline.T_OPTION}, {micheline.T_MUTEZ}, nil},
+	micheline.I_COMPARE:          {nil, nil},
+	micheline.I_EQ:               {{micheline.T_INT}},
+	micheline.I_NEQ:              {{micheline.T_INT}},
+	micheline.I_LT:               {{micheline.T_INT}},
+	micheline.I_GT:               {{micheline.T_INT}},
+	micheline.I_LE:               {{micheline.T_INT}},
+	micheline.I_GE:               {{micheline.T_INT}},
+	micheline.I_NOT:              {{micheline.T_BOOL, micheline.T_NAT, micheline.T_INT}},
+	micheline.I_AND:              {{micheline.T_BOOL, micheline.T_NAT, micheline.T_INT}, {micheline.T_BOOL, micheline.T_NAT}},
+	micheline.I_OR:               {{micheline.T_BOOL, micheline.T_NAT}, {micheline.T_BOOL, micheline.T_NAT}},
+	micheline.I_XOR:              {{micheline.T_BOOL, micheline.T_NAT}, {micheline.T_BOOL, micheline.T_NAT}},
+	micheline.I_LSL:              {{micheline.T_NAT}, {micheline.T_NAT}},
+	micheline.I_LSR:              {{micheline.T_NAT}, {micheline.T_NAT}},
+	micheline.I_ABS:              {{micheline.T_INT}},
+	micheline.I_ISNAT:            {{micheline.T_INT}},
+	micheline.I_INT:              {{micheline.T_NAT}},
+	micheline.I_NEG:              {{micheline.T_INT, micheline.T_NAT}},
+	micheline.I_SIZE:             {{micheline.T_STRING, micheline.T_BYTES, micheline.T_LIST, micheline.T_SET, micheline.T_MAP}},
+	micheline.I_SLICE:            {{micheline.T_NAT}, {micheline.T_NAT}, {micheline.T_STRING, micheline.T_BYTES}},
+	micheline.I_MEM:              {nil, {micheline.T_SET, micheline.T_MAP, micheline.T_BIG_MAP}},
+	micheline.I_GET:              {nil, {micheline.T_MAP, micheline.T_BIG_MAP}},
+	micheline.I_UPDATE:           {nil, {micheline.T_BOOL, micheline.T_OPTION}, {micheline.T_SET, micheline.T_MAP, micheline.T_BIG_MAP}},
+	micheline.I_UNPACK:           {{micheline.T_BYTES}},
+	micheline.I_BLAKE2B:          {{micheline.T_BYTES}},
+	micheline.I_SHA256:           {{micheline.T_BYTES}},
+	micheline.I_SHA512:           {{micheline.T_BYTES}},
+	micheline.I_KECCAK:           {{micheline.T_BYTES}},
+	micheline.I_SHA3:             {{micheline.T_BYTES}},
+	micheline.I_HASH_KEY:         {{micheline.T_KEY}},
+	micheline.I_CHECK_SIGNATURE:  {{micheline.T_KEY}, {micheline.T_SIGNATURE}, {micheline.T_BYTES}},
+}
+
+//checkOperands checks types of consumed values, unknown types are accepted.
+//Instructions of variable arity are checked after own pop
+func checkOperands(instr *micheline.Prim, args []symValue) error {
+	accepted := operandTypes[instr.OpCode]
+	if len(args) < len(accepted) {
+		return nil
+	}
+
+	for i := range accepted {
+		if len(accepted[i]) == 0 {
+			continue
+		}
+
+		err := expectType(instr, args[i], accepted[i]...)
+		if err != nil {
+			return err
+		}
+	}
+
+	var isMatched bool
+	switch instr.OpCode {
+	case micheline.I_CONS, micheline.I_EXEC, micheline.I_MEM, micheline.I_GET:
+		isMatched = typeEqual(typeArg(args[1].typ, 0), args[0].typ)
+	case micheline.I_TRANSFER_TOKENS:
+		isMatched = typeEqual(typeArg(args[2].typ, 0), args[0].typ)
+	case micheline.I_UPDATE:
+		isMatched = typeEqual(typeArg(args[2].typ, 0), args[0].typ)
+		switch {
+		case args[2].typ == nil:
+		case args[2].typ.OpCode == micheline.T_SET:
+			isMatched = isMatched && typeEqual(args[1].typ, symType(micheline.T_BOOL))
+		default:
+			isMatched = isMatched && typeEqual(args[1].typ, symType(micheline.T_OPTION, typeArg(args[2].typ, 1)))
+		}
+	case micheline.I_COMPARE:
+		isMatched = typeEqual(args[0].typ, args[1].typ)
+	case micheline.I_AND, micheline.I_OR, micheline.I_XOR:
+		isMatched = args[0].typ == nil || args[1].typ == nil || (args[0].typ.OpCode == micheline.T_BOOL) == (args[1].typ.OpCode == micheline.T_BOOL)
+	case micheline.I_SET_DELEGATE:
+		isMatched = typeEqual(typeArg(args[0].typ, 0), symType(micheline.T_KEY_HASH))
+	default:
+		return nil
+	}
+
+	if !isMatched {
+		return fmt.Errorf("%s: operand types mismatch", instr.OpCode)
+	}
+
+	return nil
+}
+
+func expectType(instr *micheline.Prim, v symValue, opCodes ...micheline.OpCode) error {
+	if v.typ == nil {
+		return nil
+	}
+
+	for _, opCode := range opCodes {
+		if v.typ.OpCode == opCode {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s: unexpected %s value", instr.OpCode, v.typ.OpCode)
+}
+
+//manipulate applies instructions which only move values or build and split combs, returns false for other instructions
+func (s *symStack) manipulate(instr *micheline.Prim) (isApplied bool, err error) {
+	switch instr.OpCode {
+	case micheline.I_DROP:
+		_, err = s.pop(instr, intArg(instr, 1))
+	case micheline.I_DUP:
+		n := intArg(instr, 1)
+		if n < 1 || n > len(*s) {
+			return true, fmt.Errorf("%s: stack underflow", instr.OpCode)
+		}
+		s.push((*s)[len(*s)-n])
+	case micheline.I_SWAP:
+		args, err := s.pop(instr, 2)
+		if err != nil {
+			return true, err
+		}
+		s.push(args[0], args[1])
+	case micheline.I_DIG, micheline.I_DUG:
+		n := intArg(instr, 0)
+		if n < 0 || n >= len(*s) {
+			return true, fmt.Errorf("%s: stack underflow", instr.OpCode)
+		}
+
+		pos := len(*s) - 1 - n
+		if instr.OpCode == micheline.I_DIG {
+			v := (*s)[pos]
+			*s = append((*s)[:pos], (*s)[pos+1:]...)
+			s.push(v)
+		} else {
+			v := (*s)[len(*s)-1]
+			*s = append((*s)[:pos], append(symStack{v}, (*s)[pos:len(*s)-1]...)...)
+		}
+	case micheline.I_PAIR:
+		err = s.pair(instr)
+	case micheline.I_UNPAIR:
+		err = s.unpair(instr)
+	case micheline.I_CAR, micheline.I_CDR:
+		args, err := s.pop(instr, 1)
+		if err != nil {
+			return true, err
+		}
+
+		left, right, err := splitPair(instr, args[0])
+		if err != nil {
+			return true, err
+		}
+
+		if instr.OpCode == micheline.I_CAR {
+			s.push(left)
+		} else {
+			s.push(right)
+		}
+	case micheline.I_CAST:
+		if len(*s) == 0 {
+			return true, fmt.Errorf("%s: stack underflow", instr.OpCode)
+		}
+
+		top := &(*s)[len(*s)-1]
+		if !typeEqual(top.typ, instr.Args[0]) {
+			return true, fmt.Errorf("%s: unexpected %s value", instr.OpCode, top.typ.OpCode)
+		}
+		top.typ = instr.Args[0]
+	case micheline.I_RENAME:
+	default:
+		return false, nil
+	}
+
+	return true, err
+}
+
+//pair folds n top values into right comb
+func (s *symStack) pair(instr *micheline.Prim) error {
+	n := intArg(instr, 2)
+	args, err := s.pop(instr, n)
+	if err != nil || n < 2 {
+		return fmt.Errorf("%s: wrong arity", instr.OpCode)
+	}
+
+	pair := args[n-1]
+	for i := n - 2; i >= 0; i-- {
+		pair = symValue{
+			typ:        symType(micheline.T_PAIR, args[i].typ, pair.typ),
+			value:      valuePrim(micheline.D_PAIR, args[i].value, pair.value),
+			operations: unionOperations(args[i].operations, pair.operations),
+		}
+	}
+	s.push(pair)
+
+	return nil
+}
+
+//unpair splits right comb into n values, n is bounded by comb size
+func (s *symStack) unpair(instr *micheline.Prim) error {
+	n := intArg(instr, 2)
+	if n < 2 {
+		return fmt.Errorf("%s: wrong arity", instr.OpCode)
+	}
+
+	args, err := s.pop(instr, 1)
+	if err != nil {
+		return err
+	}
+
+	if size := combSize(args[0]); size >= 0 && n > size {
+		return fmt.Errorf("%s: comb of %d values", instr.OpCode, size)
+	}
+
+	var parts symStack
+	rest := args[0]
+	for i := 0; i < n-1; i++ {
+		var left symValue
+		left, rest, err = splitPair(instr, rest)
+		if err != nil {
+			return err
+		}
+		parts = append(parts, left)
+	}
+	parts = append(parts, rest)
+
+	for i := len(parts) - 1; i >= 0; i-- {
+		s.push(parts[i])
+	}
+
+	return nil
+}
+
+//combSize counts values of right comb by type or constant, -1 when both are unknown
+func combSize(v symValue) int {
+	p := v.typ
+	if p == nil {
+		p = v.value
+	}
+
+	if p == nil {
+		return -1
+	}
+
+	//Comb literal {a; b; c}
+	if p.Type == micheline.PrimSequence {
+		return len(p.Args)
+	}
+
+	size := 1
+	for {
+		_, right, err := combSplit(p)
+		if err != nil {
+			return size
+		}
+		size, p = size+1, right
+	}
+}
+
+//pop removes n values, top value is the first
+func (s *symStack) pop(instr *micheline.Prim, n int) ([]symValue, error) {
+	if n < 0 || n > len(*s) {
+		return nil, fmt.Errorf("%s: stack underflow", instr.OpCode)
+	}
+
+	values := make([]symValue, n)
+	for i := 0; i < n; i++ {
+		values[i] = (*s)[len(*s)-1-i]
+	}
+	*s = (*s)[:len(*s)-n]
+
+	return values, nil
+}
+
+func (s *symStack) push(values ...symValue) {
+	*s = append(*s, values...)
+}
+
+//intArg reads numeric instruction argument, out of range value is -1
+func intArg(instr *micheline.Prim, defaultValue int) int {
+	if len(instr.Args) == 0 || !isPrimInt(instr.Args[0]) {
+		return defaultValue
+	}
+
+	if !instr.Args[0].Int.IsInt64() || instr.Args[0].Int.Int64() > maxStackArg {
+		return -1
+	}
+
+	return int(instr.Args[0].Int.Int64())
+}
+
+func splitPair(instr *micheline.Prim, v symValue) (left, right symValue, err error) {
+	left.typ, right.typ, err = pairTypes(instr, v.typ)
+	if err != nil {
+		return left, right, err
+	}
+
+	left.operations, right.operations = v.operations, v.operations
+
+	if v.value == nil {
+		return left, right, nil
+	}
+
+	args := v.value.Args
+	if (v.value.OpCode != micheline.D_PAIR && v.value.Type != micheline.PrimSequence) || len(args) < 2 {
+		return left, right, fmt.Errorf("%s: wrong pair value", instr.OpCode)
+	}
+
+	left.value, right.value = args[0], args[1]
+	if len(args) > 2 {
+		right.value = &micheline.Prim{Type: micheline.PrimVariadicAnno, OpCode: micheline.D_PAIR, Args: args[1:]}
+	}
+
+	return left, right, nil
+}
@@ -33,7 +33,7 @@ func BuildContractStorage(threshold uint, pubKeys []types.PubKey) (resp []byte,
 		},
 	}
 
-	return storage.MarshalJSON()
+	return FoldComb(storage).MarshalJSON()
 }
 
 func buildStorageMichelsonArgs(threshold int64, pubKeys []types.PubKey) (actionParams *micheline.Prim, err error) {
@@ -103,23 +103,26 @@ func NewContractStorageContainer(script micheline.Script) (c ContractStorageCont
 		return c, err
 	}
 
-	c.storage = script.Storage
+	c.storage, err = NormalizeCombValue(sectionArg(script.Code.Storage), script.Storage)
+	if err != nil {
+		return c, err
+	}
 
-	counter, err := GetStorageValue(e[counterEntrypoint], script.Storage)
+	counter, err := GetStorageValue(e[counterEntrypoint], c.storage)
 	if err != nil {
 		return c, err
 	}
 
 	c.counter = counter.Int.Int64()
 
-	threshold, err := GetStorageValue(e[thresholdEntrypoint], script.Storage)
+	threshold, err := GetStorageValue(e[thresholdEntrypoint], c.storage)
 	if err != nil {
 		return c, err
 	}
 
 	c.threshold = threshold.Int.Int64()
 
-	keys, err := GetStorageValue(e[keysEntrypoint], script.Storage)
+	keys, err := GetStorageValue(e[keysEntrypoint], c.storage)
 	if err != nil {
 		return c, err
 	}
@@ -180,17 +183,22 @@ func InitAnnotsEntrypoints(prim *micheline.Prim) (e Entrypoints, err error) {
 	}
 
 	e = make(Entrypoints)
-	arg := prim
-	//Use arg instead of naming prim
-	if prim.OpCode >= micheline.K_PARAMETER && prim.OpCode <= micheline.K_CODE {
-		arg = prim.Args[0]
-	}
 
-	dfs(e, newVertex(arg), []micheline.OpCode{})
+	//Paths are built over binary pairs
+	dfs(e, newVertex(NormalizeComb(sectionArg(prim))), []micheline.OpCode{})
 
 	return
 }
 
+//Use arg instead of naming prim
+func sectionArg(prim *micheline.Prim) *micheline.Prim {
+	if prim.OpCode >= micheline.K_PARAMETER && prim.OpCode <= micheline.K_CODE && len(prim.Args) > 0 {
+		return prim.Args[0]
+	}
+
+	return prim
+}
+
 func GetStorageValue(e Entrypoint, storage *micheline.Prim) (*micheline.Prim, error) {
 	return getParamsByPath(storage, e.Branch)
 }
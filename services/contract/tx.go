@@ -80,7 +80,7 @@ func BuildFullTxPayload(payload types.Payload, signatures []types.Signature) (re
 	return resp, MainEntrypoint, nil
 }
 
-func GetOperationCounter(operation Operation) (counter int64, isReject bool, err error) {
+func GetOperationCounter(actions MultisigActions, operation Operation) (counter int64, isReject bool, err error) {
 
 	if operation.Value.OpCode != micheline.D_PAIR {
 		return counter, isReject, errors.New("Wrong input param")
@@ -92,7 +92,7 @@ func GetOperationCounter(operation Operation) (counter int64, isReject bool, err
 
 	counter = operation.Value.Args[0].Args[0].Int.Int64()
 
-	customPayload, err := getMichelsonParamsByActionType(models.CustomPayload, operation.Value.Args[0].Args[1])
+	customPayload, err := getMichelsonParamsByActionType(actions, models.CustomPayload, operation.Value.Args[0].Args[1])
 	if err == nil {
 		rejectOperation, err := customPayload.MarshalJSON()
 		if err != nil {
@@ -18,95 +18,23 @@ import (
 //              (pair (nat %secondsPerTick) (nat %tokensPerTick)))))
 
 func BuildVestingContractStorage(vestingAddress, delegateAdmin types.Address, timestamp int64, secondsPerTick, tokensPerTick uint64) (resp []byte, err error) {
-
-	encodedVestingAddress, err := vestingAddress.MarshalBinary()
-	if err != nil {
-		return nil, err
-	}
-
-	encodedDelegateAdmin, err := delegateAdmin.MarshalBinary()
-	if err != nil {
-		return nil, err
-	}
-
-	storage := &micheline.Prim{
-		Type:   micheline.PrimBinary,
-		OpCode: micheline.D_PAIR,
-		Args: []*micheline.Prim{
-			{
-				//(pair %wrapped (address %target) (address %delegateAdmin))
-				Type:   micheline.PrimBinary,
-				OpCode: micheline.D_PAIR,
-				Args: []*micheline.Prim{
-					//Target address
-					{
-						Type:   micheline.PrimBytes,
-						OpCode: micheline.T_BYTES,
-						Bytes:  encodedVestingAddress,
-					},
-					//Delegate admin address
-					{
-						Type:   micheline.PrimBytes,
-						OpCode: micheline.T_BYTES,
-						Bytes:  encodedDelegateAdmin,
-					},
-				},
-			},
-			{
-				//(pair (nat %vested) ...
-				Type:   micheline.PrimBinary,
-				OpCode: micheline.D_PAIR,
-				Args: []*micheline.Prim{
-					// nat %vested
-					{
-						Type:   micheline.PrimInt,
-						OpCode: micheline.T_NAT,
-						Int:    big.NewInt(0),
-					},
-					//(pair %schedule (timestamp %epoch) ...
-					{
-						//(pair (nat %vested) ...
-						Type:   micheline.PrimBinary,
-						OpCode: micheline.D_PAIR,
-						Args: []*micheline.Prim{
-							// timestamp %epoch
-							{
-								Type:   micheline.PrimInt,
-								OpCode: micheline.T_TIMESTAMP,
-								Int:    big.NewInt(int64(timestamp)),
-							},
-							//(pair (nat %secondsPerTick) (nat %tokensPerTick))
-							{
-								Type:   micheline.PrimBinary,
-								OpCode: micheline.D_PAIR,
-								Args: []*micheline.Prim{
-									// nat %secondsPerTick
-									{
-										Type:   micheline.PrimInt,
-										OpCode: micheline.T_NAT,
-										Int:    big.NewInt(int64(secondsPerTick)),
-									},
-									// nat %tokensPerTick
-									{
-										Type:   micheline.PrimInt,
-										OpCode: micheline.T_NAT,
-										Int:    big.NewInt(int64(tokensPerTick)),
-									},
-								},
-							},
-						},
-					},
-				},
-			},
-		},
-	}
-
-	return storage.MarshalJSON()
+	return BuildVestingStorage(models.VestingContractStorageRequest{
+		Template:       models.VestingTemplateTicks,
+		VestingAddress: vestingAddress,
+		DelegateAdmin:  delegateAdmin,
+		Timestamp:      timestamp,
+		SecondsPerTick: secondsPerTick,
+		TokensPerTick:  tokensPerTick,
+	})
 }
 
+//Implicit account tag of binary address
+const publicKeyHashPrefix = 0x00
+
 const (
 	setDelegateEntrypoint = "setDelegate"
 	vestEntrypoint        = "vest"
+	revokeEntrypoint      = "revoke"
 )
 
 func VestingContractParamAndEntrypoint(req models.VestingContractOperation) (arg []byte, entrypoint string, err error) {
@@ -128,6 +56,12 @@ func VestingContractParamAndEntrypoint(req models.VestingContractOperation) (arg
 			Int:    big.NewInt(int64(req.Ticks)),
 		}
 		entrypoint = vestEntrypoint
+	case models.VestingRevoke:
+		prim = &micheline.Prim{
+			Type:   micheline.PrimNullary,
+			OpCode: micheline.D_UNIT,
+		}
+		entrypoint = revokeEntrypoint
 	default:
 		return nil, "", errors.New("wrong request type")
 	}
@@ -140,31 +74,128 @@ func VestingContractParamAndEntrypoint(req models.VestingContractOperation) (arg
 	return arg, entrypoint, nil
 }
 
+//Called vesting entrypoint with decoded argument
+type VestingOperation struct {
+	Type  models.ActionType
+	Ticks uint64
+	//Empty on delegate removal
+	Delegate types.Address
+}
+
+//ParseVestingOperation decodes vest ticks, new delegate or revocation from entrypoint argument
+func ParseVestingOperation(entrypoint string, value *micheline.Prim) (op VestingOperation, err error) {
+	if value == nil {
+		return op, errors.New("empty param")
+	}
+
+	switch entrypoint {
+	case vestEntrypoint:
+		if value.Type != micheline.PrimInt || value.Int == nil || value.Int.Sign() < 0 {
+			return op, errors.New("wrong ticks param")
+		}
+
+		op.Type = models.VestingVest
+		op.Ticks = value.Int.Uint64()
+	case setDelegateEntrypoint:
+		op.Type = models.VestingSetDelegate
+
+		switch value.OpCode {
+		case micheline.D_NONE:
+		case micheline.D_SOME:
+			if len(value.Args) != 1 {
+				return op, errors.New("wrong delegate param")
+			}
+
+			op.Delegate, err = primKeyHash(value.Args[0])
+			if err != nil {
+				return op, err
+			}
+		default:
+			return op, errors.New("wrong delegate param")
+		}
+	case revokeEntrypoint:
+		if value.OpCode != micheline.D_UNIT {
+			return op, errors.New("wrong revoke param")
+		}
+
+		op.Type = models.VestingRevoke
+	default:
+		return op, errors.New("not vesting entrypoint")
+	}
+
+	return op, nil
+}
+
+//key_hash value encoded without address tag
+func primKeyHash(p *micheline.Prim) (address types.Address, err error) {
+	switch p.Type {
+	case micheline.PrimBytes:
+		err = address.UnmarshalBinary(append([]byte{publicKeyHashPrefix}, p.Bytes...))
+		if err != nil {
+			return address, err
+		}
+	case micheline.PrimString:
+		address = types.Address(p.String)
+	default:
+		return address, errors.New("wrong key hash value")
+	}
+
+	return address, address.Validate()
+}
+
 type VestingContractStorageContainer struct {
+	Template       models.VestingTemplate
 	VestingAddress types.Address
 	DelegateAdmin  types.Address
 	VestedTicks    uint64
 	Timestamp      int64
 	SecondsPerTick uint64
 	TokensPerTick  uint64
-	storage        *micheline.Prim
+	//Zero for templates without cliff
+	Cliff           int64
+	RevocationAdmin types.Address
+	Beneficiaries   []models.VestingBeneficiary
+	storage         *micheline.Prim
 }
 
 func (c VestingContractStorageContainer) OpenedTicks() uint64 {
+	now := time.Now().Unix()
 
-	//Vesting process not started yet
-	timeDiff := time.Now().Unix() - c.Timestamp
-	if timeDiff < 0 {
+	//Vesting process not started yet or cliff not passed
+	timeDiff := now - c.Timestamp
+	if timeDiff < 0 || now < c.Cliff {
 		return 0
 	}
 
 	//Calc already opened amount
 	openedTicks := uint64(timeDiff) / c.SecondsPerTick
 
+	if openedTicks < c.VestedTicks {
+		return 0
+	}
+
 	//Subtract already vested ticks
-	openedTicks -= c.VestedTicks
+	return openedTicks - c.VestedTicks
+}
+
+//SplitAmount parts of amount paid to beneficiaries of shared template, division remainder stays on contract
+func (c VestingContractStorageContainer) SplitAmount(amount uint64) (parts []uint64) {
+	totalShares := new(big.Int)
+	for i := range c.Beneficiaries {
+		totalShares.Add(totalShares, new(big.Int).SetUint64(c.Beneficiaries[i].Share))
+	}
+
+	parts = make([]uint64, len(c.Beneficiaries))
+	if totalShares.Sign() == 0 {
+		return parts
+	}
 
-	return openedTicks
+	for i := range c.Beneficiaries {
+		part := new(big.Int).Mul(new(big.Int).SetUint64(amount), new(big.Int).SetUint64(c.Beneficiaries[i].Share))
+		parts[i] = part.Div(part, totalShares).Uint64()
+	}
+
+	return parts
 }
 
 const (
@@ -176,15 +207,6 @@ const (
 	tokensPerTickEntrypoint  = "tokenspertick"
 )
 
-var vestingContractStorageEntrypoints = map[string]micheline.OpCode{
-	targetEntrypoint:         micheline.T_ADDRESS,
-	delegateAdminEntrypoint:  micheline.T_ADDRESS,
-	vestedEntrypoint:         micheline.T_NAT,
-	epochEntrypoint:          micheline.T_TIMESTAMP,
-	secondsPerTickEntrypoint: micheline.T_NAT,
-	tokensPerTickEntrypoint:  micheline.T_NAT,
-}
-
 func NewVestingContractStorageContainer(script micheline.Script) (c VestingContractStorageContainer, err error) {
 
 	e, err := InitAnnotsEntrypoints(script.Code.Storage)
@@ -192,24 +214,23 @@ func NewVestingContractStorageContainer(script micheline.Script) (c VestingContr
 		return c, err
 	}
 
-	err = checkFields(e, vestingContractStorageEntrypoints)
+	name, template, err := detectVestingTemplate(e)
 	if err != nil {
 		return c, err
 	}
 
-	c.storage = script.Storage
-
-	address, err := GetStorageValue(e[targetEntrypoint], script.Storage)
+	c.Template = name
+	c.storage, err = NormalizeCombValue(sectionArg(script.Code.Storage), script.Storage)
 	if err != nil {
 		return c, err
 	}
 
-	err = c.VestingAddress.UnmarshalBinary(address.Bytes)
+	err = template.decode(e, c.storage, &c)
 	if err != nil {
 		return c, err
 	}
 
-	address, err = GetStorageValue(e[delegateAdminEntrypoint], script.Storage)
+	address, err := GetStorageValue(e[delegateAdminEntrypoint], c.storage)
 	if err != nil {
 		return c, err
 	}
@@ -219,26 +240,26 @@ func NewVestingContractStorageContainer(script micheline.Script) (c VestingContr
 		return c, err
 	}
 
-	amount, err := GetStorageValue(e[vestedEntrypoint], script.Storage)
+	amount, err := GetStorageValue(e[vestedEntrypoint], c.storage)
 	if err != nil {
 		return c, err
 	}
 
 	c.VestedTicks = amount.Int.Uint64()
 
-	amount, err = GetStorageValue(e[epochEntrypoint], script.Storage)
+	amount, err = GetStorageValue(e[epochEntrypoint], c.storage)
 	if err != nil {
 		return c, err
 	}
 	c.Timestamp = amount.Int.Int64()
 
-	amount, err = GetStorageValue(e[secondsPerTickEntrypoint], script.Storage)
+	amount, err = GetStorageValue(e[secondsPerTickEntrypoint], c.storage)
 	if err != nil {
 		return c, err
 	}
 	c.SecondsPerTick = amount.Int.Uint64()
 
-	amount, err = GetStorageValue(e[tokensPerTickEntrypoint], script.Storage)
+	amount, err = GetStorageValue(e[tokensPerTickEntrypoint], c.storage)
 	if err != nil {
 		return c, err
 	}
@@ -254,7 +275,7 @@ func CheckVestingContractStorage(script micheline.Script) (ok bool) {
 		return false
 	}
 
-	err = checkFields(e, vestingContractStorageEntrypoints)
+	_, _, err = detectVestingTemplate(e)
 	if err != nil {
 		return false
 	}
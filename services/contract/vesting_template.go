@@ -0,0 +1,290 @@
+package contract
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"sort"
+	"tezosign/models"
+	"tezosign/types"
+
+	"blockwatch.cc/tzindex/micheline"
+)
+
+//Vesting contract layout from resources
+type vestingTemplate struct {
+	//Template specific storage fields
+	fields map[string]micheline.OpCode
+	//Builds (pair %wrapped ...) value
+	wrapped func(req models.VestingContractStorageRequest) (*micheline.Prim, error)
+	//Builds (pair %schedule ...) value
+	schedule func(req models.VestingContractStorageRequest) *micheline.Prim
+	//Reads template specific fields
+	decode func(e Entrypoints, storage *micheline.Prim, c *VestingContractStorageContainer) error
+}
+
+const (
+	cliffEntrypoint           = "cliff"
+	revocationAdminEntrypoint = "revocationadmin"
+	beneficiariesEntrypoint   = "beneficiaries"
+)
+
+//Fields presented in all templates
+var vestingCommonStorageEntrypoints = map[string]micheline.OpCode{
+	delegateAdminEntrypoint:  micheline.T_ADDRESS,
+	vestedEntrypoint:         micheline.T_NAT,
+	epochEntrypoint:          micheline.T_TIMESTAMP,
+	secondsPerTickEntrypoint: micheline.T_NAT,
+	tokensPerTickEntrypoint:  micheline.T_NAT,
+}
+
+var vestingTemplates = map[models.VestingTemplate]vestingTemplate{
+	//(pair %wrapped (address %target) (address %delegateAdmin))
+	models.VestingTemplateTicks: {
+		fields: map[string]micheline.OpCode{
+			targetEntrypoint: micheline.T_ADDRESS,
+		},
+		wrapped:  targetWrapped,
+		schedule: ticksSchedule,
+		decode:   decodeTarget,
+	},
+	//(pair %schedule (timestamp %epoch) (pair (timestamp %cliff) (pair (nat %secondsPerTick) (nat %tokensPerTick))))
+	models.VestingTemplateCliff: {
+		fields: map[string]micheline.OpCode{
+			targetEntrypoint: micheline.T_ADDRESS,
+			cliffEntrypoint:  micheline.T_TIMESTAMP,
+		},
+		wrapped: targetWrapped,
+		schedule: func(req models.VestingContractStorageRequest) *micheline.Prim {
+			schedule := ticksSchedule(req)
+			schedule.Args[1] = pairPrim(intPrim(micheline.T_TIMESTAMP, req.Cliff), schedule.Args[1])
+			return schedule
+		},
+		decode: func(e Entrypoints, storage *micheline.Prim, c *VestingContractStorageContainer) error {
+			cliff, err := GetStorageValue(e[cliffEntrypoint], storage)
+			if err != nil {
+				return err
+			}
+			c.Cliff = cliff.Int.Int64()
+
+			return decodeTarget(e, storage, c)
+		},
+	},
+	//(pair %wrapped (address %target) (pair (address %delegateAdmin) (address %revocationAdmin)))
+	models.VestingTemplateRevocable: {
+		fields: map[string]micheline.OpCode{
+			targetEntrypoint:          micheline.T_ADDRESS,
+			revocationAdminEntrypoint: micheline.T_ADDRESS,
+		},
+		wrapped: func(req models.VestingContractStorageRequest) (*micheline.Prim, error) {
+			wrapped, err := targetWrapped(req)
+			if err != nil {
+				return nil, err
+			}
+
+			revocationAdmin, err := addressPrim(req.RevocationAdmin)
+			if err != nil {
+				return nil, err
+			}
+
+			wrapped.Args[1] = pairPrim(wrapped.Args[1], revocationAdmin)
+			return wrapped, nil
+		},
+		schedule: ticksSchedule,
+		decode: func(e Entrypoints, storage *micheline.Prim, c *VestingContractStorageContainer) error {
+			address, err := GetStorageValue(e[revocationAdminEntrypoint], storage)
+			if err != nil {
+				return err
+			}
+
+			err = c.RevocationAdmin.UnmarshalBinary(address.Bytes)
+			if err != nil {
+				return err
+			}
+
+			return decodeTarget(e, storage, c)
+		},
+	},
+	//(pair %wrapped (map %beneficiaries address nat) (address %delegateAdmin))
+	models.VestingTemplateShared: {
+		fields: map[string]micheline.OpCode{
+			beneficiariesEntrypoint: micheline.T_MAP,
+		},
+		wrapped: func(req models.VestingContractStorageRequest) (*micheline.Prim, error) {
+			beneficiaries, err := beneficiariesPrim(req.Beneficiaries)
+			if err != nil {
+				return nil, err
+			}
+
+			delegateAdmin, err := addressPrim(req.DelegateAdmin)
+			if err != nil {
+				return nil, err
+			}
+
+			return pairPrim(beneficiaries, delegateAdmin), nil
+		},
+		schedule: ticksSchedule,
+		decode: func(e Entrypoints, storage *micheline.Prim, c *VestingContractStorageContainer) error {
+			beneficiaries, err := GetStorageValue(e[beneficiariesEntrypoint], storage)
+			if err != nil {
+				return err
+			}
+
+			c.Beneficiaries = make([]models.VestingBeneficiary, len(beneficiaries.Args))
+			for i, elt := range beneficiaries.Args {
+				if !isPrimPair(elt) || !isPrimInt(elt.Args[1]) {
+					return errors.New("wrong beneficiary value")
+				}
+
+				c.Beneficiaries[i].Address, err = primAddress(elt.Args[0])
+				if err != nil {
+					return err
+				}
+
+				c.Beneficiaries[i].Share = elt.Args[1].Int.Uint64()
+			}
+
+			return nil
+		},
+	},
+}
+
+//detectVestingTemplate picks template with the largest matched set of storage fields
+func detectVestingTemplate(e Entrypoints) (name models.VestingTemplate, template vestingTemplate, err error) {
+	err = checkFields(e, vestingCommonStorageEntrypoints)
+	if err != nil {
+		return name, template, err
+	}
+
+	var matched int
+	var isAmbiguous bool
+	for templateName, t := range vestingTemplates {
+		if checkFields(e, t.fields) != nil {
+			continue
+		}
+
+		switch {
+		case len(t.fields) > matched:
+			name, template, matched, isAmbiguous = templateName, t, len(t.fields), false
+		case len(t.fields) == matched:
+			isAmbiguous = true
+		}
+	}
+
+	if matched == 0 {
+		return name, template, errors.New("unknown vesting template")
+	}
+
+	if isAmbiguous {
+		return name, template, errors.New("ambiguous vesting template")
+	}
+
+	return name, template, nil
+}
+
+//BuildVestingStorage builds initial storage of requested vesting template
+func BuildVestingStorage(req models.VestingContractStorageRequest) (resp []byte, err error) {
+	if req.Template == "" {
+		req.Template = models.VestingTemplateTicks
+	}
+
+	template, ok := vestingTemplates[req.Template]
+	if !ok {
+		return nil, errors.New("unknown vesting template")
+	}
+
+	wrapped, err := template.wrapped(req)
+	if err != nil {
+		return nil, err
+	}
+
+	//(pair %wrapped ...) (pair (nat %vested) (pair %schedule ...))
+	storage := pairPrim(wrapped, pairPrim(intPrim(micheline.T_NAT, 0), template.schedule(req)))
+
+	return FoldComb(storage).MarshalJSON()
+}
+
+func targetWrapped(req models.VestingContractStorageRequest) (*micheline.Prim, error) {
+	target, err := addressPrim(req.VestingAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	delegateAdmin, err := addressPrim(req.DelegateAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	return pairPrim(target, delegateAdmin), nil
+}
+
+func ticksSchedule(req models.VestingContractStorageRequest) *micheline.Prim {
+	return pairPrim(
+		intPrim(micheline.T_TIMESTAMP, req.Timestamp),
+		pairPrim(intPrim(micheline.T_NAT, int64(req.SecondsPerTick)), intPrim(micheline.T_NAT, int64(req.TokensPerTick))),
+	)
+}
+
+func decodeTarget(e Entrypoints, storage *micheline.Prim, c *VestingContractStorageContainer) error {
+	address, err := GetStorageValue(e[targetEntrypoint], storage)
+	if err != nil {
+		return err
+	}
+
+	return c.VestingAddress.UnmarshalBinary(address.Bytes)
+}
+
+//Map literal requires keys sorted by binary address representation
+func beneficiariesPrim(beneficiaries []models.VestingBeneficiary) (*micheline.Prim, error) {
+	elts := make([]*micheline.Prim, len(beneficiaries))
+	for i := range beneficiaries {
+		address, err := addressPrim(beneficiaries[i].Address)
+		if err != nil {
+			return nil, err
+		}
+
+		elts[i] = &micheline.Prim{
+			Type:   micheline.PrimBinary,
+			OpCode: micheline.D_ELT,
+			Args:   []*micheline.Prim{address, intPrim(micheline.T_NAT, int64(beneficiaries[i].Share))},
+		}
+	}
+
+	sort.Slice(elts, func(i, j int) bool {
+		return bytes.Compare(elts[i].Args[0].Bytes, elts[j].Args[0].Bytes) < 0
+	})
+
+	return &micheline.Prim{
+		Type: micheline.PrimSequence,
+		Args: elts,
+	}, nil
+}
+
+func addressPrim(address types.Address) (*micheline.Prim, error) {
+	encoded, err := address.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	return &micheline.Prim{
+		Type:   micheline.PrimBytes,
+		OpCode: micheline.T_BYTES,
+		Bytes:  encoded,
+	}, nil
+}
+
+func intPrim(opCode micheline.OpCode, value int64) *micheline.Prim {
+	return &micheline.Prim{
+		Type:   micheline.PrimInt,
+		OpCode: opCode,
+		Int:    big.NewInt(value),
+	}
+}
+
+func pairPrim(left, right *micheline.Prim) *micheline.Prim {
+	return &micheline.Prim{
+		Type:   micheline.PrimBinary,
+		OpCode: micheline.D_PAIR,
+		Args:   []*micheline.Prim{left, right},
+	}
+}
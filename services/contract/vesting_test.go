@@ -1,16 +1,22 @@
 package contract
 
 import (
+	"math/big"
 	"reflect"
+	"strings"
 	"testing"
+	"tezosign/models"
 	"tezosign/types"
+	"time"
+
+	"blockwatch.cc/tzindex/micheline"
 )
 
 func Test_BuildVestingContractStorage(t *testing.T) {
 	type args struct {
 		vestingAddress types.Address
 		delegateAdmin  types.Address
-		timestamp      uint64
+		timestamp      int64
 		secondsPerTick uint64
 		tokensPerTick  uint64
 	}
@@ -30,7 +36,7 @@ func Test_BuildVestingContractStorage(t *testing.T) {
 				secondsPerTick: 10,
 				tokensPerTick:  10,
 			},
-			expResult: `{"args":[{"args":[{"bytes":"0000221f3e8f57fccf16203bbd5f27590d365b190084"},{"bytes":"0000221f3e8f57fccf16203bbd5f27590d365b190084"}],"prim":"Pair"},{"args":[{"int":"0"},{"args":[{"int":"123"},{"args":[{"int":"10"},{"int":"10"}],"prim":"Pair"}],"prim":"Pair"}],"prim":"Pair"}],"prim":"Pair"}`,
+			expResult: `{"args":[{"args":[{"bytes":"0000221f3e8f57fccf16203bbd5f27590d365b190084"},{"bytes":"0000221f3e8f57fccf16203bbd5f27590d365b190084"}],"prim":"Pair"},{"int":"0"},{"int":"123"},{"int":"10"},{"int":"10"}],"prim":"Pair"}`,
 			wantErr:   false,
 		},
 	}
@@ -48,3 +54,279 @@ func Test_BuildVestingContractStorage(t *testing.T) {
 		})
 	}
 }
+
+func Test_ParseVestingOperation(t *testing.T) {
+	delegate := types.Address("tz1NkT6YCFS3mDo6kfaMFKFrRiA7w2o5dkWp")
+	encoded, err := delegate.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name       string
+		entrypoint string
+		value      *micheline.Prim
+		expOp      VestingOperation
+		wantErr    bool
+	}{
+		{
+			name:       "Vest",
+			entrypoint: "vest",
+			value:      &micheline.Prim{Type: micheline.PrimInt, OpCode: micheline.T_NAT, Int: big.NewInt(5)},
+			expOp:      VestingOperation{Type: models.VestingVest, Ticks: 5},
+		},
+		{
+			name:       "Set delegate",
+			entrypoint: "setDelegate",
+			value: &micheline.Prim{Type: micheline.PrimUnary, OpCode: micheline.D_SOME, Args: []*micheline.Prim{
+				{Type: micheline.PrimBytes, OpCode: micheline.T_BYTES, Bytes: encoded[1:]},
+			}},
+			expOp: VestingOperation{Type: models.VestingSetDelegate, Delegate: delegate},
+		},
+		{
+			name:       "Remove delegate",
+			entrypoint: "setDelegate",
+			value:      &micheline.Prim{Type: micheline.PrimNullary, OpCode: micheline.D_NONE},
+			expOp:      VestingOperation{Type: models.VestingSetDelegate},
+		},
+		{
+			name:       "Revoke",
+			entrypoint: "revoke",
+			value:      &micheline.Prim{Type: micheline.PrimNullary, OpCode: micheline.D_UNIT},
+			expOp:      VestingOperation{Type: models.VestingRevoke},
+		},
+		{
+			name:       "Wrong ticks",
+			entrypoint: "vest",
+			value:      &micheline.Prim{Type: micheline.PrimString, OpCode: micheline.T_STRING, String: "5"},
+			wantErr:    true,
+		},
+		{
+			name:       "Default entrypoint",
+			entrypoint: "default",
+			value:      &micheline.Prim{Type: micheline.PrimNullary, OpCode: micheline.D_UNIT},
+			wantErr:    true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			op, err := ParseVestingOperation(test.entrypoint, test.value)
+			if test.wantErr != (err != nil) {
+				t.Fatalf("wantErr: %t | err: %v", test.wantErr, err)
+			}
+
+			if op != test.expOp {
+				t.Errorf("op: %+v", op)
+			}
+		})
+	}
+}
+
+func Test_VestingTemplates(t *testing.T) {
+	const (
+		scheduleType = `{"prim":"pair","args":[{"prim":"nat","annots":["%vested"]},{"prim":"pair","args":[{"prim":"timestamp","annots":["%epoch"]},SCHEDULE],"annots":["%schedule"]}]}`
+		ticksType    = `{"prim":"pair","args":[{"prim":"nat","annots":["%secondsPerTick"]},{"prim":"nat","annots":["%tokensPerTick"]}]}`
+		targetType   = `{"prim":"pair","args":[{"prim":"address","annots":["%target"]},{"prim":"address","annots":["%delegateAdmin"]}],"annots":["%wrapped"]}`
+	)
+
+	storageType := func(wrapped, schedule string) string {
+		return `{"prim":"pair","args":[` + wrapped + `,` + strings.Replace(scheduleType, "SCHEDULE", schedule, 1) + `]}`
+	}
+
+	beneficiaries := []models.VestingBeneficiary{
+		{Address: "tz1dBT7PKeSDbPK1No7KNhTvrr3XoLe8vKLH", Share: 1},
+		{Address: "KT1NtGnEjacAkBph7k9HWVrN38PoYjcXTxdY", Share: 3},
+	}
+
+	testCases := []struct {
+		name        string
+		req         models.VestingContractStorageRequest
+		storageType string
+		expStorage  VestingContractStorageContainer
+	}{
+		{
+			name: "Ticks",
+			req: models.VestingContractStorageRequest{
+				VestingAddress: "tz1NkT6YCFS3mDo6kfaMFKFrRiA7w2o5dkWp",
+				DelegateAdmin:  "KT1LAuGLiaCF9A72qZtFvVhyzzNFg86fwFnV",
+				Timestamp:      123,
+				SecondsPerTick: 10,
+				TokensPerTick:  5,
+			},
+			storageType: storageType(targetType, ticksType),
+			expStorage: VestingContractStorageContainer{
+				Template:       models.VestingTemplateTicks,
+				VestingAddress: "tz1NkT6YCFS3mDo6kfaMFKFrRiA7w2o5dkWp",
+				DelegateAdmin:  "KT1LAuGLiaCF9A72qZtFvVhyzzNFg86fwFnV",
+				Timestamp:      123,
+				SecondsPerTick: 10,
+				TokensPerTick:  5,
+			},
+		},
+		{
+			name: "Cliff",
+			req: models.VestingContractStorageRequest{
+				Template:       models.VestingTemplateCliff,
+				VestingAddress: "tz1NkT6YCFS3mDo6kfaMFKFrRiA7w2o5dkWp",
+				DelegateAdmin:  "KT1LAuGLiaCF9A72qZtFvVhyzzNFg86fwFnV",
+				Timestamp:      123,
+				SecondsPerTick: 10,
+				TokensPerTick:  5,
+				Cliff:          1000,
+			},
+			storageType: storageType(targetType, `{"prim":"pair","args":[{"prim":"timestamp","annots":["%cliff"]},`+ticksType+`]}`),
+			expStorage: VestingContractStorageContainer{
+				Template:       models.VestingTemplateCliff,
+				VestingAddress: "tz1NkT6YCFS3mDo6kfaMFKFrRiA7w2o5dkWp",
+				DelegateAdmin:  "KT1LAuGLiaCF9A72qZtFvVhyzzNFg86fwFnV",
+				Timestamp:      123,
+				SecondsPerTick: 10,
+				TokensPerTick:  5,
+				Cliff:          1000,
+			},
+		},
+		{
+			name: "Revocable",
+			req: models.VestingContractStorageRequest{
+				Template:        models.VestingTemplateRevocable,
+				VestingAddress:  "tz1NkT6YCFS3mDo6kfaMFKFrRiA7w2o5dkWp",
+				DelegateAdmin:   "KT1LAuGLiaCF9A72qZtFvVhyzzNFg86fwFnV",
+				RevocationAdmin: "KT1NtGnEjacAkBph7k9HWVrN38PoYjcXTxdY",
+				Timestamp:       123,
+				SecondsPerTick:  10,
+				TokensPerTick:   5,
+			},
+			storageType: storageType(`{"prim":"pair","args":[{"prim":"address","annots":["%target"]},{"prim":"pair","args":[{"prim":"address","annots":["%delegateAdmin"]},{"prim":"address","annots":["%revocationAdmin"]}]}],"annots":["%wrapped"]}`, ticksType),
+			expStorage: VestingContractStorageContainer{
+				Template:        models.VestingTemplateRevocable,
+				VestingAddress:  "tz1NkT6YCFS3mDo6kfaMFKFrRiA7w2o5dkWp",
+				DelegateAdmin:   "KT1LAuGLiaCF9A72qZtFvVhyzzNFg86fwFnV",
+				RevocationAdmin: "KT1NtGnEjacAkBph7k9HWVrN38PoYjcXTxdY",
+				Timestamp:       123,
+				SecondsPerTick:  10,
+				TokensPerTick:   5,
+			},
+		},
+		{
+			name: "Shared",
+			req: models.VestingContractStorageRequest{
+				Template:       models.VestingTemplateShared,
+				Beneficiaries:  []models.VestingBeneficiary{beneficiaries[1], beneficiaries[0]},
+				DelegateAdmin:  "KT1LAuGLiaCF9A72qZtFvVhyzzNFg86fwFnV",
+				Timestamp:      123,
+				SecondsPerTick: 10,
+				TokensPerTick:  5,
+			},
+			storageType: storageType(`{"prim":"pair","args":[{"prim":"map","args":[{"prim":"address"},{"prim":"nat"}],"annots":["%beneficiaries"]},{"prim":"address","annots":["%delegateAdmin"]}],"annots":["%wrapped"]}`, ticksType),
+			expStorage: VestingContractStorageContainer{
+				Template: models.VestingTemplateShared,
+				//Sorted by address
+				Beneficiaries:  beneficiaries,
+				DelegateAdmin:  "KT1LAuGLiaCF9A72qZtFvVhyzzNFg86fwFnV",
+				Timestamp:      123,
+				SecondsPerTick: 10,
+				TokensPerTick:  5,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			value, err := BuildVestingStorage(test.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var storage, storageType micheline.Prim
+			if err = storage.UnmarshalJSON(value); err != nil {
+				t.Fatal(err)
+			}
+
+			if err = storageType.UnmarshalJSON([]byte(test.storageType)); err != nil {
+				t.Fatal(err)
+			}
+
+			script := micheline.Script{Code: &micheline.Code{Storage: &storageType}, Storage: &storage}
+			if !CheckVestingContractStorage(script) {
+				t.Fatal("not vesting storage")
+			}
+
+			c, err := NewVestingContractStorageContainer(script)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c.storage = nil
+			if !reflect.DeepEqual(c, test.expStorage) {
+				t.Errorf("storage: %+v", c)
+			}
+		})
+	}
+}
+
+func Test_OpenedTicks(t *testing.T) {
+	now := time.Now().Unix()
+
+	testCases := []struct {
+		name     string
+		storage  VestingContractStorageContainer
+		expTicks uint64
+	}{
+		{
+			name:     "Opened",
+			storage:  VestingContractStorageContainer{Timestamp: now - 100, SecondsPerTick: 10, VestedTicks: 3},
+			expTicks: 7,
+		},
+		{
+			name:    "Not started",
+			storage: VestingContractStorageContainer{Timestamp: now + 100, SecondsPerTick: 10},
+		},
+		{
+			name:    "Before cliff",
+			storage: VestingContractStorageContainer{Timestamp: now - 100, Cliff: now + 100, SecondsPerTick: 10},
+		},
+		{
+			name:     "After cliff",
+			storage:  VestingContractStorageContainer{Timestamp: now - 100, Cliff: now - 50, SecondsPerTick: 10},
+			expTicks: 10,
+		},
+		{
+			name:    "Vested above opened",
+			storage: VestingContractStorageContainer{Timestamp: now - 100, SecondsPerTick: 10, VestedTicks: 20},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			if ticks := test.storage.OpenedTicks(); ticks != test.expTicks {
+				t.Errorf("ticks: %d", ticks)
+			}
+		})
+	}
+}
+
+func Test_SplitAmount(t *testing.T) {
+	storage := VestingContractStorageContainer{Beneficiaries: []models.VestingBeneficiary{
+		{Address: "tz1dBT7PKeSDbPK1No7KNhTvrr3XoLe8vKLH", Share: 1},
+		{Address: "KT1NtGnEjacAkBph7k9HWVrN38PoYjcXTxdY", Share: 3},
+	}}
+
+	testCases := []struct {
+		name     string
+		amount   uint64
+		expParts []uint64
+	}{
+		{name: "Exact", amount: 20, expParts: []uint64{5, 15}},
+		{name: "Remainder", amount: 15, expParts: []uint64{3, 11}},
+		{name: "Zero", amount: 0, expParts: []uint64{0, 0}},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			if parts := storage.SplitAmount(test.amount); !reflect.DeepEqual(parts, test.expParts) {
+				t.Errorf("parts: %v", parts)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"tezosign/common/apperrors"
+	"tezosign/models"
+	"tezosign/services/contract"
+	"tezosign/types"
+)
+
+//contractCallPayload builds lambda calling entrypoint of target contract with converted parameter
+func (s *ServiceFacade) contractCallPayload(req models.ContractOperationRequest) (payload types.Payload, err error) {
+	script, isFound, err := s.indexerRepoProvider.GetIndexer().GetContractScript(req.To)
+	if err != nil {
+		return payload, err
+	}
+
+	if !isFound {
+		return payload, apperrors.New(apperrors.ErrNotFound, "contract")
+	}
+
+	lambda, err := contract.ContractCallLambda(script.ParameterSchema.MichelinePrim(), req.To, req.Entrypoint, req.Amount, req.Parameter)
+	if err != nil {
+		return payload, apperrors.New(apperrors.ErrBadParam, err.Error())
+	}
+
+	bt, err := lambda.MarshalJSON()
+	if err != nil {
+		return payload, err
+	}
+
+	return types.Payload(bt), nil
+}
+
+func (s *ServiceFacade) ContractEntrypoints(contractID types.Address) (entrypoints []models.ContractEntrypoint, err error) {
+	script, isFound, err := s.indexerRepoProvider.GetIndexer().GetContractScript(contractID)
+	if err != nil {
+		return entrypoints, err
+	}
+
+	if !isFound {
+		return entrypoints, apperrors.New(apperrors.ErrNotFound, "contract")
+	}
+
+	entrypoints, err = contract.ContractEntrypointsSchema(script.ParameterSchema.MichelinePrim())
+	if err != nil {
+		return entrypoints, apperrors.New(apperrors.ErrBadParam, err.Error())
+	}
+
+	return entrypoints, nil
+}
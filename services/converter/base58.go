@@ -1,6 +1,8 @@
-package contract
+package converter
 
 import (
+	"tezosign/types"
+
 	"github.com/anchorageoss/tezosprotocol/v2"
 )
 
@@ -13,9 +15,8 @@ const (
 	p256Prefix      = 0x02
 )
 
-//Deprecated file
-//TODO Remove
-func encodeBase58ToPrimBytes(base58 string) (encodedBytes []byte, err error) {
+//EncodeBase58ToPrimBytes converts base58 value to optimized Micheline bytes
+func EncodeBase58ToPrimBytes(base58 string) (encodedBytes []byte, err error) {
 	var prefix tezosprotocol.Base58CheckPrefix
 	prefix, encodedBytes, err = tezosprotocol.Base58CheckDecode(base58)
 	if err != nil {
@@ -44,3 +45,38 @@ func encodeBase58ToPrimBytes(base58 string) (encodedBytes []byte, err error) {
 
 	return encodedBytes, nil
 }
+
+func decodeAddress(bt []byte) (string, error) {
+	var address types.Address
+
+	err := address.UnmarshalBinary(bt)
+	if err != nil {
+		return "", err
+	}
+
+	return address.String(), nil
+}
+
+func decodeKeyHash(bt []byte) (string, error) {
+	return decodeAddress(append([]byte{publicKeyHashPrefix}, bt...))
+}
+
+func decodeKey(bt []byte) (string, error) {
+	var key types.PubKey
+
+	err := key.UnmarshalBinary(bt)
+	if err != nil {
+		return "", err
+	}
+
+	return key.String(), nil
+}
+
+func decodeSignature(bt []byte) (string, error) {
+	return tezosprotocol.Base58CheckEncode(tezosprotocol.PrefixGenericSignature, bt)
+}
+
+func decodeChainID(bt []byte) (string, error) {
+	return tezosprotocol.Base58CheckEncode(tezosprotocol.PrefixChainID, bt)
+}
+
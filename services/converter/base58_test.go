@@ -1,4 +1,4 @@
-package contract
+package converter
 
 import (
 	"encoding/hex"
@@ -85,7 +85,7 @@ func Test_EncodeBase58ToPrimBytes(t *testing.T) {
 
 	for _, test := range testCases {
 		t.Run(test.name, func(t *testing.T) {
-			got, gotErr := encodeBase58ToPrimBytes(test.args.base58)
+			got, gotErr := EncodeBase58ToPrimBytes(test.args.base58)
 			if test.wantErr && gotErr == nil {
 				t.Errorf("wantErr: %t | results %s == %s | err: %v", test.wantErr, got, test.expResult, gotErr)
 			}
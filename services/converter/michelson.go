@@ -0,0 +1,442 @@
+package converter
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"tezosign/types"
+
+	"blockwatch.cc/tzindex/micheline"
+)
+
+//Max line width of printed Michelson
+const printWidth = 80
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenInt
+	tokenString
+	tokenBytes
+	tokenIdent
+	tokenAnnot
+	tokenOpenParen
+	tokenCloseParen
+	tokenOpenBrace
+	tokenCloseBrace
+	tokenSemicolon
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+type michelsonParser struct {
+	tokens []token
+	pos    int
+}
+
+//ParseMichelson parses Michelson expression or script source to Micheline
+func ParseMichelson(src string) (*micheline.Prim, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &michelsonParser{tokens: tokens}
+
+	//Script sections are separated by semicolons without braces
+	var exprs []*micheline.Prim
+	isSequence := false
+	for p.peek().kind != tokenEOF {
+		expr, err := p.application()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+
+		if p.peek().kind == tokenSemicolon {
+			p.next()
+			isSequence = true
+			continue
+		}
+
+		if p.peek().kind != tokenEOF {
+			return nil, p.unexpected()
+		}
+	}
+
+	switch {
+	case len(exprs) == 0:
+		return nil, errors.New("empty expression")
+	case len(exprs) == 1 && !isSequence:
+		return exprs[0], nil
+	}
+
+	return sequencePrim(exprs), nil
+}
+
+func (p *michelsonParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *michelsonParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+
+	return t
+}
+
+func (p *michelsonParser) unexpected() error {
+	t := p.peek()
+	if t.kind == tokenEOF {
+		return errors.New("unexpected end of expression")
+	}
+
+	return fmt.Errorf("unexpected %q at %d", t.value, t.pos)
+}
+
+//application parses prim with annotations and arguments or single atom
+func (p *michelsonParser) application() (*micheline.Prim, error) {
+	if p.peek().kind != tokenIdent {
+		return p.atom()
+	}
+
+	prim, err := p.prim()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokenAnnot {
+		prim.Anno = append(prim.Anno, p.next().value)
+	}
+
+	for {
+		switch p.peek().kind {
+		case tokenInt, tokenString, tokenBytes, tokenIdent, tokenOpenParen, tokenOpenBrace:
+			arg, err := p.atom()
+			if err != nil {
+				return nil, err
+			}
+			prim.Args = append(prim.Args, arg)
+			continue
+		}
+		break
+	}
+
+	prim.Type = types.PrimTypeFromArgs(len(prim.Args), len(prim.Anno) > 0)
+
+	return prim, nil
+}
+
+func (p *michelsonParser) prim() (*micheline.Prim, error) {
+	t := p.next()
+
+	opCode, err := micheline.ParseOpCode(t.value)
+	if err != nil {
+		return nil, fmt.Errorf("unknown primitive %s at %d", t.value, t.pos)
+	}
+
+	return &micheline.Prim{Type: micheline.PrimNullary, OpCode: opCode}, nil
+}
+
+func (p *michelsonParser) atom() (*micheline.Prim, error) {
+	t := p.peek()
+
+	switch t.kind {
+	case tokenInt:
+		p.next()
+		value, ok := new(big.Int).SetString(t.value, 10)
+		if !ok {
+			return nil, fmt.Errorf("wrong int %s at %d", t.value, t.pos)
+		}
+		return intPrim(value), nil
+	case tokenString:
+		p.next()
+		return stringPrim(t.value), nil
+	case tokenBytes:
+		p.next()
+		value, err := hex.DecodeString(t.value[2:])
+		if err != nil {
+			return nil, fmt.Errorf("wrong bytes %s at %d", t.value, t.pos)
+		}
+		return bytesPrim(value), nil
+	case tokenIdent:
+		return p.prim()
+	case tokenOpenParen:
+		p.next()
+		expr, err := p.application()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.next().kind != tokenCloseParen {
+			return nil, fmt.Errorf("unclosed parenthesis at %d", t.pos)
+		}
+		return expr, nil
+	case tokenOpenBrace:
+		p.next()
+		return p.sequence(t)
+	}
+
+	return nil, p.unexpected()
+}
+
+func (p *michelsonParser) sequence(open token) (*micheline.Prim, error) {
+	args := []*micheline.Prim{}
+
+	for {
+		switch p.peek().kind {
+		case tokenCloseBrace:
+			p.next()
+			return sequencePrim(args), nil
+		case tokenEOF:
+			return nil, fmt.Errorf("unclosed brace at %d", open.pos)
+		}
+
+		expr, err := p.application()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, expr)
+
+		switch p.peek().kind {
+		case tokenSemicolon:
+			p.next()
+		case tokenCloseBrace:
+		default:
+			return nil, p.unexpected()
+		}
+	}
+}
+
+func tokenize(src string) (tokens []token, err error) {
+	for i := 0; i < len(src); {
+		c := src[i]
+		start := i
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+			continue
+		case c == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			continue
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed comment at %d", i)
+			}
+			i += end + 4
+			continue
+		case c == '(' || c == ')' || c == '{' || c == '}' || c == ';':
+			kind := map[byte]tokenKind{'(': tokenOpenParen, ')': tokenCloseParen, '{': tokenOpenBrace, '}': tokenCloseBrace, ';': tokenSemicolon}[c]
+			tokens = append(tokens, token{kind: kind, value: string(c), pos: i})
+			i++
+			continue
+		case c == '"':
+			var value string
+			value, i, err = readString(src, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, value: value, pos: start})
+			continue
+		case strings.HasPrefix(src[i:], "0x"):
+			i += 2
+			for i < len(src) && isHexDigit(src[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenBytes, value: src[start:i], pos: start})
+			continue
+		case c == '-' || isDigit(c):
+			i++
+			for i < len(src) && isDigit(src[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenInt, value: src[start:i], pos: start})
+			continue
+		case c == '%' || c == '@' || c == ':':
+			i++
+			for i < len(src) && isAnnotChar(src[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenAnnot, value: src[start:i], pos: start})
+			continue
+		case isLetter(c):
+			for i < len(src) && (isLetter(src[i]) || isDigit(src[i])) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, value: src[start:i], pos: start})
+			continue
+		}
+
+		return nil, fmt.Errorf("unexpected %q at %d", c, i)
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(src)}), nil
+}
+
+//readString reads quoted string with escapes, returns position after closing quote
+func readString(src string, start int) (string, int, error) {
+	var value strings.Builder
+
+	for i := start + 1; i < len(src); i++ {
+		switch src[i] {
+		case '"':
+			return value.String(), i + 1, nil
+		case '\n':
+			return "", i, fmt.Errorf("unclosed string at %d", start)
+		case '\\':
+			i++
+			if i == len(src) {
+				break
+			}
+
+			escaped, ok := map[byte]byte{'"': '"', '\\': '\\', 'n': '\n', 'r': '\r', 't': '\t', 'b': '\b'}[src[i]]
+			if !ok {
+				return "", i, fmt.Errorf("wrong escape at %d", i)
+			}
+			value.WriteByte(escaped)
+		default:
+			value.WriteByte(src[i])
+		}
+	}
+
+	return "", len(src), fmt.Errorf("unclosed string at %d", start)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_'
+}
+
+func isAnnotChar(c byte) bool {
+	return isLetter(c) || isDigit(c) || c == '.' || c == '%' || c == '@'
+}
+
+//PrintMichelson formats Micheline as indented Michelson source
+func PrintMichelson(p *micheline.Prim) string {
+	//Script sections are printed without braces
+	if isScript(p) {
+		sections := make([]string, len(p.Args))
+		for i := range p.Args {
+			sections[i] = printExpr(p.Args[i], 0, false) + " ;"
+		}
+		return strings.Join(sections, "\n")
+	}
+
+	return printExpr(p, 0, false)
+}
+
+func isScript(p *micheline.Prim) bool {
+	if p.Type != micheline.PrimSequence || len(p.Args) == 0 {
+		return false
+	}
+
+	for _, arg := range p.Args {
+		if !arg.OpCode.IsKey() || arg.Type == micheline.PrimSequence || arg.Type == micheline.PrimInt {
+			return false
+		}
+	}
+
+	return true
+}
+
+//printExpr breaks expression into lines when it does not fit the width
+func printExpr(p *micheline.Prim, indent int, isArg bool) string {
+	inline := printInline(p, isArg)
+	if indent+len(inline) <= printWidth {
+		return inline
+	}
+
+	switch p.Type {
+	case micheline.PrimInt, micheline.PrimString, micheline.PrimBytes:
+		return inline
+	case micheline.PrimSequence:
+		var b strings.Builder
+		b.WriteString("{ ")
+		for i, arg := range p.Args {
+			if i > 0 {
+				b.WriteString(" ;\n" + strings.Repeat(" ", indent+2))
+			}
+			b.WriteString(printExpr(arg, indent+2, false))
+		}
+		b.WriteString(" }")
+		return b.String()
+	}
+
+	//Arguments are aligned under the prim name
+	argsIndent := indent + 2
+	var b strings.Builder
+	if isArg {
+		b.WriteString("(")
+		argsIndent++
+	}
+
+	b.WriteString(strings.Join(append([]string{p.OpCode.String()}, p.Anno...), " "))
+	for _, arg := range p.Args {
+		b.WriteString("\n" + strings.Repeat(" ", argsIndent))
+		b.WriteString(printExpr(arg, argsIndent, true))
+	}
+
+	if isArg {
+		b.WriteString(")")
+	}
+
+	return b.String()
+}
+
+func printInline(p *micheline.Prim, isArg bool) string {
+	switch p.Type {
+	case micheline.PrimInt:
+		return p.Int.String()
+	case micheline.PrimString:
+		return quoteString(p.String)
+	case micheline.PrimBytes:
+		return "0x" + hex.EncodeToString(p.Bytes)
+	case micheline.PrimSequence:
+		if len(p.Args) == 0 {
+			return "{}"
+		}
+
+		args := make([]string, len(p.Args))
+		for i := range p.Args {
+			args[i] = printInline(p.Args[i], false)
+		}
+		return "{ " + strings.Join(args, " ; ") + " }"
+	}
+
+	parts := append([]string{p.OpCode.String()}, p.Anno...)
+	for i := range p.Args {
+		parts = append(parts, printInline(p.Args[i], true))
+	}
+
+	expr := strings.Join(parts, " ")
+	if isArg && len(parts) > 1 {
+		return "(" + expr + ")"
+	}
+
+	return expr
+}
+
+func quoteString(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`, "\b", `\b`)
+	return `"` + replacer.Replace(value) + `"`
+}
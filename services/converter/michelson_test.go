@@ -0,0 +1,173 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"blockwatch.cc/tzindex/micheline"
+)
+
+func TestParseMichelson_Resources(t *testing.T) {
+	for _, name := range []string{"contract", "vesting", "vesting_cliff", "vesting_revocable", "vesting_shared"} {
+		t.Run(name, func(t *testing.T) {
+			src, err := ioutil.ReadFile("../../resources/" + name + ".tz")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			jsonSrc, err := ioutil.ReadFile("../../resources/" + name + ".json")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var sections []*micheline.Prim
+			err = json.Unmarshal(jsonSrc, &sections)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			script, err := ParseMichelson(string(src))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(script.Args) != len(sections) {
+				t.Fatalf("sections: got %d, want %d", len(script.Args), len(sections))
+			}
+
+			//Sections order differs between sources
+			for _, expected := range sections {
+				var parsed *micheline.Prim
+				for _, section := range script.Args {
+					if section.OpCode == expected.OpCode {
+						parsed = section
+					}
+				}
+
+				if parsed == nil {
+					t.Fatalf("section %s not found", expected.OpCode)
+				}
+
+				checkSameBinary(t, parsed, expected)
+			}
+
+			printed, err := ParseMichelson(PrintMichelson(script))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			checkSameBinary(t, printed, script)
+		})
+	}
+}
+
+func TestParseMichelson(t *testing.T) {
+	testCases := []struct {
+		src  string
+		json string
+		err  bool
+	}{
+		{src: "12", json: `{"int":"12"}`},
+		{src: "-7", json: `{"int":"-7"}`},
+		{src: `"a\"b\\c\n"`, json: `{"string":"a\"b\\c\n"}`},
+		{src: "0xBEEF", json: `{"bytes":"beef"}`},
+		{src: "{}", json: `[]`},
+		{src: "Pair 1 (Some \"a\")", json: `{"prim":"Pair","args":[{"int":"1"},{"prim":"Some","args":[{"string":"a"}]}]}`},
+		{src: "pair :t (nat %a) # comment\n (string %b)", json: `{"prim":"pair","args":[{"prim":"nat","annots":["%a"]},{"prim":"string","annots":["%b"]}],"annots":[":t"]}`},
+		{src: "{ DUP @x ; /* comment */ CAR ; }", json: `[{"prim":"DUP","annots":["@x"]},{"prim":"CAR"}]`},
+		{src: "parameter unit ; storage unit", json: `[{"prim":"parameter","args":[{"prim":"unit"}]},{"prim":"storage","args":[{"prim":"unit"}]}]`},
+		{src: "", err: true},
+		{src: "FOO", err: true},
+		{src: "{ DUP", err: true},
+		{src: "(Pair 1 2", err: true},
+		{src: `"abc`, err: true},
+		{src: `"\q"`, err: true},
+		{src: "Pair 1 2 }", err: true},
+		{src: "/* 1", err: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.src, func(t *testing.T) {
+			parsed, err := ParseMichelson(tc.src)
+			if tc.err {
+				if err == nil {
+					t.Errorf("expected error, got %+v", parsed)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected := &micheline.Prim{}
+			err = expected.UnmarshalJSON([]byte(tc.json))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			checkSameBinary(t, parsed, expected)
+		})
+	}
+}
+
+func TestPrintMichelson(t *testing.T) {
+	testCases := []struct {
+		src      string
+		expected string
+	}{
+		{src: "Pair 1 (Some \"a\\nb\")", expected: `Pair 1 (Some "a\nb")`},
+		{src: "{ DROP ; NIL operation }", expected: "{ DROP ; NIL operation }"},
+		{src: "{}", expected: "{}"},
+		{src: "parameter unit ; storage (pair nat nat) ; code { CDR ; NIL operation ; PAIR }", expected: "parameter unit ;\nstorage (pair nat nat) ;\ncode { CDR ; NIL operation ; PAIR } ;"},
+		{
+			src:      `{ PUSH string "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa" ; IF { PUSH nat 1 ; FAILWITH } { UNIT ; DROP } }`,
+			expected: "{ PUSH string \"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa\" ;\n  IF { PUSH nat 1 ; FAILWITH } { UNIT ; DROP } }",
+		},
+		{
+			src:      "or (pair %aaaaaaaaaaaaaaaaaaaa (address %to) (mutez %value)) (option %bbbbbbbbbbbbbbbbbbbbbbbbb key_hash)",
+			expected: "or\n  (pair %aaaaaaaaaaaaaaaaaaaa (address %to) (mutez %value))\n  (option %bbbbbbbbbbbbbbbbbbbbbbbbb key_hash)",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.src, func(t *testing.T) {
+			parsed, err := ParseMichelson(tc.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			printed := PrintMichelson(parsed)
+			if printed != tc.expected {
+				t.Errorf("got\n%s\nwant\n%s", printed, tc.expected)
+			}
+
+			for _, line := range strings.Split(printed, "\n") {
+				if len(line) > printWidth {
+					t.Errorf("line exceeds width: %s", line)
+				}
+			}
+		})
+	}
+}
+
+func checkSameBinary(t *testing.T, got, expected *micheline.Prim) {
+	t.Helper()
+
+	gotBytes, err := got.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedBytes, err := expected.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(gotBytes, expectedBytes) {
+		t.Errorf("got %x, want %x", gotBytes, expectedBytes)
+	}
+}
@@ -0,0 +1,492 @@
+package converter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	"blockwatch.cc/tzindex/micheline"
+)
+
+const (
+	//Packed data prefix
+	packWatermark = 0x05
+	//Binary address length without entrypoint
+	addressBinaryLength = 22
+	signatureLength     = 64
+	chainIDLength       = 4
+)
+
+//Pack type checks value and serializes it like PACK instruction
+func Pack(t, v *micheline.Prim) ([]byte, error) {
+	optimized, err := ToOptimized(t, v)
+	if err != nil {
+		return nil, err
+	}
+
+	bt, err := optimized.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{packWatermark}, bt...), nil
+}
+
+//Unpack deserializes packed value of type to readable form
+func Unpack(t *micheline.Prim, packed []byte) (*micheline.Prim, error) {
+	if len(packed) < 2 || packed[0] != packWatermark {
+		return nil, errors.New("not packed data")
+	}
+
+	v, err := ParseBinary(packed[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	return ToReadable(t, v)
+}
+
+//ParseBinary decodes binary Micheline
+func ParseBinary(bt []byte) (v *micheline.Prim, err error) {
+	//Binary decoder panics on malformed data
+	defer func() {
+		if r := recover(); r != nil {
+			v, err = nil, errors.New("malformed binary data")
+		}
+	}()
+
+	v = &micheline.Prim{}
+	err = v.UnmarshalBinary(bt)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+//ParseExpression parses Micheline JSON or JSON string with Michelson source
+func ParseExpression(raw []byte) (*micheline.Prim, error) {
+	var src string
+	if json.Unmarshal(raw, &src) == nil {
+		return ParseMichelson(src)
+	}
+
+	v := &micheline.Prim{}
+	err := v.UnmarshalJSON(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	//JSON decoder accepts objects without value
+	err = checkExpression(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func checkExpression(v *micheline.Prim) error {
+	if v == nil {
+		return errors.New("empty expression")
+	}
+
+	switch v.Type {
+	case micheline.PrimInt:
+		if v.Int == nil {
+			return errors.New("empty int")
+		}
+	case micheline.PrimString, micheline.PrimBytes:
+	case micheline.PrimSequence:
+	default:
+		if !v.OpCode.IsValid() {
+			return fmt.Errorf("unknown primitive %d", v.OpCode)
+		}
+	}
+
+	for _, arg := range v.Args {
+		if err := checkExpression(arg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//ToOptimized type checks value and converts it to binary form
+func ToOptimized(t, v *micheline.Prim) (*micheline.Prim, error) {
+	return convertValue(t, v, true)
+}
+
+//ToReadable type checks value and converts it to human readable form
+func ToReadable(t, v *micheline.Prim) (*micheline.Prim, error) {
+	return convertValue(t, v, false)
+}
+
+func convertValue(t, v *micheline.Prim, isOptimized bool) (_ *micheline.Prim, err error) {
+	if t == nil || v == nil {
+		return nil, errors.New("empty value")
+	}
+
+	switch t.OpCode {
+	case micheline.T_INT, micheline.T_NAT, micheline.T_MUTEZ:
+		if v.Type != micheline.PrimInt || v.Int == nil {
+			return nil, fmt.Errorf("%s value expected", t.OpCode)
+		}
+
+		if t.OpCode != micheline.T_INT && v.Int.Sign() < 0 {
+			return nil, fmt.Errorf("negative %s value", t.OpCode)
+		}
+
+		if t.OpCode == micheline.T_MUTEZ && v.Int.Cmp(big.NewInt(math.MaxInt64)) > 0 {
+			return nil, errors.New("mutez overflow")
+		}
+
+		return intPrim(v.Int), nil
+	case micheline.T_TIMESTAMP:
+		return convertTimestamp(v, isOptimized)
+	case micheline.T_STRING:
+		if v.Type != micheline.PrimString {
+			return nil, errors.New("string value expected")
+		}
+		return stringPrim(v.String), nil
+	case micheline.T_BYTES:
+		if v.Type != micheline.PrimBytes {
+			return nil, errors.New("bytes value expected")
+		}
+		return bytesPrim(v.Bytes), nil
+	case micheline.T_UNIT:
+		if !isPrim(v, micheline.D_UNIT, 0) {
+			return nil, errors.New("unit value expected")
+		}
+		return nullaryPrim(micheline.D_UNIT), nil
+	case micheline.T_BOOL:
+		if !isPrim(v, micheline.D_TRUE, 0) && !isPrim(v, micheline.D_FALSE, 0) {
+			return nil, errors.New("bool value expected")
+		}
+		return nullaryPrim(v.OpCode), nil
+	case micheline.T_ADDRESS, micheline.T_CONTRACT:
+		return convertBase58(v, isOptimized, encodeAddress, decodeAddressWithEntrypoint)
+	case micheline.T_KEY_HASH:
+		return convertBase58(v, isOptimized, encodeKeyHash, decodeKeyHash)
+	case micheline.T_KEY:
+		return convertBase58(v, isOptimized, encodeKey, decodeKey)
+	case micheline.T_SIGNATURE:
+		return convertBase58(v, isOptimized, encodeSignature, decodeSignature)
+	case micheline.T_CHAIN_ID:
+		return convertBase58(v, isOptimized, encodeChainID, decodeChainID)
+	case micheline.T_OPTION:
+		switch {
+		case len(t.Args) != 1:
+			return nil, errors.New("wrong option type")
+		case isPrim(v, micheline.D_NONE, 0):
+			return nullaryPrim(micheline.D_NONE), nil
+		case isPrim(v, micheline.D_SOME, 1):
+			value, err := convertValue(t.Args[0], v.Args[0], isOptimized)
+			if err != nil {
+				return nil, err
+			}
+			return unaryPrim(micheline.D_SOME, value), nil
+		}
+		return nil, errors.New("option value expected")
+	case micheline.T_OR:
+		if len(t.Args) != 2 || (!isPrim(v, micheline.D_LEFT, 1) && !isPrim(v, micheline.D_RIGHT, 1)) {
+			return nil, errors.New("or value expected")
+		}
+
+		branch := t.Args[0]
+		if v.OpCode == micheline.D_RIGHT {
+			branch = t.Args[1]
+		}
+
+		value, err := convertValue(branch, v.Args[0], isOptimized)
+		if err != nil {
+			return nil, err
+		}
+		return unaryPrim(v.OpCode, value), nil
+	case micheline.T_PAIR:
+		return convertPair(t, v, isOptimized)
+	case micheline.T_LIST, micheline.T_SET:
+		if len(t.Args) != 1 || v.Type != micheline.PrimSequence {
+			return nil, fmt.Errorf("%s value expected", t.OpCode)
+		}
+
+		elems := make([]*micheline.Prim, len(v.Args))
+		for i := range v.Args {
+			elems[i], err = convertValue(t.Args[0], v.Args[i], isOptimized)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return sequencePrim(elems), nil
+	case micheline.T_MAP, micheline.T_BIG_MAP:
+		//Big map is stored by id
+		if t.OpCode == micheline.T_BIG_MAP && v.Type == micheline.PrimInt {
+			return intPrim(v.Int), nil
+		}
+
+		if len(t.Args) != 2 || v.Type != micheline.PrimSequence {
+			return nil, fmt.Errorf("%s value expected", t.OpCode)
+		}
+
+		elems := make([]*micheline.Prim, len(v.Args))
+		for i, elt := range v.Args {
+			if !isPrim(elt, micheline.D_ELT, 2) {
+				return nil, errors.New("map element expected")
+			}
+
+			key, err := convertValue(t.Args[0], elt.Args[0], isOptimized)
+			if err != nil {
+				return nil, err
+			}
+
+			value, err := convertValue(t.Args[1], elt.Args[1], isOptimized)
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = &micheline.Prim{Type: micheline.PrimBinary, OpCode: micheline.D_ELT, Args: []*micheline.Prim{key, value}}
+		}
+		return sequencePrim(elems), nil
+	case micheline.T_LAMBDA:
+		if v.Type != micheline.PrimSequence {
+			return nil, errors.New("lambda code expected")
+		}
+		return v, nil
+	}
+
+	return nil, fmt.Errorf("%s values are not supported", t.OpCode)
+}
+
+func convertTimestamp(v *micheline.Prim, isOptimized bool) (*micheline.Prim, error) {
+	var timestamp time.Time
+
+	switch v.Type {
+	case micheline.PrimInt:
+		if isOptimized || !v.Int.IsInt64() {
+			return intPrim(v.Int), nil
+		}
+		timestamp = time.Unix(v.Int.Int64(), 0).UTC()
+	case micheline.PrimString:
+		var err error
+		timestamp, err = time.Parse(time.RFC3339, v.String)
+		if err != nil {
+			return nil, fmt.Errorf("wrong timestamp %s", v.String)
+		}
+
+		if isOptimized {
+			return intPrim(big.NewInt(timestamp.Unix())), nil
+		}
+	default:
+		return nil, errors.New("timestamp value expected")
+	}
+
+	//RFC3339 supports 4 digit years only
+	if timestamp.Year() < 0 || timestamp.Year() > 9999 {
+		return intPrim(big.NewInt(timestamp.Unix())), nil
+	}
+
+	return stringPrim(timestamp.UTC().Format(time.RFC3339)), nil
+}
+
+//convertBase58 converts base58 string to bytes or back, value is validated in both forms
+func convertBase58(v *micheline.Prim, isOptimized bool, encode func(string) ([]byte, error), decode func([]byte) (string, error)) (*micheline.Prim, error) {
+	var value string
+	var bt []byte
+	var err error
+
+	switch v.Type {
+	case micheline.PrimString:
+		value = v.String
+		bt, err = encode(value)
+	case micheline.PrimBytes:
+		bt = v.Bytes
+		value, err = decode(bt)
+	default:
+		return nil, errors.New("string or bytes value expected")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if isOptimized {
+		return bytesPrim(bt), nil
+	}
+
+	return stringPrim(value), nil
+}
+
+//Right comb pairs are folded to nested binary pairs
+func convertPair(t, v *micheline.Prim, isOptimized bool) (*micheline.Prim, error) {
+	leftType, rightType, err := combSplit(t)
+	if err != nil {
+		return nil, err
+	}
+
+	//Comb literal {a; b; c}
+	if v.Type == micheline.PrimSequence {
+		v = &micheline.Prim{Type: micheline.PrimVariadicAnno, OpCode: micheline.D_PAIR, Args: v.Args}
+	}
+
+	if !isPrim(v, micheline.D_PAIR, len(v.Args)) {
+		return nil, errors.New("pair value expected")
+	}
+
+	leftValue, rightValue, err := combSplit(v)
+	if err != nil {
+		return nil, err
+	}
+
+	left, err := convertValue(leftType, leftValue, isOptimized)
+	if err != nil {
+		return nil, err
+	}
+
+	right, err := convertValue(rightType, rightValue, isOptimized)
+	if err != nil {
+		return nil, err
+	}
+
+	return &micheline.Prim{Type: micheline.PrimBinary, OpCode: micheline.D_PAIR, Args: []*micheline.Prim{left, right}}, nil
+}
+
+func combSplit(p *micheline.Prim) (left, right *micheline.Prim, err error) {
+	if len(p.Args) < 2 {
+		return nil, nil, errors.New("wrong pair")
+	}
+
+	if len(p.Args) == 2 {
+		return p.Args[0], p.Args[1], nil
+	}
+
+	return p.Args[0], &micheline.Prim{Type: micheline.PrimVariadicAnno, OpCode: p.OpCode, Args: p.Args[1:]}, nil
+}
+
+//Address with optional entrypoint suffix
+func encodeAddress(value string) ([]byte, error) {
+	parts := strings.SplitN(value, "%", 2)
+
+	bt, err := EncodeBase58ToPrimBytes(parts[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(bt) != addressBinaryLength || (bt[0] != publicKeyHashPrefix && bt[0] != contractHashPrefix) {
+		return nil, fmt.Errorf("wrong address %s", value)
+	}
+
+	if len(parts) == 2 {
+		bt = append(bt, parts[1]...)
+	}
+
+	return bt, nil
+}
+
+func decodeAddressWithEntrypoint(bt []byte) (string, error) {
+	if len(bt) < addressBinaryLength {
+		return "", errors.New("wrong address bytes")
+	}
+
+	address, err := decodeAddress(bt[:addressBinaryLength])
+	if err != nil {
+		return "", err
+	}
+
+	if len(bt) > addressBinaryLength {
+		address = fmt.Sprintf("%s%%%s", address, bt[addressBinaryLength:])
+	}
+
+	return address, nil
+}
+
+func encodeKeyHash(value string) ([]byte, error) {
+	bt, err := EncodeBase58ToPrimBytes(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(bt) != addressBinaryLength || bt[0] != publicKeyHashPrefix {
+		return nil, fmt.Errorf("wrong key hash %s", value)
+	}
+
+	//Key hash has no address tag
+	return bt[1:], nil
+}
+
+func encodeKey(value string) ([]byte, error) {
+	bt, err := EncodeBase58ToPrimBytes(value)
+	if err != nil {
+		return nil, err
+	}
+
+	//Key is validated by decoding
+	_, err = decodeKey(bt)
+	if err != nil {
+		return nil, fmt.Errorf("wrong key %s", value)
+	}
+
+	return bt, nil
+}
+
+func encodeSignature(value string) ([]byte, error) {
+	bt, err := EncodeBase58ToPrimBytes(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(bt) != signatureLength {
+		return nil, fmt.Errorf("wrong signature %s", value)
+	}
+
+	return bt, nil
+}
+
+func encodeChainID(value string) ([]byte, error) {
+	bt, err := EncodeBase58ToPrimBytes(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(bt) != chainIDLength {
+		return nil, fmt.Errorf("wrong chain id %s", value)
+	}
+
+	return bt, nil
+}
+
+//isPrim checks prim opcode and args count
+func isPrim(v *micheline.Prim, opCode micheline.OpCode, argsCount int) bool {
+	switch v.Type {
+	case micheline.PrimInt, micheline.PrimString, micheline.PrimBytes, micheline.PrimSequence:
+		return false
+	}
+
+	return v.OpCode == opCode && len(v.Args) == argsCount
+}
+
+func intPrim(value *big.Int) *micheline.Prim {
+	return &micheline.Prim{Type: micheline.PrimInt, Int: new(big.Int).Set(value)}
+}
+
+func stringPrim(value string) *micheline.Prim {
+	return &micheline.Prim{Type: micheline.PrimString, String: value}
+}
+
+func bytesPrim(value []byte) *micheline.Prim {
+	return &micheline.Prim{Type: micheline.PrimBytes, Bytes: value}
+}
+
+func nullaryPrim(opCode micheline.OpCode) *micheline.Prim {
+	return &micheline.Prim{Type: micheline.PrimNullary, OpCode: opCode}
+}
+
+func unaryPrim(opCode micheline.OpCode, arg *micheline.Prim) *micheline.Prim {
+	return &micheline.Prim{Type: micheline.PrimUnary, OpCode: opCode, Args: []*micheline.Prim{arg}}
+}
+
+func sequencePrim(args []*micheline.Prim) *micheline.Prim {
+	return &micheline.Prim{Type: micheline.PrimSequence, Args: args}
+}
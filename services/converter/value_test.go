@@ -0,0 +1,156 @@
+package converter
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestPack(t *testing.T) {
+	testCases := []struct {
+		name   string
+		typ    string
+		value  string
+		packed string
+		err    bool
+	}{
+		{name: "nat", typ: "nat", value: "1", packed: "050001"},
+		{name: "negative int", typ: "int", value: "-1", packed: "050041"},
+		{name: "string", typ: "string", value: `"a"`, packed: "05010000000161"},
+		{name: "unit", typ: "unit", value: "Unit", packed: "05030b"},
+		{name: "bool", typ: "bool", value: "True", packed: "05030a"},
+		{name: "bytes", typ: "bytes", value: "0xbeef", packed: "050a00000002beef"},
+		{name: "pair", typ: "pair nat nat", value: "Pair 1 2", packed: "05070700010002"},
+		{name: "comb pair", typ: "pair nat nat nat", value: "{ 1 ; 2 ; 3 }", packed: "0507070001070700020003"},
+		{name: "option", typ: "option nat", value: "Some 1", packed: "0505090001"},
+		{name: "or", typ: "or nat string", value: "Left 1", packed: "0505050001"},
+		{name: "list", typ: "list nat", value: "{ 1 ; 2 }", packed: "05020000000400010002"},
+		{name: "mutez", typ: "mutez", value: "1000000", packed: "050080897a"},
+		{name: "timestamp", typ: "timestamp", value: `"1970-01-01T00:01:00Z"`, packed: "05003c"},
+		{name: "key hash", typ: "key_hash", value: `"tz1VSUr8wwNhLAzempoch5d6hLRiTh8Cjcjb"`, packed: "050a00000015006b82198cb179e8306c1bedd08f12dc863f328886"},
+		{name: "address", typ: "address", value: `"tz1VSUr8wwNhLAzempoch5d6hLRiTh8Cjcjb"`, packed: "050a0000001600006b82198cb179e8306c1bedd08f12dc863f328886"},
+		{name: "negative nat", typ: "nat", value: "-1", err: true},
+		{name: "negative mutez", typ: "mutez", value: "-1", err: true},
+		{name: "wrong address", typ: "address", value: `"tz1VSUr8wwNhLAzempoch5d6hLRiTh8Cjcja"`, err: true},
+		{name: "wrong pair", typ: "pair nat nat", value: "Pair 1", err: true},
+		{name: "type mismatch", typ: "list nat", value: `{ "a" }`, err: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			typ, err := ParseMichelson(tc.typ)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			value, err := ParseMichelson(tc.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			packed, err := Pack(typ, value)
+			if tc.err {
+				if err == nil {
+					t.Errorf("expected error, got %x", packed)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if hex.EncodeToString(packed) != tc.packed {
+				t.Errorf("got %x, want %s", packed, tc.packed)
+			}
+		})
+	}
+}
+
+func TestPackUnpack(t *testing.T) {
+	testCases := []struct {
+		typ   string
+		value string
+	}{
+		{typ: "address", value: `"KT1LAuGLiaCF9A72qZtFvVhyzzNFg86fwFnV"`},
+		{typ: "address", value: `"KT1LAuGLiaCF9A72qZtFvVhyzzNFg86fwFnV%transfer"`},
+		{typ: "address", value: `"tz3Mo3gHekQhCmykfnC58ecqJLXrjMKzkF2Q"`},
+		{typ: "key", value: `"edpkuEZ8FpnCWY2mNUpNYaF4GH3zZuCYKoNjZJPvtoKEki2ZfbFPbS"`},
+		{typ: "key_hash", value: `"tz1dBT7PKeSDbPK1No7KNhTvrr3XoLe8vKLH"`},
+		{typ: "signature", value: `"sigMzWBjb43ybExvtWcHhxoVXGaNFvwgxW3Q2hc7mDBAUtxjgWXJVXr6b3W2d1SF8rQ4wtt4Kj9nGQYy7YXZJ9h84iCuwo6v"`},
+		{typ: "chain_id", value: `"NetXjD3HPJJjmcd"`},
+		{typ: "timestamp", value: `"2021-03-01T10:00:00Z"`},
+		{typ: "mutez", value: "9223372036854775807"},
+		{typ: "pair (nat %a) (pair (string %b) (bytes %c))", value: `Pair 1 (Pair "b" 0x00)`},
+		{typ: "or (option address) (list (pair key_hash mutez))", value: `Right { Pair "tz1dBT7PKeSDbPK1No7KNhTvrr3XoLe8vKLH" 10 }`},
+		{typ: "option (or nat address)", value: `Some (Right "tz1VSUr8wwNhLAzempoch5d6hLRiTh8Cjcjb")`},
+		{typ: "map string (set nat)", value: `{ Elt "a" { 1 ; 2 } ; Elt "b" {} }`},
+		{typ: "big_map nat unit", value: "12"},
+		{typ: "lambda unit unit", value: "{ DROP ; UNIT }"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.typ, func(t *testing.T) {
+			typ, err := ParseMichelson(tc.typ)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			value, err := ParseMichelson(tc.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			packed, err := Pack(typ, value)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			unpacked, err := Unpack(typ, packed)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if PrintMichelson(unpacked) != PrintMichelson(value) {
+				t.Errorf("got %s, want %s", PrintMichelson(unpacked), PrintMichelson(value))
+			}
+		})
+	}
+}
+
+func TestUnpack_Malformed(t *testing.T) {
+	typ, err := ParseMichelson("pair nat string")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, packed := range []string{"", "0000", "05", "0507", "050707000101", "0501ffffffff"} {
+		bt, _ := hex.DecodeString(packed)
+		if _, err := Unpack(typ, bt); err == nil {
+			t.Errorf("%s: expected error", packed)
+		}
+	}
+}
+
+func TestParseExpression(t *testing.T) {
+	testCases := []struct {
+		raw string
+		err bool
+	}{
+		{raw: `"Pair 1 \"a\""`},
+		{raw: `{"prim":"Pair","args":[{"int":"1"},{"string":"a"}]}`},
+		{raw: `{}`, err: true},
+		{raw: `null`, err: true},
+		{raw: `{"prim":"foo"}`, err: true},
+		{raw: `"Pair 1"`},
+		{raw: `"Pair (1"`, err: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.raw, func(t *testing.T) {
+			_, err := ParseExpression([]byte(tc.raw))
+			if (err != nil) != tc.err {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
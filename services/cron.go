@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"tezosign/common/log"
 	"tezosign/conf"
 	"tezosign/infrustructure"
@@ -19,7 +20,7 @@ func AddToCron(cron *gron.Cron, conf conf.Config, n infrustructure.NetworkContex
 		log.Info("Sheduling operations saver every", zap.Duration("sec", dur))
 		cron.AddFunc(gron.Every(dur), func() {
 
-			service := New(repos.New(n.Db), repos.New(n.IndexerDB), n.Client, nil, network)
+			service := New(context.Background(), repos.New(n.Db), repos.New(n.IndexerDB), n.Client, nil, network).WithCache(n.Cache)
 
 			count, err := service.CheckOperations()
 			if err != nil {
@@ -37,7 +38,7 @@ func AddToCron(cron *gron.Cron, conf conf.Config, n infrustructure.NetworkContex
 		log.Info("Sheduling assets saver every", zap.Duration("sec", dur))
 		cron.AddFunc(gron.Every(dur), func() {
 
-			service := New(repos.New(n.Db), repos.New(n.IndexerDB), n.Client, nil, network)
+			service := New(context.Background(), repos.New(n.Db), repos.New(n.IndexerDB), n.Client, nil, network).WithCache(n.Cache)
 
 			count, err := service.AssetsIncomeOperations()
 			if err != nil {
@@ -49,4 +50,144 @@ func AddToCron(cron *gron.Cron, conf conf.Config, n infrustructure.NetworkContex
 	} else {
 		log.Info("no sheduling assets due to missing Assets in config")
 	}
+
+	if conf.Cron.RateLimit > 0 && conf.API.RateLimit.IsPostgres() {
+		dur := time.Duration(conf.Cron.RateLimit) * time.Second
+		log.Info("Sheduling rate limit buckets cleanup every", zap.Duration("sec", dur))
+		cron.AddFunc(gron.Every(dur), func() {
+
+			service := New(context.Background(), repos.New(n.Db), repos.New(n.IndexerDB), n.Client, nil, network).WithCache(n.Cache)
+
+			count, err := service.CleanupRateLimitBuckets()
+			if err != nil {
+				log.Error("CleanupRateLimitBuckets failed", zap.Error(err))
+				return
+			}
+			log.Info("Removed rate limit buckets", zap.Int64("count", count))
+		})
+	}
+
+	if conf.Cron.RevokedTokens > 0 {
+		dur := time.Duration(conf.Cron.RevokedTokens) * time.Second
+		log.Info("Sheduling revoked tokens cleanup every", zap.Duration("sec", dur))
+		cron.AddFunc(gron.Every(dur), func() {
+
+			service := New(context.Background(), repos.New(n.Db), repos.New(n.IndexerDB), n.Client, nil, network).WithCache(n.Cache)
+
+			count, err := service.CleanupRevokedTokens()
+			if err != nil {
+				log.Error("CleanupRevokedTokens failed", zap.Error(err))
+				return
+			}
+			log.Info("Removed revoked tokens", zap.Int64("count", count))
+		})
+	}
+
+	if conf.Cron.TokenBalances > 0 {
+		dur := time.Duration(conf.Cron.TokenBalances) * time.Second
+		log.Info("Sheduling token balances refresh every", zap.Duration("sec", dur))
+		cron.AddFunc(gron.Every(dur), func() {
+
+			service := New(context.Background(), repos.New(n.Db), repos.New(n.IndexerDB), n.Client, nil, network).WithCache(n.Cache).WithConfig(conf)
+
+			count, err := service.RefreshTokenBalances()
+			if err != nil {
+				log.Error("RefreshTokenBalances failed", zap.Error(err))
+				return
+			}
+			log.Info("Updated token balances", zap.Uint64("count", count))
+		})
+	} else {
+		log.Info("no sheduling token balances due to missing TokenBalances in config")
+	}
+
+	if conf.Cron.AssetDiscovery > 0 {
+		dur := time.Duration(conf.Cron.AssetDiscovery) * time.Second
+		log.Info("Sheduling asset discovery every", zap.Duration("sec", dur))
+		cron.AddFunc(gron.Every(dur), func() {
+
+			service := New(context.Background(), repos.New(n.Db), repos.New(n.IndexerDB), n.Client, nil, network).WithCache(n.Cache).WithConfig(conf)
+
+			count, err := service.DiscoverAssets()
+			if err != nil {
+				log.Error("DiscoverAssets failed", zap.Error(err))
+				return
+			}
+			log.Info("Suggested assets", zap.Uint64("count", count))
+		})
+	} else {
+		log.Info("no sheduling asset discovery due to missing AssetDiscovery in config")
+	}
+
+	if conf.Cron.AssetPrices > 0 {
+		dur := time.Duration(conf.Cron.AssetPrices) * time.Second
+		log.Info("Sheduling asset prices snapshots every", zap.Duration("sec", dur))
+		cron.AddFunc(gron.Every(dur), func() {
+
+			service := New(context.Background(), repos.New(n.Db), repos.New(n.IndexerDB), n.Client, nil, network).WithCache(n.Cache).WithConfig(conf)
+
+			count, err := service.RefreshAssetPrices()
+			if err != nil {
+				log.Error("RefreshAssetPrices failed", zap.Error(err))
+				return
+			}
+			log.Info("Saved asset prices", zap.Uint64("count", count))
+		})
+	} else {
+		log.Info("no sheduling asset prices due to missing AssetPrices in config")
+	}
+
+	if conf.Cron.Portfolios > 0 {
+		dur := time.Duration(conf.Cron.Portfolios) * time.Second
+		log.Info("Sheduling portfolios sampling every", zap.Duration("sec", dur))
+		cron.AddFunc(gron.Every(dur), func() {
+
+			service := New(context.Background(), repos.New(n.Db), repos.New(n.IndexerDB), n.Client, nil, network).WithCache(n.Cache).WithConfig(conf)
+
+			count, err := service.SamplePortfolios()
+			if err != nil {
+				log.Error("SamplePortfolios failed", zap.Error(err))
+				return
+			}
+			log.Info("Sampled portfolios", zap.Uint64("count", count))
+		})
+	} else {
+		log.Info("no sheduling portfolios due to missing Portfolios in config")
+	}
+
+	if networkConf, _ := conf.Network(network); conf.Cron.VestingClaims > 0 && networkConf.VestingClaimProposer != "" {
+		dur := time.Duration(conf.Cron.VestingClaims) * time.Second
+		log.Info("Sheduling vesting claims proposals every", zap.Duration("sec", dur))
+		cron.AddFunc(gron.Every(dur), func() {
+
+			service := New(context.Background(), repos.New(n.Db), repos.New(n.IndexerDB), n.Client, nil, network).WithCache(n.Cache).WithConfig(conf)
+
+			count, err := service.ProposeVestingClaims()
+			if err != nil {
+				log.Error("ProposeVestingClaims failed", zap.Error(err))
+				return
+			}
+			log.Info("Proposed vesting claims", zap.Uint64("count", count))
+		})
+	} else {
+		log.Info("no sheduling vesting claims due to missing VestingClaims or VestingClaimProposer in config")
+	}
+
+	if conf.Cron.VestingOperations > 0 {
+		dur := time.Duration(conf.Cron.VestingOperations) * time.Second
+		log.Info("Sheduling vesting operations sync every", zap.Duration("sec", dur))
+		cron.AddFunc(gron.Every(dur), func() {
+
+			service := New(context.Background(), repos.New(n.Db), repos.New(n.IndexerDB), n.Client, nil, network).WithCache(n.Cache)
+
+			count, err := service.SyncVestingOperations()
+			if err != nil {
+				log.Error("SyncVestingOperations failed", zap.Error(err))
+				return
+			}
+			log.Info("Vesting operations", zap.Uint64("count", count))
+		})
+	} else {
+		log.Info("no sheduling vesting operations due to missing VestingOperations in config")
+	}
 }
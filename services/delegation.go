@@ -0,0 +1,93 @@
+package services
+
+import (
+	"tezosign/common/apperrors"
+	"tezosign/models"
+	"tezosign/types"
+)
+
+//Returns nil info for not delegated account
+func (s *ServiceFacade) getDelegationInfo(account models.Account) (info *models.DelegationInfo, err error) {
+	if !account.DelegateID.Valid {
+		return nil, nil
+	}
+
+	indexerRepo := s.indexerRepoProvider.GetIndexer()
+
+	baker, isFound, err := indexerRepo.GetAccountByID(uint64(account.DelegateID.Int64))
+	if err != nil {
+		return nil, err
+	}
+
+	if !isFound {
+		return nil, apperrors.New(apperrors.ErrNotFound, "delegate")
+	}
+
+	info = &models.DelegationInfo{
+		Baker: baker.Address,
+	}
+
+	if account.DelegationLevel.Valid {
+		info.DelegationLevel = uint64(account.DelegationLevel.Int64)
+	}
+
+	//Rewards are counted from configured payout accounts only, baker own transfers are not rewards
+	var senderIDs []uint64
+
+	network, _ := s.cfg.Network(s.net)
+	if bakerConf, isFound := network.Baker(baker.Address.String()); isFound {
+		info.BakerFee = &bakerConf.Fee
+
+		for _, payout := range bakerConf.Payouts {
+			payoutAccount, isFound, err := indexerRepo.GetAccount(types.Address(payout))
+			if err != nil {
+				return nil, err
+			}
+
+			if isFound {
+				senderIDs = append(senderIDs, payoutAccount.Id)
+			}
+		}
+	}
+
+	transfers, err := indexerRepo.GetIncomingTransfersSummary(account.Id, senderIDs, info.DelegationLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	info.EstimatedRewards = transfers.Amount
+	info.RewardsTransfers = transfers.Count
+
+	return info, nil
+}
+
+func (s *ServiceFacade) BakersList(params models.CommonParams) (bakers []models.Baker, err error) {
+	indexerRepo := s.indexerRepoProvider.GetIndexer()
+
+	block, err := indexerRepo.GetLastBlock()
+	if err != nil {
+		return nil, err
+	}
+
+	bakers, err = indexerRepo.GetActiveBakers(block.Level, params)
+	if err != nil {
+		return nil, err
+	}
+
+	constants, err := s.rpcClient.Constants(s.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	network, _ := s.cfg.Network(s.net)
+	for i := range bakers {
+		bakers[i].CalcCapacity(constants.FrozenDepositsPercentage)
+
+		if bakerConf, isFound := network.Baker(bakers[i].Address.String()); isFound {
+			fee := bakerConf.Fee
+			bakers[i].Fee = &fee
+		}
+	}
+
+	return bakers, nil
+}
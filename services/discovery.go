@@ -0,0 +1,350 @@
+package services
+
+import (
+	"database/sql"
+	"tezosign/common/apperrors"
+	"tezosign/common/log"
+	"tezosign/models"
+	"tezosign/services/contract"
+	"tezosign/types"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	assetDiscoveryCursor = "asset_discovery"
+	//Max levels scanned per run
+	assetDiscoveryLevels = 10000
+)
+
+type discoveredAsset struct {
+	contractID uint64
+	asset      types.Address
+	assetType  models.AssetType
+	tokenID    uint64
+}
+
+//DiscoverAssets creates inactive asset suggestions for tokens transferred to multisig contracts
+func (s *ServiceFacade) DiscoverAssets() (count uint64, err error) {
+	indexerRepo := s.indexerRepoProvider.GetIndexer()
+	cursorRepo := s.repoProvider.GetCursor()
+
+	contractsMap, err := s.allContractsMap()
+	if err != nil {
+		return count, err
+	}
+
+	addresses := make([]string, 0, len(contractsMap))
+	for address := range contractsMap {
+		addresses = append(addresses, address.String())
+	}
+
+	if len(addresses) == 0 {
+		return count, nil
+	}
+
+	cursor, isFound, err := cursorRepo.GetCursor(assetDiscoveryCursor)
+	if err != nil {
+		return count, err
+	}
+
+	//First run starts from the earliest registered contract origination
+	if !isFound {
+		firstLevel, isFound, err := indexerRepo.GetFirstActivityLevel(addresses)
+		if err != nil {
+			return count, err
+		}
+
+		if !isFound || firstLevel == 0 {
+			return count, nil
+		}
+
+		cursor.Level = firstLevel - 1
+	}
+
+	block, err := indexerRepo.GetLastBlock()
+	if err != nil {
+		return count, err
+	}
+
+	toLevel := block.Level
+	if toLevel > cursor.Level+assetDiscoveryLevels {
+		toLevel = cursor.Level + assetDiscoveryLevels
+	}
+
+	if toLevel <= cursor.Level {
+		return count, nil
+	}
+
+	for offset := 0; offset < len(addresses); offset += contractsBatchSize {
+		end := offset + contractsBatchSize
+		if end > len(addresses) {
+			end = len(addresses)
+		}
+
+		operations, err := indexerRepo.GetTransfersTo(addresses[offset:end], cursor.Level, toLevel)
+		if err != nil {
+			return count, err
+		}
+
+		suggested, err := s.suggestAssets(contractsMap, operations)
+		if err != nil {
+			return count, err
+		}
+
+		count += suggested
+	}
+
+	err = cursorRepo.SaveCursor(assetDiscoveryCursor, toLevel)
+	if err != nil {
+		return count, err
+	}
+
+	return count, nil
+}
+
+func (s *ServiceFacade) allContractsMap() (contractsMap map[types.Address]models.Contract, err error) {
+	contractsMap = map[types.Address]models.Contract{}
+
+	for offset := 0; ; offset += contractsBatchSize {
+		contracts, err := s.repoProvider.GetContract().GetContractsList(contractsBatchSize, offset)
+		if err != nil {
+			return contractsMap, err
+		}
+
+		for i := range contracts {
+			contractsMap[contracts[i].Address] = contracts[i]
+		}
+
+		if len(contracts) < contractsBatchSize {
+			return contractsMap, nil
+		}
+	}
+}
+
+func (s *ServiceFacade) suggestAssets(contractsMap map[types.Address]models.Contract, operations []models.AssetTransferOperation) (count uint64, err error) {
+	//Detected standard by asset contract, empty if not FA
+	standards := map[types.Address]models.AssetType{}
+	//Operation level of first transfer by discovered token
+	discovered := map[discoveredAsset]uint64{}
+
+	for i := range operations {
+		if operations[i].RawParameters == nil {
+			continue
+		}
+
+		assetType, ok := standards[operations[i].Asset]
+		if !ok {
+			assetType, err = s.detectFAStandart(operations[i].Asset)
+			if err != nil {
+				return count, err
+			}
+			standards[operations[i].Asset] = assetType
+		}
+
+		if assetType == "" {
+			continue
+		}
+
+		transfers, err := contract.AssetOperation(operations[i].RawParameters.MichelinePrim(), assetType)
+		if err != nil {
+			log.Ctx(s.ctx).Debug("skip transfer params", zap.String("operation", operations[i].OpHash), zap.Error(err))
+			continue
+		}
+
+		for _, transfer := range transfers {
+			for _, tx := range transfer.Txs {
+				c, ok := contractsMap[tx.To]
+				if !ok {
+					continue
+				}
+
+				key := discoveredAsset{contractID: c.ID, asset: operations[i].Asset, assetType: assetType}
+				if assetType == models.TypeFA2 {
+					key.tokenID = tx.TokenID
+				}
+
+				if _, ok = discovered[key]; !ok {
+					discovered[key] = operations[i].Level
+				}
+			}
+		}
+	}
+
+	for key, level := range discovered {
+		isCreated, err := s.createAssetSuggestion(key, level)
+		if err != nil {
+			return count, err
+		}
+
+		if isCreated {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+//FA2 checked first, empty type for not FA contracts
+func (s *ServiceFacade) detectFAStandart(address types.Address) (assetType models.AssetType, err error) {
+	for _, t := range []models.AssetType{models.TypeFA2, models.TypeFA12} {
+		isFA, err := s.checkFAStandart(address, t)
+		if err != nil {
+			return assetType, err
+		}
+
+		if isFA {
+			return t, nil
+		}
+	}
+
+	return "", nil
+}
+
+func (s *ServiceFacade) createAssetSuggestion(key discoveredAsset, level uint64) (isCreated bool, err error) {
+	assetRepo := s.repoProvider.GetAsset()
+
+	//FA1.2 token not contain token id
+	var tokenID *uint64
+	if key.assetType == models.TypeFA2 {
+		tokenID = &key.tokenID
+	}
+
+	//Known, dismissed or global asset
+	_, isFound, err := assetRepo.GetAsset(key.contractID, key.asset, tokenID)
+	if err != nil {
+		return false, err
+	}
+
+	if isFound {
+		return false, nil
+	}
+
+	asset := models.Asset{
+		ContractType: key.assetType,
+		Address:      key.asset,
+		TokenID:      tokenID,
+		IsActive:     false,
+		IsSuggested:  true,
+		ContractID: sql.NullInt64{
+			Int64: int64(key.contractID),
+			Valid: true,
+		},
+		//Discovered transfer will be saved as income after accept
+		LastOperationBlockLevel: level - 1,
+		UpdatedAt:               time.Now(),
+	}
+
+	s.fillAssetFromMetadata(&asset)
+
+	err = assetRepo.CreateAsset(asset)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (s *ServiceFacade) AssetSuggestions(contractAddress types.Address) (assets []models.Asset, err error) {
+	c, isFound, err := s.repoProvider.GetContract().GetContract(contractAddress)
+	if err != nil {
+		return assets, err
+	}
+
+	if !isFound {
+		return assets, apperrors.New(apperrors.ErrNotFound, "contract")
+	}
+
+	assets, err = s.repoProvider.GetAsset().GetSuggestedAssets(c.ID)
+	if err != nil {
+		return assets, err
+	}
+
+	return assets, nil
+}
+
+//AcceptAssetSuggestion activates suggested asset, name, ticker and scale can be overridden by owner
+func (s *ServiceFacade) AcceptAssetSuggestion(contractAddress types.Address, reqAsset models.Asset) (asset models.Asset, err error) {
+	asset, err = s.getAssetSuggestion(contractAddress, reqAsset)
+	if err != nil {
+		return asset, err
+	}
+
+	if reqAsset.Name != "" {
+		asset.Name = reqAsset.Name
+	}
+
+	if reqAsset.Ticker != "" {
+		asset.Ticker = reqAsset.Ticker
+	}
+
+	if reqAsset.Scale != 0 {
+		asset.Scale = reqAsset.Scale
+	}
+
+	if err = asset.Validate(); err != nil {
+		return asset, apperrors.New(apperrors.ErrBadRequest, err.Error())
+	}
+
+	assetRepo := s.repoProvider.GetAsset()
+
+	asset.UpdatedAt = time.Now()
+	err = assetRepo.UpdateAsset(asset)
+	if err != nil {
+		return asset, err
+	}
+
+	err = assetRepo.EnableContractAsset(asset.ID)
+	if err != nil {
+		return asset, err
+	}
+
+	tokensMap, err := s.getContractTokensBalancesMap(contractAddress)
+	if err != nil {
+		return asset, err
+	}
+
+	asset.Balances = tokensMap[asset.Address]
+	asset.IsActive = true
+	asset.IsSuggested = false
+
+	return asset, nil
+}
+
+func (s *ServiceFacade) DismissAssetSuggestion(contractAddress types.Address, reqAsset models.Asset) (err error) {
+	asset, err := s.getAssetSuggestion(contractAddress, reqAsset)
+	if err != nil {
+		return err
+	}
+
+	//Dismissed asset stays disabled and is not suggested again
+	err = s.repoProvider.GetAsset().DisableContractAsset(asset.ID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *ServiceFacade) getAssetSuggestion(contractAddress types.Address, reqAsset models.Asset) (asset models.Asset, err error) {
+	c, isFound, err := s.repoProvider.GetContract().GetContract(contractAddress)
+	if err != nil {
+		return asset, err
+	}
+
+	if !isFound {
+		return asset, apperrors.New(apperrors.ErrNotFound, "contract")
+	}
+
+	asset, isFound, err = s.repoProvider.GetAsset().GetAsset(c.ID, reqAsset.Address, reqAsset.TokenID)
+	if err != nil {
+		return asset, err
+	}
+
+	if !isFound || !asset.IsSuggested {
+		return asset, apperrors.New(apperrors.ErrNotFound, "asset suggestion")
+	}
+
+	return asset, nil
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"tezosign/models"
+	"tezosign/services/contract"
+	"tezosign/types"
+)
+
+//Node error of simulation by not revealed source
+const unrevealedKeyError = "unrevealed_key"
+
+//Node is not reachable, operation is not checked
+type rpcUnavailableError struct {
+	err error
+}
+
+func (e rpcUnavailableError) Error() string {
+	return e.err.Error()
+}
+
+func (e rpcUnavailableError) Unwrap() error {
+	return e.err
+}
+
+func rpcError(err error) error {
+	var failure models.OperationFailure
+	if errors.As(err, &failure) {
+		return err
+	}
+
+	return rpcUnavailableError{err: err}
+}
+
+//estimateContractOperation simulates multisig call submitted by user on head block, returns submitter balance for fee check
+func (s *ServiceFacade) estimateContractOperation(userPubKey types.PubKey, contractAddress types.Address, param models.OperationParameter) (estimate models.OperationEstimate, balance int64, err error) {
+	source, err := userPubKey.Address()
+	if err != nil {
+		return estimate, balance, err
+	}
+
+	chainID, err := s.rpcClient.ChainID(s.ctx)
+	if err != nil {
+		return estimate, balance, rpcError(err)
+	}
+
+	branch, err := s.rpcClient.BlockHash(s.ctx)
+	if err != nil {
+		return estimate, balance, rpcError(err)
+	}
+
+	counter, err := s.rpcClient.Counter(s.ctx, source.String())
+	if err != nil {
+		return estimate, balance, rpcError(err)
+	}
+
+	constants, err := s.rpcClient.Constants(s.ctx)
+	if err != nil {
+		return estimate, balance, rpcError(err)
+	}
+
+	req := contract.BuildRunOperationRequest(chainID, branch, source, contractAddress, counter, constants, param)
+
+	result, err := s.rpcClient.RunOperation(s.ctx, req)
+	if err != nil {
+		return estimate, balance, rpcError(err)
+	}
+
+	estimate, err = contract.EstimateOperation(req.Operation.Contents[0], result, constants)
+	if err != nil {
+		return estimate, balance, err
+	}
+
+	balance, err = s.rpcClient.Balance(s.ctx, source.String())
+	if err != nil {
+		return estimate, balance, rpcError(err)
+	}
+
+	return estimate, balance, nil
+}
+
+//isEstimationSkipped submitter is not revealed or node is unavailable, operation can not be checked
+func isEstimationSkipped(err error) bool {
+	var unavailable rpcUnavailableError
+	if errors.As(err, &unavailable) {
+		return true
+	}
+
+	var failure models.OperationFailure
+	return errors.As(err, &failure) && strings.Contains(failure.Reason, unrevealedKeyError)
+}
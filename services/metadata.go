@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"tezosign/common/apperrors"
+	"tezosign/common/log"
+	"tezosign/models"
+	"tezosign/services/contract"
+	"tezosign/services/metadata"
+	"tezosign/types"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const defaultMetadataRefreshPeriod = 24 * time.Hour
+
+func (s *ServiceFacade) metadataResolver() *metadata.Resolver {
+	return metadata.NewResolver(metadata.NewFetchers(s.cfg.Metadata), s)
+}
+
+func (s *ServiceFacade) metadataRefreshPeriod() time.Duration {
+	if s.cfg.Metadata.RefreshPeriod > 0 {
+		return time.Duration(s.cfg.Metadata.RefreshPeriod) * time.Second
+	}
+	return defaultMetadataRefreshPeriod
+}
+
+//GetTokenMetadata returns cached metadata, stale cache is refreshed
+func (s *ServiceFacade) GetTokenMetadata(assetID types.Address, tokenID uint64) (resp models.TokenMetadata, err error) {
+	cacheKey := fmt.Sprintf("%s/%d", assetID, tokenID)
+	if value, ok := s.cache.Get(cacheTokenMetadata, cacheKey); ok {
+		return value.(models.TokenMetadata), nil
+	}
+
+	metadataRepo := s.repoProvider.GetMetadata()
+
+	cached, isFound, err := metadataRepo.GetTokenMetadata(assetID, tokenID)
+	if err != nil {
+		return resp, err
+	}
+
+	if isFound && !cached.IsStale() {
+		s.cache.SetUntil(cacheTokenMetadata, cacheKey, cached, time.Time(cached.NextRefreshAt))
+		return cached, nil
+	}
+
+	resp, err = s.resolveTokenMetadata(assetID, tokenID)
+	if err != nil {
+		//Keep serving stale cache while metadata source is unavailable
+		if isFound {
+			log.Ctx(s.ctx).Warn("token metadata refresh failed", zap.String("asset", assetID.String()), zap.Error(err))
+			return cached, nil
+		}
+		return resp, err
+	}
+
+	now := time.Now()
+	resp.UpdatedAt = types.JSONTimestamp(now)
+	resp.NextRefreshAt = types.JSONTimestamp(now.Add(s.metadataRefreshPeriod()))
+
+	err = metadataRepo.SaveTokenMetadata(resp)
+	if err != nil {
+		return resp, err
+	}
+
+	s.cache.SetUntil(cacheTokenMetadata, cacheKey, resp, time.Time(resp.NextRefreshAt))
+
+	return resp, nil
+}
+
+func (s *ServiceFacade) resolveTokenMetadata(assetID types.Address, tokenID uint64) (resp models.TokenMetadata, err error) {
+	bigMapValue, isFound, err := s.getStorageBigMapValue(assetID, contract.MetaDataEntrypoint, func() (string, error) {
+		return contract.GetBigMapKeyHash(tokenID)
+	})
+	if err != nil {
+		return resp, err
+	}
+
+	if !isFound {
+		return resp, apperrors.New(apperrors.ErrNotFound, "big_map value")
+	}
+
+	tokenInfo, err := contract.ParseTokenInfo(bigMapValue)
+	if err != nil {
+		return resp, err
+	}
+
+	return s.metadataResolver().ResolveToken(s.ctx, assetID, tokenID, tokenInfo)
+}
+
+//MetadataValue implements metadata.StorageReader for tezos-storage URIs
+func (s *ServiceFacade) MetadataValue(ctx context.Context, contractAddress types.Address, key string) ([]byte, error) {
+	bigMapValue, isFound, err := s.getStorageBigMapValue(contractAddress, contract.ContractMetadataEntrypoint, func() (string, error) {
+		return contract.GetBigMapStringKeyHash(key)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !isFound {
+		return nil, apperrors.New(apperrors.ErrNotFound, "metadata key")
+	}
+
+	return contract.ParseBytesValue(bigMapValue)
+}
+
+//Lookup value of big map placed in contract storage by annotation
+func (s *ServiceFacade) getStorageBigMapValue(contractAddress types.Address, annotation string, keyHash func() (string, error)) (value []byte, isFound bool, err error) {
+	indexerRepo := s.indexerRepoProvider.GetIndexer()
+
+	script, isFound, err := indexerRepo.GetContractScript(contractAddress)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !isFound {
+		return nil, false, apperrors.New(apperrors.ErrNotFound, "script")
+	}
+
+	e, err := contract.InitAnnotsEntrypoints(script.StorageSchema.MichelinePrim())
+	if err != nil {
+		return nil, false, err
+	}
+
+	entrypoint, ok := e[annotation]
+	if !ok {
+		return nil, false, apperrors.New(apperrors.ErrBadRequest, annotation)
+	}
+
+	storage, isFound, err := indexerRepo.GetContractStorage(contractAddress)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !isFound {
+		return nil, false, apperrors.New(apperrors.ErrNotFound, "storage")
+	}
+
+	bigMap, err := contract.GetStorageValue(entrypoint, storage.RawValue.MichelinePrim())
+	if err != nil {
+		return nil, false, err
+	}
+
+	hash, err := keyHash()
+	if err != nil {
+		return nil, false, err
+	}
+
+	return s.rpcClient.BigMapKey(s.ctx, bigMap.Int.Int64(), hash)
+}
+
+//Fill empty asset fields by token metadata
+func (s *ServiceFacade) fillAssetFromMetadata(asset *models.Asset) {
+	if asset.Name != "" && asset.Ticker != "" && asset.Scale != 0 {
+		return
+	}
+
+	var tokenID uint64
+	if asset.TokenID != nil {
+		tokenID = *asset.TokenID
+	}
+
+	tokenMetadata, err := s.GetTokenMetadata(asset.Address, tokenID)
+	if err != nil {
+		log.Ctx(s.ctx).Debug("asset metadata not resolved", zap.String("asset", asset.Address.String()), zap.Error(err))
+		return
+	}
+
+	info := tokenMetadata.Info
+	if asset.Name == "" {
+		asset.Name = info.Name
+	}
+
+	if asset.Ticker == "" {
+		asset.Ticker = info.Symbol
+	}
+
+	if asset.Scale == 0 {
+		asset.Scale = info.Decimals
+	}
+}
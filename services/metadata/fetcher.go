@@ -0,0 +1,241 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"tezosign/conf"
+	"time"
+)
+
+const (
+	SchemeHTTPS = "https"
+	SchemeIPFS  = "ipfs"
+
+	defaultTimeout     = 10 * time.Second
+	defaultMaxSize     = 1 << 20
+	defaultIPFSGateway = "https://cloudflare-ipfs.com"
+	maxRedirects       = 5
+)
+
+//Fetcher loads off-chain document by URI
+type Fetcher interface {
+	Fetch(ctx context.Context, uri *url.URL) ([]byte, error)
+}
+
+//Fetchers by URI scheme
+type Fetchers map[string]Fetcher
+
+func NewFetchers(cfg conf.Metadata) Fetchers {
+	timeout := defaultTimeout
+	if cfg.Timeout > 0 {
+		timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+
+	maxSize := int64(defaultMaxSize)
+	if cfg.MaxSize > 0 {
+		maxSize = cfg.MaxSize
+	}
+
+	gateway := defaultIPFSGateway
+	if cfg.IPFSGateway != "" {
+		gateway = cfg.IPFSGateway
+	}
+
+	//URIs are controlled by contracts, only public https hosts are allowed
+	publicFetcher := &HTTPFetcher{
+		Client:   newPublicClient(timeout),
+		MaxSize:  maxSize,
+		CheckURL: checkPublicURL,
+	}
+
+	//Gateway is trusted and may be local node
+	gatewayFetcher := &HTTPFetcher{
+		Client:  &http.Client{Timeout: timeout},
+		MaxSize: maxSize,
+	}
+
+	return Fetchers{
+		SchemeHTTPS: publicFetcher,
+		SchemeIPFS: &IPFSFetcher{
+			Gateway: strings.TrimSuffix(gateway, "/"),
+			HTTP:    gatewayFetcher,
+		},
+	}
+}
+
+type HTTPFetcher struct {
+	Client  *http.Client
+	MaxSize int64
+	//Optional URI check before request
+	CheckURL func(ctx context.Context, uri *url.URL) error
+}
+
+func (f *HTTPFetcher) Fetch(ctx context.Context, uri *url.URL) ([]byte, error) {
+	if f.CheckURL != nil {
+		err := f.CheckURL(ctx, uri)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Not OK status code: %d", resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, f.MaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("ReadAll error: %s", err.Error())
+	}
+
+	if int64(len(body)) > f.MaxSize {
+		return nil, fmt.Errorf("document exceeds %d bytes", f.MaxSize)
+	}
+
+	return body, nil
+}
+
+//Loads ipfs://<cid>/<path> through HTTP gateway
+type IPFSFetcher struct {
+	Gateway string
+	HTTP    Fetcher
+}
+
+func (f *IPFSFetcher) Fetch(ctx context.Context, uri *url.URL) ([]byte, error) {
+	if uri.Host == "" {
+		return nil, fmt.Errorf("empty ipfs cid")
+	}
+
+	gatewayURI, err := url.Parse(fmt.Sprintf("%s/ipfs/%s%s", f.Gateway, uri.Host, uri.EscapedPath()))
+	if err != nil {
+		return nil, err
+	}
+
+	return f.HTTP.Fetch(ctx, gatewayURI)
+}
+
+//newPublicClient checks every redirect and refuses non public addresses at dial time, after DNS resolution
+func newPublicClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return fmt.Errorf("address %s is not allowed", host)
+			}
+
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			//Proxy would be dialed instead of target
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+
+			return checkPublicURL(req.Context(), req.URL)
+		},
+	}
+}
+
+//checkPublicURL allows https URIs which host resolves to public addresses only
+func checkPublicURL(ctx context.Context, uri *url.URL) error {
+	if uri.Scheme != SchemeHTTPS {
+		return fmt.Errorf("scheme %s is not allowed", uri.Scheme)
+	}
+
+	host := uri.Hostname()
+	if host == "" {
+		return fmt.Errorf("empty host")
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return err
+	}
+
+	for _, addr := range addrs {
+		if !isPublicIP(addr.IP) {
+			return fmt.Errorf("host %s resolves to not allowed address %s", host, addr.IP)
+		}
+	}
+
+	return nil
+}
+
+var nonPublicNets = mustParseCIDRs(
+	//This network
+	"0.0.0.0/8",
+	//Private networks
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"fc00::/7",
+	//Carrier-grade NAT
+	"100.64.0.0/10",
+	//Benchmarking
+	"198.18.0.0/15",
+	//Reserved
+	"240.0.0.0/4",
+	//NAT64
+	"64:ff9b::/96",
+)
+
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() || ip.IsMulticast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+
+	for _, ipNet := range nonPublicNets {
+		if ipNet.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func mustParseCIDRs(cidrs ...string) (nets []*net.IPNet) {
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+
+		nets = append(nets, ipNet)
+	}
+
+	return nets
+}
@@ -0,0 +1,156 @@
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"tezosign/models"
+	"tezosign/types"
+)
+
+const (
+	SchemeTezosStorage = "tezos-storage"
+	SchemeSHA256       = "sha256"
+
+	//sha256:// URIs can wrap each other
+	maxNestingDepth = 3
+
+	//token_info key which contains off-chain metadata URI
+	TokenInfoURIKey = ""
+)
+
+//StorageReader reads contract %metadata big map for tezos-storage URIs
+type StorageReader interface {
+	MetadataValue(ctx context.Context, contract types.Address, key string) ([]byte, error)
+}
+
+//Resolver follows TZIP-16 metadata URIs
+type Resolver struct {
+	fetchers Fetchers
+	storage  StorageReader
+}
+
+func NewResolver(fetchers Fetchers, storage StorageReader) *Resolver {
+	return &Resolver{
+		fetchers: fetchers,
+		storage:  storage,
+	}
+}
+
+//Fetch resolves URI relative to contract, tezos-storage URI without host refers to contract itself
+func (r *Resolver) Fetch(ctx context.Context, contract types.Address, rawURI string) ([]byte, error) {
+	return r.fetch(ctx, contract, rawURI, 0)
+}
+
+func (r *Resolver) fetch(ctx context.Context, contract types.Address, rawURI string, depth int) ([]byte, error) {
+	if depth > maxNestingDepth {
+		return nil, fmt.Errorf("metadata uri nesting too deep")
+	}
+
+	uri, err := url.Parse(rawURI)
+	if err != nil {
+		return nil, fmt.Errorf("wrong metadata uri: %s", err.Error())
+	}
+
+	switch uri.Scheme {
+	case SchemeTezosStorage:
+		storageContract, key, err := parseTezosStorageURI(uri, contract)
+		if err != nil {
+			return nil, err
+		}
+
+		if r.storage == nil {
+			return nil, fmt.Errorf("tezos-storage reader not presented")
+		}
+
+		return r.storage.MetadataValue(ctx, storageContract, key)
+	case SchemeSHA256:
+		expectedHash, innerURI, err := parseSHA256URI(uri)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := r.fetch(ctx, contract, innerURI, depth+1)
+		if err != nil {
+			return nil, err
+		}
+
+		hash := sha256.Sum256(data)
+		if !bytes.Equal(hash[:], expectedHash) {
+			return nil, fmt.Errorf("metadata sha256 mismatch")
+		}
+
+		return data, nil
+	default:
+		fetcher, ok := r.fetchers[uri.Scheme]
+		if !ok {
+			return nil, fmt.Errorf("unsupported metadata uri scheme %s", uri.Scheme)
+		}
+
+		return fetcher.Fetch(ctx, uri)
+	}
+}
+
+//tezos-storage:<key> or tezos-storage://<contract>[.<chain_id>]/<key>
+func parseTezosStorageURI(uri *url.URL, contract types.Address) (types.Address, string, error) {
+	rawKey := uri.Opaque
+	if uri.Host != "" {
+		contract = types.Address(strings.SplitN(uri.Host, ".", 2)[0])
+		rawKey = strings.TrimPrefix(uri.EscapedPath(), "/")
+	}
+
+	if err := contract.Validate(); err != nil {
+		return "", "", fmt.Errorf("wrong tezos-storage contract")
+	}
+
+	key, err := url.PathUnescape(rawKey)
+	if err != nil {
+		return "", "", fmt.Errorf("wrong tezos-storage key")
+	}
+
+	return contract, key, nil
+}
+
+//sha256://0x<hash>/<escaped uri>
+func parseSHA256URI(uri *url.URL) (hash []byte, innerURI string, err error) {
+	hash, err = hex.DecodeString(strings.TrimPrefix(uri.Host, "0x"))
+	if err != nil || len(hash) != sha256.Size {
+		return nil, "", fmt.Errorf("wrong sha256 hash")
+	}
+
+	innerURI, err = url.PathUnescape(strings.TrimPrefix(uri.EscapedPath(), "/"))
+	if err != nil || innerURI == "" {
+		return nil, "", fmt.Errorf("wrong sha256 uri")
+	}
+
+	return hash, innerURI, nil
+}
+
+//ResolveToken merges on-chain token_info with off-chain document referenced by "" key
+func (r *Resolver) ResolveToken(ctx context.Context, contract types.Address, tokenID uint64, tokenInfo map[string][]byte) (metadata models.TokenMetadata, err error) {
+	metadata = models.TokenMetadata{
+		Address: contract,
+		TokenID: tokenID,
+	}
+
+	var offChain []byte
+	if uri, ok := tokenInfo[TokenInfoURIKey]; ok && len(uri) > 0 {
+		metadata.URI = string(uri)
+
+		offChain, err = r.Fetch(ctx, contract, metadata.URI)
+		if err != nil {
+			return metadata, err
+		}
+	}
+
+	metadata.Info, err = ParseTokenInfo(tokenInfo, offChain)
+	if err != nil {
+		return metadata, err
+	}
+
+	return metadata, nil
+}
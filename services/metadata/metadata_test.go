@@ -0,0 +1,201 @@
+package metadata
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"tezosign/conf"
+	"tezosign/types"
+	"time"
+)
+
+const testContract types.Address = "KT1K9gCRgaLRFKTErYt1wVxA3Frb9FjasjTV"
+
+//Local stand-in for off-chain storages
+type mapFetcher map[string][]byte
+
+func (f mapFetcher) Fetch(ctx context.Context, uri *url.URL) ([]byte, error) {
+	data, ok := f[uri.String()]
+	if !ok {
+		return nil, fmt.Errorf("not found %s", uri.String())
+	}
+	return data, nil
+}
+
+type mapStorage map[string][]byte
+
+func (s mapStorage) MetadataValue(ctx context.Context, contract types.Address, key string) ([]byte, error) {
+	data, ok := s[contract.String()+"/"+key]
+	if !ok {
+		return nil, fmt.Errorf("not found %s", key)
+	}
+	return data, nil
+}
+
+func Test_ResolverFetch(t *testing.T) {
+	document := []byte(`{"name":"Token"}`)
+	hash := sha256.Sum256(document)
+
+	https := mapFetcher{
+		"https://example.com/token.json":            document,
+		"https://gateway.test/ipfs/QmCid/token.json": document,
+	}
+
+	resolver := NewResolver(Fetchers{
+		SchemeHTTPS: https,
+		SchemeIPFS:  &IPFSFetcher{Gateway: "https://gateway.test", HTTP: https},
+	}, mapStorage{
+		testContract.String() + "/here": document,
+		"KT1PWx2mnDueood7fEmfbBDKx1D9BAnnXitn/key with/slash": document,
+	})
+
+	testCases := []struct {
+		name    string
+		uri     string
+		wantErr bool
+	}{
+		{name: "https", uri: "https://example.com/token.json"},
+		{name: "ipfs", uri: "ipfs://QmCid/token.json"},
+		{name: "tezos-storage self", uri: "tezos-storage:here"},
+		{name: "tezos-storage other contract", uri: "tezos-storage://KT1PWx2mnDueood7fEmfbBDKx1D9BAnnXitn.NetXdQprcVkpaWU/key%20with%2Fslash"},
+		{name: "sha256", uri: fmt.Sprintf("sha256://0x%s/https:%%2F%%2Fexample.com%%2Ftoken.json", hex.EncodeToString(hash[:]))},
+		{name: "sha256 mismatch", uri: fmt.Sprintf("sha256://0x%s/https:%%2F%%2Fexample.com%%2Ftoken.json", hex.EncodeToString(make([]byte, 32))), wantErr: true},
+		{name: "unknown scheme", uri: "ftp://example.com/token.json", wantErr: true},
+		{name: "missing key", uri: "tezos-storage:missing", wantErr: true},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			data, gotErr := resolver.Fetch(context.Background(), testContract, test.uri)
+			if test.wantErr != (gotErr != nil) {
+				t.Errorf("wantErr: %t | err: %v", test.wantErr, gotErr)
+			}
+
+			if gotErr == nil && string(data) != string(document) {
+				t.Errorf("data: %s", data)
+			}
+		})
+	}
+}
+
+func Test_ResolveToken(t *testing.T) {
+	resolver := NewResolver(Fetchers{
+		SchemeHTTPS: mapFetcher{
+			"https://example.com/token.json": []byte(`{"name":"Off-chain","symbol":"OFF","decimals":8,"formats":[{"uri":"ipfs://QmCid","mimeType":"image/png"}],"attributes":[{"name":"color","value":"red"}],"tags":"malformed"}`),
+		},
+	}, nil)
+
+	metadata, err := resolver.ResolveToken(context.Background(), testContract, 1, map[string][]byte{
+		"":         []byte("https://example.com/token.json"),
+		"symbol":   []byte("ONC"),
+		"decimals": []byte("6"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info := metadata.Info
+	if info.Name != "Off-chain" || info.Symbol != "ONC" || info.Decimals != 6 {
+		t.Errorf("info: %+v", info)
+	}
+
+	if len(info.Formats) != 1 || info.Formats[0].MimeType != "image/png" || len(info.Attributes) != 1 || info.Tags != nil {
+		t.Errorf("info: %+v", info)
+	}
+
+	if metadata.URI != "https://example.com/token.json" || metadata.TokenID != 1 {
+		t.Errorf("metadata: %+v", metadata)
+	}
+}
+
+func Test_HTTPFetcherMaxSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name":"Token with long name"}`))
+	}))
+	defer server.Close()
+
+	uri, _ := url.Parse(server.URL)
+
+	_, err := (&HTTPFetcher{Client: server.Client(), MaxSize: 1024}).Fetch(context.Background(), uri)
+	if err != nil {
+		t.Errorf("err: %v", err)
+	}
+
+	_, err = (&HTTPFetcher{Client: server.Client(), MaxSize: 8}).Fetch(context.Background(), uri)
+	if err == nil {
+		t.Errorf("expected size error")
+	}
+}
+
+func Test_PublicFetcher(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name":"Internal"}`))
+	}))
+	defer server.Close()
+
+	fetchers := NewFetchers(conf.Metadata{})
+	if _, ok := fetchers["http"]; ok {
+		t.Error("plain http is allowed")
+	}
+
+	testCases := []struct {
+		name string
+		uri  string
+	}{
+		{name: "Loopback", uri: server.URL},
+		{name: "Link-local", uri: "https://169.254.169.254/latest/meta-data"},
+		{name: "Private", uri: "https://10.0.0.1/token.json"},
+		{name: "IPv6 loopback", uri: "https://[::1]/token.json"},
+		{name: "Localhost", uri: "https://localhost/token.json"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			uri, _ := url.Parse(test.uri)
+
+			if _, err := fetchers[SchemeHTTPS].Fetch(context.Background(), uri); err == nil {
+				t.Errorf("%s is fetched", test.uri)
+			}
+		})
+	}
+
+	//Dial time check blocks addresses which passed resolution
+	publicClient := newPublicClient(time.Second)
+	publicClient.Transport.(*http.Transport).TLSClientConfig = server.Client().Transport.(*http.Transport).TLSClientConfig
+
+	uri, _ := url.Parse(server.URL)
+
+	_, err := (&HTTPFetcher{Client: publicClient, MaxSize: 1024}).Fetch(context.Background(), uri)
+	if err == nil {
+		t.Error("loopback address is dialed")
+	}
+}
+
+func Test_IsPublicIP(t *testing.T) {
+	for ip, expPublic := range map[string]bool{
+		"1.1.1.1":         true,
+		"2606:4700::1111": true,
+		"127.0.0.1":       false,
+		"10.1.2.3":        false,
+		"172.20.0.1":      false,
+		"192.168.1.1":     false,
+		"169.254.169.254": false,
+		"100.64.0.1":      false,
+		"0.0.0.0":         false,
+		"224.0.0.1":       false,
+		"::1":             false,
+		"fe80::1":         false,
+		"fd00::1":         false,
+		"::ffff:10.0.0.1": false,
+	} {
+		if isPublicIP(net.ParseIP(ip)) != expPublic {
+			t.Errorf("ip: %s | public: %t", ip, !expPublic)
+		}
+	}
+}
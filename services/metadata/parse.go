@@ -0,0 +1,96 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"tezosign/models"
+)
+
+//TZIP-21 fields
+const (
+	fieldName            = "name"
+	fieldSymbol          = "symbol"
+	fieldDecimals        = "decimals"
+	fieldDescription     = "description"
+	fieldThumbnailURI    = "thumbnailUri"
+	fieldDisplayURI      = "displayUri"
+	fieldArtifactURI     = "artifactUri"
+	fieldIsBooleanAmount = "isBooleanAmount"
+	fieldTags            = "tags"
+	fieldFormats         = "formats"
+	fieldAttributes      = "attributes"
+)
+
+//ParseTokenInfo parses TZIP-21 fields, on-chain token_info values override off-chain document
+//Malformed optional fields are skipped
+func ParseTokenInfo(onChain map[string][]byte, offChain []byte) (info models.TokenMetadataInfo, err error) {
+	fields := map[string]json.RawMessage{}
+
+	if len(offChain) > 0 {
+		err = json.Unmarshal(offChain, &fields)
+		if err != nil {
+			return info, fmt.Errorf("wrong off-chain metadata: %s", err.Error())
+		}
+	}
+
+	for key, value := range onChain {
+		if key == TokenInfoURIKey {
+			continue
+		}
+		fields[key] = onChainValue(value)
+	}
+
+	decodeField(fields, fieldName, &info.Name)
+	decodeField(fields, fieldSymbol, &info.Symbol)
+	decodeField(fields, fieldDescription, &info.Description)
+	decodeField(fields, fieldThumbnailURI, &info.ThumbnailURI)
+	decodeField(fields, fieldDisplayURI, &info.DisplayURI)
+	decodeField(fields, fieldArtifactURI, &info.ArtifactURI)
+	decodeField(fields, fieldTags, &info.Tags)
+	decodeField(fields, fieldFormats, &info.Formats)
+	decodeField(fields, fieldAttributes, &info.Attributes)
+
+	//Decimals and flags are strings on-chain and numbers/booleans off-chain
+	if raw, ok := fields[fieldDecimals]; ok {
+		decimals, err := strconv.ParseUint(unquote(raw), 10, 8)
+		if err == nil {
+			info.Decimals = uint8(decimals)
+		}
+	}
+
+	if raw, ok := fields[fieldIsBooleanAmount]; ok {
+		info.IsBooleanAmount, _ = strconv.ParseBool(unquote(raw))
+	}
+
+	return info, nil
+}
+
+//On-chain values are UTF8 bytes, complex values are JSON encoded
+func onChainValue(value []byte) json.RawMessage {
+	trimmed := strings.TrimSpace(string(value))
+	if (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) && json.Valid(value) {
+		return value
+	}
+
+	bt, _ := json.Marshal(string(value))
+	return bt
+}
+
+func decodeField(fields map[string]json.RawMessage, key string, v interface{}) {
+	raw, ok := fields[key]
+	if !ok {
+		return
+	}
+
+	_ = json.Unmarshal(raw, v)
+}
+
+func unquote(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return string(raw)
+}
@@ -1,7 +1,6 @@
 package services
 
 import (
-	"context"
 	"fmt"
 	"tezosign/common/apperrors"
 	"tezosign/models"
@@ -38,7 +37,10 @@ func (s *ServiceFacade) GetOperationsList(userPubKey types.PubKey, contractID ty
 
 func (s *ServiceFacade) CheckOperations() (counter int64, err error) {
 	//Init transaction
-	s.repoProvider.Start(context.Background())
+	err = s.repoProvider.Start(s.ctx)
+	if err != nil {
+		return counter, err
+	}
 	defer s.repoProvider.RollbackUnlessCommitted()
 
 	// Get contracts
@@ -54,7 +56,7 @@ func (s *ServiceFacade) CheckOperations() (counter int64, err error) {
 		return counter, err
 	}
 
-	networkID, err := s.rpcClient.ChainID(context.Background())
+	networkID, err := s.rpcClient.ChainID(s.ctx)
 	if err != nil {
 		return counter, err
 	}
@@ -104,6 +106,11 @@ func (s *ServiceFacade) processOperations(repo contractRepo.Repo, indexerRepo in
 		return counter, apperrors.New(apperrors.ErrNotFound, "contract")
 	}
 
+	actions, err := contract.NewMultisigActions(script.ParameterSchema.MichelinePrim())
+	if err != nil {
+		return counter, err
+	}
+
 	for j := range operations {
 		//Not success tx
 		if operations[j].Status != 1 {
@@ -137,7 +144,7 @@ func (s *ServiceFacade) processOperations(repo contractRepo.Repo, indexerRepo in
 		}
 
 		//Parse value
-		counter, isReject, err := contract.GetOperationCounter(contract.Operation{
+		counter, isReject, err := contract.GetOperationCounter(actions, contract.Operation{
 			Entrypoint: operations[j].Entrypoint,
 			Value:      operations[j].RawParameters.MichelinePrim(),
 		})
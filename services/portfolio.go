@@ -0,0 +1,260 @@
+package services
+
+import (
+	"tezosign/common/apperrors"
+	"tezosign/common/log"
+	"tezosign/models"
+	"tezosign/services/pricing"
+	"tezosign/types"
+	"time"
+
+	"github.com/wedancedalot/decimal"
+	"go.uber.org/zap"
+)
+
+const (
+	portfolioChangeDay  = 24 * time.Hour
+	portfolioChangeWeek = 7 * 24 * time.Hour
+)
+
+//Portfolio values XTZ, tokens and vestings of contract, vestings and changes are visible for owners only
+func (s *ServiceFacade) Portfolio(userPubKey types.PubKey, contractAddress types.Address) (portfolio models.Portfolio, err error) {
+	c, isFound, err := s.repoProvider.GetContract().GetContract(contractAddress)
+	if err != nil {
+		return portfolio, err
+	}
+
+	if !isFound {
+		return portfolio, apperrors.New(apperrors.ErrNotFound, "contract")
+	}
+
+	isOwner, err := s.GetUserAllowance(userPubKey, contractAddress)
+	if err != nil {
+		return portfolio, err
+	}
+
+	quote, err := s.indexerRepoProvider.GetIndexer().GetTezosQuote()
+	if err != nil {
+		return portfolio, err
+	}
+
+	prices, err := s.lastAssetPrices()
+	if err != nil {
+		return portfolio, err
+	}
+
+	portfolio, err = s.buildPortfolio(c, isOwner, prices, quote)
+	if err != nil {
+		return portfolio, err
+	}
+
+	//Snapshots are built with owner assets, totals of viewer are not comparable
+	if !isOwner {
+		return portfolio, nil
+	}
+
+	portfolioRepo := s.repoProvider.GetPortfolio()
+	now := time.Now()
+
+	daySnapshot, isFound, err := portfolioRepo.GetSnapshotBefore(c.ID, now.Add(-portfolioChangeDay))
+	if err != nil {
+		return portfolio, err
+	}
+
+	if isFound {
+		portfolio.Change24h = models.NewPortfolioChange(portfolio.TotalXTZ, daySnapshot.TotalXTZ)
+	}
+
+	weekSnapshot, isFound, err := portfolioRepo.GetSnapshotBefore(c.ID, now.Add(-portfolioChangeWeek))
+	if err != nil {
+		return portfolio, err
+	}
+
+	if isFound {
+		portfolio.Change7d = models.NewPortfolioChange(portfolio.TotalXTZ, weekSnapshot.TotalXTZ)
+	}
+
+	return portfolio, nil
+}
+
+func (s *ServiceFacade) PortfolioHistory(contractAddress types.Address, params models.CommonParams) (snapshots []models.PortfolioSnapshot, err error) {
+	c, isFound, err := s.repoProvider.GetContract().GetContract(contractAddress)
+	if err != nil {
+		return snapshots, err
+	}
+
+	if !isFound {
+		return snapshots, apperrors.New(apperrors.ErrNotFound, "contract")
+	}
+
+	return s.repoProvider.GetPortfolio().GetSnapshots(c.ID, params)
+}
+
+//SamplePortfolios saves total value of all contracts
+func (s *ServiceFacade) SamplePortfolios() (count uint64, err error) {
+	quote, err := s.indexerRepoProvider.GetIndexer().GetTezosQuote()
+	if err != nil {
+		return count, err
+	}
+
+	prices, err := s.lastAssetPrices()
+	if err != nil {
+		return count, err
+	}
+
+	contractRepo := s.repoProvider.GetContract()
+	for offset := 0; ; offset += contractsBatchSize {
+		contracts, err := contractRepo.GetContractsList(contractsBatchSize, offset)
+		if err != nil {
+			return count, err
+		}
+
+		now := types.JSONTimestamp(time.Now())
+		snapshots := make([]models.PortfolioSnapshot, 0, len(contracts))
+		for i := range contracts {
+			portfolio, err := s.buildPortfolio(contracts[i], true, prices, quote)
+			if err != nil {
+				log.Ctx(s.ctx).Error("portfolio valuation failed", zap.String("contract", contracts[i].Address.String()), zap.Error(err))
+				continue
+			}
+
+			snapshots = append(snapshots, models.PortfolioSnapshot{
+				ContractID: contracts[i].ID,
+				TotalXTZ:   portfolio.TotalXTZ,
+				TotalUSD:   portfolio.TotalXTZ.Mul(quote.Usd),
+				CreatedAt:  now,
+			})
+		}
+
+		err = s.repoProvider.GetPortfolio().SaveSnapshots(snapshots)
+		if err != nil {
+			return count, err
+		}
+
+		count += uint64(len(snapshots))
+
+		if len(contracts) < contractsBatchSize {
+			return count, nil
+		}
+	}
+}
+
+func (s *ServiceFacade) lastAssetPrices() (prices map[pricing.TokenRef]decimal.Decimal, err error) {
+	snapshots, err := s.repoProvider.GetPrice().GetLastPriceSnapshots()
+	if err != nil {
+		return prices, err
+	}
+
+	prices = make(map[pricing.TokenRef]decimal.Decimal, len(snapshots))
+	for i := range snapshots {
+		prices[pricing.TokenRef{Address: snapshots[i].Asset, TokenID: snapshots[i].TokenID}] = snapshots[i].Price
+	}
+
+	return prices, nil
+}
+
+func (s *ServiceFacade) buildPortfolio(c models.Contract, isOwner bool, prices map[pricing.TokenRef]decimal.Decimal, quote models.Quote) (portfolio models.Portfolio, err error) {
+	indexerRepo := s.indexerRepoProvider.GetIndexer()
+
+	acc, isFound, err := indexerRepo.GetAccount(c.Address)
+	if err != nil {
+		return portfolio, err
+	}
+
+	if !isFound {
+		return portfolio, apperrors.New(apperrors.ErrNotFound, "contract")
+	}
+
+	items := []models.PortfolioItem{xtzPortfolioItem(models.PortfolioItemXTZ, c.Address, acc.Balance)}
+
+	assets, err := s.repoProvider.GetAsset().GetAssetsList(c.ID, isOwner, true, false)
+	if err != nil {
+		return portfolio, err
+	}
+
+	tokensMap, err := s.getContractTokensBalancesMap(c.Address)
+	if err != nil {
+		return portfolio, err
+	}
+
+	for i := range assets {
+		items = append(items, tokenPortfolioItem(assets[i], tokensMap[assets[i].Address], prices))
+	}
+
+	//Vestings are hidden for viewers
+	if isOwner {
+		vestings, err := s.repoProvider.GetVesting().GetVestingsList(c.ID)
+		if err != nil {
+			return portfolio, err
+		}
+
+		for i := range vestings {
+			vestingAcc, _, err := indexerRepo.GetAccount(vestings[i].Address)
+			if err != nil {
+				return portfolio, err
+			}
+
+			item := xtzPortfolioItem(models.PortfolioItemVesting, vestings[i].Address, vestingAcc.Balance)
+			item.Name = vestings[i].Name
+			items = append(items, item)
+		}
+	}
+
+	return newPortfolio(c.Address, items, quote), nil
+}
+
+func xtzPortfolioItem(kind models.PortfolioItemKind, address types.Address, mutez uint64) models.PortfolioItem {
+	balance := decimal.New(int64(mutez), -TezosPrecision)
+
+	return models.PortfolioItem{
+		Kind:     kind,
+		Address:  address,
+		Ticker:   "XTZ",
+		Balance:  balance,
+		XTZValue: balance,
+	}
+}
+
+func tokenPortfolioItem(asset models.Asset, balances []models.TokenBalance, prices map[pricing.TokenRef]decimal.Decimal) models.PortfolioItem {
+	token := assetTokenRef(asset)
+
+	item := models.PortfolioItem{
+		Kind:    models.PortfolioItemToken,
+		Address: asset.Address,
+		Name:    asset.Name,
+		Ticker:  asset.Ticker,
+		TokenID: asset.TokenID,
+	}
+
+	for i := range balances {
+		if balances[i].TokenId == token.TokenID {
+			item.Balance = balances[i].Balance.Div(decimal.New(1, int32(asset.Scale)))
+			break
+		}
+	}
+
+	//Token without DEX price not valued
+	price, ok := prices[token]
+	if !ok {
+		return item
+	}
+
+	item.Price = &price
+	item.XTZValue = item.Balance.Mul(price).Truncate(TezosPrecision)
+
+	return item
+}
+
+func newPortfolio(address types.Address, items []models.PortfolioItem, quote models.Quote) (portfolio models.Portfolio) {
+	portfolio.Address = address
+	portfolio.Items = items
+
+	for i := range portfolio.Items {
+		portfolio.Items[i].Fiat = quote.Convert(portfolio.Items[i].XTZValue)
+		portfolio.TotalXTZ = portfolio.TotalXTZ.Add(portfolio.Items[i].XTZValue)
+	}
+
+	portfolio.TotalFiat = quote.Convert(portfolio.TotalXTZ)
+
+	return portfolio
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"testing"
+	"tezosign/models"
+	"tezosign/services/pricing"
+
+	"github.com/wedancedalot/decimal"
+)
+
+func Test_Portfolio(t *testing.T) {
+	tokenID := uint64(1)
+
+	assets := []models.Asset{
+		{Address: "KT1K9gCRgaLRFKTErYt1wVxA3Frb9FjasjTV", Scale: 2, Ticker: "TKN"},
+		{Address: "KT1PWx2mnDueood7fEmfbBDKx1D9BAnnXitn", Scale: 0, Ticker: "NFT", TokenID: &tokenID},
+	}
+
+	balances := map[int][]models.TokenBalance{
+		0: {{Balance: decimal.New(1500, 0)}},
+		1: {{Balance: decimal.New(5, 0), TokenId: 0}, {Balance: decimal.New(3, 0), TokenId: 1}},
+	}
+
+	prices := map[pricing.TokenRef]decimal.Decimal{
+		{Address: assets[0].Address}: decimal.New(2, 0),
+	}
+
+	items := []models.PortfolioItem{xtzPortfolioItem(models.PortfolioItemXTZ, "KT1Contract", 2500000)}
+	for i := range assets {
+		items = append(items, tokenPortfolioItem(assets[i], balances[i], prices))
+	}
+
+	portfolio := newPortfolio("KT1Contract", items, models.Quote{Usd: decimal.New(3, 0)})
+
+	testCases := []struct {
+		name     string
+		item     models.PortfolioItem
+		balance  decimal.Decimal
+		xtzValue decimal.Decimal
+	}{
+		{name: "XTZ", item: portfolio.Items[0], balance: decimal.New(25, -1), xtzValue: decimal.New(25, -1)},
+		{name: "Scaled token", item: portfolio.Items[1], balance: decimal.New(15, 0), xtzValue: decimal.New(30, 0)},
+		{name: "Token without price", item: portfolio.Items[2], balance: decimal.New(3, 0), xtzValue: decimal.Zero},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			if !test.item.Balance.Equal(test.balance) || !test.item.XTZValue.Equal(test.xtzValue) {
+				t.Errorf("balance: %s | xtz value: %s", test.item.Balance, test.item.XTZValue)
+			}
+		})
+	}
+
+	if !portfolio.TotalXTZ.Equal(decimal.New(325, -1)) || !portfolio.TotalFiat["usd"].Equal(decimal.New(975, -1)) {
+		t.Errorf("total: %s | usd: %s", portfolio.TotalXTZ, portfolio.TotalFiat["usd"])
+	}
+
+	change := models.NewPortfolioChange(portfolio.TotalXTZ, decimal.New(25, 0))
+	if !change.XTZ.Equal(decimal.New(75, -1)) || !change.Percent.Equal(decimal.New(30, 0)) {
+		t.Errorf("change: %s | percent: %s", change.XTZ, change.Percent)
+	}
+}
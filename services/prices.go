@@ -0,0 +1,310 @@
+package services
+
+import (
+	"math/big"
+	"tezosign/common/apperrors"
+	"tezosign/common/log"
+	"tezosign/conf"
+	"tezosign/models"
+	"tezosign/services/pricing"
+	"tezosign/types"
+	"time"
+
+	"github.com/wedancedalot/decimal"
+	"go.uber.org/zap"
+)
+
+const (
+	//Max contracts checked as pool candidates per asset
+	poolCandidatesLimit  = 50
+	poolsDiscoveryPeriod = 24 * time.Hour
+	//XTZ
+	defaultMinPoolLiquidity = 100
+)
+
+//Price source state for single cron run, pools are read once
+type priceSource struct {
+	s      *ServiceFacade
+	dex    conf.Dex
+	states map[types.Address]*pricing.Pool
+}
+
+func (s *ServiceFacade) newPriceSource() *priceSource {
+	network, _ := s.cfg.Network(s.net)
+
+	return &priceSource{
+		s:      s,
+		dex:    network.Dex,
+		states: map[types.Address]*pricing.Pool{},
+	}
+}
+
+//Current pool reserves, nil if address is not trusted supported DEX
+func (p *priceSource) pool(address types.Address) (*pricing.Pool, error) {
+	pool, ok := p.states[address]
+	if ok {
+		return pool, nil
+	}
+
+	origin, isFound, err := p.s.indexerRepoProvider.GetIndexer().GetContractOrigin(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isFound || !isTrustedPool(p.dex, origin) {
+		p.states[address] = nil
+		return nil, nil
+	}
+
+	script, storage, err := p.s.getContractScriptAndStorage(address)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := pricing.ParsePool(address, script.StorageSchema.MichelinePrim(), storage.RawValue.MichelinePrim())
+	if err == nil {
+		//Storage XTZ counter is maintained by contract code, real balance is used
+		if state.Right.Token.IsXTZ() {
+			state.Right.Amount = new(big.Int).SetUint64(origin.Balance)
+		}
+
+		pool = &state
+	}
+
+	p.states[address] = pool
+
+	return pool, nil
+}
+
+//Anyone can deploy contract with pool-like storage, only pools of known factories or code are used
+func isTrustedPool(dex conf.Dex, origin models.ContractOrigin) bool {
+	if origin.CreatorAddress.Valid {
+		for _, factory := range dex.Factories {
+			if origin.CreatorAddress.String == factory {
+				return true
+			}
+		}
+	}
+
+	if origin.CodeHash.Valid {
+		for _, codeHash := range dex.CodeHashes {
+			if origin.CodeHash.Int64 == codeHash {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (p *priceSource) minLiquidity() decimal.Decimal {
+	if p.dex.MinLiquidity > 0 {
+		return decimal.New(p.dex.MinLiquidity, 0)
+	}
+
+	return decimal.New(defaultMinPoolLiquidity, 0)
+}
+
+//Known pools of token, refreshed by indexer storages search
+func (p *priceSource) tokenPools(asset models.Asset, token pricing.TokenRef) (pools []pricing.Pool, err error) {
+	priceRepo := p.s.repoProvider.GetPrice()
+
+	known, err := priceRepo.GetAssetPools(token.Address, token.TokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(known) == 0 || time.Since(known[0].UpdatedAt) > poolsDiscoveryPeriod {
+		known, err = p.discoverPools(asset, token)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range known {
+		pool, err := p.pool(known[i].Address)
+		if err != nil {
+			log.Ctx(p.s.ctx).Warn("pool state failed", zap.String("pool", known[i].Address.String()), zap.Error(err))
+			continue
+		}
+
+		if pool == nil {
+			continue
+		}
+
+		pools = append(pools, *pool)
+	}
+
+	return pools, nil
+}
+
+func (p *priceSource) discoverPools(asset models.Asset, token pricing.TokenRef) (pools []models.DexPool, err error) {
+	candidates, err := p.s.indexerRepoProvider.GetIndexer().GetContractsStoragesMentioning(token.Address, p.dex.Factories, p.dex.CodeHashes, poolCandidatesLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	//Legacy exchange address
+	if asset.DexterAddress != nil {
+		candidates = append(candidates, types.Address(*asset.DexterAddress))
+	}
+
+	now := time.Now()
+	for i := range candidates {
+		pool, err := p.pool(candidates[i])
+		if err != nil {
+			log.Ctx(p.s.ctx).Debug("pool candidate skipped", zap.String("contract", candidates[i].String()), zap.Error(err))
+			continue
+		}
+
+		if pool == nil {
+			continue
+		}
+
+		if _, _, ok := pool.Sides(token); !ok {
+			continue
+		}
+
+		pools = append(pools, models.DexPool{
+			DEX:       pool.DEX,
+			Address:   pool.Address,
+			Asset:     token.Address,
+			TokenID:   token.TokenID,
+			UpdatedAt: now,
+		})
+	}
+
+	err = p.s.repoProvider.GetPrice().SavePools(pools)
+	if err != nil {
+		return nil, err
+	}
+
+	return pools, nil
+}
+
+func assetTokenRef(asset models.Asset) pricing.TokenRef {
+	token := pricing.TokenRef{Address: asset.Address}
+	if asset.TokenID != nil {
+		token.TokenID = *asset.TokenID
+	}
+	return token
+}
+
+//RefreshAssetPrices saves aggregated price snapshots of active assets
+func (s *ServiceFacade) RefreshAssetPrices() (count uint64, err error) {
+	assets, err := s.repoProvider.GetAsset().GetAssetsList(0, false, true, true)
+	if err != nil {
+		return count, err
+	}
+
+	source := s.newPriceSource()
+	market := pricing.NewMarket()
+
+	tokens := make([]pricing.TokenRef, 0, len(assets))
+	tokenPools := map[pricing.TokenRef][]pricing.Pool{}
+
+	for i := range assets {
+		token := assetTokenRef(assets[i])
+		if _, ok := market.Scales[token]; ok {
+			continue
+		}
+
+		market.Scales[token] = assets[i].Scale
+		tokens = append(tokens, token)
+
+		tokenPools[token], err = source.tokenPools(assets[i], token)
+		if err != nil {
+			log.Ctx(s.ctx).Error("asset pools failed", zap.String("asset", token.Address.String()), zap.Error(err))
+		}
+	}
+
+	//Token to token pools are priced by counter token XTZ price
+	for _, token := range tokens {
+		price, _, err := pricing.Aggregate(poolPrices(market, token, tokenPools[token], true, source.minLiquidity()))
+		if err != nil {
+			continue
+		}
+
+		market.Prices[token] = price
+	}
+
+	now := types.JSONTimestamp(time.Now())
+	snapshots := make([]models.AssetPriceSnapshot, 0, len(tokens))
+	for _, token := range tokens {
+		prices := poolPrices(market, token, tokenPools[token], false, source.minLiquidity())
+
+		price, liquidity, err := pricing.Aggregate(prices)
+		if err != nil {
+			continue
+		}
+
+		snapshots = append(snapshots, models.AssetPriceSnapshot{
+			Asset:      token.Address,
+			TokenID:    token.TokenID,
+			Price:      price,
+			Liquidity:  liquidity,
+			PoolsCount: uint64(len(prices)),
+			CreatedAt:  now,
+		})
+	}
+
+	err = s.repoProvider.GetPrice().SavePriceSnapshots(snapshots)
+	if err != nil {
+		return count, err
+	}
+
+	return uint64(len(snapshots)), nil
+}
+
+//Prices of token by pools, not priced and shallow pools are skipped
+func poolPrices(market pricing.Market, token pricing.TokenRef, pools []pricing.Pool, onlyXTZ bool, minLiquidity decimal.Decimal) (prices []pricing.PoolPrice) {
+	for _, pool := range pools {
+		_, counter, _ := pool.Sides(token)
+		if onlyXTZ && !counter.Token.IsXTZ() {
+			continue
+		}
+
+		price, err := market.PriceByPool(pool, token)
+		if err != nil || price.Liquidity.LessThan(minLiquidity) {
+			continue
+		}
+
+		prices = append(prices, price)
+	}
+
+	return prices
+}
+
+//GetAssetPrice last aggregated price with fiat conversion
+func (s *ServiceFacade) GetAssetPrice(asset types.Address, tokenID uint64) (resp models.AssetPrice, err error) {
+	priceRepo := s.repoProvider.GetPrice()
+
+	snapshot, isFound, err := priceRepo.GetLastPriceSnapshot(asset, tokenID)
+	if err != nil {
+		return resp, err
+	}
+
+	if !isFound {
+		return resp, apperrors.New(apperrors.ErrNotFound, "price")
+	}
+
+	pools, err := priceRepo.GetAssetPools(asset, tokenID)
+	if err != nil {
+		return resp, err
+	}
+
+	quote, err := s.indexerRepoProvider.GetIndexer().GetTezosQuote()
+	if err != nil {
+		return resp, err
+	}
+
+	return models.AssetPrice{
+		AssetPriceSnapshot: snapshot,
+		Fiat:               quote.Convert(snapshot.Price),
+		Pools:              pools,
+	}, nil
+}
+
+func (s *ServiceFacade) AssetPriceHistory(asset types.Address, tokenID uint64, params models.CommonParams) (snapshots []models.AssetPriceSnapshot, err error) {
+	return s.repoProvider.GetPrice().GetPriceSnapshots(asset, tokenID, params)
+}
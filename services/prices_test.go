@@ -0,0 +1,50 @@
+package services
+
+import (
+	"database/sql"
+	"testing"
+	"tezosign/conf"
+	"tezosign/models"
+)
+
+func Test_IsTrustedPool(t *testing.T) {
+	dex := conf.Dex{
+		Factories:  []string{"KT1factory"},
+		CodeHashes: []int64{42},
+	}
+
+	testCases := []struct {
+		name   string
+		origin models.ContractOrigin
+		result bool
+	}{
+		{
+			name:   "Known factory",
+			origin: models.ContractOrigin{CreatorAddress: sql.NullString{String: "KT1factory", Valid: true}},
+			result: true,
+		},
+		{
+			name:   "Known code hash",
+			origin: models.ContractOrigin{CreatorAddress: sql.NullString{String: "tz1user", Valid: true}, CodeHash: sql.NullInt64{Int64: 42, Valid: true}},
+			result: true,
+		},
+		{
+			name:   "Unknown creator and code",
+			origin: models.ContractOrigin{CreatorAddress: sql.NullString{String: "tz1user", Valid: true}, CodeHash: sql.NullInt64{Int64: 7, Valid: true}},
+			result: false,
+		},
+		{
+			name:   "No origin info",
+			origin: models.ContractOrigin{},
+			result: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if res := isTrustedPool(dex, tc.origin); res != tc.result {
+				t.Errorf("expected %v got %v", tc.result, res)
+			}
+		})
+	}
+}
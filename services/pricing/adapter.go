@@ -0,0 +1,222 @@
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"tezosign/services/contract"
+	"tezosign/types"
+
+	"blockwatch.cc/tzindex/micheline"
+)
+
+const (
+	DEXDexter    = "dexter"
+	DEXQuipuswap = "quipuswap"
+	DEXPlenty    = "plenty"
+)
+
+//Token reference, empty address means XTZ
+type TokenRef struct {
+	Address types.Address
+	TokenID uint64
+}
+
+func (t TokenRef) IsXTZ() bool {
+	return t.Address == ""
+}
+
+type Reserve struct {
+	Token  TokenRef
+	Amount *big.Int
+}
+
+//Constant product pool state
+type Pool struct {
+	DEX     string
+	Address types.Address
+	Left    Reserve
+	Right   Reserve
+}
+
+//Side of pool with token and counter side
+func (p Pool) Sides(token TokenRef) (reserve Reserve, counter Reserve, ok bool) {
+	switch token {
+	case p.Left.Token:
+		return p.Left, p.Right, true
+	case p.Right.Token:
+		return p.Right, p.Left, true
+	default:
+		return reserve, counter, false
+	}
+}
+
+//Adapter reads pool state from DEX storage layout
+type Adapter interface {
+	DEX() string
+	Pool(e contract.Entrypoints, storage *micheline.Prim) (Pool, error)
+}
+
+var errLayout = errors.New("not matched storage layout")
+
+//Adapters are checked in order, first matched layout is used
+var Adapters = []Adapter{
+	quipuswapAdapter{},
+	dexterAdapter{},
+	plentyAdapter{},
+}
+
+//ParsePool detects DEX by storage layout and reads pool reserves
+func ParsePool(address types.Address, storageSchema, storage *micheline.Prim) (pool Pool, err error) {
+	e, err := contract.InitAnnotsEntrypoints(storageSchema)
+	if err != nil {
+		return pool, err
+	}
+
+	for _, adapter := range Adapters {
+		pool, err = adapter.Pool(e, storage)
+		if errors.Is(err, errLayout) {
+			continue
+		}
+		if err != nil {
+			return pool, fmt.Errorf("%s pool: %w", adapter.DEX(), err)
+		}
+
+		pool.DEX = adapter.DEX()
+		pool.Address = address
+		return pool, nil
+	}
+
+	return pool, errLayout
+}
+
+//Quipuswap: token_pool, tez_pool, token_address, token_id for FA2
+type quipuswapAdapter struct{}
+
+func (quipuswapAdapter) DEX() string {
+	return DEXQuipuswap
+}
+
+func (quipuswapAdapter) Pool(e contract.Entrypoints, storage *micheline.Prim) (pool Pool, err error) {
+	return xtzPool(e, storage, "tokenpool", "tezpool", "tokenaddress", "tokenid")
+}
+
+//Dexter: tokenPool, xtzPool, tokenAddress
+type dexterAdapter struct{}
+
+func (dexterAdapter) DEX() string {
+	return DEXDexter
+}
+
+func (dexterAdapter) Pool(e contract.Entrypoints, storage *micheline.Prim) (pool Pool, err error) {
+	return xtzPool(e, storage, "tokenpool", "xtzpool", "tokenaddress", "tokenid")
+}
+
+//Plenty token to token swap: token1_pool, token2_pool, token1Address, token2Address, token1Id, token2Id
+type plentyAdapter struct{}
+
+func (plentyAdapter) DEX() string {
+	return DEXPlenty
+}
+
+func (plentyAdapter) Pool(e contract.Entrypoints, storage *micheline.Prim) (pool Pool, err error) {
+	if !hasAnnots(e, "token1pool", "token2pool", "token1address", "token2address") {
+		return pool, errLayout
+	}
+
+	pool.Left, err = tokenReserve(e, storage, "token1pool", "token1address", "token1id")
+	if err != nil {
+		return pool, err
+	}
+
+	pool.Right, err = tokenReserve(e, storage, "token2pool", "token2address", "token2id")
+	if err != nil {
+		return pool, err
+	}
+
+	return pool, nil
+}
+
+func xtzPool(e contract.Entrypoints, storage *micheline.Prim, tokenPoolAnno, xtzPoolAnno, tokenAddressAnno, tokenIDAnno string) (pool Pool, err error) {
+	if !hasAnnots(e, tokenPoolAnno, xtzPoolAnno, tokenAddressAnno) {
+		return pool, errLayout
+	}
+
+	pool.Left, err = tokenReserve(e, storage, tokenPoolAnno, tokenAddressAnno, tokenIDAnno)
+	if err != nil {
+		return pool, err
+	}
+
+	pool.Right.Amount, err = storageInt(e[xtzPoolAnno], storage)
+	if err != nil {
+		return pool, err
+	}
+
+	return pool, nil
+}
+
+func tokenReserve(e contract.Entrypoints, storage *micheline.Prim, poolAnno, addressAnno, tokenIDAnno string) (reserve Reserve, err error) {
+	reserve.Amount, err = storageInt(e[poolAnno], storage)
+	if err != nil {
+		return reserve, err
+	}
+
+	address, err := contract.GetStorageValue(e[addressAnno], storage)
+	if err != nil {
+		return reserve, err
+	}
+
+	reserve.Token.Address, err = primAddress(address)
+	if err != nil {
+		return reserve, err
+	}
+
+	//FA1.2 pools not contain token id
+	if entrypoint, ok := e[tokenIDAnno]; ok {
+		tokenID, err := storageInt(entrypoint, storage)
+		if err != nil {
+			return reserve, err
+		}
+		reserve.Token.TokenID = tokenID.Uint64()
+	}
+
+	return reserve, nil
+}
+
+func hasAnnots(e contract.Entrypoints, annots ...string) bool {
+	for _, anno := range annots {
+		if _, ok := e[anno]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func storageInt(entrypoint contract.Entrypoint, storage *micheline.Prim) (*big.Int, error) {
+	prim, err := contract.GetStorageValue(entrypoint, storage)
+	if err != nil {
+		return nil, err
+	}
+
+	if prim.Type != micheline.PrimInt || prim.Int == nil {
+		return nil, errors.New("wrong int value")
+	}
+
+	return prim.Int, nil
+}
+
+func primAddress(p *micheline.Prim) (address types.Address, err error) {
+	switch p.Type {
+	case micheline.PrimBytes:
+		err = address.UnmarshalBinary(p.Bytes)
+		if err != nil {
+			return address, err
+		}
+	case micheline.PrimString:
+		address = types.Address(p.String)
+	default:
+		return address, errors.New("wrong address value")
+	}
+
+	return address, nil
+}
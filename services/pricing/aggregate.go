@@ -0,0 +1,86 @@
+package pricing
+
+import (
+	"errors"
+
+	"github.com/wedancedalot/decimal"
+)
+
+const XTZScale = 6
+
+//Price of token in XTZ by single pool
+type PoolPrice struct {
+	Pool Pool
+	//XTZ per whole token
+	Price decimal.Decimal
+	//XTZ value of counter side reserve
+	Liquidity decimal.Decimal
+}
+
+//Known token prices and scales used to price token to token pools
+type Market struct {
+	Scales map[TokenRef]uint8
+	Prices map[TokenRef]decimal.Decimal
+}
+
+func NewMarket() Market {
+	return Market{
+		Scales: map[TokenRef]uint8{},
+		Prices: map[TokenRef]decimal.Decimal{},
+	}
+}
+
+//PriceByPool token price in XTZ, counter token must be XTZ or priced in market
+func (m Market) PriceByPool(pool Pool, token TokenRef) (price PoolPrice, err error) {
+	reserve, counter, ok := pool.Sides(token)
+	if !ok {
+		return price, errors.New("token not presented in pool")
+	}
+
+	scale, ok := m.Scales[token]
+	if !ok {
+		return price, errors.New("unknown token scale")
+	}
+
+	if reserve.Amount == nil || counter.Amount == nil || reserve.Amount.Sign() <= 0 || counter.Amount.Sign() <= 0 {
+		return price, errors.New("empty pool")
+	}
+
+	counterPrice := decimal.New(1, 0)
+	counterScale := uint8(XTZScale)
+	if !counter.Token.IsXTZ() {
+		counterPrice, ok = m.Prices[counter.Token]
+		if !ok {
+			return price, errors.New("counter token not priced")
+		}
+
+		counterScale, ok = m.Scales[counter.Token]
+		if !ok {
+			return price, errors.New("unknown counter token scale")
+		}
+	}
+
+	tokenAmount := decimal.NewFromBigInt(reserve.Amount, -int32(scale))
+	counterAmount := decimal.NewFromBigInt(counter.Amount, -int32(counterScale))
+
+	price.Pool = pool
+	price.Liquidity = counterAmount.Mul(counterPrice)
+	price.Price = price.Liquidity.Div(tokenAmount)
+
+	return price, nil
+}
+
+//Aggregate liquidity weighted average price
+func Aggregate(prices []PoolPrice) (price decimal.Decimal, liquidity decimal.Decimal, err error) {
+	weighted := decimal.Zero
+	for i := range prices {
+		weighted = weighted.Add(prices[i].Price.Mul(prices[i].Liquidity))
+		liquidity = liquidity.Add(prices[i].Liquidity)
+	}
+
+	if liquidity.Sign() <= 0 {
+		return price, liquidity, errors.New("no liquidity")
+	}
+
+	return weighted.Div(liquidity), liquidity, nil
+}
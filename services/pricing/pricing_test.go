@@ -0,0 +1,150 @@
+package pricing
+
+import (
+	"math/big"
+	"testing"
+	"tezosign/types"
+
+	"blockwatch.cc/tzindex/micheline"
+	"github.com/wedancedalot/decimal"
+)
+
+const (
+	testToken   types.Address = "KT1K9gCRgaLRFKTErYt1wVxA3Frb9FjasjTV"
+	testCounter types.Address = "KT1PWx2mnDueood7fEmfbBDKx1D9BAnnXitn"
+)
+
+func typePrim(opCode micheline.OpCode, anno string) *micheline.Prim {
+	return &micheline.Prim{Type: micheline.PrimNullaryAnno, OpCode: opCode, Anno: []string{"%" + anno}}
+}
+
+func pair(l, r *micheline.Prim) *micheline.Prim {
+	return &micheline.Prim{Type: micheline.PrimBinary, OpCode: micheline.T_PAIR, Args: []*micheline.Prim{l, r}}
+}
+
+func valuePair(l, r *micheline.Prim) *micheline.Prim {
+	return &micheline.Prim{Type: micheline.PrimBinary, OpCode: micheline.D_PAIR, Args: []*micheline.Prim{l, r}}
+}
+
+func intValue(v int64) *micheline.Prim {
+	return &micheline.Prim{Type: micheline.PrimInt, OpCode: micheline.T_INT, Int: big.NewInt(v)}
+}
+
+func stringValue(v types.Address) *micheline.Prim {
+	return &micheline.Prim{Type: micheline.PrimString, OpCode: micheline.T_STRING, String: v.String()}
+}
+
+func Test_ParsePool(t *testing.T) {
+	testCases := []struct {
+		name    string
+		schema  *micheline.Prim
+		storage *micheline.Prim
+		expPool Pool
+		wantErr bool
+	}{
+		{
+			name: "Quipuswap FA2",
+			schema: pair(
+				pair(typePrim(micheline.T_NAT, "token_pool"), typePrim(micheline.T_MUTEZ, "tez_pool")),
+				pair(typePrim(micheline.T_ADDRESS, "token_address"), typePrim(micheline.T_NAT, "token_id")),
+			),
+			storage: valuePair(valuePair(intValue(500), intValue(1000)), valuePair(stringValue(testToken), intValue(3))),
+			expPool: Pool{
+				DEX:   DEXQuipuswap,
+				Left:  Reserve{Token: TokenRef{Address: testToken, TokenID: 3}, Amount: big.NewInt(500)},
+				Right: Reserve{Amount: big.NewInt(1000)},
+			},
+		},
+		{
+			name: "Dexter",
+			schema: pair(
+				pair(typePrim(micheline.T_NAT, "tokenPool"), typePrim(micheline.T_MUTEZ, "xtzPool")),
+				typePrim(micheline.T_ADDRESS, "tokenAddress"),
+			),
+			storage: valuePair(valuePair(intValue(10), intValue(20)), stringValue(testToken)),
+			expPool: Pool{
+				DEX:   DEXDexter,
+				Left:  Reserve{Token: TokenRef{Address: testToken}, Amount: big.NewInt(10)},
+				Right: Reserve{Amount: big.NewInt(20)},
+			},
+		},
+		{
+			name: "Plenty",
+			schema: pair(
+				pair(typePrim(micheline.T_NAT, "token1_pool"), typePrim(micheline.T_NAT, "token2_pool")),
+				pair(typePrim(micheline.T_ADDRESS, "token1Address"), typePrim(micheline.T_ADDRESS, "token2Address")),
+			),
+			storage: valuePair(valuePair(intValue(7), intValue(8)), valuePair(stringValue(testToken), stringValue(testCounter))),
+			expPool: Pool{
+				DEX:   DEXPlenty,
+				Left:  Reserve{Token: TokenRef{Address: testToken}, Amount: big.NewInt(7)},
+				Right: Reserve{Token: TokenRef{Address: testCounter}, Amount: big.NewInt(8)},
+			},
+		},
+		{
+			name:    "Not DEX",
+			schema:  pair(typePrim(micheline.T_NAT, "counter"), typePrim(micheline.T_ADDRESS, "admin")),
+			storage: valuePair(intValue(1), stringValue(testToken)),
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			pool, err := ParsePool(testCounter, test.schema, test.storage)
+			if test.wantErr != (err != nil) {
+				t.Fatalf("wantErr: %t | err: %v", test.wantErr, err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if pool.DEX != test.expPool.DEX || pool.Left.Token != test.expPool.Left.Token || pool.Right.Token != test.expPool.Right.Token ||
+				pool.Left.Amount.Cmp(test.expPool.Left.Amount) != 0 || pool.Right.Amount.Cmp(test.expPool.Right.Amount) != 0 {
+				t.Errorf("pool: %+v", pool)
+			}
+		})
+	}
+}
+
+func Test_Aggregate(t *testing.T) {
+	token := TokenRef{Address: testToken}
+	counter := TokenRef{Address: testCounter}
+
+	market := NewMarket()
+	market.Scales[token] = 2
+	market.Scales[counter] = 0
+	market.Prices[counter] = decimal.New(2, 0)
+
+	pools := []Pool{
+		//100 tokens for 50 XTZ
+		{Left: Reserve{Token: token, Amount: big.NewInt(10000)}, Right: Reserve{Amount: big.NewInt(50000000)}},
+		//10 tokens for 10 counter tokens (20 XTZ)
+		{Left: Reserve{Token: counter, Amount: big.NewInt(10)}, Right: Reserve{Token: token, Amount: big.NewInt(1000)}},
+	}
+
+	prices := make([]PoolPrice, 0, len(pools))
+	for i := range pools {
+		price, err := market.PriceByPool(pools[i], token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		prices = append(prices, price)
+	}
+
+	price, liquidity, err := Aggregate(prices)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//(0.5*50 + 2*20) / 70
+	expPrice := decimal.New(65, 0).Div(decimal.New(70, 0))
+	if !price.Equal(expPrice) || !liquidity.Equal(decimal.New(70, 0)) {
+		t.Errorf("price: %s | liquidity: %s", price, liquidity)
+	}
+
+	if _, _, err = Aggregate(nil); err == nil {
+		t.Error("expected no liquidity error")
+	}
+}
@@ -0,0 +1,13 @@
+package services
+
+import "time"
+
+//Remove buckets which are already refilled, they are equal to new ones
+func (s *ServiceFacade) CleanupRateLimitBuckets() (count int64, err error) {
+	count, err = s.repoProvider.GetRateLimit().DeleteFullBuckets(time.Now())
+	if err != nil {
+		return count, err
+	}
+
+	return count, nil
+}
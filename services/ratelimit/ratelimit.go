@@ -0,0 +1,143 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"tezosign/conf"
+	"tezosign/models"
+	"tezosign/repos"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Limiter interface {
+	Take(ctx context.Context, key string, budget conf.RateLimitBudget) (allowed bool, retryAfter time.Duration, err error)
+}
+
+func New(cfg conf.RateLimit, db *gorm.DB) Limiter {
+	if cfg.IsPostgres() {
+		return NewPostgresLimiter(db)
+	}
+
+	return NewMemoryLimiter()
+}
+
+//Token bucket algorithm
+//Bucket is refilled continuously with budget.RefillRate() tokens per second up to budget.Requests tokens
+func take(bucket *models.RateLimitBucket, budget conf.RateLimitBudget, now time.Time) (allowed bool, retryAfter time.Duration) {
+	capacity := float64(budget.Requests)
+	rate := budget.RefillRate()
+
+	elapsed := now.Sub(bucket.UpdatedAt).Seconds()
+	if elapsed < 0 {
+		elapsed = 0
+	}
+
+	bucket.Tokens = math.Min(capacity, bucket.Tokens+elapsed*rate)
+	bucket.UpdatedAt = now
+
+	if bucket.Tokens >= 1 {
+		bucket.Tokens--
+		allowed = true
+	} else {
+		retryAfter = time.Duration((1 - bucket.Tokens) / rate * float64(time.Second))
+	}
+
+	bucket.FullAt = now.Add(time.Duration((capacity - bucket.Tokens) / rate * float64(time.Second)))
+
+	return allowed, retryAfter
+}
+
+func newBucket(key string, budget conf.RateLimitBudget, now time.Time) models.RateLimitBucket {
+	return models.RateLimitBucket{
+		Key:       key,
+		Tokens:    float64(budget.Requests),
+		UpdatedAt: now,
+		FullAt:    now,
+	}
+}
+
+const sweepInterval = 10 * time.Minute
+
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*models.RateLimitBucket
+	lastSweep time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		buckets:   map[string]*models.RateLimitBucket{},
+		lastSweep: time.Now(),
+	}
+}
+
+func (l *MemoryLimiter) Take(ctx context.Context, key string, budget conf.RateLimitBudget) (allowed bool, retryAfter time.Duration, err error) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	//Full buckets are equal to new ones so they can be dropped
+	if now.Sub(l.lastSweep) > sweepInterval {
+		for k, bucket := range l.buckets {
+			if bucket.FullAt.Before(now) {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		b := newBucket(key, budget, now)
+		bucket = &b
+		l.buckets[key] = bucket
+	}
+
+	allowed, retryAfter = take(bucket, budget, now)
+
+	return allowed, retryAfter, nil
+}
+
+type PostgresLimiter struct {
+	db *gorm.DB
+}
+
+func NewPostgresLimiter(db *gorm.DB) *PostgresLimiter {
+	return &PostgresLimiter{db: db}
+}
+
+func (l *PostgresLimiter) Take(ctx context.Context, key string, budget conf.RateLimitBudget) (allowed bool, retryAfter time.Duration, err error) {
+	now := time.Now()
+
+	repoProvider := repos.New(l.db)
+	err = repoProvider.Start(ctx)
+	if err != nil {
+		return false, 0, err
+	}
+	defer repoProvider.RollbackUnlessCommitted()
+
+	repo := repoProvider.GetRateLimit()
+
+	bucket, err := repo.GetOrCreateBucketForUpdate(newBucket(key, budget, now))
+	if err != nil {
+		return false, 0, err
+	}
+
+	allowed, retryAfter = take(&bucket, budget, now)
+
+	err = repo.UpdateBucket(bucket)
+	if err != nil {
+		return false, 0, err
+	}
+
+	err = repoProvider.Commit()
+	if err != nil {
+		return false, 0, err
+	}
+
+	return allowed, retryAfter, nil
+}
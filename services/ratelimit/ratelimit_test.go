@@ -0,0 +1,106 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"tezosign/conf"
+	"tezosign/models"
+	"time"
+)
+
+func Test_Take(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	budget := conf.RateLimitBudget{Requests: 2, Period: 10}
+
+	type args struct {
+		bucket models.RateLimitBucket
+		now    time.Time
+	}
+
+	testCases := []struct {
+		name          string
+		args          args
+		expAllowed    bool
+		expRetryAfter time.Duration
+		expTokens     float64
+	}{
+		{
+			name: "New bucket",
+			args: args{
+				bucket: newBucket("key", budget, now),
+				now:    now,
+			},
+			expAllowed: true,
+			expTokens:  1,
+		},
+		{
+			name: "Empty bucket",
+			args: args{
+				bucket: models.RateLimitBucket{Tokens: 0, UpdatedAt: now},
+				now:    now,
+			},
+			expAllowed:    false,
+			expRetryAfter: 5 * time.Second,
+			expTokens:     0,
+		},
+		{
+			name: "Partially refilled bucket",
+			args: args{
+				bucket: models.RateLimitBucket{Tokens: 0, UpdatedAt: now},
+				now:    now.Add(6 * time.Second),
+			},
+			expAllowed: true,
+			expTokens:  0.2,
+		},
+		{
+			name: "Refill not exceeds capacity",
+			args: args{
+				bucket: models.RateLimitBucket{Tokens: 1, UpdatedAt: now},
+				now:    now.Add(time.Hour),
+			},
+			expAllowed: true,
+			expTokens:  1,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			bucket := test.args.bucket
+			allowed, retryAfter := take(&bucket, budget, test.args.now)
+			if allowed != test.expAllowed {
+				t.Errorf("allowed %t != %t", allowed, test.expAllowed)
+			}
+
+			if retryAfter != test.expRetryAfter {
+				t.Errorf("retryAfter %s != %s", retryAfter, test.expRetryAfter)
+			}
+
+			if diff := bucket.Tokens - test.expTokens; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("tokens %f != %f", bucket.Tokens, test.expTokens)
+			}
+		})
+	}
+}
+
+func Test_MemoryLimiter(t *testing.T) {
+	limiter := NewMemoryLimiter()
+	budget := conf.RateLimitBudget{Requests: 3, Period: 3600}
+
+	for i := 0; i < 3; i++ {
+		allowed, _, err := limiter.Take(context.Background(), "ip", budget)
+		if err != nil || !allowed {
+			t.Fatalf("request %d should be allowed, err: %v", i, err)
+		}
+	}
+
+	allowed, retryAfter, err := limiter.Take(context.Background(), "ip", budget)
+	if err != nil || allowed || retryAfter <= 0 {
+		t.Errorf("request should be limited: allowed %t retryAfter %s err: %v", allowed, retryAfter, err)
+	}
+
+	//Other keys have own buckets
+	allowed, _, err = limiter.Take(context.Background(), "another_ip", budget)
+	if err != nil || !allowed {
+		t.Errorf("another key should be allowed, err: %v", err)
+	}
+}
@@ -3,15 +3,22 @@ package rpc_client
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"strconv"
 	"strings"
+	"sync"
+	"tezosign/common/log"
 	"tezosign/models"
 	"tezosign/services/rpc_client/client"
 	"tezosign/services/rpc_client/client/big_map"
 	"tezosign/services/rpc_client/client/chains"
 	"tezosign/services/rpc_client/client/contracts"
+	"tezosign/services/rpc_client/client/helpers"
+	"time"
 
 	"blockwatch.cc/tzindex/micheline"
+	httptransport "github.com/go-openapi/runtime/client"
+	"go.uber.org/zap"
 )
 
 const headBlock = "head"
@@ -19,18 +26,112 @@ const BlocksInCycle = 4096
 
 type Tezos struct {
 	client        *client.Tezosrpc
+	pool          *pool
 	network       models.Network
 	isTestNetwork bool //we have to use a separate flag due to stupid nodes configs...
+
+	done      chan struct{}
+	closeOnce sync.Once
 }
 
 func New(cfg client.TransportConfig, network models.Network, isTestNetwork bool) *Tezos {
-	cli := client.NewHTTPClientWithConfig(nil, &cfg)
+	return NewPool([]client.TransportConfig{cfg}, PoolConfig{}, network, isTestNetwork)
+}
+
+//NewPool creates client with failover between nodes, first node is preferred
+func NewPool(configs []client.TransportConfig, cfg PoolConfig, network models.Network, isTestNetwork bool) *Tezos {
+	p := newPool(configs, cfg)
 
-	return &Tezos{
-		client:        cli,
+	//Node scheme, host and base path are set by pool
+	transport := httptransport.New(client.DefaultHost, "/", client.DefaultSchemes)
+	transport.Transport = p
+
+	t := &Tezos{
+		client:        client.New(transport, nil),
+		pool:          p,
 		network:       network,
 		isTestNetwork: isTestNetwork,
+		done:          make(chan struct{}),
+	}
+
+	if p.cfg.HealthCheckPeriod > 0 {
+		go t.runHealthChecks()
 	}
+
+	return t
+}
+
+//Close stops health checks
+func (t *Tezos) Close() {
+	t.closeOnce.Do(func() {
+		close(t.done)
+	})
+}
+
+func (t *Tezos) runHealthChecks() {
+	ticker := time.NewTicker(t.pool.cfg.HealthCheckPeriod)
+	defer ticker.Stop()
+
+	for {
+		t.checkHealth(context.Background())
+
+		select {
+		case <-t.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+//checkHealth marks unreachable and lagging behind best head nodes as unhealthy
+func (t *Tezos) checkHealth(ctx context.Context) {
+	levels := make([]int64, len(t.pool.nodes))
+	errs := make([]error, len(t.pool.nodes))
+
+	var wg sync.WaitGroup
+	for i := range t.pool.nodes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			levels[i], errs[i] = t.HeadLevel(withNode(ctx, i))
+		}(i)
+	}
+	wg.Wait()
+
+	var best int64
+	for i := range levels {
+		if errs[i] == nil && levels[i] > best {
+			best = levels[i]
+		}
+	}
+
+	for i, n := range t.pool.nodes {
+		isHealthy := errs[i] == nil && levels[i]+t.pool.cfg.MaxLevelLag >= best
+		if !isHealthy {
+			log.Warn("rpc node is unhealthy", zap.String("host", n.host), zap.Int64("level", levels[i]), zap.Int64("best_level", best), zap.Error(errs[i]))
+		}
+
+		n.setHealth(levels[i], isHealthy)
+	}
+}
+
+func (t *Tezos) HeadLevel(ctx context.Context) (level int64, err error) {
+	params := chains.NewGetBlockHeaderParamsWithContext(ctx)
+	resp, err := t.client.Chains.GetBlockHeader(params)
+	if err != nil {
+		return level, err
+	}
+
+	var header struct {
+		Level int64 `json:"level"`
+	}
+
+	err = remarshal(resp.Payload, &header)
+	if err != nil {
+		return level, err
+	}
+
+	return header.Level, nil
 }
 
 func (t *Tezos) Script(ctx context.Context, contractHash string) (bm micheline.Script, err error) {
@@ -123,3 +224,73 @@ func (t *Tezos) BigMapKey(ctx context.Context, bigMapID int64, keyHash string) (
 
 	return value, true, nil
 }
+
+func (t *Tezos) Counter(ctx context.Context, address string) (counter int64, err error) {
+	params := contracts.NewGetContractCounterParamsWithContext(ctx).WithContract(address)
+	resp, err := t.client.Contracts.GetContractCounter(params)
+	if err != nil {
+		return counter, err
+	}
+
+	counter, err = strconv.ParseInt(resp.Payload, 10, 64)
+	if err != nil {
+		return counter, err
+	}
+
+	return counter, nil
+}
+
+func (t *Tezos) BlockHash(ctx context.Context) (hash string, err error) {
+	params := chains.NewGetBlockHashParamsWithContext(ctx)
+	resp, err := t.client.Chains.GetBlockHash(params)
+	if err != nil {
+		return hash, err
+	}
+
+	return resp.Payload, nil
+}
+
+func (t *Tezos) Constants(ctx context.Context) (constants models.ProtocolConstants, err error) {
+	params := chains.NewGetConstantsParamsWithContext(ctx)
+	resp, err := t.client.Chains.GetConstants(params)
+	if err != nil {
+		return constants, err
+	}
+
+	err = remarshal(resp.Payload, &constants)
+	if err != nil {
+		return constants, err
+	}
+
+	return constants, nil
+}
+
+//RunOperation simulates operation without signature check on head block
+func (t *Tezos) RunOperation(ctx context.Context, req models.RunOperationRequest) (result models.RunOperationResult, err error) {
+	params := helpers.NewRunOperationParamsWithContext(ctx).WithBody(req)
+	resp, err := t.client.Helpers.RunOperation(params)
+	if err != nil {
+		if failure, ok := err.(*helpers.RunOperationInternalServerError); ok {
+			bt, _ := json.Marshal(failure.Payload)
+			return result, models.OperationFailure{Reason: fmt.Sprintf("run_operation: %s", bt)}
+		}
+
+		return result, err
+	}
+
+	err = remarshal(resp.Payload, &result)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func remarshal(payload interface{}, v interface{}) error {
+	bt, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(bt, v)
+}
@@ -60,6 +60,108 @@ func (a *Client) GetChaID(params *GetChaIDParams) (*GetChaIDOK, error) {
 	panic(msg)
 }
 
+/*
+GetBlockHash get block hash API
+*/
+func (a *Client) GetBlockHash(params *GetBlockHashParams) (*GetBlockHashOK, error) {
+	// TODO: Validate the params before sending
+	if params == nil {
+		params = NewGetBlockHashParams()
+	}
+
+	result, err := a.transport.Submit(&runtime.ClientOperation{
+		ID:                 "getBlockHash",
+		Method:             "GET",
+		PathPattern:        "/chains/main/blocks/head/hash",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{""},
+		Schemes:            []string{"http"},
+		Params:             params,
+		Reader:             &GetBlockHashReader{formats: a.formats},
+		Context:            params.Context,
+		Client:             params.HTTPClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+	success, ok := result.(*GetBlockHashOK)
+	if ok {
+		return success, nil
+	}
+	// unexpected success response
+	// safeguard: normally, absent a default response, unknown success responses return an error above: so this is a codegen issue
+	msg := fmt.Sprintf("unexpected success response for getBlockHash: API contract not enforced by server. Client expected to get an error, but got: %T", result)
+	panic(msg)
+}
+
+/*
+GetConstants get constants API
+*/
+func (a *Client) GetConstants(params *GetConstantsParams) (*GetConstantsOK, error) {
+	// TODO: Validate the params before sending
+	if params == nil {
+		params = NewGetConstantsParams()
+	}
+
+	result, err := a.transport.Submit(&runtime.ClientOperation{
+		ID:                 "getConstants",
+		Method:             "GET",
+		PathPattern:        "/chains/main/blocks/head/context/constants",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{""},
+		Schemes:            []string{"http"},
+		Params:             params,
+		Reader:             &GetConstantsReader{formats: a.formats},
+		Context:            params.Context,
+		Client:             params.HTTPClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+	success, ok := result.(*GetConstantsOK)
+	if ok {
+		return success, nil
+	}
+	// unexpected success response
+	// safeguard: normally, absent a default response, unknown success responses return an error above: so this is a codegen issue
+	msg := fmt.Sprintf("unexpected success response for getConstants: API contract not enforced by server. Client expected to get an error, but got: %T", result)
+	panic(msg)
+}
+
+/*
+GetBlockHeader get block header API
+*/
+func (a *Client) GetBlockHeader(params *GetBlockHeaderParams) (*GetBlockHeaderOK, error) {
+	// TODO: Validate the params before sending
+	if params == nil {
+		params = NewGetBlockHeaderParams()
+	}
+
+	result, err := a.transport.Submit(&runtime.ClientOperation{
+		ID:                 "getBlockHeader",
+		Method:             "GET",
+		PathPattern:        "/chains/main/blocks/head/header",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{""},
+		Schemes:            []string{"http"},
+		Params:             params,
+		Reader:             &GetBlockHeaderReader{formats: a.formats},
+		Context:            params.Context,
+		Client:             params.HTTPClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+	success, ok := result.(*GetBlockHeaderOK)
+	if ok {
+		return success, nil
+	}
+	// unexpected success response
+	// safeguard: normally, absent a default response, unknown success responses return an error above: so this is a codegen issue
+	msg := fmt.Sprintf("unexpected success response for getBlockHeader: API contract not enforced by server. Client expected to get an error, but got: %T", result)
+	panic(msg)
+}
+
 // SetTransport changes the transport on the client
 func (a *Client) SetTransport(transport runtime.ClientTransport) {
 	a.transport = transport
@@ -162,6 +162,40 @@ func (a *Client) GetContractStorage(params *GetContractStorageParams) (*GetContr
 	panic(msg)
 }
 
+/*
+GetContractCounter get contract counter API
+*/
+func (a *Client) GetContractCounter(params *GetContractCounterParams) (*GetContractCounterOK, error) {
+	// TODO: Validate the params before sending
+	if params == nil {
+		params = NewGetContractCounterParams()
+	}
+
+	result, err := a.transport.Submit(&runtime.ClientOperation{
+		ID:                 "getContractCounter",
+		Method:             "GET",
+		PathPattern:        "/chains/main/blocks/head/context/contracts/{contract}/counter",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{""},
+		Schemes:            []string{"http"},
+		Params:             params,
+		Reader:             &GetContractCounterReader{formats: a.formats},
+		Context:            params.Context,
+		Client:             params.HTTPClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+	success, ok := result.(*GetContractCounterOK)
+	if ok {
+		return success, nil
+	}
+	// unexpected success response
+	// safeguard: normally, absent a default response, unknown success responses return an error above: so this is a codegen issue
+	msg := fmt.Sprintf("unexpected success response for getContractCounter: API contract not enforced by server. Client expected to get an error, but got: %T", result)
+	panic(msg)
+}
+
 // SetTransport changes the transport on the client
 func (a *Client) SetTransport(transport runtime.ClientTransport) {
 	a.transport = transport
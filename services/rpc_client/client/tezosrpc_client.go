@@ -14,6 +14,7 @@ import (
 	"tezosign/services/rpc_client/client/big_map"
 	"tezosign/services/rpc_client/client/chains"
 	"tezosign/services/rpc_client/client/contracts"
+	"tezosign/services/rpc_client/client/helpers"
 )
 
 // Default tezosrpc HTTP client.
@@ -65,6 +66,8 @@ func New(transport runtime.ClientTransport, formats strfmt.Registry) *Tezosrpc {
 
 	cli.Contracts = contracts.New(transport, formats)
 
+	cli.Helpers = helpers.New(transport, formats)
+
 	return cli
 }
 
@@ -115,6 +118,8 @@ type Tezosrpc struct {
 
 	Contracts *contracts.Client
 
+	Helpers *helpers.Client
+
 	Transport runtime.ClientTransport
 }
 
@@ -128,4 +133,6 @@ func (c *Tezosrpc) SetTransport(transport runtime.ClientTransport) {
 
 	c.Contracts.SetTransport(transport)
 
+	c.Helpers.SetTransport(transport)
+
 }
@@ -0,0 +1,129 @@
+package rpc_client
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"tezosign/models"
+	"tezosign/services/rpc_client/client"
+)
+
+//Local stand-in for node RPC
+func newTestNode(t *testing.T, handler http.HandlerFunc) *Tezos {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	uri, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return New(client.TransportConfig{Host: uri.Host, BasePath: "/", Schemes: []string{"http"}}, models.NetworkMain, false)
+}
+
+func Test_TezosHead(t *testing.T) {
+	responses := map[string]string{
+		"/chains/main/blocks/head/hash": `"BLockGenesisGenesisGenesisGenesisGenesisf79b5d1CoW2"`,
+		"/chains/main/blocks/head/context/contracts/tz1VSUr8wwNhLAzempoch5d6hLRiTh8Cjcjb/counter": `"1234"`,
+		"/chains/main/blocks/head/context/constants":                                              `{"hard_gas_limit_per_operation":"1040000","hard_storage_limit_per_operation":"60000","cost_per_byte":"250","origination_size":257,"blocks_per_cycle":4096}`,
+	}
+
+	tezos := newTestNode(t, func(w http.ResponseWriter, r *http.Request) {
+		resp, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(resp))
+	})
+
+	ctx := context.Background()
+
+	hash, err := tezos.BlockHash(ctx)
+	if err != nil || hash != "BLockGenesisGenesisGenesisGenesisGenesisf79b5d1CoW2" {
+		t.Errorf("hash: %s err: %v", hash, err)
+	}
+
+	counter, err := tezos.Counter(ctx, "tz1VSUr8wwNhLAzempoch5d6hLRiTh8Cjcjb")
+	if err != nil || counter != 1234 {
+		t.Errorf("counter: %d err: %v", counter, err)
+	}
+
+	constants, err := tezos.Constants(ctx)
+	expected := models.ProtocolConstants{HardGasLimitPerOperation: 1040000, HardStorageLimitPerOperation: 60000, CostPerByte: 250, OriginationSize: 257}
+	if err != nil || constants != expected {
+		t.Errorf("constants: %+v err: %v", constants, err)
+	}
+
+	_, err = tezos.Counter(ctx, "tz1NkT6YCFS3mDo6kfaMFKFrRiA7w2o5dkWp")
+	if err == nil {
+		t.Error("unknown contract counter")
+	}
+}
+
+func Test_TezosRunOperation(t *testing.T) {
+	req := models.RunOperationRequest{
+		Operation: models.RunOperation{
+			Branch: "BLockGenesisGenesisGenesisGenesisGenesisf79b5d1CoW2",
+			Contents: []models.RunTransaction{{
+				Kind:        "transaction",
+				Source:      "tz1VSUr8wwNhLAzempoch5d6hLRiTh8Cjcjb",
+				Counter:     1235,
+				Destination: "KT1LAuGLiaCF9A72qZtFvVhyzzNFg86fwFnV",
+				Parameters:  &models.TransactionParameters{Entrypoint: "main_parameter", Value: json.RawMessage(`{"int":"1"}`)},
+			}},
+		},
+		ChainID: "NetXdQprcVkpaWU",
+	}
+
+	var received models.RunOperationRequest
+	tezos := newTestNode(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/chains/main/blocks/head/helpers/scripts/run_operation" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		body, _ := ioutil.ReadAll(r.Body)
+		err := json.Unmarshal(body, &received)
+		if err != nil {
+			t.Error(err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if received.Operation.Contents[0].Counter != 1235 {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`[{"kind":"temporary","id":"proto.008-PtEdo2Zk.contract.counter_in_the_future"}]`))
+			return
+		}
+
+		w.Write([]byte(`{"contents":[{"kind":"transaction","metadata":{"operation_result":{"status":"applied","consumed_gas":"1500","paid_storage_size_diff":"4"}}}]}`))
+	})
+
+	result, err := tezos.RunOperation(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(received.Operation.Contents[0].Parameters.Value) != `{"int":"1"}` || received.Operation.Contents[0].Fee != 0 {
+		t.Errorf("request: %+v", received)
+	}
+
+	opResult := result.Contents[0].Metadata.OperationResult
+	if opResult.Status != "applied" || opResult.ConsumedGas != 1500 || opResult.PaidStorageSizeDiff != 4 {
+		t.Errorf("result: %+v", opResult)
+	}
+
+	req.Operation.Contents[0].Counter = 1300
+	_, err = tezos.RunOperation(context.Background(), req)
+	if err == nil {
+		t.Error("node error is not returned")
+	}
+}
@@ -0,0 +1,310 @@
+package rpc_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"path"
+	"sync"
+	"tezosign/common/log"
+	"tezosign/services/rpc_client/client"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultRequestTimeout   = 10 * time.Second
+	defaultRetries          = 2
+	defaultFailureThreshold = 3
+	defaultCooldownPeriod   = 30 * time.Second
+	defaultMaxLevelLag      = 2
+
+	retryBaseDelay = 100 * time.Millisecond
+)
+
+var errNoNodes = errors.New("no rpc nodes")
+
+type PoolConfig struct {
+	//Single node request deadline
+	Timeout time.Duration
+	//Retries of GET requests on next nodes, 0 for default, negative disables retries
+	Retries int
+	//Consecutive failures which open node circuit
+	FailureThreshold int
+	//Period of open circuit
+	CooldownPeriod time.Duration
+	//Head level polling period, 0 disables health checks
+	HealthCheckPeriod time.Duration
+	//Allowed head level lag behind best node
+	MaxLevelLag int64
+}
+
+func (c PoolConfig) withDefaults() PoolConfig {
+	if c.Timeout <= 0 {
+		c.Timeout = defaultRequestTimeout
+	}
+
+	if c.Retries < 0 {
+		c.Retries = 0
+	} else if c.Retries == 0 {
+		c.Retries = defaultRetries
+	}
+
+	if c.FailureThreshold <= 0 {
+		c.FailureThreshold = defaultFailureThreshold
+	}
+
+	if c.CooldownPeriod <= 0 {
+		c.CooldownPeriod = defaultCooldownPeriod
+	}
+
+	if c.MaxLevelLag <= 0 {
+		c.MaxLevelLag = defaultMaxLevelLag
+	}
+
+	return c
+}
+
+type node struct {
+	scheme   string
+	host     string
+	basePath string
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	//Health check state, unknown node is healthy
+	level     int64
+	isHealthy bool
+}
+
+func newNode(cfg client.TransportConfig) *node {
+	scheme := "https"
+	if len(cfg.Schemes) > 0 {
+		scheme = cfg.Schemes[0]
+	}
+
+	return &node{
+		scheme:    scheme,
+		host:      cfg.Host,
+		basePath:  cfg.BasePath,
+		isHealthy: true,
+	}
+}
+
+//isAvailable reports closed or half-open circuit of healthy node
+func (n *node) isAvailable(now time.Time) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.isHealthy && !now.Before(n.openUntil)
+}
+
+func (n *node) success() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.failures = 0
+	n.openUntil = time.Time{}
+}
+
+//failure returns true if node circuit is opened
+func (n *node) failure(threshold int, cooldown time.Duration) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.failures++
+	if n.failures < threshold {
+		return false
+	}
+
+	//Half-open circuit is reopened on first failure
+	n.openUntil = time.Now().Add(cooldown)
+
+	return true
+}
+
+func (n *node) setHealth(level int64, isHealthy bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.level = level
+	n.isHealthy = isHealthy
+}
+
+type nodeKey struct{}
+
+//withNode pins request to single pool node, used by health checks
+func withNode(ctx context.Context, index int) context.Context {
+	return context.WithValue(ctx, nodeKey{}, index)
+}
+
+//pool is http transport that spreads generated client requests over node endpoints
+type pool struct {
+	nodes     []*node
+	cfg       PoolConfig
+	transport http.RoundTripper
+}
+
+func newPool(configs []client.TransportConfig, cfg PoolConfig) *pool {
+	p := &pool{
+		cfg:       cfg.withDefaults(),
+		transport: http.DefaultTransport,
+	}
+
+	for i := range configs {
+		p.nodes = append(p.nodes, newNode(configs[i]))
+	}
+
+	return p
+}
+
+func (p *pool) RoundTrip(req *http.Request) (*http.Response, error) {
+	if len(p.nodes) == 0 {
+		return nil, errNoNodes
+	}
+
+	if index, ok := req.Context().Value(nodeKey{}).(int); ok {
+		return p.roundTripNode(req, p.nodes[index])
+	}
+
+	//Requests with body are not idempotent
+	retries := 0
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		retries = p.cfg.Retries
+	}
+
+	tried := make([]bool, len(p.nodes))
+
+	var resp *http.Response
+	var err error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			err = sleepCtx(req.Context(), retryDelay(attempt))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		index := p.pick(tried)
+		tried[index] = true
+
+		resp, err = p.roundTripNode(req, p.nodes[index])
+		if !isNodeFailure(req, resp, err) || req.Context().Err() != nil {
+			return resp, err
+		}
+
+		if attempt < retries && resp != nil {
+			resp.Body.Close()
+		}
+	}
+
+	return resp, err
+}
+
+//pick returns first available node not tried by request, configured order is priority
+func (p *pool) pick(tried []bool) int {
+	now := time.Now()
+
+	for i, n := range p.nodes {
+		if !tried[i] && n.isAvailable(now) {
+			return i
+		}
+	}
+
+	//Untried nodes are down, try them anyway in priority order
+	for i := range p.nodes {
+		if !tried[i] {
+			return i
+		}
+	}
+
+	for i := range tried {
+		tried[i] = false
+	}
+
+	return p.pick(tried)
+}
+
+func (p *pool) roundTripNode(req *http.Request, n *node) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), p.cfg.Timeout)
+
+	nodeReq := req.Clone(ctx)
+	nodeReq.URL.Scheme = n.scheme
+	nodeReq.URL.Host = n.host
+	nodeReq.URL.Path = path.Join("/", n.basePath, req.URL.Path)
+	nodeReq.Host = n.host
+
+	resp, err := p.transport.RoundTrip(nodeReq)
+	if isNodeFailure(req, resp, err) && req.Context().Err() == nil {
+		if n.failure(p.cfg.FailureThreshold, p.cfg.CooldownPeriod) {
+			log.Warn("rpc node circuit opened", zap.String("host", n.host), zap.Error(nodeError(resp, err)))
+		}
+	} else if err == nil {
+		n.success()
+	}
+
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	//Deadline covers body reading
+	resp.Body = cancelBody{ReadCloser: resp.Body, cancel: cancel}
+
+	return resp, nil
+}
+
+//isNodeFailure separates node faults from protocol errors, node responds to failed simulation with 500
+func isNodeFailure(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	if req.Method == http.MethodPost {
+		return resp.StatusCode == http.StatusBadGateway || resp.StatusCode == http.StatusServiceUnavailable || resp.StatusCode == http.StatusGatewayTimeout
+	}
+
+	return resp.StatusCode >= http.StatusInternalServerError
+}
+
+func nodeError(resp *http.Response, err error) error {
+	if err != nil {
+		return err
+	}
+
+	return fmt.Errorf("status %d", resp.StatusCode)
+}
+
+//retryDelay is exponential backoff with jitter
+func retryDelay(attempt int) time.Duration {
+	max := retryBaseDelay << uint(attempt-1)
+	return max/2 + time.Duration(rand.Int63n(int64(max/2)+1))
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,177 @@
+package rpc_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"tezosign/models"
+	"tezosign/services/rpc_client/client"
+	"time"
+)
+
+const testAddress = "tz1VSUr8wwNhLAzempoch5d6hLRiTh8Cjcjb"
+
+type testPoolNode struct {
+	config client.TransportConfig
+	hits   int64
+}
+
+//newTestPoolNode starts node stub answering balance and head header with status, zero status hangs request
+func newTestPoolNode(t *testing.T, status int, level string) *testPoolNode {
+	t.Helper()
+
+	n := &testPoolNode{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/chains/main/blocks/head/header" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"level":` + level + `}`))
+			return
+		}
+
+		atomic.AddInt64(&n.hits, 1)
+
+		if status == 0 {
+			<-r.Context().Done()
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(`"100"`))
+	}))
+	t.Cleanup(server.Close)
+
+	uri, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n.config = client.TransportConfig{Host: uri.Host, Schemes: []string{"http"}}
+
+	return n
+}
+
+func (n *testPoolNode) Hits() int64 {
+	return atomic.LoadInt64(&n.hits)
+}
+
+func newTestPool(cfg PoolConfig, nodes ...*testPoolNode) *Tezos {
+	configs := make([]client.TransportConfig, len(nodes))
+	for i := range nodes {
+		configs[i] = nodes[i].config
+	}
+
+	return NewPool(configs, cfg, models.NetworkMain, false)
+}
+
+func Test_PoolFailover(t *testing.T) {
+	testCases := []struct {
+		name        string
+		status      int
+		cfg         PoolConfig
+		wantErr     bool
+		expPrimary  int64
+		expFallback int64
+	}{
+		{name: "Healthy primary", status: http.StatusOK, expPrimary: 1},
+		{name: "Bad gateway", status: http.StatusBadGateway, expPrimary: 1, expFallback: 1},
+		{name: "Timeout", status: 0, cfg: PoolConfig{Timeout: 50 * time.Millisecond}, expPrimary: 1, expFallback: 1},
+		//Protocol error is not retried
+		{name: "Not found", status: http.StatusNotFound, wantErr: true, expPrimary: 1},
+		{name: "Retries disabled", status: http.StatusBadGateway, cfg: PoolConfig{Retries: -1}, wantErr: true, expPrimary: 1},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			primary := newTestPoolNode(t, test.status, "1")
+			fallback := newTestPoolNode(t, http.StatusOK, "1")
+			tezos := newTestPool(test.cfg, primary, fallback)
+			defer tezos.Close()
+
+			balance, err := tezos.Balance(context.Background(), testAddress)
+			if test.wantErr != (err != nil) {
+				t.Fatalf("wantErr: %t | err: %v", test.wantErr, err)
+			}
+
+			if !test.wantErr && balance != 100 {
+				t.Errorf("balance: %d", balance)
+			}
+
+			if primary.Hits() != test.expPrimary || fallback.Hits() != test.expFallback {
+				t.Errorf("hits: %d %d, want %d %d", primary.Hits(), fallback.Hits(), test.expPrimary, test.expFallback)
+			}
+		})
+	}
+}
+
+func Test_PoolPostIsNotRetried(t *testing.T) {
+	primary := newTestPoolNode(t, http.StatusBadGateway, "1")
+	fallback := newTestPoolNode(t, http.StatusOK, "1")
+	tezos := newTestPool(PoolConfig{}, primary, fallback)
+	defer tezos.Close()
+
+	_, err := tezos.RunOperation(context.Background(), models.RunOperationRequest{})
+	if err == nil {
+		t.Error("failed run_operation is not returned")
+	}
+
+	if primary.Hits() != 1 || fallback.Hits() != 0 {
+		t.Errorf("hits: %d %d", primary.Hits(), fallback.Hits())
+	}
+}
+
+func Test_PoolCircuitBreaker(t *testing.T) {
+	primary := newTestPoolNode(t, http.StatusBadGateway, "1")
+	fallback := newTestPoolNode(t, http.StatusOK, "1")
+	tezos := newTestPool(PoolConfig{FailureThreshold: 2, CooldownPeriod: time.Hour}, primary, fallback)
+	defer tezos.Close()
+
+	for i := 0; i < 4; i++ {
+		_, err := tezos.Balance(context.Background(), testAddress)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	//Circuit is opened after second failure
+	if primary.Hits() != 2 || fallback.Hits() != 4 {
+		t.Errorf("hits: %d %d", primary.Hits(), fallback.Hits())
+	}
+
+	//Cooldown is passed, half-open circuit is reopened on failure
+	tezos.pool.nodes[0].openUntil = time.Now()
+
+	_, err := tezos.Balance(context.Background(), testAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if primary.Hits() != 3 || tezos.pool.nodes[0].isAvailable(time.Now()) {
+		t.Errorf("hits: %d, primary available: %t", primary.Hits(), tezos.pool.nodes[0].isAvailable(time.Now()))
+	}
+}
+
+func Test_PoolHealthCheck(t *testing.T) {
+	lagging := newTestPoolNode(t, http.StatusOK, "100")
+	best := newTestPoolNode(t, http.StatusOK, "110")
+	tezos := newTestPool(PoolConfig{MaxLevelLag: 5}, lagging, best)
+	defer tezos.Close()
+
+	tezos.checkHealth(context.Background())
+
+	if tezos.pool.nodes[0].isHealthy || tezos.pool.nodes[0].level != 100 || !tezos.pool.nodes[1].isHealthy {
+		t.Errorf("health: %t %t", tezos.pool.nodes[0].isHealthy, tezos.pool.nodes[1].isHealthy)
+	}
+
+	_, err := tezos.Balance(context.Background(), testAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if lagging.Hits() != 0 || best.Hits() != 1 {
+		t.Errorf("hits: %d %d", lagging.Hits(), best.Hits())
+	}
+}
@@ -0,0 +1,37 @@
+package schedule
+
+import (
+	"tezosign/models"
+	"time"
+)
+
+//Claim returns opened ticks available to vest and whether rule requires claiming them now
+func Claim(p Params, rule models.VestingClaimRule, now time.Time) (ticks uint64, isDue bool) {
+	openedTick := p.openedTick(now)
+	if openedTick <= p.VestedTicks {
+		return 0, false
+	}
+
+	ticks = openedTick - p.VestedTicks
+
+	//Vest above contract balance fails
+	if maxTicks := p.Balance / p.TokensPerTick; ticks > maxTicks {
+		ticks = maxTicks
+	}
+
+	if ticks == 0 {
+		return 0, false
+	}
+
+	if rule.MinAmount > 0 && ticks*p.TokensPerTick >= rule.MinAmount {
+		return ticks, true
+	}
+
+	if rule.IntervalDays > 0 {
+		if rule.LastClaimAt == nil || now.Sub(rule.LastClaimAt.Time()) >= time.Duration(rule.IntervalDays)*24*time.Hour {
+			return ticks, true
+		}
+	}
+
+	return ticks, false
+}
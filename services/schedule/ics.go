@@ -0,0 +1,61 @@
+package schedule
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"tezosign/models"
+	"tezosign/types"
+	"time"
+
+	"github.com/wedancedalot/decimal"
+)
+
+const (
+	icsTimeLayout = "20060102T150405Z"
+	xtzPrecision  = 6
+)
+
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+//ICS exports schedule unlocks and fully vested date as iCalendar events
+func ICS(address types.Address, schedule models.VestingSchedule, now time.Time) []byte {
+	buf := &bytes.Buffer{}
+
+	writeLine(buf, "BEGIN:VCALENDAR")
+	writeLine(buf, "VERSION:2.0")
+	writeLine(buf, "PRODID:-//tezosign//vesting//EN")
+	writeLine(buf, "CALSCALE:GREGORIAN")
+	writeLine(buf, "X-WR-CALNAME:"+icsEscaper.Replace("Vesting "+address.String()))
+
+	for i := range schedule.Unlocks {
+		summary := fmt.Sprintf("Vesting unlock %s XTZ", xtz(schedule.Unlocks[i].Amount))
+		start := schedule.Unlocks[i].Time.Time()
+		writeEvent(buf, fmt.Sprintf("%s-%d", address, start.Unix()), start, now, summary)
+	}
+
+	writeEvent(buf, fmt.Sprintf("%s-vested", address), schedule.FullyVestedAt.Time(), now, fmt.Sprintf("Vesting fully vested %s XTZ", xtz(schedule.Balance)))
+
+	writeLine(buf, "END:VCALENDAR")
+
+	return buf.Bytes()
+}
+
+func writeEvent(buf *bytes.Buffer, uid string, start, now time.Time, summary string) {
+	writeLine(buf, "BEGIN:VEVENT")
+	writeLine(buf, "UID:"+uid+"@tezosign")
+	writeLine(buf, "DTSTAMP:"+now.UTC().Format(icsTimeLayout))
+	writeLine(buf, "DTSTART:"+start.UTC().Format(icsTimeLayout))
+	writeLine(buf, "SUMMARY:"+icsEscaper.Replace(summary))
+	writeLine(buf, "END:VEVENT")
+}
+
+//iCalendar requires CRLF line endings
+func writeLine(buf *bytes.Buffer, line string) {
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
+
+func xtz(mutez uint64) string {
+	return decimal.New(int64(mutez), -xtzPrecision).String()
+}
@@ -0,0 +1,163 @@
+package schedule
+
+import (
+	"errors"
+	"tezosign/models"
+	"tezosign/types"
+	"time"
+)
+
+//Vesting contract state used for projection
+type Params struct {
+	Timestamp      int64
+	SecondsPerTick uint64
+	TokensPerTick  uint64
+	VestedTicks    uint64
+	//Ticks opened before cliff unlock at cliff, zero without cliff
+	Cliff int64
+	//Contract balance, all not vested ticks are paid from it
+	Balance uint64
+}
+
+func (p Params) Validate() error {
+	if p.SecondsPerTick == 0 {
+		return errors.New("seconds per tick")
+	}
+
+	if p.TokensPerTick == 0 {
+		return errors.New("tokens per tick")
+	}
+
+	return nil
+}
+
+//Last tick paid by current balance
+func (p Params) FinalTick() uint64 {
+	return p.VestedTicks + (p.Balance+p.TokensPerTick-1)/p.TokensPerTick
+}
+
+func (p Params) TickTime(tick uint64) time.Time {
+	t := p.Timestamp + int64(tick*p.SecondsPerTick)
+	if t < p.Cliff {
+		t = p.Cliff
+	}
+
+	return time.Unix(t, 0).UTC()
+}
+
+//Last tick unlocked at cliff
+func (p Params) cliffTick() uint64 {
+	if p.Cliff <= p.Timestamp {
+		return 0
+	}
+
+	return uint64(p.Cliff-p.Timestamp) / p.SecondsPerTick
+}
+
+//Ticks opened to now, vested ticks included
+func (p Params) openedTick(now time.Time) uint64 {
+	diff := now.Unix() - p.Timestamp
+	if diff < 0 || now.Unix() < p.Cliff {
+		return 0
+	}
+
+	return uint64(diff) / p.SecondsPerTick
+}
+
+//Amount unlocked by not vested ticks up to tick
+func (p Params) cumulative(tick uint64) uint64 {
+	if tick <= p.VestedTicks {
+		return 0
+	}
+
+	amount := (tick - p.VestedTicks) * p.TokensPerTick
+	if amount > p.Balance {
+		return p.Balance
+	}
+
+	return amount
+}
+
+//Project builds unlock schedule of not vested ticks, limit and offset are applied to unlocks
+func Project(p Params, granularity models.VestingScheduleGranularity, now time.Time, limit, offset int) (schedule models.VestingSchedule, err error) {
+	if err = p.Validate(); err != nil {
+		return schedule, err
+	}
+
+	if err = granularity.Validate(); err != nil {
+		return schedule, err
+	}
+
+	finalTick := p.FinalTick()
+
+	openedTick := p.openedTick(now)
+	if openedTick > finalTick {
+		openedTick = finalTick
+	}
+
+	schedule = models.VestingSchedule{
+		Granularity:   granularity,
+		Balance:       p.Balance,
+		OpenedBalance: p.cumulative(openedTick),
+		FinalTick:     finalTick,
+		FullyVestedAt: types.JSONTimestamp(p.TickTime(finalTick)),
+		Unlocks:       []models.VestingUnlock{},
+	}
+
+	index := 0
+	for tick := p.VestedTicks + 1; tick <= finalTick && len(schedule.Unlocks) < limit; {
+		lastTick := tick
+		var periodStart *types.JSONTimestamp
+
+		//Ticks before cliff are unlocked by one event
+		if cliffTick := p.cliffTick(); lastTick < cliffTick {
+			lastTick = cliffTick
+			if lastTick > finalTick {
+				lastTick = finalTick
+			}
+		}
+
+		if granularity != models.VestingScheduleTick {
+			start, end := period(p.TickTime(tick), granularity)
+			periodStart = &start
+
+			//Last tick before period end
+			lastTick = uint64(end.Unix()-1-p.Timestamp) / p.SecondsPerTick
+			if lastTick > finalTick {
+				lastTick = finalTick
+			}
+		}
+
+		if index >= offset {
+			schedule.Unlocks = append(schedule.Unlocks, models.VestingUnlock{
+				Time:        types.JSONTimestamp(p.TickTime(lastTick)),
+				PeriodStart: periodStart,
+				Ticks:       lastTick - tick + 1,
+				Amount:      p.cumulative(lastTick) - p.cumulative(tick-1),
+				Cumulative:  p.cumulative(lastTick),
+				IsOpened:    lastTick <= openedTick,
+			})
+		}
+
+		index++
+		tick = lastTick + 1
+	}
+
+	return schedule, nil
+}
+
+//UTC period containing time
+func period(t time.Time, granularity models.VestingScheduleGranularity) (start types.JSONTimestamp, end time.Time) {
+	var s time.Time
+
+	switch granularity {
+	case models.VestingScheduleMonth:
+		s = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		end = s.AddDate(0, 1, 0)
+	default:
+		s = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		end = s.AddDate(0, 0, 1)
+	}
+
+	return types.JSONTimestamp(s), end
+}
@@ -0,0 +1,274 @@
+package schedule
+
+import (
+	"strings"
+	"testing"
+	"tezosign/models"
+	"tezosign/types"
+	"time"
+)
+
+func Test_Project(t *testing.T) {
+	//2021-01-01T00:00:00Z
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+	hourly := Params{Timestamp: start, SecondsPerTick: 3600, TokensPerTick: 10, VestedTicks: 2, Balance: 1005}
+
+	testCases := []struct {
+		name        string
+		params      Params
+		granularity models.VestingScheduleGranularity
+		now         time.Time
+		limit       int
+		offset      int
+		expFinal    uint64
+		expOpened   uint64
+		expUnlocks  []models.VestingUnlock
+		wantErr     bool
+	}{
+		{
+			name:        "Ticks with remainder",
+			params:      Params{Timestamp: start, SecondsPerTick: 60, TokensPerTick: 10, VestedTicks: 0, Balance: 25},
+			granularity: models.VestingScheduleTick,
+			now:         time.Unix(start+120, 0),
+			limit:       10,
+			expFinal:    3,
+			expOpened:   20,
+			expUnlocks: []models.VestingUnlock{
+				{Ticks: 1, Amount: 10, Cumulative: 10, IsOpened: true},
+				{Ticks: 1, Amount: 10, Cumulative: 20, IsOpened: true},
+				{Ticks: 1, Amount: 5, Cumulative: 25},
+			},
+		},
+		{
+			name:        "Ticks offset",
+			params:      Params{Timestamp: start, SecondsPerTick: 60, TokensPerTick: 10, VestedTicks: 0, Balance: 25},
+			granularity: models.VestingScheduleTick,
+			now:         time.Unix(start, 0),
+			limit:       1,
+			offset:      1,
+			expFinal:    3,
+			expUnlocks: []models.VestingUnlock{
+				{Ticks: 1, Amount: 10, Cumulative: 20},
+			},
+		},
+		{
+			name:        "Ticks with cliff",
+			params:      Params{Timestamp: start, SecondsPerTick: 60, TokensPerTick: 10, VestedTicks: 0, Balance: 50, Cliff: start + 150},
+			granularity: models.VestingScheduleTick,
+			now:         time.Unix(start+150, 0),
+			limit:       10,
+			expFinal:    5,
+			expOpened:   20,
+			expUnlocks: []models.VestingUnlock{
+				//Ticks 1..2 at cliff
+				{Ticks: 2, Amount: 20, Cumulative: 20, IsOpened: true},
+				{Ticks: 1, Amount: 10, Cumulative: 30},
+				{Ticks: 1, Amount: 10, Cumulative: 40},
+				{Ticks: 1, Amount: 10, Cumulative: 50},
+			},
+		},
+		{
+			name:        "Days",
+			params:      hourly,
+			granularity: models.VestingScheduleDay,
+			now:         time.Unix(start+30*3600, 0),
+			limit:       10,
+			//2 vested + 101 ticks
+			expFinal:  103,
+			expOpened: 280,
+			expUnlocks: []models.VestingUnlock{
+				//Ticks 3..23
+				{Ticks: 21, Amount: 210, Cumulative: 210, IsOpened: true},
+				//Ticks 24..47
+				{Ticks: 24, Amount: 240, Cumulative: 450},
+				{Ticks: 24, Amount: 240, Cumulative: 690},
+				{Ticks: 24, Amount: 240, Cumulative: 930},
+				//Ticks 96..103
+				{Ticks: 8, Amount: 75, Cumulative: 1005},
+			},
+		},
+		{
+			name:        "Months",
+			params:      hourly,
+			granularity: models.VestingScheduleMonth,
+			now:         time.Unix(start, 0),
+			limit:       10,
+			expFinal:    103,
+			expUnlocks: []models.VestingUnlock{
+				{Ticks: 101, Amount: 1005, Cumulative: 1005},
+			},
+		},
+		{
+			name:        "Fully vested",
+			params:      Params{Timestamp: start, SecondsPerTick: 60, TokensPerTick: 10, VestedTicks: 5, Balance: 0},
+			granularity: models.VestingScheduleDay,
+			now:         time.Unix(start, 0),
+			limit:       10,
+			expFinal:    5,
+		},
+		{
+			name:        "Wrong granularity",
+			params:      hourly,
+			granularity: "week",
+			wantErr:     true,
+		},
+		{
+			name:        "Zero tick",
+			params:      Params{Timestamp: start, TokensPerTick: 10},
+			granularity: models.VestingScheduleTick,
+			wantErr:     true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			schedule, err := Project(test.params, test.granularity, test.now, test.limit, test.offset)
+			if test.wantErr != (err != nil) {
+				t.Fatalf("wantErr: %t | err: %v", test.wantErr, err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if schedule.FinalTick != test.expFinal || schedule.OpenedBalance != test.expOpened {
+				t.Errorf("final tick: %d | opened: %d", schedule.FinalTick, schedule.OpenedBalance)
+			}
+
+			if !schedule.FullyVestedAt.Time().Equal(test.params.TickTime(test.expFinal)) {
+				t.Errorf("fully vested at: %s", schedule.FullyVestedAt.Time())
+			}
+
+			if len(schedule.Unlocks) != len(test.expUnlocks) {
+				t.Fatalf("unlocks: %+v", schedule.Unlocks)
+			}
+
+			for i, exp := range test.expUnlocks {
+				unlock := schedule.Unlocks[i]
+				if unlock.Ticks != exp.Ticks || unlock.Amount != exp.Amount || unlock.Cumulative != exp.Cumulative || unlock.IsOpened != exp.IsOpened {
+					t.Errorf("unlock %d: %+v", i, unlock)
+				}
+			}
+		})
+	}
+}
+
+func Test_ICS(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	params := Params{Timestamp: start.Unix(), SecondsPerTick: 86400, TokensPerTick: 1500000, Balance: 3000000}
+
+	schedule, err := Project(params, models.VestingScheduleTick, start, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ics := string(ICS("KT1K9gCRgaLRFKTErYt1wVxA3Frb9FjasjTV", schedule, start))
+
+	for _, exp := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"DTSTART:20210102T000000Z\r\n",
+		"SUMMARY:Vesting unlock 1.5 XTZ\r\n",
+		"SUMMARY:Vesting fully vested 3 XTZ\r\n",
+		"UID:KT1K9gCRgaLRFKTErYt1wVxA3Frb9FjasjTV-1609545600@tezosign\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(ics, exp) {
+			t.Errorf("missing %q in %s", exp, ics)
+		}
+	}
+
+	//2 unlocks + fully vested
+	if count := strings.Count(ics, "BEGIN:VEVENT"); count != 3 {
+		t.Errorf("events: %d", count)
+	}
+}
+
+func Test_Claim(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	params := Params{Timestamp: start.Unix(), SecondsPerTick: 3600, TokensPerTick: 10, VestedTicks: 2, Balance: 1000}
+	weekAgo := types.JSONTimestamp(start.Add(10*time.Hour - 7*24*time.Hour))
+	dayAgo := types.JSONTimestamp(start.Add(10*time.Hour - 24*time.Hour))
+
+	testCases := []struct {
+		name     string
+		params   Params
+		rule     models.VestingClaimRule
+		now      time.Time
+		expTicks uint64
+		expDue   bool
+	}{
+		{
+			name:     "Min amount reached",
+			params:   params,
+			rule:     models.VestingClaimRule{MinAmount: 80},
+			now:      start.Add(10 * time.Hour),
+			expTicks: 8,
+			expDue:   true,
+		},
+		{
+			name:     "Min amount not reached",
+			params:   params,
+			rule:     models.VestingClaimRule{MinAmount: 81},
+			now:      start.Add(10 * time.Hour),
+			expTicks: 8,
+		},
+		{
+			name:     "First interval claim",
+			params:   params,
+			rule:     models.VestingClaimRule{IntervalDays: 7},
+			now:      start.Add(10 * time.Hour),
+			expTicks: 8,
+			expDue:   true,
+		},
+		{
+			name:     "Interval passed",
+			params:   params,
+			rule:     models.VestingClaimRule{IntervalDays: 7, LastClaimAt: &weekAgo},
+			now:      start.Add(10 * time.Hour),
+			expTicks: 8,
+			expDue:   true,
+		},
+		{
+			name:     "Interval not passed",
+			params:   params,
+			rule:     models.VestingClaimRule{IntervalDays: 7, LastClaimAt: &dayAgo},
+			now:      start.Add(10 * time.Hour),
+			expTicks: 8,
+		},
+		{
+			name:     "Capped by balance",
+			params:   Params{Timestamp: start.Unix(), SecondsPerTick: 3600, TokensPerTick: 10, VestedTicks: 2, Balance: 35},
+			rule:     models.VestingClaimRule{MinAmount: 10},
+			now:      start.Add(10 * time.Hour),
+			expTicks: 3,
+			expDue:   true,
+		},
+		{
+			name:   "Nothing opened",
+			params: params,
+			rule:   models.VestingClaimRule{MinAmount: 10, IntervalDays: 1},
+			now:    start.Add(2 * time.Hour),
+		},
+		{
+			name:   "Before cliff",
+			params: Params{Timestamp: start.Unix(), SecondsPerTick: 3600, TokensPerTick: 10, Balance: 1000, Cliff: start.Add(12 * time.Hour).Unix()},
+			rule:   models.VestingClaimRule{MinAmount: 10},
+			now:    start.Add(10 * time.Hour),
+		},
+		{
+			name:   "Not started",
+			params: params,
+			rule:   models.VestingClaimRule{IntervalDays: 1},
+			now:    start.Add(-time.Hour),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			ticks, isDue := Claim(test.params, test.rule, test.now)
+			if ticks != test.expTicks || isDue != test.expDue {
+				t.Errorf("ticks: %d | due: %t", ticks, isDue)
+			}
+		})
+	}
+}
@@ -2,12 +2,21 @@ package services
 
 import (
 	"context"
+	"tezosign/conf"
 	"tezosign/models"
 	"tezosign/repos/asset"
 	"tezosign/repos/auth"
+	"tezosign/repos/balance"
 	"tezosign/repos/contract"
+	"tezosign/repos/cursor"
 	"tezosign/repos/indexer"
+	"tezosign/repos/metadata"
+	"tezosign/repos/portfolio"
+	"tezosign/repos/price"
+	"tezosign/repos/ratelimit"
+	"tezosign/repos/session"
 	"tezosign/repos/vesting"
+	"tezosign/services/cache"
 	"tezosign/types"
 
 	"blockwatch.cc/tzindex/micheline"
@@ -19,7 +28,7 @@ type (
 
 	//Db transaction
 	DBTx interface {
-		Start(ctx context.Context)
+		Start(ctx context.Context) error
 		RollbackUnlessCommitted()
 		Commit() error
 	}
@@ -31,6 +40,13 @@ type (
 		GetAuth() auth.Repo
 		GetAsset() asset.Repo
 		GetVesting() vesting.Repo
+		GetRateLimit() ratelimit.Repo
+		GetSession() session.Repo
+		GetMetadata() metadata.Repo
+		GetBalance() balance.Repo
+		GetCursor() cursor.Repo
+		GetPrice() price.Repo
+		GetPortfolio() portfolio.Repo
 
 		DBTx
 	}
@@ -46,13 +62,19 @@ type (
 		ManagerKey(ctx context.Context, address string) (pubKey string, err error)
 		Balance(ctx context.Context, address string) (balance int64, err error)
 		BigMapKey(ctx context.Context, bigMapID int64, keyHash string) (value []byte, isFound bool, err error)
+		Counter(ctx context.Context, address string) (counter int64, err error)
+		BlockHash(ctx context.Context) (hash string, err error)
+		Constants(ctx context.Context) (constants models.ProtocolConstants, err error)
+		RunOperation(ctx context.Context, req models.RunOperationRequest) (result models.RunOperationResult, err error)
 	}
 
 	AuthProvider interface {
-		GenerateAuthTokens(pubKey types.PubKey) (string, string, error)
+		GenerateAuthTokens(pubKey types.PubKey, sessionID string) (accessToken string, refreshToken string, jti string, err error)
 
 		EncodeSessionCookie(data map[string]string) (string, error)
 		DecodeSessionCookie(cookie string) (map[string]string, error)
+
+		JWKS() models.JWKS
 	}
 
 	ServiceFacade struct {
@@ -61,10 +83,22 @@ type (
 		rpcClient           RPCProvider
 		auth                AuthProvider
 		net                 models.Network
+
+		cfg   conf.Config
+		cache *cache.Cache
+		//Request or job context
+		ctx context.Context
 	}
 )
 
-func New(rp Provider, iRp IndexerProvider, rpcClient RPCProvider, auth AuthProvider, net models.Network) *ServiceFacade {
+//WithConfig sets optional service params
+func (s *ServiceFacade) WithConfig(cfg conf.Config) *ServiceFacade {
+	s.cfg = cfg
+	return s
+}
+
+//New binds node calls and transactions to ctx, db connections should be bound by caller
+func New(ctx context.Context, rp Provider, iRp IndexerProvider, rpcClient RPCProvider, auth AuthProvider, net models.Network) *ServiceFacade {
 
 	return &ServiceFacade{
 		repoProvider:        rp,
@@ -72,5 +106,6 @@ func New(rp Provider, iRp IndexerProvider, rpcClient RPCProvider, auth AuthProvi
 		auth:                auth,
 		rpcClient:           rpcClient,
 		net:                 net,
+		ctx:                 ctx,
 	}
 }
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"tezosign/models"
+	"tezosign/services/cache"
+	"tezosign/types"
+	"time"
+)
+
+type contextRPC struct {
+	RPCProvider
+	ctx context.Context
+}
+
+func (r *contextRPC) ChainID(ctx context.Context) (string, error) {
+	r.ctx = ctx
+	return "", ctx.Err()
+}
+
+func Test_ServiceContext(t *testing.T) {
+	type ctxKey struct{}
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "request"))
+	cancel()
+
+	rpc := &contextRPC{}
+	s := New(ctx, nil, nil, rpc, nil, models.NetworkMain).WithCache(cache.New(time.Hour, 0))
+
+	_, _, err := s.estimateContractOperation(types.PubKey("edpkuNVuqdPhCsrYqkq21qW2hYTSZWMjQQjfyogoPZ2AfqCmonziNh"), "KT1LAuGLiaCF9A72qZtFvVhyzzNFg86fwFnV", models.OperationParameter{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err: %v", err)
+	}
+
+	if rpc.ctx == nil || rpc.ctx.Value(ctxKey{}) != "request" {
+		t.Error("request context is not passed to rpc")
+	}
+}
+
+func Test_IsEstimationSkipped(t *testing.T) {
+	testCases := []struct {
+		name      string
+		err       error
+		isSkipped bool
+	}{
+		{name: "Node unavailable", err: rpcError(errors.New("connection refused")), isSkipped: true},
+		{name: "Unrevealed submitter", err: rpcError(models.OperationFailure{Reason: `run_operation: [{"id":"proto.008-PtEdo2Zk.contract.unrevealed_key"}]`}), isSkipped: true},
+		{name: "Script failure", err: models.OperationFailure{Reason: "operation failed: proto.008-PtEdo2Zk.michelson_v1.script_rejected"}},
+		{name: "Limit exceeded", err: models.OperationFailure{Reason: "gas limit exceeded"}},
+		{name: "Unknown error", err: errors.New("forge error")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if isSkipped := isEstimationSkipped(tc.err); isSkipped != tc.isSkipped {
+				t.Errorf("expected %v got %v", tc.isSkipped, isSkipped)
+			}
+		})
+	}
+}
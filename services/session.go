@@ -0,0 +1,132 @@
+package services
+
+import (
+	"tezosign/common/apperrors"
+	"tezosign/conf"
+	"tezosign/models"
+	"tezosign/types"
+	"time"
+)
+
+func (s *ServiceFacade) SessionsList(userPubKey types.PubKey, currentSessionID string) (sessions []models.Session, err error) {
+	sessions, err = s.repoProvider.GetSession().GetActiveSessionsByPubKey(userPubKey)
+	if err != nil {
+		return sessions, err
+	}
+
+	for i := range sessions {
+		sessions[i].IsCurrent = sessions[i].UUID == currentSessionID
+	}
+
+	return sessions, nil
+}
+
+func (s *ServiceFacade) RevokeSession(userPubKey types.PubKey, sessionID string) (err error) {
+	session, isFound, err := s.repoProvider.GetSession().GetSession(sessionID)
+	if err != nil {
+		return err
+	}
+
+	//Do not expose sessions of other users
+	if !isFound || session.PubKey != userPubKey {
+		return apperrors.New(apperrors.ErrNotFound, "session")
+	}
+
+	if session.IsRevoked() {
+		return nil
+	}
+
+	err = s.repoProvider.Start(s.ctx)
+	if err != nil {
+		return err
+	}
+	defer s.repoProvider.RollbackUnlessCommitted()
+
+	err = s.revokeSession(session)
+	if err != nil {
+		return err
+	}
+
+	return s.repoProvider.Commit()
+}
+
+func (s *ServiceFacade) RevokeOtherSessions(userPubKey types.PubKey, currentSessionID string) (count int64, err error) {
+	sessions, err := s.repoProvider.GetSession().GetActiveSessionsByPubKey(userPubKey)
+	if err != nil {
+		return count, err
+	}
+
+	err = s.repoProvider.Start(s.ctx)
+	if err != nil {
+		return count, err
+	}
+	defer s.repoProvider.RollbackUnlessCommitted()
+
+	for i := range sessions {
+		if sessions[i].UUID == currentSessionID {
+			continue
+		}
+
+		err = s.revokeSession(sessions[i])
+		if err != nil {
+			return count, err
+		}
+
+		count++
+	}
+
+	err = s.repoProvider.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (s *ServiceFacade) IsAccessTokenRevoked(claims models.AccessTokenClaims) (isRevoked bool, err error) {
+	return s.repoProvider.GetSession().IsSessionRevoked(claims.SessionID)
+}
+
+//Remove deny-list items of already expired access tokens
+func (s *ServiceFacade) CleanupRevokedTokens() (count int64, err error) {
+	count, err = s.repoProvider.GetSession().DeleteExpiredRevokedTokens(time.Now())
+	if err != nil {
+		return count, err
+	}
+
+	return count, nil
+}
+
+//Mark session revoked, deactivate refresh token and deny all access tokens of session
+func (s *ServiceFacade) revokeSession(session models.Session) (err error) {
+	sessionRepo := s.repoProvider.GetSession()
+
+	err = sessionRepo.RevokeSession(session.ID)
+	if err != nil {
+		return err
+	}
+
+	authRepo := s.repoProvider.GetAuth()
+	token, isFound, err := authRepo.GetAuthToken(session.RefreshToken)
+	if err != nil {
+		return err
+	}
+
+	if isFound && !token.IsUsed {
+		_, err = authRepo.MarkAsUsedAuthToken(token.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	//Tokens issued before revocation live not longer than TtlJWT
+	err = sessionRepo.CreateRevokedToken(models.RevokedToken{
+		SessionUUID: session.UUID,
+		ExpiresAt:   time.Now().Add(conf.TtlJWT * time.Second),
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
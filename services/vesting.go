@@ -5,14 +5,16 @@ import (
 	"tezosign/common/apperrors"
 	"tezosign/models"
 	"tezosign/services/contract"
+	"tezosign/services/schedule"
 	"tezosign/types"
+	"time"
 
 	"blockwatch.cc/tzindex/micheline"
 )
 
 func (s *ServiceFacade) BuildVestingContractInitStorage(storageReq models.VestingContractStorageRequest) (resp []byte, err error) {
 
-	resp, err = contract.BuildVestingContractStorage(storageReq.VestingAddress, storageReq.DelegateAdmin, storageReq.Timestamp, storageReq.SecondsPerTick, storageReq.TokensPerTick)
+	resp, err = contract.BuildVestingStorage(storageReq)
 	if err != nil {
 		return resp, err
 	}
@@ -34,17 +36,13 @@ func (s *ServiceFacade) VestingContractInfo(contractID types.Address) (info mode
 	}
 
 	var delegate types.Address
-	if account.DelegateID.Valid {
-		delegateAccount, isFound, err := indexerRepo.GetAccountByID(uint64(account.DelegateID.Int64))
-		if err != nil {
-			return info, err
-		}
-
-		if !isFound {
-			return info, apperrors.New(apperrors.ErrNotFound, "delegate")
-		}
+	delegation, err := s.getDelegationInfo(account)
+	if err != nil {
+		return info, err
+	}
 
-		delegate = delegateAccount.Address
+	if delegation != nil {
+		delegate = delegation.Baker
 	}
 
 	script, storage, err := s.getContractScriptAndStorage(contractID)
@@ -70,21 +68,69 @@ func (s *ServiceFacade) VestingContractInfo(contractID types.Address) (info mode
 	openedAmount := openedTicks * storageContainer.TokensPerTick
 	vestedAmount := storageContainer.VestedTicks * storageContainer.TokensPerTick
 
+	//Shared template pays rounded down parts to each beneficiary
+	if storageContainer.Template == models.VestingTemplateShared {
+		parts := storageContainer.SplitAmount(openedAmount)
+
+		openedAmount = 0
+		for i := range storageContainer.Beneficiaries {
+			storageContainer.Beneficiaries[i].OpenedAmount = parts[i]
+			openedAmount += parts[i]
+		}
+	}
+
 	return models.VestingContractInfo{
 		Balance:       account.Balance,
 		OpenedBalance: openedAmount,
 		Delegate:      delegate,
+		Delegation:    delegation,
 		Storage: models.VestingContractStorageRequest{
-			VestingAddress: storageContainer.VestingAddress,
-			DelegateAdmin:  storageContainer.DelegateAdmin,
-			VestedAmount:   vestedAmount,
-			Timestamp:      storageContainer.Timestamp,
-			SecondsPerTick: storageContainer.SecondsPerTick,
-			TokensPerTick:  storageContainer.TokensPerTick,
+			Template:        storageContainer.Template,
+			VestingAddress:  storageContainer.VestingAddress,
+			DelegateAdmin:   storageContainer.DelegateAdmin,
+			VestedAmount:    vestedAmount,
+			Timestamp:       storageContainer.Timestamp,
+			SecondsPerTick:  storageContainer.SecondsPerTick,
+			TokensPerTick:   storageContainer.TokensPerTick,
+			Cliff:           storageContainer.Cliff,
+			RevocationAdmin: storageContainer.RevocationAdmin,
+			Beneficiaries:   storageContainer.Beneficiaries,
 		},
 	}, nil
 }
 
+//VestingSchedule projects unlocks of not vested balance
+func (s *ServiceFacade) VestingSchedule(contractID types.Address, granularity models.VestingScheduleGranularity, params models.CommonParams) (vestingSchedule models.VestingSchedule, err error) {
+
+	account, isFound, err := s.indexerRepoProvider.GetIndexer().GetAccount(contractID)
+	if err != nil {
+		return vestingSchedule, err
+	}
+
+	if !isFound {
+		return vestingSchedule, apperrors.New(apperrors.ErrNotFound, "contract")
+	}
+
+	storageContainer, err := s.getVestingContractStorage(contractID)
+	if err != nil {
+		return vestingSchedule, err
+	}
+
+	vestingSchedule, err = schedule.Project(schedule.Params{
+		Timestamp:      storageContainer.Timestamp,
+		SecondsPerTick: storageContainer.SecondsPerTick,
+		TokensPerTick:  storageContainer.TokensPerTick,
+		VestedTicks:    storageContainer.VestedTicks,
+		Cliff:          storageContainer.Cliff,
+		Balance:        account.Balance,
+	}, granularity, time.Now(), params.Limit, params.Offset)
+	if err != nil {
+		return vestingSchedule, apperrors.New(apperrors.ErrBadParam, err.Error())
+	}
+
+	return vestingSchedule, nil
+}
+
 func (s *ServiceFacade) VestingContractOperation(req models.VestingContractOperation) (param models.OperationParameter, err error) {
 
 	value, entrypoint, err := contract.VestingContractParamAndEntrypoint(req)
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"errors"
+	"tezosign/common/apperrors"
+	"tezosign/common/log"
+	"tezosign/models"
+	"tezosign/services/schedule"
+	"tezosign/types"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func (s *ServiceFacade) VestingClaimRules(contractAddress types.Address) (rules []models.VestingClaimRule, err error) {
+	c, isFound, err := s.repoProvider.GetContract().GetContract(contractAddress)
+	if err != nil {
+		return rules, err
+	}
+
+	if !isFound {
+		return rules, apperrors.New(apperrors.ErrNotFound, "contract")
+	}
+
+	return s.repoProvider.GetVesting().GetClaimRules(c.ID)
+}
+
+//SaveVestingClaimRule creates or replaces auto claim rule of contract vesting, active rule requires proposer to be contract owner
+func (s *ServiceFacade) SaveVestingClaimRule(contractAddress types.Address, rule models.VestingClaimRule) (err error) {
+	c, isFound, err := s.repoProvider.GetContract().GetContract(contractAddress)
+	if err != nil {
+		return err
+	}
+
+	if !isFound {
+		return apperrors.New(apperrors.ErrNotFound, "contract")
+	}
+
+	vestingRepo := s.repoProvider.GetVesting()
+	vesting, isFound, err := vestingRepo.GetVesting(c.ID, rule.VestingAddress)
+	if err != nil {
+		return err
+	}
+
+	if !isFound {
+		return apperrors.New(apperrors.ErrNotFound, "vesting")
+	}
+
+	//Claims are proposed by single network key, it must be able to propose for contract
+	if rule.IsActive {
+		proposer, err := s.vestingClaimProposer()
+		if err != nil {
+			return apperrors.New(apperrors.ErrNotAllowed, "vesting claim proposer not configured")
+		}
+
+		isOwner, err := s.GetUserAllowance(proposer, contractAddress)
+		if err != nil {
+			return err
+		}
+
+		if !isOwner {
+			return apperrors.New(apperrors.ErrNotAllowed, "vesting claim proposer not contains in storage")
+		}
+	}
+
+	rule.VestingID = vesting.ID
+
+	return vestingRepo.SaveClaimRule(rule)
+}
+
+func (s *ServiceFacade) RemoveVestingClaimRule(contractAddress types.Address, vestingAddress types.Address) (err error) {
+	c, isFound, err := s.repoProvider.GetContract().GetContract(contractAddress)
+	if err != nil {
+		return err
+	}
+
+	if !isFound {
+		return apperrors.New(apperrors.ErrNotFound, "contract")
+	}
+
+	vestingRepo := s.repoProvider.GetVesting()
+	vesting, isFound, err := vestingRepo.GetVesting(c.ID, vestingAddress)
+	if err != nil {
+		return err
+	}
+
+	if !isFound {
+		return apperrors.New(apperrors.ErrNotFound, "vesting")
+	}
+
+	return vestingRepo.DeleteClaimRule(vesting.ID)
+}
+
+//ProposeVestingClaims creates vest requests for due rules on behalf of configured proposer
+func (s *ServiceFacade) ProposeVestingClaims() (count uint64, err error) {
+	proposer, err := s.vestingClaimProposer()
+	if err != nil {
+		return count, err
+	}
+
+	vestingRepo := s.repoProvider.GetVesting()
+	rules, err := vestingRepo.GetActiveClaimRules()
+	if err != nil {
+		return count, err
+	}
+
+	for i := range rules {
+		req, isDue, err := s.vestingClaim(rules[i])
+		if err != nil {
+			log.Ctx(s.ctx).Error("vesting claim check failed", zap.String("vesting", rules[i].VestingAddress.String()), zap.Error(err))
+			continue
+		}
+
+		if !isDue {
+			continue
+		}
+
+		request, err := s.contractOperation(proposer, req, true)
+		if err != nil {
+			log.Ctx(s.ctx).Error("vesting claim proposal failed", zap.String("vesting", rules[i].VestingAddress.String()), zap.Error(err))
+			continue
+		}
+
+		err = vestingRepo.UpdateClaimRuleRequest(rules[i].ID, request.Hash, time.Now())
+		if err != nil {
+			return count, err
+		}
+
+		count++
+	}
+
+	return count, nil
+}
+
+func (s *ServiceFacade) vestingClaimProposer() (proposer types.PubKey, err error) {
+	network, isFound := s.cfg.Network(s.net)
+	if !isFound || network.VestingClaimProposer == "" {
+		return proposer, errors.New("vesting claim proposer not configured")
+	}
+
+	proposer = types.PubKey(network.VestingClaimProposer)
+	if err = proposer.Validate(); err != nil {
+		return proposer, err
+	}
+
+	return proposer, nil
+}
+
+//vestingClaim builds vest request when rule is due, previous claim must be processed
+func (s *ServiceFacade) vestingClaim(rule models.VestingClaimRule) (req models.ContractOperationRequest, isDue bool, err error) {
+	c, err := s.repoProvider.GetContract().GetContractByID(rule.ContractID)
+	if err != nil {
+		return req, false, err
+	}
+
+	if rule.RequestHash != nil {
+		request, isFound, err := s.repoProvider.GetContract().GetPayloadByHash(*rule.RequestHash)
+		if err != nil {
+			return req, false, err
+		}
+
+		if isFound && request.Status == models.StatusPending {
+			return req, false, nil
+		}
+	}
+
+	account, isFound, err := s.indexerRepoProvider.GetIndexer().GetAccount(rule.VestingAddress)
+	if err != nil {
+		return req, false, err
+	}
+
+	if !isFound {
+		return req, false, apperrors.New(apperrors.ErrNotFound, "vesting")
+	}
+
+	storage, err := s.getVestingContractStorage(rule.VestingAddress)
+	if err != nil {
+		return req, false, err
+	}
+
+	params := schedule.Params{
+		Timestamp:      storage.Timestamp,
+		SecondsPerTick: storage.SecondsPerTick,
+		TokensPerTick:  storage.TokensPerTick,
+		VestedTicks:    storage.VestedTicks,
+		Cliff:          storage.Cliff,
+		Balance:        account.Balance,
+	}
+
+	if err = params.Validate(); err != nil {
+		return req, false, err
+	}
+
+	ticks, isDue := schedule.Claim(params, rule, time.Now())
+	if !isDue {
+		return req, false, nil
+	}
+
+	req = models.ContractOperationRequest{
+		ContractID: c.Address,
+		Type:       models.VestingVest,
+		VestingID:  rule.VestingAddress,
+		Ticks:      ticks,
+	}
+
+	if err = req.Validate(); err != nil {
+		return req, false, err
+	}
+
+	return req, true, nil
+}
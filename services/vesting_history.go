@@ -0,0 +1,133 @@
+package services
+
+import (
+	"tezosign/common/log"
+	"tezosign/models"
+	"tezosign/services/contract"
+	"tezosign/types"
+
+	"go.uber.org/zap"
+)
+
+const vestingOperationsCursor = "vesting_operations:"
+
+//SyncVestingOperations ingests vest and setDelegate calls of registered vestings
+func (s *ServiceFacade) SyncVestingOperations() (count uint64, err error) {
+	err = s.repoProvider.Start(s.ctx)
+	if err != nil {
+		return count, err
+	}
+	defer s.repoProvider.RollbackUnlessCommitted()
+
+	addresses, err := s.repoProvider.GetVesting().GetVestingAddresses()
+	if err != nil {
+		return count, err
+	}
+
+	for i := range addresses {
+		synced, err := s.syncVestingOperations(addresses[i])
+		if err != nil {
+			return count, err
+		}
+
+		count += synced
+	}
+
+	err = s.repoProvider.Commit()
+	if err != nil {
+		return count, err
+	}
+
+	return count, nil
+}
+
+func (s *ServiceFacade) syncVestingOperations(address types.Address) (count uint64, err error) {
+	vestingRepo := s.repoProvider.GetVesting()
+	cursorRepo := s.repoProvider.GetCursor()
+
+	cursor, _, err := cursorRepo.GetCursor(vestingOperationsCursor + address.String())
+	if err != nil {
+		return count, err
+	}
+
+	transactions, err := s.indexerRepoProvider.GetIndexer().GetContractOperations(address, cursor.Level, "")
+	if err != nil {
+		return count, err
+	}
+
+	if len(transactions) == 0 {
+		return count, nil
+	}
+
+	storage, err := s.getVestingContractStorage(address)
+	if err != nil {
+		log.Ctx(s.ctx).Error("vesting storage read failed", zap.String("vesting", address.String()), zap.Error(err))
+		return count, nil
+	}
+
+	operations := make([]models.VestingOperation, 0, len(transactions))
+	for i := range transactions {
+		if transactions[i].Status != models.OperationStatusApplied || transactions[i].RawParameters == nil {
+			continue
+		}
+
+		op, err := contract.ParseVestingOperation(transactions[i].Entrypoint, transactions[i].RawParameters.MichelinePrim())
+		if err != nil {
+			log.Ctx(s.ctx).Debug("skip vesting operation", zap.String("tx", transactions[i].OpHash), zap.Error(err))
+			continue
+		}
+
+		operations = append(operations, models.VestingOperation{
+			VestingAddress: address,
+			Type:           op.Type,
+			Ticks:          op.Ticks,
+			Amount:         op.Ticks * storage.TokensPerTick,
+			Delegate:       op.Delegate,
+			Level:          transactions[i].Level,
+			OpHash:         transactions[i].OpHash,
+			Nonce:          transactions[i].Nonce,
+			Timestamp:      transactions[i].Timestamp,
+		})
+	}
+
+	err = vestingRepo.SaveOperations(operations)
+	if err != nil {
+		return count, err
+	}
+
+	err = cursorRepo.SaveCursor(vestingOperationsCursor+address.String(), transactions[len(transactions)-1].Level)
+	if err != nil {
+		return count, err
+	}
+
+	recordedTicks, err := vestingRepo.GetVestedTicks(address)
+	if err != nil {
+		return count, err
+	}
+
+	if reconciliation := models.NewVestingReconciliation(storage.VestedTicks, recordedTicks, storage.TokensPerTick); !reconciliation.IsConsistent {
+		log.Ctx(s.ctx).Warn("vesting vested counter mismatch", zap.String("vesting", address.String()),
+			zap.Uint64("vested_ticks", reconciliation.VestedTicks), zap.Uint64("recorded_ticks", reconciliation.RecordedTicks))
+	}
+
+	return uint64(len(operations)), nil
+}
+
+func (s *ServiceFacade) VestingHistory(vestingAddress types.Address, params models.CommonParams) (operations []models.VestingOperation, err error) {
+	return s.repoProvider.GetVesting().GetOperations(vestingAddress, params)
+}
+
+//VestingReconciliation compares on-chain vested counter with recorded vest calls
+func (s *ServiceFacade) VestingReconciliation(vestingAddress types.Address) (reconciliation models.VestingReconciliation, err error) {
+	storage, err := s.getVestingContractStorage(vestingAddress)
+	if err != nil {
+		return reconciliation, err
+	}
+
+	recordedTicks, err := s.repoProvider.GetVesting().GetVestedTicks(vestingAddress)
+	if err != nil {
+		return reconciliation, err
+	}
+
+	return models.NewVestingReconciliation(storage.VestedTicks, recordedTicks, storage.TokensPerTick), nil
+}
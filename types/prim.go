@@ -5,6 +5,7 @@ import (
 	"database/sql/driver"
 	"errors"
 	"fmt"
+	"io"
 	"math/big"
 
 	"blockwatch.cc/tzindex/micheline"
@@ -16,67 +17,96 @@ const (
 	MichelineTypeBytes       = 32
 	MichelineTypeString      = 64
 	MichelineTypeArray       = 96
+	MichelineTypePrim        = 128
 
 	AnnotationTypeField    = 64
 	AnnotationTypeType     = 128
 	AnnotationTypeVariable = 192
 )
 
+//Markers of length stored as 7 bit int after tag
+const (
+	valueLenMarker  = 0x1F
+	argsLenMarker   = 0x07
+	annotsLenMarker = 0x0F
+	annoLenMarker   = 0x3F
+)
+
+//Max bytes of 7 bit encoded int32
+const max7BitIntBytes = 5
+
 type TZKTPrim micheline.Prim
 
 func (p *TZKTPrim) UnmarshalBinary(data []byte) (err error) {
 	return p.DecodeBuffer(bytes.NewBuffer(data))
 }
 
+func (p TZKTPrim) MarshalBinary() ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+
+	err := p.EncodeBuffer(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
 func (p TZKTPrim) MichelinePrim() *micheline.Prim {
 	prim := micheline.Prim(p)
 	return &prim
 }
 
-//TODO add more OpCodes
-func PrimTypeFromTypeCode(o micheline.OpCode) micheline.PrimType {
-
-	switch o {
-	case micheline.D_PAIR, micheline.T_PAIR, micheline.T_OR, micheline.T_LAMBDA:
-		return micheline.PrimBinary
-	case micheline.K_STORAGE, micheline.K_PARAMETER, micheline.T_LIST, micheline.T_OPTION, micheline.D_LEFT, micheline.D_RIGHT, micheline.D_SOME,
-		micheline.I_NIL, micheline.I_DROP, micheline.I_SOME:
-		return micheline.PrimUnary
-	case micheline.T_KEY, micheline.T_STRING, micheline.T_NAT, micheline.T_INT, micheline.T_MUTEZ,
-		micheline.T_TIMESTAMP, micheline.T_SIGNATURE, micheline.T_UNIT, micheline.T_ADDRESS, micheline.T_KEY_HASH, micheline.T_OPERATION,
-		micheline.D_NONE:
-		return micheline.PrimNullary
+//PrimTypeFromArgs returns prim type by args count, prims with more than 2 args are variadic with annotations
+func PrimTypeFromArgs(argsCount int, hasAnnots bool) micheline.PrimType {
+	var primType micheline.PrimType
+
+	switch argsCount {
+	case 0:
+		primType = micheline.PrimNullary
+	case 1:
+		primType = micheline.PrimUnary
+	case 2:
+		primType = micheline.PrimBinary
 	default:
-		return micheline.PrimBytes
+		return micheline.PrimVariadicAnno
+	}
+
+	//Anno type follows plain one
+	if hasAnnots {
+		primType++
 	}
 
+	return primType
 }
 
 //Implementation of TZKT decoder
 //https://github.com/baking-bad/netezos/blob/master/Netezos/Encoding/Micheline/Micheline.cs#L72
 func (p *TZKTPrim) DecodeBuffer(buf *bytes.Buffer) (err error) {
-	tag, _ := buf.ReadByte()
+	tag, err := buf.ReadByte()
+	if err != nil {
+		return err
+	}
 
-	if tag >= 0x80 {
+	if tag >= MichelineTypePrim {
 
 		bt, err := buf.ReadByte()
 		if err != nil {
 			return err
 		}
 
+		//Prims of newer protocols are kept with raw opcode like micheline decoder does, interpreting them is up to consumer
 		p.OpCode = micheline.OpCode(bt)
 
-		p.Type = PrimTypeFromTypeCode(p.OpCode)
-
 		//Init int field
 		if p.OpCode == micheline.T_INT || p.OpCode == micheline.T_NAT {
 			p.Int = big.NewInt(0)
 		}
 
-		var args = (tag & 0x70) >> 4
+		var args = int(tag&0x70) >> 4
 
 		if args > 0 {
-			if args == 0x07 {
+			if args == argsLenMarker {
 				args, err = Read7BitInt(buf)
 				if err != nil {
 					return err
@@ -101,10 +131,10 @@ func (p *TZKTPrim) DecodeBuffer(buf *bytes.Buffer) (err error) {
 			p.Args = arr
 		}
 
-		var annotsLen = tag & 0x0F
+		var annotsLen = int(tag & 0x0F)
 
 		if annotsLen > 0 {
-			if annotsLen == 0x0F {
+			if annotsLen == annotsLenMarker {
 				annotsLen, err = Read7BitInt(buf)
 				if err != nil {
 					return err
@@ -121,16 +151,15 @@ func (p *TZKTPrim) DecodeBuffer(buf *bytes.Buffer) (err error) {
 				annots = append(annots, anno)
 			}
 
-			//Change to Anno type
-			p.Type = p.Type + 1
-
 			p.Anno = annots
 		}
 
+		p.Type = PrimTypeFromArgs(len(p.Args), len(p.Anno) > 0)
+
 	} else {
 
-		cnt := tag & 0x1F
-		if cnt == 0x1F {
+		cnt := int(tag & 0x1F)
+		if cnt == valueLenMarker {
 			cnt, err = Read7BitInt(buf)
 			if err != nil {
 				return err
@@ -164,24 +193,32 @@ func (p *TZKTPrim) DecodeBuffer(buf *bytes.Buffer) (err error) {
 		case MichelineTypeBytes:
 			p.Type = micheline.PrimBytes
 			p.OpCode = micheline.T_BYTES
-			p.Bytes = buf.Next(int(cnt))
+			p.Bytes, err = readBytes(buf, cnt)
+			if err != nil {
+				return err
+			}
 
 		case MichelineTypeInt:
 			p.Type = micheline.PrimInt
 			p.OpCode = micheline.T_INT
 
-			//Revers bytes array to convert from little endian to big endian
-			bt := buf.Next(int(cnt))
-			for i, j := 0, len(bt)-1; i < j; i, j = i+1, j-1 {
-				bt[i], bt[j] = bt[j], bt[i]
+			bt, err := readBytes(buf, cnt)
+			if err != nil {
+				return err
 			}
 
-			p.Int = big.NewInt(0).SetBytes(bt)
+			p.Int = decodeInt(bt)
 
 		case MichelineTypeString:
 			p.Type = micheline.PrimString
 			p.OpCode = micheline.T_STRING
-			p.String = string(buf.Next(int(cnt)))
+
+			bt, err := readBytes(buf, cnt)
+			if err != nil {
+				return err
+			}
+
+			p.String = string(bt)
 
 		default:
 			return errors.New("Wrong tag")
@@ -191,37 +228,180 @@ func (p *TZKTPrim) DecodeBuffer(buf *bytes.Buffer) (err error) {
 	return nil
 }
 
-func Read7BitInt(data *bytes.Buffer) (res uint8, err error) {
+//Implementation of TZKT encoder
+//https://github.com/baking-bad/netezos/blob/master/Netezos/Encoding/Micheline/Micheline.cs#L20
+func (p TZKTPrim) EncodeBuffer(buf *bytes.Buffer) (err error) {
+	switch p.Type {
+	case micheline.PrimInt:
+		if p.Int == nil {
+			return errors.New("empty int value")
+		}
+
+		bt := encodeInt(p.Int)
+		writeValueTag(buf, MichelineTypeInt, len(bt))
+		buf.Write(bt)
+
+	case micheline.PrimString:
+		writeValueTag(buf, MichelineTypeString, len(p.String))
+		buf.WriteString(p.String)
+
+	case micheline.PrimBytes:
+		writeValueTag(buf, MichelineTypeBytes, len(p.Bytes))
+		buf.Write(p.Bytes)
+
+	case micheline.PrimSequence:
+		writeValueTag(buf, MichelineTypeArray, len(p.Args))
+
+		err = encodeArgs(buf, p.Args)
+		if err != nil {
+			return err
+		}
+
+	case micheline.PrimNullary, micheline.PrimNullaryAnno, micheline.PrimUnary, micheline.PrimUnaryAnno,
+		micheline.PrimBinary, micheline.PrimBinaryAnno, micheline.PrimVariadicAnno:
+		tag := MichelineTypePrim | lenField(len(p.Args), argsLenMarker)<<4 | lenField(len(p.Anno), annotsLenMarker)
+		buf.WriteByte(tag)
+		buf.WriteByte(byte(p.OpCode))
+
+		if len(p.Args) >= argsLenMarker {
+			Write7BitInt(buf, len(p.Args))
+		}
+
+		err = encodeArgs(buf, p.Args)
+		if err != nil {
+			return err
+		}
+
+		if len(p.Anno) >= annotsLenMarker {
+			Write7BitInt(buf, len(p.Anno))
+		}
+
+		for i := range p.Anno {
+			err = WriteAnno(buf, p.Anno[i])
+			if err != nil {
+				return err
+			}
+		}
+
+	default:
+		return fmt.Errorf("unknown prim type %d", p.Type)
+	}
+
+	return nil
+}
+
+func encodeArgs(buf *bytes.Buffer, args []*micheline.Prim) (err error) {
+	for i := range args {
+		if args[i] == nil {
+			return errors.New("empty prim arg")
+		}
+
+		err = TZKTPrim(*args[i]).EncodeBuffer(buf)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//Length is stored in tag bits, bigger one is written after tag
+func lenField(length int, marker byte) byte {
+	if length >= int(marker) {
+		return marker
+	}
+
+	return byte(length)
+}
+
+func writeValueTag(buf *bytes.Buffer, valueType byte, length int) {
+	buf.WriteByte(valueType | lenField(length, valueLenMarker))
+
+	if length >= valueLenMarker {
+		Write7BitInt(buf, length)
+	}
+}
+
+func readBytes(buf *bytes.Buffer, length int) ([]byte, error) {
+	if buf.Len() < length {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	//Copy to not share buffer memory
+	return append([]byte{}, buf.Next(length)...), nil
+}
+
+//Ints are little endian two's complement like .NET BigInteger
+func decodeInt(bt []byte) *big.Int {
+	//Revers bytes array to convert from little endian to big endian
+	be := make([]byte, len(bt))
+	for i := range bt {
+		be[len(bt)-1-i] = bt[i]
+	}
+
+	value := new(big.Int).SetBytes(be)
+
+	//Negative value has sign bit
+	if len(be) > 0 && be[0]&0x80 != 0 {
+		value.Sub(value, new(big.Int).Lsh(big.NewInt(1), uint(len(be)*8)))
+	}
+
+	return value
+}
+
+func encodeInt(value *big.Int) []byte {
+	//Negative value is inverted magnitude of -value-1
+	magnitude, fill := value, byte(0)
+	if value.Sign() < 0 {
+		magnitude, fill = new(big.Int).Sub(new(big.Int).Neg(value), big.NewInt(1)), 0xFF
+	}
+
+	be := magnitude.Bytes()
+	bt := make([]byte, len(be), len(be)+1)
+	for i := range be {
+		bt[len(be)-1-i] = be[i] ^ fill
+	}
+
+	//Keep sign bit
+	if len(bt) == 0 || bt[len(bt)-1]&0x80 != fill&0x80 {
+		bt = append(bt, fill)
+	}
+
+	return bt
+}
+
+func Read7BitInt(data *bytes.Buffer) (res int, err error) {
 
 	var b byte
 
-	for bits := 0; bits < 28; {
+	for i := 0; i < max7BitIntBytes; i++ {
 		b, err = data.ReadByte()
 		if err != nil {
 			return res, err
 		}
 
-		res |= (b & 0x7F) << bits
-		bits += 7
+		//Last byte holds 4 bits only
+		if i == max7BitIntBytes-1 && b > 0x0F {
+			return res, errors.New("Int32 overflow")
+		}
+
+		res |= int(b&0x7F) << (7 * i)
 
 		if b < 0x80 {
 			return res, nil
 		}
-
 	}
 
-	b, err = data.ReadByte()
-	if err != nil {
-		return res, err
-	}
+	return res, nil
+}
 
-	if b > 0x0F {
-		return res, errors.New("Int32 overflow")
+func Write7BitInt(buf *bytes.Buffer, value int) {
+	for value >= 0x80 {
+		buf.WriteByte(byte(value) | 0x80)
+		value >>= 7
 	}
 
-	res |= b << 28
-
-	return res, nil
+	buf.WriteByte(byte(value))
 }
 
 func ReadAnno(buf *bytes.Buffer) (anno string, err error) {
@@ -230,9 +410,9 @@ func ReadAnno(buf *bytes.Buffer) (anno string, err error) {
 		return anno, err
 	}
 
-	var cnt = tag & 0x3F
+	var cnt = int(tag & 0x3F)
 
-	if cnt == 0x3F {
+	if cnt == annoLenMarker {
 		cnt, err = Read7BitInt(buf)
 		if err != nil {
 			return anno, err
@@ -253,7 +433,42 @@ func ReadAnno(buf *bytes.Buffer) (anno string, err error) {
 		return anno, errors.New("invalid annotation tag")
 	}
 
-	return fmt.Sprint(annoPrefix, string(buf.Next(int(cnt)))), nil
+	bt, err := readBytes(buf, cnt)
+	if err != nil {
+		return anno, err
+	}
+
+	return fmt.Sprint(annoPrefix, string(bt)), nil
+}
+
+func WriteAnno(buf *bytes.Buffer, anno string) error {
+	if len(anno) == 0 {
+		return errors.New("empty annotation")
+	}
+
+	var annoType byte
+
+	switch anno[:1] {
+	case micheline.VarAnnoPrefix:
+		annoType = AnnotationTypeField
+	case micheline.TypeAnnoPrefix:
+		annoType = AnnotationTypeType
+	case micheline.FieldAnnoPrefix:
+		annoType = AnnotationTypeVariable
+	default:
+		return fmt.Errorf("invalid annotation %s", anno)
+	}
+
+	value := anno[1:]
+	buf.WriteByte(annoType | lenField(len(value), annoLenMarker))
+
+	if len(value) >= annoLenMarker {
+		Write7BitInt(buf, len(value))
+	}
+
+	buf.WriteString(value)
+
+	return nil
 }
 
 func (p *TZKTPrim) Scan(value interface{}) (err error) {
@@ -279,5 +494,5 @@ func (p *TZKTPrim) Scan(value interface{}) (err error) {
 }
 func (p TZKTPrim) Value() (driver.Value, error) {
 
-	return []byte{}, errors.New("Not implemented")
+	return p.MarshalBinary()
 }
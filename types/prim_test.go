@@ -1,7 +1,13 @@
 package types
 
 import (
+	"bytes"
 	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"math/rand"
+	"path/filepath"
 	"testing"
 
 	"blockwatch.cc/tzindex/micheline"
@@ -78,3 +84,266 @@ func TestTZKTPrim_UnmarshalBinary(t *testing.T) {
 		})
 	}
 }
+
+func TestTZKTPrim_MarshalBinary(t *testing.T) {
+	testCases := []struct {
+		name string
+		prim string
+		tzkt string
+	}{
+		{
+			name: "Negative int",
+			prim: `{"int":"-129"}`,
+			tzkt: "027fff",
+		},
+		{
+			name: "Sign byte",
+			prim: `{"int":"128"}`,
+			tzkt: "028000",
+		},
+		{
+			name: "Long string",
+			prim: `{"string":"0123456789012345678901234567890123456789"}`,
+			tzkt: "5f28" + hex.EncodeToString([]byte("0123456789012345678901234567890123456789")),
+		},
+		{
+			name: "Variadic pair",
+			prim: `{"prim":"Pair","args":[{"int":"1"},{"int":"2"},{"int":"3"}]}`,
+			tzkt: "b007010101020103",
+		},
+		{
+			name: "Ticket instruction",
+			prim: `{"prim":"TICKET","annots":["@t"]}`,
+			tzkt: "8188c174",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			prim := micheline.Prim{}
+
+			err := prim.UnmarshalJSON([]byte(test.prim))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			bt, err := TZKTPrim(prim).MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if hex.EncodeToString(bt) != test.tzkt {
+				t.Errorf("results %x == %s", bt, test.tzkt)
+			}
+
+			checkTZKTRoundTrip(t, &prim)
+		})
+	}
+}
+
+func TestTZKTPrim_Corpus(t *testing.T) {
+	files, err := filepath.Glob("../resources/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		code := micheline.Code{}
+		err = code.UnmarshalJSON(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, prim := range []*micheline.Prim{code.Param, code.Storage, code.Code} {
+			checkTZKTRoundTrip(t, prim)
+		}
+	}
+
+	//Mainnet scripts with storage
+	files, err = filepath.Glob("testdata/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		script := micheline.Script{}
+		err = json.Unmarshal(data, &script)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, prim := range []*micheline.Prim{script.Code.Param, script.Code.Storage, script.Code.Code, script.Storage} {
+			checkTZKTRoundTrip(t, prim)
+		}
+	}
+
+	//TzKT raw values
+	for _, raw := range []string{
+		"a0070105a0070103633f210086e0a56668788415cc426351dfbb387164cd9366f9f76e87824a4e8115c2b3903f21009a3c622cb845c95c5c68800e4e1b80d0dbaec82055a33ad871103f8590c773dd3f210013b3977385b5be4c8996da7956126da11837b297823551d016df033eb8e6be9f",
+		"a007a007360000597ab90d925c9b6e99cadd721e8b1cd60d8b907a360000597ab90d925c9b6e99cadd721e8b1cd60d8b907aa0070100a00704bf685360a00702e80302e803",
+	} {
+		bt, err := hex.DecodeString(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		prim := TZKTPrim{}
+		err = prim.UnmarshalBinary(bt)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		encoded, err := prim.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if hex.EncodeToString(encoded) != raw {
+			t.Errorf("results %x == %s", encoded, raw)
+		}
+	}
+}
+
+func TestTZKTPrim_UnknownOpCode(t *testing.T) {
+	//view "get" unit nat { CDR } section of newer protocols
+	view := &micheline.Prim{
+		Type:   micheline.PrimVariadicAnno,
+		OpCode: micheline.OpCode(0x91),
+		Args: []*micheline.Prim{
+			{Type: micheline.PrimString, OpCode: micheline.T_STRING, String: "get"},
+			{Type: micheline.PrimNullary, OpCode: micheline.T_UNIT},
+			{Type: micheline.PrimNullary, OpCode: micheline.T_NAT},
+			{Type: micheline.PrimSequence, Args: []*micheline.Prim{{Type: micheline.PrimNullary, OpCode: micheline.I_CDR}}},
+		},
+	}
+
+	bt := checkTZKTRoundTrip(t, view)
+
+	decoded := TZKTPrim{}
+	err := decoded.UnmarshalBinary(bt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.OpCode != view.OpCode || len(decoded.Args) != len(view.Args) {
+		t.Errorf("decoded %+v", decoded)
+	}
+}
+
+func TestTZKTPrim_Fuzz(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 2000; i++ {
+		prim := randomPrim(rnd, 4)
+
+		bt := checkTZKTRoundTrip(t, prim)
+		if t.Failed() {
+			return
+		}
+
+		//Corrupted data should fail without panic
+		corrupted := append([]byte{}, bt[:rnd.Intn(len(bt)+1)]...)
+		if len(corrupted) > 0 {
+			corrupted[rnd.Intn(len(corrupted))] ^= byte(rnd.Intn(255) + 1)
+		}
+
+		_ = (&TZKTPrim{}).UnmarshalBinary(corrupted)
+	}
+}
+
+//checkTZKTRoundTrip compares prim binary forms before and after TZKT encoding
+func checkTZKTRoundTrip(t *testing.T, prim *micheline.Prim) []byte {
+	expected, err := prim.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bt, err := TZKTPrim(*prim).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded := TZKTPrim{}
+	err = decoded.UnmarshalBinary(bt)
+	if err != nil {
+		t.Fatalf("decode %x: %s", bt, err.Error())
+	}
+
+	result, err := decoded.MichelinePrim().MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(result, expected) {
+		t.Errorf("round trip of %x: results %x == %x", bt, result, expected)
+	}
+
+	return bt
+}
+
+func randomPrim(rnd *rand.Rand, depth int) *micheline.Prim {
+	kind := rnd.Intn(5)
+	if depth == 0 {
+		kind = rnd.Intn(3)
+	}
+
+	switch kind {
+	case 0:
+		value := new(big.Int).Rand(rnd, new(big.Int).Lsh(big.NewInt(1), uint(rnd.Intn(300)+1)))
+		if rnd.Intn(2) == 0 {
+			value.Neg(value)
+		}
+		return &micheline.Prim{Type: micheline.PrimInt, Int: value}
+	case 1:
+		return &micheline.Prim{Type: micheline.PrimString, String: randomName(rnd, 80)}
+	case 2:
+		value := make([]byte, rnd.Intn(80))
+		rnd.Read(value)
+		return &micheline.Prim{Type: micheline.PrimBytes, Bytes: value}
+	case 3:
+		args := make([]*micheline.Prim, rnd.Intn(10))
+		for i := range args {
+			args[i] = randomPrim(rnd, depth-1)
+		}
+		return &micheline.Prim{Type: micheline.PrimSequence, Args: args}
+	}
+
+	var args []*micheline.Prim
+	for i := rnd.Intn(10) - 2; i > 0; i-- {
+		args = append(args, randomPrim(rnd, depth-1))
+	}
+
+	var annots []string
+	for i := rnd.Intn(20) - 4; i > 0; i-- {
+		prefix := []string{micheline.VarAnnoPrefix, micheline.TypeAnnoPrefix, micheline.FieldAnnoPrefix}[rnd.Intn(3)]
+		annots = append(annots, prefix+randomName(rnd, 70))
+	}
+
+	return &micheline.Prim{
+		Type:   PrimTypeFromArgs(len(args), len(annots) > 0),
+		OpCode: micheline.OpCode(rnd.Intn(int(micheline.I_GET_AND_UPDATE) + 1)),
+		Args:   args,
+		Anno:   annots,
+	}
+}
+
+func randomName(rnd *rand.Rand, maxLen int) string {
+	const letters = "abcdefghijklmnopqrstuvwxyz_0123456789"
+
+	name := make([]byte, rnd.Intn(maxLen)+1)
+	for i := range name {
+		name[i] = letters[rnd.Intn(len(letters))]
+	}
+
+	return string(name)
+}